@@ -107,6 +107,8 @@ func getAgentConfig(opt *agentOption) (*common.Configuration, error) {
 		SysPath:                 opt.SysPath,
 		MountPath:               opt.MountPath,
 		DiscoverInterval:        opt.Interval,
+		EventDriven:             opt.EventDriven,
+		ResyncInterval:          opt.Resync,
 		LogicalVolumeNamePrefix: opt.LVNamePrefix,
 		RegExp:                  opt.RegExp,
 	}
@@ -28,6 +28,8 @@ type agentOption struct {
 	SysPath      string
 	MountPath    string
 	Interval     int
+	EventDriven  bool
+	Resync       int
 	LVNamePrefix string
 	RegExp       string
 }
@@ -39,6 +41,8 @@ func (option *agentOption) addFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&option.SysPath, "path.sysfs", "/sys", "Path of sysfs mountpoint")
 	fs.StringVar(&option.MountPath, "path.mount", "/mnt/open-local", "Path that specifies mount path of local volumes")
 	fs.IntVar(&option.Interval, "interval", common.DefaultInterval, "The interval that the agent checks the local storage at one time")
+	fs.BoolVar(&option.EventDriven, "event-driven", true, "Rediscover the local storage on kernel uevents and mount table changes, falling back to the interval if they can not be watched")
+	fs.IntVar(&option.Resync, "resync-interval", common.DefaultResyncInterval, "The interval that the agent discovers all the local storage at one time when event driven")
 	fs.StringVar(&option.LVNamePrefix, "lvname", "local", "The prefix of Logical Volume Name created by open-local")
-	fs.StringVar(&option.RegExp, "regexp", "^(s|v|xv)d[a-z]+$", "regexp is used to filter device names")
+	fs.StringVar(&option.RegExp, "regexp", common.DefaultRegExp, "regexp is used to filter device names")
 }
@@ -20,12 +20,17 @@ import (
 	"fmt"
 	"time"
 
+	localtype "github.com/alibaba/open-local/pkg"
 	"github.com/alibaba/open-local/pkg/controller"
 	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
 	informers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
 	"github.com/alibaba/open-local/pkg/signals"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
@@ -73,6 +78,30 @@ func Start(opt *controllerOption) error {
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
 	localInformerFactory := informers.NewSharedInformerFactory(localClient, time.Second*30)
 
+	if opt.EnableStorageCapacity {
+		if opt.ThinOvercommitRatio < 1.0 {
+			return fmt.Errorf("thin overcommit ratio %f must not be less than 1.0", opt.ThinOvercommitRatio)
+		}
+		dynamicClient, err := dynamic.NewForConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("Error building dynamic client: %s", err.Error())
+		}
+		resource := controller.CapacityResource(opt.StorageCapacityVersion)
+		dynamicInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, time.Second*30, opt.StorageCapacityNamespace, func(options *metav1.ListOptions) {
+			options.LabelSelector = labels.SelectorFromSet(labels.Set{
+				localtype.LabelCapacityDriverName: localtype.ProvisionerName,
+				localtype.LabelCapacityManagedBy:  localtype.CapacityManagedBy,
+			}).String()
+		})
+		capacityController := controller.NewCapacityController(dynamicClient, resource, opt.StorageCapacityNamespace, opt.ThinOvercommitRatio, localInformerFactory.Csi().V1alpha1().NodeLocalStorages(), kubeInformerFactory.Storage().V1(), kubeInformerFactory.Core().V1(), dynamicInformerFactory.ForResource(resource))
+		dynamicInformerFactory.Start(stopCh)
+		go func() {
+			if err := capacityController.Run(2, stopCh); err != nil {
+				log.Errorf("Error running capacity controller: %s", err.Error())
+			}
+		}()
+	}
+
 	controller := controller.NewController(kubeClient, localClient, kubeInformerFactory.Core().V1().Nodes(), localInformerFactory.Csi().V1alpha1().NodeLocalStorages(), localInformerFactory.Csi().V1alpha1().NodeLocalStorageInitConfigs(), opt.InitConfig)
 
 	kubeInformerFactory.Start(stopCh)
@@ -17,17 +17,36 @@ limitations under the License.
 package controller
 
 import (
+	"os"
+
+	localtype "github.com/alibaba/open-local/pkg"
 	"github.com/spf13/pflag"
 )
 
 type controllerOption struct {
-	Master     string
-	Kubeconfig string
-	InitConfig string
+	Master                   string
+	Kubeconfig               string
+	InitConfig               string
+	EnableStorageCapacity    bool
+	StorageCapacityNamespace string
+	StorageCapacityVersion   string
+	ThinOvercommitRatio      float64
 }
 
 func (option *controllerOption) addFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&option.Kubeconfig, "kubeconfig", option.Kubeconfig, "Path to the kubeconfig file to use.")
 	fs.StringVar(&option.Master, "master", option.Master, "URL/IP for master.")
 	fs.StringVar(&option.InitConfig, "initconfig", "open-local", "initconfig is NodeLocalStorageInitConfig(CRD) for controller to create NodeLocalStorage")
+	fs.BoolVar(&option.EnableStorageCapacity, "enable-storage-capacity", false, "Publish CSIStorageCapacity objects of every node and open-local storage class.")
+	fs.StringVar(&option.StorageCapacityNamespace, "storage-capacity-namespace", defaultNamespace(), "Namespace of the CSIStorageCapacity objects, default to the namespace of controller pod.")
+	fs.StringVar(&option.StorageCapacityVersion, "storage-capacity-version", localtype.DefaultCapacityVersion, "API version of CSIStorageCapacity, v1 for kubernetes 1.24+, v1beta1 for 1.21~1.23.")
+	fs.Float64Var(&option.ThinOvercommitRatio, "thin-overcommit-ratio", localtype.DefaultThinOvercommitRatio, "Ratio of the virtual size of thin lvm volumes to the size of the thin pool, same as the thinOvercommitRatio of the scheduling policy.")
+}
+
+// defaultNamespace returns the namespace of the pod, or kube-system
+func defaultNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	return "kube-system"
 }
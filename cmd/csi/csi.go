@@ -17,8 +17,12 @@ limitations under the License.
 package csi
 
 import (
+	"fmt"
+
+	localtype "github.com/alibaba/open-local/pkg"
 	"github.com/alibaba/open-local/pkg/csi"
 	"github.com/alibaba/open-local/pkg/om"
+	"github.com/alibaba/open-local/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -44,13 +48,31 @@ func init() {
 
 // Start will start agent
 func Start(opt *csiOption) error {
-	log.Infof("CSI Driver Name: %s, nodeID: %s, endPoints %s", opt.Driver, opt.NodeID, opt.Endpoint)
+	log.Infof("CSI Driver Name: %s, nodeID: %s, endPoints %s, mode %s", opt.Driver, opt.NodeID, opt.Endpoint, opt.Mode)
+	switch opt.Mode {
+	case csi.ModeAll, csi.ModeController, csi.ModeNode:
+	default:
+		return fmt.Errorf("unknown mode %s, must be one of all, controller and node", opt.Mode)
+	}
+	if opt.Mode == csi.ModeNode && opt.LvmdOptions.ClientTLS.CertFile != "" {
+		return fmt.Errorf("the lvmd client certificate is the controller identity and must not be set in node mode")
+	}
+	switch opt.LvmdOptions.Transport {
+	case localtype.LvmdTransportGRPC:
+	case localtype.LvmdTransportCRD:
+		// requests of crd transport are authenticated by service account tokens, the certificates would be ignored
+		if opt.LvmdOptions.Server.TLS != (utils.TLSOptions{}) || opt.LvmdOptions.ClientTLS != (utils.TLSOptions{}) {
+			return fmt.Errorf("lvmd tls flags can not be set with crd transport")
+		}
+	default:
+		return fmt.Errorf("unknown lvmd transport %s, must be one of grpc and crd", opt.LvmdOptions.Transport)
+	}
 
 	// Storage devops
 	go om.StorageOM()
 
 	// go func(endPoint string) {
-	driver := csi.NewDriver(opt.Driver, opt.NodeID, opt.Endpoint, opt.SysPath, opt.GrpcConnectionTimeout)
+	driver := csi.NewDriver(opt.Driver, opt.NodeID, opt.Endpoint, opt.SysPath, opt.Mode, opt.GrpcConnectionTimeout, &opt.LvmdOptions)
 	driver.Run()
 	// }(opt.Endpoint)
 
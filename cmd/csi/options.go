@@ -17,7 +17,9 @@ limitations under the License.
 package csi
 
 import (
+	localtype "github.com/alibaba/open-local/pkg"
 	"github.com/alibaba/open-local/pkg/csi"
+	"github.com/alibaba/open-local/pkg/csi/server"
 	"github.com/spf13/pflag"
 )
 
@@ -26,7 +28,9 @@ type csiOption struct {
 	NodeID                string
 	Driver                string
 	SysPath               string
+	Mode                  string
 	GrpcConnectionTimeout int
+	LvmdOptions           csi.LvmdOptions
 }
 
 func (option *csiOption) addFlags(fs *pflag.FlagSet) {
@@ -34,5 +38,18 @@ func (option *csiOption) addFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&option.NodeID, "nodeID", "", "the id of node")
 	fs.StringVar(&option.Driver, "driver", csi.DefaultDriverName, "the name of CSI driver")
 	fs.StringVar(&option.SysPath, "path.sysfs", "/host_sys", "Path of sysfs mountpoint")
+	fs.StringVar(&option.Mode, "mode", csi.ModeAll, "the csi services to serve: all, controller or node. Only node mode starts lvmd and only controller mode dials it, so the controller certificate can stay out of node pods")
 	fs.IntVar(&option.GrpcConnectionTimeout, "grpc-connection-timeout", csi.DefaultConnectTimeout, "grpc connection timeout(second)")
+	fs.StringVar(&option.LvmdOptions.Server.TLS.CertFile, "lvmd-tls-server-cert", "", "the certificate lvmd serves with, mutual tls is enabled if server cert, key and ca are all set")
+	fs.StringVar(&option.LvmdOptions.Server.TLS.KeyFile, "lvmd-tls-server-key", "", "the private key of lvmd server certificate")
+	fs.StringVar(&option.LvmdOptions.Server.TLS.CAFile, "lvmd-tls-client-ca", "", "the ca to verify client certificates of lvmd")
+	fs.StringSliceVar(&option.LvmdOptions.Server.ControllerIdentities, "lvmd-controller-identities", []string{server.DefaultControllerIdentity}, "the common names of client certificates allowed to invoke the lvmd methods which are not read only")
+	fs.StringVar(&option.LvmdOptions.ClientTLS.CertFile, "lvmd-tls-client-cert", "", "the certificate csi controller dials lvmd with, mutual tls is enabled if client cert, key and ca are all set")
+	fs.StringVar(&option.LvmdOptions.ClientTLS.KeyFile, "lvmd-tls-client-key", "", "the private key of csi controller client certificate")
+	fs.StringVar(&option.LvmdOptions.ClientTLS.CAFile, "lvmd-tls-server-ca", "", "the ca to verify lvmd server certificates")
+	fs.StringVar(&option.LvmdOptions.ServerName, "lvmd-tls-server-name", csi.DefaultLvmdServerName, "the name verified against lvmd server certificates")
+	fs.StringVar(&option.LvmdOptions.Transport, "lvmd-transport", localtype.LvmdTransportGRPC, "how csi controller reaches lvmd: grpc through port 1736 of the node, or crd through LvmdRequest objects so no port is needed. The identities of crd transport are service account users, e.g. system:serviceaccount:kube-system:open-local-controller")
+	fs.StringVar(&option.LvmdOptions.Request.ServiceAccount, "lvmd-service-account", csi.DefaultLvmdServiceAccount, "the namespace/name of the service account of csi controller, a token bound to the node and the request is issued for every LvmdRequest, used by crd transport")
+	fs.StringSliceVar(&option.LvmdOptions.Request.NodeIdentities, "lvmd-node-identities", []string{csi.DefaultLvmdNodeIdentity}, "the service account users of node plugins whose results of LvmdRequests csi controller accepts, used by crd transport")
+	fs.StringVar(&option.LvmdOptions.ReplyTokenFile, "lvmd-reply-token-file", localtype.DefaultLvmdReplyTokenFile, "the service account token of audience open-local-lvmd-reply the node plugin writes the results of LvmdRequests with, used by crd transport")
 }
@@ -0,0 +1,175 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alibaba/open-local/pkg/scheduler"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const explainPath = "/explain"
+
+var (
+	opt = explainOption{}
+)
+
+var Cmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Explain why a pod or pvc fits or not each node",
+	Long: `explain asks the scheduler extender to evaluate the predicates and priorities of a pending pod or pvc
+against each node with its current cache, and prints the failure reasons of each node and volume and the scores`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := Run(&opt, os.Stdout); err != nil {
+			log.Fatalf("error :%s, quitting now\n", err.Error())
+		}
+	},
+}
+
+func init() {
+	opt.addFlags(Cmd.Flags())
+}
+
+func Run(opt *explainOption, out io.Writer) error {
+	if err := opt.validate(); err != nil {
+		return err
+	}
+	params := map[string]string{}
+	if opt.Pod != "" {
+		params["pod"] = opt.Pod
+	} else {
+		params["pvc"] = opt.PVC
+	}
+	if len(opt.Nodes) > 0 {
+		params["nodes"] = strings.Join(opt.Nodes, ",")
+	}
+
+	body, err := opt.get(params)
+	if err != nil {
+		return err
+	}
+	if opt.Output == OutputJSON {
+		_, err = fmt.Fprintln(out, string(body))
+		return err
+	}
+	explanation := &scheduler.Explanation{}
+	if err := json.Unmarshal(body, explanation); err != nil {
+		return fmt.Errorf("failed to decode explanation: %s", err.Error())
+	}
+	return PrintExplanation(out, explanation)
+}
+
+// get requests the explanation from the extender, directly or through the service proxy of apiserver
+func (option *explainOption) get(params map[string]string) ([]byte, error) {
+	if option.Server != "" {
+		query := url.Values{}
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		resp, err := http.Get(strings.TrimSuffix(option.Server, "/") + explainPath + "?" + query.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("failed to request scheduler extender: %s", err.Error())
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("scheduler extender responded %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		return body, nil
+	}
+
+	cfg, err := clientcmd.BuildConfigFromFlags(option.Master, option.Kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubeconfig: %s", err.Error())
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubernetes clientset: %s", err.Error())
+	}
+	body, err := kubeClient.CoreV1().Services(option.Namespace).ProxyGet("http", option.Service, option.Port, explainPath, params).DoRaw(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to request scheduler extender %s/%s: %s, %s", option.Namespace, option.Service, err.Error(), strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// PrintExplanation prints a node per row, followed by the failure reasons of the node and its volumes
+func PrintExplanation(out io.Writer, explanation *scheduler.Explanation) error {
+	if explanation.Pod != "" {
+		fmt.Fprintf(out, "Pod: %s\n", explanation.Pod)
+	} else {
+		fmt.Fprintf(out, "PVC: %s\n", explanation.PersistentVolumeClaim)
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tFITS\tSCORE\tPRIORITIES")
+	for _, node := range explanation.Nodes {
+		if !node.Fits {
+			fmt.Fprintf(w, "%s\t%t\t-\t-\n", node.Node, node.Fits)
+			continue
+		}
+		scores := make([]string, 0, len(node.Scores))
+		for _, score := range node.Scores {
+			scores = append(scores, fmt.Sprintf("%s=%dx%d", score.Name, score.Score, score.Weight))
+		}
+		fmt.Fprintf(w, "%s\t%t\t%d\t%s\n", node.Node, node.Fits, node.Score, strings.Join(scores, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, node := range explanation.Nodes {
+		if node.Fits {
+			continue
+		}
+		fmt.Fprintf(out, "\nNode %s:\n", node.Node)
+		if node.Error != "" {
+			fmt.Fprintf(out, "  error: %s\n", node.Error)
+		}
+		for _, reason := range node.Reasons {
+			fmt.Fprintf(out, "  %s\n", reason)
+		}
+		for _, volume := range node.Volumes {
+			if volume.Fits {
+				fmt.Fprintf(out, "  pvc %s(%s): fits\n", volume.PersistentVolumeClaim, volume.VolumeType)
+				continue
+			}
+			if volume.Error != "" {
+				fmt.Fprintf(out, "  pvc %s(%s): error: %s\n", volume.PersistentVolumeClaim, volume.VolumeType, volume.Error)
+			}
+			for _, reason := range volume.Reasons {
+				fmt.Fprintf(out, "  pvc %s(%s): %s\n", volume.PersistentVolumeClaim, volume.VolumeType, reason)
+			}
+		}
+	}
+	return nil
+}
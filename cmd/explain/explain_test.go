@@ -0,0 +1,55 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != explainPath || r.URL.Query().Get("pod") != "default/nginx" || r.URL.Query().Get("nodes") != "node1,node2" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"pod":"default/nginx","nodes":[
+			{"node":"node1","fits":true,"scores":[{"name":"CapacityMatch","score":5,"weight":2}],"score":10},
+			{"node":"node2","fits":false,"reasons":["Insufficient LVM storage on node node2"],
+			 "volumes":[{"persistentVolumeClaim":"default/data","volumeType":"LVM","fits":false,"reasons":["Insufficient LVM storage on node node2"]}],"score":0}]}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	opt := &explainOption{Pod: "default/nginx", Nodes: []string{"node1", "node2"}, Server: server.URL, Output: OutputTable}
+	if err := Run(opt, &out); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, expected := range []string{"Pod: default/nginx", "CapacityMatch=5x2", "Node node2:", "pvc default/data(LVM): Insufficient LVM storage"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expect %q in output:\n%s", expected, out.String())
+		}
+	}
+
+	opt = &explainOption{Pod: "default/nginx", PVC: "default/data", Output: OutputTable}
+	if err := Run(opt, &out); err == nil {
+		t.Errorf("expect error when both pod and pvc are set")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+type explainOption struct {
+	Master     string
+	Kubeconfig string
+	Pod        string
+	PVC        string
+	Nodes      []string
+	Server     string
+	Namespace  string
+	Service    string
+	Port       string
+	Output     string
+}
+
+func (option *explainOption) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&option.Kubeconfig, "kubeconfig", option.Kubeconfig, "Path to the kubeconfig file to use.")
+	fs.StringVar(&option.Master, "master", option.Master, "URL/IP for master.")
+	fs.StringVar(&option.Pod, "pod", option.Pod, "<namespace>/<name> of the pod to explain")
+	fs.StringVar(&option.PVC, "pvc", option.PVC, "<namespace>/<name> of the pvc to explain, as if it is the only volume of a pod")
+	fs.StringSliceVar(&option.Nodes, "nodes", option.Nodes, "Nodes to explain, all nodes by default")
+	fs.StringVar(&option.Server, "server", option.Server, "URL of the scheduler extender, e.g. http://127.0.0.1:23000. If not set, the extender is accessed through the service proxy of apiserver")
+	fs.StringVar(&option.Namespace, "namespace", "kube-system", "Namespace of the scheduler extender service")
+	fs.StringVar(&option.Service, "service", "open-local-scheduler-extender", "Name of the scheduler extender service")
+	fs.StringVar(&option.Port, "port", "23000", "Port of the scheduler extender service")
+	fs.StringVarP(&option.Output, "output", "o", OutputTable, "Output format: table or json")
+}
+
+func (option *explainOption) validate() error {
+	if (option.Pod == "") == (option.PVC == "") {
+		return fmt.Errorf("exactly one of --pod and --pvc must be set")
+	}
+	if option.Output != OutputTable && option.Output != OutputJSON {
+		return fmt.Errorf("unsupported output format %q, must be %s or %s", option.Output, OutputTable, OutputJSON)
+	}
+	return nil
+}
@@ -29,7 +29,9 @@ import (
 	"github.com/alibaba/open-local/cmd/controller"
 	"github.com/alibaba/open-local/cmd/csi"
 	"github.com/alibaba/open-local/cmd/doc"
+	"github.com/alibaba/open-local/cmd/explain"
 	"github.com/alibaba/open-local/cmd/scheduler"
+	"github.com/alibaba/open-local/cmd/simulate"
 	"github.com/alibaba/open-local/cmd/version"
 	localtype "github.com/alibaba/open-local/pkg"
 )
@@ -56,6 +58,8 @@ func addCommands() {
 		scheduler.Cmd,
 		csi.Cmd,
 		controller.Cmd,
+		explain.Cmd,
+		simulate.Cmd,
 		version.Cmd,
 		doc.Cmd.Cmd,
 	)
@@ -17,15 +17,19 @@ limitations under the License.
 package scheduler
 
 import (
+	"context"
 	"fmt"
 
 	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
 	informers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
 	"github.com/alibaba/open-local/pkg/scheduler/server"
 	volumesnapshot "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
 	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
@@ -56,12 +60,12 @@ func Run(opt *extenderOption) error {
 	// set up signals so we handle the first shutdown signal gracefully
 	stopCh := signals.SetupSignalHandler()
 
-	weights, err := opt.ParseWeight()
+	schedulingPolicy, err := opt.ParsePolicy()
 	if err != nil {
 		return err
 	}
 
-	err = opt.ParseStrategy()
+	err = opt.ValidateCacheOptions()
 	if err != nil {
 		return err
 	}
@@ -84,16 +88,53 @@ func Run(opt *extenderOption) error {
 		return fmt.Errorf("error building snapshot clientset: %s", err.Error())
 	}
 
+	var policyNamespace, policyName string
+	if opt.PolicyConfigMap != "" {
+		if policyNamespace, policyName, err = opt.PolicyConfigMapKey(); err != nil {
+			return err
+		}
+		cm, err := kubeClient.CoreV1().ConfigMaps(policyNamespace).Get(context.Background(), policyName, metav1.GetOptions{})
+		if err == nil {
+			if schedulingPolicy, err = policy.FromConfigMap(cm); err != nil {
+				return err
+			}
+		} else if errors.IsNotFound(err) {
+			log.Warningf("policy configmap %s not found, scheduling with the policy of flags until it is created", opt.PolicyConfigMap)
+		} else {
+			return fmt.Errorf("error getting policy configmap %s: %s", opt.PolicyConfigMap, err.Error())
+		}
+	}
+	log.Infof("scheduling policy: %+v", *schedulingPolicy)
+
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
 	localStorageInformerFactory := informers.NewSharedInformerFactory(localClient, 0)
 	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(snapClient, 0)
 
-	extenderServer := server.NewExtenderServer(kubeClient, localClient, snapClient, kubeInformerFactory, localStorageInformerFactory, snapshotInformerFactory, opt.Port, weights)
+	extenderServer := server.NewExtenderServer(kubeClient, localClient, snapClient, kubeInformerFactory, localStorageInformerFactory, snapshotInformerFactory, opt.Port, schedulingPolicy)
+	extenderServer.Ctx.ClusterNodeCache.AssumeTTL = opt.AssumeTTL
+	extenderServer.Ctx.CacheReconcileInterval = opt.CacheReconcileInterval
 
 	log.Info("starting open-local scheduler extender")
 	kubeInformerFactory.Start(stopCh)
 	localStorageInformerFactory.Start(stopCh)
 	snapshotInformerFactory.Start(stopCh)
+	if opt.PolicyFile != "" {
+		go extenderServer.WatchPolicyFile(opt.PolicyFile, opt.PolicyReloadInterval, stopCh)
+	} else if opt.PolicyConfigMap != "" {
+		extenderServer.WatchPolicyConfigMap(policyNamespace, policyName, stopCh)
+	}
+	if opt.LeaderElect {
+		err = extenderServer.RunLeaderElection(server.LeaderElectionConfig{
+			Namespace:     opt.LeaderElectNamespace,
+			Name:          opt.LeaderElectName,
+			LeaseDuration: opt.LeaseDuration,
+			RenewDeadline: opt.RenewDeadline,
+			RetryPeriod:   opt.RetryPeriod,
+		}, stopCh)
+		if err != nil {
+			return err
+		}
+	}
 	extenderServer.Start(stopCh)
 	log.Info("quitting now")
 	return nil
@@ -29,6 +29,7 @@ import (
 	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
 	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
 	localinformers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
 	"github.com/alibaba/open-local/pkg/scheduler/server"
 	volumesnapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
 	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
@@ -813,7 +814,7 @@ func (f *fixture) newExtender() (*server.ExtenderServer, kubeinformers.SharedInf
 	localInformer := localinformers.NewSharedInformerFactory(f.localclient, noResyncPeriodFunc())
 	snapInforer := volumesnapshotinformers.NewSharedInformerFactory(f.snapclient, noResyncPeriodFunc())
 
-	extenderServer := server.NewExtenderServer(f.kubeclient, f.localclient, f.snapclient, k8sInformer, localInformer, snapInforer, TestPort, localtype.NewNodeAntiAffinityWeight())
+	extenderServer := server.NewExtenderServer(f.kubeclient, f.localclient, f.snapclient, k8sInformer, localInformer, snapInforer, TestPort, policy.NewDefaultPolicy(localtype.StrategyBinpack))
 
 	return extenderServer, k8sInformer, localInformer, snapInforer
 }
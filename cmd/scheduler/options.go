@@ -18,11 +18,14 @@ package scheduler
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alibaba/open-local/pkg"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm/priorities"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 )
@@ -34,6 +37,18 @@ type extenderOption struct {
 	Port                    int32
 	EnabledNodeAntiAffinity string
 	Strategy                string
+	ThinOvercommitRatio     float64
+	AssumeTTL               time.Duration
+	CacheReconcileInterval  time.Duration
+	PolicyFile              string
+	PolicyConfigMap         string
+	PolicyReloadInterval    time.Duration
+	LeaderElect             bool
+	LeaderElectNamespace    string
+	LeaderElectName         string
+	LeaseDuration           time.Duration
+	RenewDeadline           time.Duration
+	RetryPeriod             time.Duration
 }
 
 const (
@@ -44,8 +59,28 @@ func (option *extenderOption) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&option.Kubeconfig, "kubeconfig", option.Kubeconfig, "Path to the kubeconfig file to use.")
 	fs.StringVar(&option.Master, "master", option.Master, "URL/IP for master.")
 	fs.Int32Var(&option.Port, "port", option.Port, "Port for receiving scheduler callback, set to '0' to disable http server")
-	fs.StringVar(&option.EnabledNodeAntiAffinity, "enabled-node-anti-affinity", option.EnabledNodeAntiAffinity, "whether enable node anti-affinity for open-local storage backend, example format: 'MountPoint=5,LVM=3'")
-	fs.StringVar(&option.Strategy, "scheduler-strategy", "binpack", "Scheduler Strategy: binpack or spread")
+	fs.StringVar(&option.EnabledNodeAntiAffinity, "enabled-node-anti-affinity", option.EnabledNodeAntiAffinity, "whether enable node anti-affinity for open-local storage backend, example format: 'MountPoint=5,LVM=3', ignored if the scheduling policy is set")
+	fs.StringVar(&option.Strategy, "scheduler-strategy", "binpack", "Scheduler Strategy: binpack, spread or most-free-vg, ignored if the scheduling policy is set")
+	fs.Float64Var(&option.ThinOvercommitRatio, "thin-overcommit-ratio", pkg.DefaultThinOvercommitRatio, "Ratio of the virtual size of thin LVM volumes to the size of the thin pool, must be no less than 1.0, ignored if the scheduling policy is set")
+	fs.DurationVar(&option.AssumeTTL, "assume-ttl", pkg.DefaultAssumeTTL, "How long the storage assumed for a PVC is kept in cache before its PV is created")
+	fs.StringVar(&option.PolicyFile, "policy-file", option.PolicyFile, "Path to the scheduling policy file, which is reloaded when changed")
+	fs.StringVar(&option.PolicyConfigMap, "policy-configmap", option.PolicyConfigMap, "<namespace>/<name> of the ConfigMap with the scheduling policy under key policy.yaml, which is reloaded when changed")
+	fs.DurationVar(&option.PolicyReloadInterval, "policy-reload-interval", policy.DefaultReloadInterval, "Interval to check the changes of the scheduling policy file")
+	fs.DurationVar(&option.CacheReconcileInterval, "cache-reconcile-interval", pkg.DefaultCacheReconcileInterval, "Interval to rebuild the cache from apiserver and release the expired assumed storage")
+	fs.BoolVar(&option.LeaderElect, "leader-elect", false, "Run replicas of scheduler extender as leader and standbys, only the leader serves scheduling requests and reports ready")
+	fs.StringVar(&option.LeaderElectNamespace, "leader-elect-namespace", defaultNamespace(), "Namespace of the lease of leader election, default to the namespace of scheduler extender pod")
+	fs.StringVar(&option.LeaderElectName, "leader-elect-name", pkg.DefaultLeaderElectionName, "Name of the lease of leader election")
+	fs.DurationVar(&option.LeaseDuration, "leader-elect-lease-duration", 15*time.Second, "Duration that standbys wait before taking over the lease of a leader which stops renewing it")
+	fs.DurationVar(&option.RenewDeadline, "leader-elect-renew-deadline", 10*time.Second, "Duration that the leader retries renewing the lease before giving up the leadership")
+	fs.DurationVar(&option.RetryPeriod, "leader-elect-retry-period", 2*time.Second, "Duration between tries of acquiring and renewing the lease")
+}
+
+// defaultNamespace returns the namespace of the pod, or kube-system
+func defaultNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	return "kube-system"
 }
 
 func (option *extenderOption) ParseWeight() (weights *pkg.NodeAntiAffinityWeight, err error) {
@@ -89,15 +124,47 @@ func (option *extenderOption) ParseWeight() (weights *pkg.NodeAntiAffinityWeight
 	return
 }
 
-func (option *extenderOption) ParseStrategy() error {
-	switch option.Strategy {
-	case "binpack":
-		pkg.SchedulerStrategy = pkg.StrategyBinpack
-	case "spread":
-		pkg.SchedulerStrategy = pkg.StrategySpread
-	default:
-		return fmt.Errorf("Scheduler strategy parameter may be wrong. You can set one of those: binpack or spread")
+// ParsePolicy returns the scheduling policy in the policy file, or the one of flags
+// --scheduler-strategy, --enabled-node-anti-affinity and --thin-overcommit-ratio if no policy file is set
+func (option *extenderOption) ParsePolicy() (*policy.Policy, error) {
+	if option.PolicyFile != "" && option.PolicyConfigMap != "" {
+		return nil, fmt.Errorf("only one of policy file and policy configmap can be set")
+	}
+	if option.PolicyReloadInterval <= 0 {
+		return nil, fmt.Errorf("policy reload interval must be positive, current value is %v", option.PolicyReloadInterval)
+	}
+	if option.PolicyFile != "" {
+		return policy.Load(option.PolicyFile)
+	}
+	weights, err := option.ParseWeight()
+	if err != nil {
+		return nil, err
+	}
+	p := policy.NewDefaultPolicy(pkg.StrategyType(option.Strategy))
+	p.NodeAntiAffinity = weights.Items(true)
+	p.ThinOvercommitRatio = option.ThinOvercommitRatio
+	if err := p.Validate(); err != nil {
+		return nil, err
 	}
+	return p, nil
+}
+
+// PolicyConfigMapKey returns the namespace and name of the policy configmap
+func (option *extenderOption) PolicyConfigMapKey() (namespace, name string, err error) {
+	parts := strings.Split(option.PolicyConfigMap, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("policy configmap %q is invalid, <namespace>/<name> is expected", option.PolicyConfigMap)
+	}
+	return parts[0], parts[1], nil
+}
 
+// ValidateCacheOptions checks the assume ttl and cache reconcile interval
+func (option *extenderOption) ValidateCacheOptions() error {
+	if option.AssumeTTL <= 0 {
+		return fmt.Errorf("assume ttl must be positive, current value is %v", option.AssumeTTL)
+	}
+	if option.CacheReconcileInterval <= 0 {
+		return fmt.Errorf("cache reconcile interval must be positive, current value is %v", option.CacheReconcileInterval)
+	}
 	return nil
 }
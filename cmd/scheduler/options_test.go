@@ -19,6 +19,7 @@ package scheduler
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/alibaba/open-local/pkg"
 )
@@ -87,3 +88,81 @@ func TestExtenderOptions_GetWeight(t *testing.T) {
 		})
 	}
 }
+
+func TestExtenderOptions_ThinOvercommitRatio(t *testing.T) {
+	tests := []struct {
+		name    string
+		ratio   float64
+		wantErr bool
+	}{
+		{
+			name:    "test-default",
+			ratio:   pkg.DefaultThinOvercommitRatio,
+			wantErr: false,
+		},
+		{
+			name:    "test-overcommit",
+			ratio:   2.5,
+			wantErr: false,
+		},
+		{
+			name:    "test-invalid-range",
+			ratio:   0.5,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option := &extenderOption{
+				Strategy:             string(pkg.StrategyBinpack),
+				ThinOvercommitRatio:  tt.ratio,
+				PolicyReloadInterval: time.Second,
+			}
+			p, err := option.ParsePolicy()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && p.ThinOvercommitRatio != tt.ratio {
+				t.Errorf("ParsePolicy() thin overcommit ratio = %v, want %v", p.ThinOvercommitRatio, tt.ratio)
+			}
+		})
+	}
+}
+
+func TestExtenderOptions_ParsePolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		option       extenderOption
+		wantStrategy pkg.StrategyType
+		wantErr      bool
+	}{
+		{
+			name:         "test-flags",
+			option:       extenderOption{Strategy: "most-free-vg", EnabledNodeAntiAffinity: "MountPoint=5", PolicyReloadInterval: time.Second},
+			wantStrategy: pkg.StrategyMostFreeVG,
+		},
+		{
+			name:    "test-invalid-strategy",
+			option:  extenderOption{Strategy: "random", PolicyReloadInterval: time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "test-file-and-configmap",
+			option:  extenderOption{PolicyFile: "/etc/open-local/policy.yaml", PolicyConfigMap: "kube-system/open-local-policy", PolicyReloadInterval: time.Second},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.option.ParsePolicy()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && p.Strategy != tt.wantStrategy {
+				t.Errorf("ParsePolicy() strategy = %v, want %v", p.Strategy, tt.wantStrategy)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"fmt"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	"github.com/spf13/pflag"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+type simulateOption struct {
+	Cluster             []string
+	Workloads           []string
+	PolicyFile          string
+	Strategy            string
+	ThinOvercommitRatio float64
+	Output              string
+}
+
+func (option *simulateOption) addFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&option.Cluster, "cluster", option.Cluster, "YAML/JSON files or directories of the cluster snapshot: NodeLocalStorages, Nodes, StorageClasses, PVs, PVCs and Pods, e.g. the output of 'kubectl get nls,sc,pv,pvc,po -A -o yaml'")
+	fs.StringSliceVar(&option.Workloads, "workload", option.Workloads, "YAML/JSON files or directories of the StatefulSets, Pods and PVCs to place, in order")
+	fs.StringVar(&option.PolicyFile, "policy-file", option.PolicyFile, "Path to the scheduling policy file of the scheduler extender")
+	fs.StringVar(&option.Strategy, "scheduler-strategy", string(localtype.StrategyBinpack), "Scheduler Strategy: binpack, spread or most-free-vg, ignored if the policy file is set")
+	fs.Float64Var(&option.ThinOvercommitRatio, "thin-overcommit-ratio", localtype.DefaultThinOvercommitRatio, "Ratio of the virtual size of thin LVM volumes to the size of the thin pool, must be no less than 1.0, ignored if the policy file is set")
+	fs.StringVarP(&option.Output, "output", "o", OutputTable, "Output format: table or json")
+}
+
+func (option *simulateOption) parse() (*policy.Policy, error) {
+	if len(option.Cluster) == 0 || len(option.Workloads) == 0 {
+		return nil, fmt.Errorf("both --cluster and --workload must be set")
+	}
+	if option.Output != OutputTable && option.Output != OutputJSON {
+		return nil, fmt.Errorf("unsupported output format %q, must be %s or %s", option.Output, OutputTable, OutputJSON)
+	}
+	if option.PolicyFile != "" {
+		return policy.Load(option.PolicyFile)
+	}
+	p := policy.NewDefaultPolicy(localtype.StrategyType(option.Strategy))
+	p.ThinOvercommitRatio = option.ThinOvercommitRatio
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler/simulator"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	opt = simulateOption{}
+)
+
+var Cmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate the scheduling of workloads on a snapshot of the cluster",
+	Long: `simulate places the pods of StatefulSets and Pods one by one on a snapshot of the cluster, with the same
+predicates, priorities and storage assumption of the scheduler extender, and prints the placements, the failures
+and the remaining storage of each node`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// the algorithms log every pod and node, even the failures are logged as errors, which hides the result
+		if os.Getenv(localtype.EnvLogLevel) == "" {
+			log.SetLevel(log.FatalLevel)
+		}
+		if err := Run(&opt, os.Stdout); err != nil {
+			log.Fatalf("error :%s, quitting now\n", err.Error())
+		}
+	},
+}
+
+func init() {
+	opt.addFlags(Cmd.Flags())
+}
+
+func Run(opt *simulateOption, out io.Writer) error {
+	schedulingPolicy, err := opt.parse()
+	if err != nil {
+		return err
+	}
+	cluster, err := simulator.LoadFiles(opt.Cluster)
+	if err != nil {
+		return err
+	}
+	workloads, err := simulator.LoadFiles(opt.Workloads)
+	if err != nil {
+		return err
+	}
+	s, err := simulator.New(cluster, schedulingPolicy)
+	if err != nil {
+		return err
+	}
+	result, err := s.Run(workloads)
+	if err != nil {
+		return err
+	}
+
+	if opt.Output == OutputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	return PrintResult(out, result)
+}
+
+// PrintResult prints the placements, failures and node storage as tables
+func PrintResult(out io.Writer, result *simulator.Result) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tNODE\tSCORE\tVOLUMES")
+	for _, placement := range result.Placements {
+		volumes := make([]string, 0, len(placement.Volumes))
+		for _, volume := range placement.Volumes {
+			volumes = append(volumes, fmt.Sprintf("%s(%s %s %s)", volume.PersistentVolumeClaim, volume.VolumeType, location(volume), quantity(volume.Size)))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", placement.Pod, placement.Node, placement.Score, strings.Join(volumes, ","))
+	}
+	for _, failure := range result.Failures {
+		fmt.Fprintf(w, "%s\t<none>\t-\t-\n", failure.Pod)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%d placed, %d failed, %d skipped\n", len(result.Placements), len(result.Failures), len(result.Skipped))
+
+	for _, failure := range result.Failures {
+		fmt.Fprintf(out, "\nPod %s:\n", failure.Pod)
+		if failure.Error != "" {
+			fmt.Fprintf(out, "  error: %s\n", failure.Error)
+		}
+		nodes := make([]string, 0, len(failure.Reasons))
+		for node := range failure.Reasons {
+			nodes = append(nodes, node)
+		}
+		sort.Strings(nodes)
+		for _, node := range nodes {
+			fmt.Fprintf(out, "  %s: %s\n", node, strings.Join(failure.Reasons[node], "; "))
+		}
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tTYPE\tNAME\tCAPACITY\tREQUESTED\tFREE")
+	for _, node := range result.Nodes {
+		for _, vg := range node.VGs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", node.Node, localtype.VolumeTypeLVM, vg.Name, quantity(vg.Capacity), quantity(vg.Requested), quantity(vg.Free))
+		}
+		for _, quota := range node.Quotas {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", node.Node, localtype.VolumeTypeQuota, quota.Name, quantity(quota.Capacity), quantity(quota.Requested), quantity(quota.Free))
+		}
+		for _, mp := range node.MountPoints {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", node.Node, localtype.VolumeTypeMountPoint, mp.Name, quantity(mp.Capacity), allocated(mp), free(mp))
+		}
+		for _, device := range node.Devices {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", node.Node, localtype.VolumeTypeDevice, device.Name, quantity(device.Capacity), allocated(device), free(device))
+		}
+	}
+	return w.Flush()
+}
+
+func location(volume simulator.Volume) string {
+	switch {
+	case volume.VgName != "":
+		return volume.VgName
+	case volume.Device != "":
+		return volume.Device
+	default:
+		return volume.MountPoint
+	}
+}
+
+func quantity(size int64) string {
+	return resource.NewQuantity(size, resource.BinarySI).String()
+}
+
+func allocated(r simulator.ExclusiveCapacity) string {
+	if r.Allocated {
+		return quantity(r.Capacity)
+	}
+	return quantity(0)
+}
+
+func free(r simulator.ExclusiveCapacity) string {
+	if r.Allocated {
+		return quantity(0)
+	}
+	return quantity(r.Capacity)
+}
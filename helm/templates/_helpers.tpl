@@ -0,0 +1,79 @@
+{{/*
+whether csi controller runs in the controller deployments instead of the csi-plugin of every node,
+so that the controller certificate or service account never reaches the nodes
+*/}}
+{{- define "open-local.splitController" -}}
+{{- if and .Values.lvmd.tls.enabled (eq .Values.lvmd.transport "crd") }}
+{{- fail "lvmd.tls is not used by crd transport, which authenticates csi controller by its service account" }}
+{{- end }}
+{{- if or .Values.lvmd.tls.enabled (eq .Values.lvmd.transport "crd") }}true{{ end }}
+{{- end -}}
+
+{{/*
+csi-plugin serving the controller service only, added to the controller deployments if the controller is split
+*/}}
+{{- define "open-local.controllerPlugin" -}}
+- name: csi-plugin
+  image: {{ .Values.images.local.image }}:{{ .Values.images.local.tag }}
+  imagePullPolicy: Always
+  args:
+    - csi
+    - "--mode=controller"
+    - "--endpoint=unix://var/lib/kubelet/plugins/{{ .Values.driver }}/csi.sock"
+    - "--nodeID=$(KUBE_NODE_NAME)"
+    - "--driver={{ .Values.driver }}"
+    - "--lvmd-transport={{ .Values.lvmd.transport }}"
+    {{- if eq .Values.lvmd.transport "crd" }}
+    - "--lvmd-service-account={{ .Values.namespace }}/{{ .Values.name }}-controller"
+    - "--lvmd-node-identities=system:serviceaccount:{{ .Values.namespace }}:{{ .Values.name }}-agent"
+    {{- end }}
+    {{- if .Values.lvmd.tls.enabled }}
+    - "--lvmd-tls-client-cert=/etc/{{ .Values.name }}/lvmd-tls/client.crt"
+    - "--lvmd-tls-client-key=/etc/{{ .Values.name }}/lvmd-tls/client.key"
+    - "--lvmd-tls-server-ca=/etc/{{ .Values.name }}/lvmd-tls/ca.crt"
+    - "--lvmd-tls-server-name={{ .Values.lvmd.tls.server_name }}"
+    {{- end }}
+  env:
+    - name: KUBE_NODE_NAME
+      valueFrom:
+        fieldRef:
+          apiVersion: v1
+          fieldPath: spec.nodeName
+    - name: TZ
+      value: Asia/Shanghai
+  resources:
+    limits:
+      cpu: 500m
+      memory: 512Mi
+    requests:
+      cpu: 50m
+      memory: 128Mi
+  volumeMounts:
+    - name: socket-dir
+      mountPath: /var/lib/kubelet/plugins/{{ .Values.driver }}
+    {{- if .Values.lvmd.tls.enabled }}
+    - name: lvmd-tls
+      mountPath: /etc/{{ .Values.name }}/lvmd-tls
+      readOnly: true
+    {{- end }}
+{{- end -}}
+
+{{/*
+socket of the sidecar, the csi-plugin of the deployment if the controller is split or the one of the node otherwise
+*/}}
+{{- define "open-local.controllerVolumes" -}}
+{{- if include "open-local.splitController" . }}
+- name: socket-dir
+  emptyDir: {}
+{{- if .Values.lvmd.tls.enabled }}
+- name: lvmd-tls
+  secret:
+    secretName: {{ .Values.lvmd.tls.controller_secret }}
+{{- end }}
+{{- else }}
+- name: socket-dir
+  hostPath:
+    path: {{ .Values.agent.kubelet_dir }}/plugins/{{ .Values.driver }}
+    type: DirectoryOrCreate
+{{- end }}
+{{- end -}}
@@ -26,6 +26,10 @@ type Configuration struct {
 	MountPath string
 	// DisconverInterval is the duration(second) that the agent checks at one time
 	DiscoverInterval int
+	// EventDriven rediscovers the storage on kernel uevents and mount table changes
+	EventDriven bool
+	// ResyncInterval is the duration(second) that the agent discovers all the storage at one time when EventDriven is set
+	ResyncInterval int
 	// LogicalVolumeNamePrefix is the prefix of LogicalVolume Name
 	LogicalVolumeNamePrefix string
 	// RegExp is used to filter device names
@@ -38,4 +42,8 @@ const (
 	// DefaultInterval is the duration(second) that the agent checks at one time
 	DefaultInterval int    = 60
 	DefaultEndpoint string = "unix://tmp/csi.sock"
+	// DefaultResyncInterval is the duration(second) that the agent discovers all the storage at one time when event driven
+	DefaultResyncInterval int = 600
+	// DefaultRegExp matches the names of scsi, virtio, xen and nvme disks
+	DefaultRegExp string = `^((s|v|xv)d[a-z]+|nvme[0-9]+n[0-9]+)$`
 )
@@ -61,7 +61,19 @@ func (c *Agent) Run(stopCh <-chan struct{}) error {
 
 	// Start the informer factories to begin populating the informer caches
 	discoverer := discovery.NewDiscoverer(c.Configuration, c.kubeclientset, c.localclientset, c.snapclientset, c.eventRecorder)
-	go wait.Until(discoverer.Discover, time.Duration(discoverer.DiscoverInterval)*time.Second, stopCh)
+	discoverInterval := discoverer.DiscoverInterval
+	if discoverer.EventDriven {
+		if err := discoverer.Watch(stopCh); err != nil {
+			log.Warningf("watch storage changes failed, discover storage every %d seconds: %s", discoverInterval, err.Error())
+		} else {
+			// the full discovery is only a safety resync, and the list config is not watched by uevents
+			discoverInterval = discoverer.ResyncInterval
+			go wait.Until(discoverer.Refilter, time.Duration(discoverer.DiscoverInterval)*time.Second, stopCh)
+			// the health of disks changes without uevents
+			go wait.Until(discoverer.CheckHealth, time.Duration(discoverer.DiscoverInterval)*time.Second, stopCh)
+		}
+	}
+	go wait.Until(discoverer.Discover, time.Duration(discoverInterval)*time.Second, stopCh)
 	go wait.Until(discoverer.InitResource, time.Duration(discoverer.DiscoverInterval)*time.Second, stopCh)
 
 	// get auto expand snapshot interval
@@ -76,6 +88,16 @@ func (c *Agent) Run(stopCh <-chan struct{}) error {
 	}
 	go wait.Until(discoverer.ExpandSnapshotLVIfNeeded, time.Duration(expandSnapInterval)*time.Second, stopCh)
 
+	// get backup snapshot interval
+	backupSnapInterval := discoverer.DiscoverInterval
+	if tmp := os.Getenv(localtype.EnvBackupSnapshotInterval); tmp != "" {
+		backupSnapInterval, err = strconv.Atoi(tmp)
+		if err != nil {
+			return err
+		}
+	}
+	go wait.Until(discoverer.BackupSnapshotsIfNeeded, time.Duration(backupSnapInterval)*time.Second, stopCh)
+
 	log.Info("Started open-local agent")
 	<-stopCh
 	log.Info("Shutting down agent")
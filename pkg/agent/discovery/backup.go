@@ -0,0 +1,187 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/backup"
+	"github.com/alibaba/open-local/pkg/utils/lvm"
+	snapshotapi "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// updateAnnotationRetries is the number of attempts to update annotations on conflicts
+const updateAnnotationRetries = 5
+
+// BackupSnapshotsIfNeeded backs up the snapshot lvs whose VolumeSnapshotClass sets a backup target,
+// the status of the backup is recorded in annotations of the VolumeSnapshotContent
+func (d *Discoverer) BackupSnapshotsIfNeeded() {
+	prefix := os.Getenv(localtype.EnvSnapshotPrefix)
+	if prefix == "" {
+		prefix = localtype.DefaultSnapshotPrefix
+	}
+
+	lvs, err := getAllLocalSnapshotLV()
+	if err != nil {
+		log.Errorf("[BackupSnapshotsIfNeeded]get open-local snapshot lv failed: %s", err.Error())
+		return
+	}
+	for _, lv := range lvs {
+		// skip the temporary snapshots of clones
+		if !strings.HasPrefix(lv.Name(), prefix) {
+			continue
+		}
+		snapContent, err := d.snapclient.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), strings.Replace(lv.Name(), prefix, "snapcontent", 1), metav1.GetOptions{})
+		if err != nil {
+			if !k8serr.IsNotFound(err) {
+				log.Errorf("[BackupSnapshotsIfNeeded]get snapContent %s error: %s", lv.Name(), err.Error())
+			}
+			continue
+		}
+		if snapContent.Annotations[localtype.AnnotationBackupStatus] == localtype.BackupStatusCompleted || snapContent.Spec.VolumeSnapshotClassName == nil {
+			continue
+		}
+		snapClass, err := d.snapclient.SnapshotV1().VolumeSnapshotClasses().Get(context.TODO(), *snapContent.Spec.VolumeSnapshotClassName, metav1.GetOptions{})
+		if err != nil {
+			log.Errorf("[BackupSnapshotsIfNeeded]get snapClass %s error: %s", *snapContent.Spec.VolumeSnapshotClassName, err.Error())
+			continue
+		}
+		if snapClass.Parameters[localtype.ParamBackupTarget] == "" {
+			continue
+		}
+		d.backupSnapshot(lv, snapContent, snapClass.Parameters)
+	}
+}
+
+func (d *Discoverer) backupSnapshot(lv *lvm.LogicalVolume, snapContent *snapshotapi.VolumeSnapshotContent, params map[string]string) {
+	log.Infof("[backupSnapshot]backing up snapshot lv %s to %s", lv.Name(), params[localtype.ParamBackupTarget])
+	// the snapshot types are not registered in the scheme of the recorder
+	ref := &corev1.ObjectReference{
+		APIVersion: snapshotapi.SchemeGroupVersion.String(),
+		Kind:       "VolumeSnapshotContent",
+		Name:       snapContent.Name,
+		UID:        snapContent.UID,
+	}
+	d.recorder.Eventf(ref, corev1.EventTypeNormal, localtype.EventBackupStarted, "backing up snapshot %s at node %s", lv.Name(), d.Nodename)
+	if err := d.updateSnapshotContentAnnotations(snapContent.Name, map[string]string{
+		localtype.AnnotationBackupStatus:  localtype.BackupStatusInProgress,
+		localtype.AnnotationBackupMessage: "",
+		localtype.AnnotationBackupName:    lv.Name(),
+	}); err != nil {
+		log.Errorf("[backupSnapshot]update status of snapContent %s error: %s", snapContent.Name, err.Error())
+		return
+	}
+
+	manifest, stats, err := d.uploadSnapshot(lv, snapContent, params)
+	if err != nil {
+		log.Errorf("[backupSnapshot]back up snapshot lv %s failed: %s", lv.Name(), err.Error())
+		d.recorder.Eventf(ref, corev1.EventTypeWarning, localtype.EventBackupFailed, "backing up snapshot %s failed: %s", lv.Name(), err.Error())
+		if err := d.updateSnapshotContentAnnotations(snapContent.Name, map[string]string{
+			localtype.AnnotationBackupStatus:  localtype.BackupStatusFailed,
+			localtype.AnnotationBackupMessage: err.Error(),
+		}); err != nil {
+			log.Errorf("[backupSnapshot]update status of snapContent %s error: %s", snapContent.Name, err.Error())
+		}
+		return
+	}
+
+	message := fmt.Sprintf("uploaded %d of %d chunks", stats.UploadedChunks, stats.Chunks)
+	if manifest.Base != "" {
+		message = fmt.Sprintf("%s, incremental to backup %s", message, manifest.Base)
+	}
+	d.recorder.Eventf(ref, corev1.EventTypeNormal, localtype.EventBackupSucceeded, "backed up snapshot %s: %s", lv.Name(), message)
+	if err := d.updateSnapshotContentAnnotations(snapContent.Name, map[string]string{
+		localtype.AnnotationBackupStatus:         localtype.BackupStatusCompleted,
+		localtype.AnnotationBackupMessage:        message,
+		localtype.AnnotationBackupSize:           strconv.FormatInt(manifest.Size, 10),
+		localtype.AnnotationBackupUploadedBytes:  strconv.FormatInt(stats.UploadedBytes, 10),
+		localtype.AnnotationBackupCompletionTime: manifest.CreatedAt.Format(time.RFC3339),
+	}); err != nil {
+		log.Errorf("[backupSnapshot]update status of snapContent %s error: %s", snapContent.Name, err.Error())
+		return
+	}
+	log.Infof("[backupSnapshot]backed up snapshot lv %s: %s", lv.Name(), message)
+}
+
+// uploadSnapshot backs up the snapshot lv, named after the lv, incremental to the latest backup of its origin volume.
+// The source pvc of the VolumeSnapshot is recorded as the owner, so that the backup is only restored to its namespace
+func (d *Discoverer) uploadSnapshot(lv *lvm.LogicalVolume, snapContent *snapshotapi.VolumeSnapshotContent, params map[string]string) (*backup.Manifest, *backup.Stats, error) {
+	ref := snapContent.Spec.VolumeSnapshotRef
+	snapshot, err := d.snapclient.SnapshotV1().VolumeSnapshots(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get snapshot %s/%s failed: %s", ref.Namespace, ref.Name, err.Error())
+	}
+	if snapshot.Spec.Source.PersistentVolumeClaimName == nil {
+		return nil, nil, fmt.Errorf("snapshot %s/%s has no source pvc", ref.Namespace, ref.Name)
+	}
+	owner := backup.Owner{Namespace: ref.Namespace, PVC: *snapshot.Spec.Source.PersistentVolumeClaimName}
+
+	target, err := backup.NewTargetFromParameters(d.kubeclientset, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	path, err := lv.Path()
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	// the size of the snapshot device is the size of its origin
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	return backup.Backup(context.Background(), target, f, size, lv.Name(), lv.OriginLVName(), snapContent.Name, owner, nil)
+}
+
+func (d *Discoverer) updateSnapshotContentAnnotations(name string, annotations map[string]string) (err error) {
+	for i := 0; i < updateAnnotationRetries; i++ {
+		var snapContent *snapshotapi.VolumeSnapshotContent
+		snapContent, err = d.snapclient.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if snapContent.Annotations == nil {
+			snapContent.Annotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			snapContent.Annotations[key] = value
+		}
+		_, err = d.snapclient.SnapshotV1().VolumeSnapshotContents().Update(context.TODO(), snapContent, metav1.UpdateOptions{})
+		if !k8serr.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -32,30 +32,54 @@ func (d *Discoverer) discoverDevices(newStatus *localv1alpha1.NodeLocalStorageSt
 	if err != nil {
 		return err
 	}
+	links, err := deviceutil.GetByIDLinks(d.byIDPath)
+	if err != nil {
+		return err
+	}
 	for _, blockName := range blockDirs {
 		if blockRegExp.MatchString(blockName.Name()) {
-			device, err := deviceutil.GetBlockInfo(d.SysPath, blockName.Name())
+			deviceInfos, err := d.discoverDisk(blockName.Name(), links)
 			if err != nil {
 				return err
 			}
-
-			devices, err := deviceutil.GetPartitionsInfo(d.SysPath, blockName.Name())
-			if err != nil {
-				return err
-			}
-			devices = append(devices, device)
-
-			for _, device := range devices {
-				var deviceInfo localv1alpha1.DeviceInfo
-				deviceInfo.Name = device.Name
-				deviceInfo.MediaType = device.MediaType
-				deviceInfo.ReadOnly = device.ReadOnly
-				deviceInfo.Total = device.Total
-				deviceInfo.Condition = localv1alpha1.StorageReady
-				newStatus.NodeStorageInfo.DeviceInfos = append(newStatus.NodeStorageInfo.DeviceInfos, deviceInfo)
-			}
+			newStatus.NodeStorageInfo.DeviceInfos = append(newStatus.NodeStorageInfo.DeviceInfos, deviceInfos...)
 		}
 	}
 
 	return nil
 }
+
+// discoverDisk returns the infos of the disk and its partitions, whose by-id links are taken from links
+func (d *Discoverer) discoverDisk(blockName string, links map[string]string) ([]localv1alpha1.DeviceInfo, error) {
+	device, err := deviceutil.GetBlockInfo(d.SysPath, blockName)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := deviceutil.GetPartitionsInfo(d.SysPath, blockName)
+	if err != nil {
+		return nil, err
+	}
+	devices = append(devices, device)
+	// partitions share the health of the disk
+	health := d.health.check(blockName)
+
+	var deviceInfos []localv1alpha1.DeviceInfo
+	for _, device := range devices {
+		var deviceInfo localv1alpha1.DeviceInfo
+		deviceInfo.Name = device.Name
+		deviceInfo.MediaType = device.MediaType
+		deviceInfo.Parent = device.ParentName
+		deviceInfo.ByID = links[device.Name]
+		deviceInfo.Serial = device.Serial
+		deviceInfo.WWN = device.WWN
+		deviceInfo.Model = device.Model
+		deviceInfo.PartLabel = device.PartLabel
+		deviceInfo.ReadOnly = device.ReadOnly
+		deviceInfo.Total = device.Total
+		deviceInfo.Condition = health.Condition
+		deviceInfos = append(deviceInfos, deviceInfo)
+	}
+
+	return deviceInfos, nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alibaba/open-local/pkg/agent/common"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+)
+
+func TestDiscoverNVMeDevices(t *testing.T) {
+	// fake sysfs of a nvme disk with two partitions, its hidden multipath path, a scsi disk and a loop device
+	dir, err := ioutil.TempDir("", "nvme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sysPath, byIDPath := filepath.Join(dir, "sys"), filepath.Join(dir, "by-id")
+	files := map[string]string{
+		"block/nvme0n1/queue/rotational":   "0",
+		"block/nvme0n1/ro":                 "0",
+		"block/nvme0n1/size":               "2097152",
+		"block/nvme0n1/wwid":               "eui.0025388b71b2c3d4",
+		"block/nvme0n1/device/serial":      "S4EWNX0N123456",
+		"block/nvme0n1/nvme0n1p1/ro":       "0",
+		"block/nvme0n1/nvme0n1p1/size":     "1048576",
+		"block/nvme0n1/nvme0n1p1/uevent":   "DEVTYPE=partition\nPARTNAME=open-local-pool-1",
+		"block/nvme0n1/nvme0n1p2/ro":       "0",
+		"block/nvme0n1/nvme0n1p2/size":     "1046528",
+		"block/nvme0c0n1/queue/rotational": "0",
+		"block/sdb/queue/rotational":       "1",
+		"block/sdb/ro":                     "0",
+		"block/sdb/size":                   "2097152",
+		"block/loop0/queue/rotational":     "0",
+	}
+	for name, data := range files {
+		path := filepath.Join(sysPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(byIDPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		"nvme-eui.0025388b71b2c3d4":       "../../nvme0n1",
+		"nvme-eui.0025388b71b2c3d4-part1": "../../nvme0n1p1",
+		"nvme-eui.0025388b71b2c3d4-part2": "../../nvme0n1p2",
+	} {
+		if err := os.Symlink(target, filepath.Join(byIDPath, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	health := newHealthChecker(sysPath)
+	health.run = func(cmd string) ([]byte, error) {
+		return nil, fmt.Errorf("%s: not found", strings.Fields(cmd)[0])
+	}
+	d := &Discoverer{
+		Configuration: &common.Configuration{SysPath: sysPath, RegExp: common.DefaultRegExp},
+		byIDPath:      byIDPath,
+		health:        health,
+	}
+	status := &localv1alpha1.NodeLocalStorageStatus{}
+	if err := d.discoverDevices(status); err != nil {
+		t.Fatalf("failed to discover devices: %v", err)
+	}
+	ready := localv1alpha1.StorageReady
+	// partitions are listed before their disk
+	want := []localv1alpha1.DeviceInfo{
+		{Name: "/dev/nvme0n1p1", ByID: filepath.Join(byIDPath, "nvme-eui.0025388b71b2c3d4-part1"), Parent: "/dev/nvme0n1", PartLabel: "open-local-pool-1", MediaType: "ssd", Total: 512 << 20, Condition: ready},
+		{Name: "/dev/nvme0n1p2", ByID: filepath.Join(byIDPath, "nvme-eui.0025388b71b2c3d4-part2"), Parent: "/dev/nvme0n1", MediaType: "ssd", Total: 511 << 20, Condition: ready},
+		{Name: "/dev/nvme0n1", ByID: filepath.Join(byIDPath, "nvme-eui.0025388b71b2c3d4"), Serial: "S4EWNX0N123456", WWN: "eui.0025388b71b2c3d4", MediaType: "ssd", Total: 1 << 30, Condition: ready},
+		{Name: "/dev/sdb", MediaType: "hdd", Total: 1 << 30, Condition: ready},
+	}
+	if got := status.NodeStorageInfo.DeviceInfos; !reflect.DeepEqual(got, want) {
+		t.Errorf("discoverDevices() = %+v, want %+v", got, want)
+	}
+}
@@ -21,20 +21,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
-	localtype "github.com/alibaba/open-local/pkg"
 	"github.com/alibaba/open-local/pkg/agent/common"
 	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
 	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
 	"github.com/alibaba/open-local/pkg/utils"
-	"github.com/alibaba/open-local/pkg/utils/lvm"
+	deviceutil "github.com/alibaba/open-local/pkg/utils/device"
 	units "github.com/docker/go-units"
 	snapshot "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
 	log "github.com/sirupsen/logrus"
-	corev1 "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -52,6 +52,14 @@ type Discoverer struct {
 	// K8sMounter used to verify mountpoints
 	K8sMounter mount.Interface
 	recorder   record.EventRecorder
+	// byIDPath is where the by-id links of block devices are
+	byIDPath string
+	// health collects the health of disks
+	health *healthChecker
+	// partitionErrors is the last error partitioning each disk in ResourceToBeInited
+	partitionErrors map[string]string
+	// lock serializes the periodic and the event-driven discoveries
+	lock sync.Mutex
 }
 
 type ReservedVGInfo struct {
@@ -68,17 +76,23 @@ const (
 // NewDiscoverer return Discoverer
 func NewDiscoverer(config *common.Configuration, kubeclientset kubernetes.Interface, localclientset clientset.Interface, snapclient snapshot.Interface, recorder record.EventRecorder) *Discoverer {
 	return &Discoverer{
-		Configuration:  config,
-		localclientset: localclientset,
-		kubeclientset:  kubeclientset,
-		snapclient:     snapclient,
-		K8sMounter:     mount.New("" /* default mount path */),
-		recorder:       recorder,
+		Configuration:   config,
+		localclientset:  localclientset,
+		kubeclientset:   kubeclientset,
+		snapclient:      snapclient,
+		K8sMounter:      mount.New("" /* default mount path */),
+		recorder:        recorder,
+		byIDPath:        deviceutil.ByIDPath,
+		health:          newHealthChecker(config.SysPath),
+		partitionErrors: make(map[string]string),
 	}
 }
 
 // Discover update local storage periodically
 func (d *Discoverer) Discover() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
 	if nls, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().Get(context.Background(), d.Nodename, metav1.GetOptions{}); err != nil {
 		if k8serr.IsNotFound(err) {
 			log.Infof("node local storage %s not found, waiting for the controller to create the resource", d.Nodename)
@@ -89,71 +103,74 @@ func (d *Discoverer) Discover() {
 		}
 	} else {
 		log.Debugf("update node local storage %s status", d.Nodename)
-		nlsCopy := nls.DeepCopy()
 		// get anno
-		reservedVGInfos := make(map[string]ReservedVGInfo)
-		if anno, exist := nlsCopy.Annotations[AnnoStorageReserve]; exist {
-			if reservedVGInfos, err = getReservedVGInfo(anno); err != nil {
-				log.Errorf("get reserved vg info failed: %s, but we ignore...", err.Error())
-				return
-			}
+		reservedVGInfos, err := d.reservedVGInfos(nls)
+		if err != nil {
+			log.Errorf("get reserved vg info failed: %s, but we ignore...", err.Error())
+			return
 		}
 		// get status first, for we need support regexp
 		newStatus := new(localv1alpha1.NodeLocalStorageStatus)
-		if err := d.discoverVGs(newStatus, reservedVGInfos); err != nil {
-			log.Errorf("discover VG error: %s", err.Error())
-			return
-		}
+		// devices go first, whose media types are used by VGs
 		if err := d.discoverDevices(newStatus); err != nil {
 			log.Errorf("discover Device error: %s", err.Error())
 			return
 		}
+		if err := d.discoverVGs(newStatus, reservedVGInfos); err != nil {
+			log.Errorf("discover VG error: %s", err.Error())
+			return
+		}
 		if err := d.discoverMountPoints(newStatus); err != nil {
 			log.Errorf("discover MountPoint error: %s", err.Error())
 			return
 		}
-		newStatus.NodeStorageInfo.Phase = localv1alpha1.NodeStorageRunning
-		newStatus.NodeStorageInfo.State.Status = localv1alpha1.ConditionTrue
-		newStatus.NodeStorageInfo.State.Type = localv1alpha1.StorageReady
-		newStatus.NodeStorageInfo.State.LastHeartbeatTime = metav1.Now()
-		nlsCopy.Status.NodeStorageInfo = newStatus.NodeStorageInfo
-		nlsCopy.Status.FilteredStorageInfo.VolumeGroups = FilterVGInfo(nlsCopy)
-		nlsCopy.Status.FilteredStorageInfo.MountPoints = FilterMPInfo(nlsCopy)
-		nlsCopy.Status.FilteredStorageInfo.Devices = FilterDeviceInfo(nlsCopy)
-		nlsCopy.Status.FilteredStorageInfo.UpdateStatus.Status = localv1alpha1.UpdateStatusAccepted
-		nlsCopy.Status.FilteredStorageInfo.UpdateStatus.LastUpdateTime = metav1.Now()
-		nlsCopy.Status.FilteredStorageInfo.UpdateStatus.Reason = ""
+		d.updateStatus(nls, newStatus)
+	}
+}
 
-		// only update status
-		log.Infof("update nls %s", nlsCopy.Name)
-		_, err = d.localclientset.CsiV1alpha1().NodeLocalStorages().UpdateStatus(context.Background(), nlsCopy, metav1.UpdateOptions{})
-		if err != nil {
-			log.Errorf("local storage CRD updateStatus error: %s", err.Error())
-			return
-		}
+func (d *Discoverer) reservedVGInfos(nls *localv1alpha1.NodeLocalStorage) (map[string]ReservedVGInfo, error) {
+	if anno, exist := nls.Annotations[AnnoStorageReserve]; exist {
+		return getReservedVGInfo(anno)
 	}
+	return make(map[string]ReservedVGInfo), nil
 }
 
-// InitResource will create relevant resource
+// updateStatus updates the node storage info of nls to the one of newStatus, and filters it again
+func (d *Discoverer) updateStatus(nls *localv1alpha1.NodeLocalStorage, newStatus *localv1alpha1.NodeLocalStorageStatus) {
+	d.recordHealthEvents(nls, newStatus)
+	nlsCopy := nls.DeepCopy()
+	newStatus.NodeStorageInfo.Phase = localv1alpha1.NodeStorageRunning
+	newStatus.NodeStorageInfo.State.Status = localv1alpha1.ConditionTrue
+	newStatus.NodeStorageInfo.State.Type = localv1alpha1.StorageReady
+	newStatus.NodeStorageInfo.State.LastHeartbeatTime = metav1.Now()
+	nlsCopy.Status.NodeStorageInfo = newStatus.NodeStorageInfo
+	nlsCopy.Status.FilteredStorageInfo.VolumeGroups = FilterVGInfo(nlsCopy)
+	nlsCopy.Status.FilteredStorageInfo.MountPoints = FilterMPInfo(nlsCopy)
+	nlsCopy.Status.FilteredStorageInfo.Devices = FilterDeviceInfo(nlsCopy)
+	nlsCopy.Status.FilteredStorageInfo.UpdateStatus.Status = localv1alpha1.UpdateStatusAccepted
+	nlsCopy.Status.FilteredStorageInfo.UpdateStatus.LastUpdateTime = metav1.Now()
+	nlsCopy.Status.FilteredStorageInfo.UpdateStatus.Reason = ""
+
+	// only update status
+	log.Infof("update nls %s", nlsCopy.Name)
+	if _, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().UpdateStatus(context.Background(), nlsCopy, metav1.UpdateOptions{}); err != nil {
+		log.Errorf("local storage CRD updateStatus error: %s", err.Error())
+	}
+}
+
+// InitResource will create relevant resource, and reconcile the vgs to their devices
 func (d *Discoverer) InitResource() {
 	nls, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().Get(context.Background(), d.Nodename, metav1.GetOptions{})
 	if err != nil {
 		log.Errorf("get node local storage %s failed: %s", d.Nodename, err.Error())
 		return
 	}
-	vgs := nls.Spec.ResourceToBeInited.VGs
-	mountpoints := nls.Spec.ResourceToBeInited.MountPoints
-	for _, vg := range vgs {
-
-		if _, err := lvm.LookupVolumeGroup(vg.Name); err == lvm.ErrVolumeGroupNotFound {
-			err := d.createVG(vg.Name, vg.Devices)
-			if err != nil {
-				msg := fmt.Sprintf("create vg %s with device %v failed: %s. you can try command \"vgcreate %s %v --force\" manually on this node", vg.Name, vg.Devices, err.Error(), vg.Name, strings.Join(vg.Devices, " "))
-				log.Error(msg)
-				d.recorder.Event(nls, corev1.EventTypeWarning, localtype.EventCreateVGFailed, msg)
-			}
-		}
+	// vgs and mountpoints may be on the new partitions, which are initialized after the partitions are discovered
+	if d.reconcilePartitions(nls) {
+		return
 	}
+	mountpoints := nls.Spec.ResourceToBeInited.MountPoints
+	d.reconcileVGs(nls)
 	for _, mp := range mountpoints {
 		notMounted, err := d.K8sMounter.IsLikelyNotMountPoint(mp.Path)
 		if err != nil && strings.Contains(err.Error(), "no such file or directory") {
@@ -163,6 +180,7 @@ func (d *Discoverer) InitResource() {
 			}
 		}
 		if notMounted {
+			mp.Device = resolveDevice(nls.Status.NodeStorageInfo.DeviceInfos, mp.Device)
 			fsType := mp.FsType
 			if fsType == "" {
 				fsType = DefaultFS
@@ -242,13 +260,55 @@ func FilterMPInfo(nls *localv1alpha1.NodeLocalStorage) []string {
 	return FilterInfo(mpSlice, nls.Spec.ListConfig.MountPoints.Include, nls.Spec.ListConfig.MountPoints.Exclude)
 }
 
+// FilterDeviceInfo returns the names of the devices, which are matched by their names, by-id links, serials, wwns or by-partlabel links
 func FilterDeviceInfo(nls *localv1alpha1.NodeLocalStorage) []string {
 	var devSlice []string
 	for _, dev := range nls.Status.NodeStorageInfo.DeviceInfos {
-		devSlice = append(devSlice, dev.Name)
+		ids := deviceIdentities(dev)
+		if matchInfo(ids, nls.Spec.ListConfig.Devices.Include) && !matchInfo(ids, nls.Spec.ListConfig.Devices.Exclude) {
+			devSlice = append(devSlice, dev.Name)
+		}
+	}
+
+	return devSlice
+}
+
+// deviceIdentities returns the name, by-id link, serial, wwn and by-partlabel link of the device which are known
+func deviceIdentities(dev localv1alpha1.DeviceInfo) []string {
+	var ids []string
+	for _, id := range []string{dev.Name, dev.ByID, dev.Serial, dev.WWN} {
+		if id != "" {
+			ids = append(ids, id)
+		}
 	}
+	if dev.PartLabel != "" {
+		ids = append(ids, filepath.Join(deviceutil.ByPartLabelPath, dev.PartLabel))
+	}
+	return ids
+}
+
+// resolveDevice returns the name of the device identified by its name, by-id link, serial, wwn or by-partlabel link,
+// id itself is returned if no device is identified
+func resolveDevice(devices []localv1alpha1.DeviceInfo, id string) string {
+	for _, dev := range devices {
+		if utils.ContainsString(deviceIdentities(dev), id) {
+			return dev.Name
+		}
+	}
+	return id
+}
 
-	return FilterInfo(devSlice, nls.Spec.ListConfig.Devices.Include, nls.Spec.ListConfig.Devices.Exclude)
+// matchInfo returns true if any of info is matched by any of the regexps
+func matchInfo(info []string, regs []string) bool {
+	for _, r := range regs {
+		reg := regexp.MustCompile(r)
+		for _, i := range info {
+			if reg.FindString(i) == i {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func FilterInfo(info []string, include []string, exclude []string) []string {
@@ -17,7 +17,15 @@ limitations under the License.
 package discovery
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/alibaba/open-local/pkg/agent/common"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	deviceutil "github.com/alibaba/open-local/pkg/utils/device"
 )
 
 func TestFilterInfo(t *testing.T) {
@@ -59,6 +67,116 @@ func TestFilterInfo(t *testing.T) {
 	}
 }
 
+func TestVGMediaType(t *testing.T) {
+	// fake sysfs of a hdd sdb with partition sdb1 and a ssd sdc
+	sysPath, err := ioutil.TempDir("", "sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sysPath)
+	files := map[string]string{
+		"block/sdb/queue/rotational": "1\n",
+		"block/sdb/sdb1/partition":   "1\n",
+		"block/sdc/queue/rotational": "0\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(sysPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(sysPath, "class/block"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{"sdb": "../../block/sdb", "sdb1": "../../block/sdb/sdb1", "sdc": "../../block/sdc"} {
+		if err := os.Symlink(target, filepath.Join(sysPath, "class/block", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := &Discoverer{Configuration: &common.Configuration{SysPath: sysPath}}
+	devices := []localv1alpha1.DeviceInfo{{Name: "/dev/sdd", MediaType: "ssd"}}
+	tests := []struct {
+		name string
+		pvs  []string
+		want string
+	}{
+		{"partition of hdd", []string{"/dev/sdb1"}, "hdd"},
+		{"ssd disks", []string{"/dev/sdc", "/dev/sdd"}, "ssd"},
+		{"mixed", []string{"/dev/sdb1", "/dev/sdc"}, ""},
+		{"unknown", []string{"/dev/sde"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.vgMediaType(tt.pvs, devices); got != tt.want {
+				t.Errorf("vgMediaType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterDeviceInfo(t *testing.T) {
+	nls := &localv1alpha1.NodeLocalStorage{}
+	nls.Status.NodeStorageInfo.DeviceInfos = []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb", ByID: "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", Serial: "ZA1B2C3D", WWN: "naa.5000c500a0b1c2d3"},
+		{Name: "/dev/sdb1", ByID: "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3-part1", Parent: "/dev/sdb"},
+		{Name: "/dev/sdc", Serial: "ZA4E5F6G"},
+		{Name: "/dev/sdd"},
+		{Name: "/dev/sdd1", Parent: "/dev/sdd", PartLabel: "raw-0"},
+	}
+	nls.Spec.ListConfig.Devices.Include = []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3.*", "ZA.*", "/dev/disk/by-partlabel/raw-.*"}
+	nls.Spec.ListConfig.Devices.Exclude = []string{"/dev/sdb1"}
+
+	if got, want := FilterDeviceInfo(nls), []string{"/dev/sdb", "/dev/sdc", "/dev/sdd1"}; !sameStringSlice(got, want) {
+		t.Errorf("FilterDeviceInfo() = %v, want %v", got, want)
+	}
+	for id, want := range map[string]string{
+		"naa.5000c500a0b1c2d3":                         "/dev/sdb",
+		"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3-part1": "/dev/sdb1",
+		"/dev/sdd":                     "/dev/sdd",
+		"/dev/disk/by-partlabel/raw-0": "/dev/sdd1",
+		"/dev/sde":                     "/dev/sde",
+	} {
+		if got := resolveDevice(nls.Status.NodeStorageInfo.DeviceInfos, id); got != want {
+			t.Errorf("resolveDevice(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestGetByIDLinks(t *testing.T) {
+	byIDPath, err := ioutil.TempDir("", "by-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(byIDPath)
+	for link, target := range map[string]string{
+		"ata-ST4000NM0035_ZA1B2C3D":       "../../sdb",
+		"wwn-0x5000c500a0b1c2d3":          "../../sdb",
+		"ata-ST4000NM0035_ZA1B2C3D-part1": "../../sdb1",
+		"virtio-bp1a2b3c4d5e6f":           "../../vdb",
+	} {
+		if err := os.Symlink(target, filepath.Join(byIDPath, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links, err := deviceutil.GetByIDLinks(byIDPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"/dev/sdb":  filepath.Join(byIDPath, "wwn-0x5000c500a0b1c2d3"),
+		"/dev/sdb1": filepath.Join(byIDPath, "ata-ST4000NM0035_ZA1B2C3D-part1"),
+		"/dev/vdb":  filepath.Join(byIDPath, "virtio-bp1a2b3c4d5e6f"),
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("GetByIDLinks() = %v, want %v", links, want)
+	}
+}
+
 func sameStringSlice(x, y []string) bool {
 	if len(x) != len(y) {
 		return false
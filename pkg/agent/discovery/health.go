@@ -0,0 +1,327 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ioErrorWindow is the duration that a disk stays degraded after its I/O error counter increases
+const ioErrorWindow = 10 * time.Minute
+
+// DiskHealth is the health of a disk collected by smartctl, nvme-cli or sysfs
+type DiskHealth struct {
+	Condition localv1alpha1.StorageConditionType
+	Reason    string
+}
+
+// healthChecker collects the health of disks, it remembers the I/O error counters of disks to find new errors
+type healthChecker struct {
+	sysPath string
+	// run runs the command on the host and returns its stdout, which is kept when the command fails
+	run func(cmd string) ([]byte, error)
+	// tools records whether smartctl and nvme are installed on the host
+	tools map[string]bool
+	// ioErrors records the I/O error counter of each disk and when it increased
+	ioErrors map[string]ioErrorRecord
+	// last records the last health of each disk, whose reason is used by events
+	last map[string]DiskHealth
+}
+
+type ioErrorRecord struct {
+	count       uint64
+	increasedAt time.Time
+}
+
+func newHealthChecker(sysPath string) *healthChecker {
+	return &healthChecker{
+		sysPath: sysPath,
+		run: func(cmd string) ([]byte, error) {
+			return exec.Command("sh", "-c", localtype.NsenterCmd+cmd).Output()
+		},
+		tools:    make(map[string]bool),
+		ioErrors: make(map[string]ioErrorRecord),
+		last:     make(map[string]DiskHealth),
+	}
+}
+
+// check returns the worst health of the disk, e.g. sdb, reported by its sysfs state, SMART and I/O error counter
+func (h *healthChecker) check(disk string) DiskHealth {
+	health := DiskHealth{Condition: localv1alpha1.StorageReady}
+	devicePath := filepath.Join(h.sysPath, "block", disk, "device")
+	if state := readSysfs(filepath.Join(devicePath, "state")); state != "" && state != "running" && state != "live" {
+		health = worseHealth(health, DiskHealth{localv1alpha1.StorageFailed, fmt.Sprintf("device state is %s", state)})
+	}
+	if h.installed("smartctl") {
+		health = worseHealth(health, h.checkSmartctl(disk))
+	} else if strings.HasPrefix(disk, "nvme") && h.installed("nvme") {
+		health = worseHealth(health, h.checkNVMe(disk))
+	}
+	if count, err := parseSysfsCounter(readSysfs(filepath.Join(devicePath, "ioerr_cnt"))); err == nil {
+		record, ok := h.ioErrors[disk]
+		if ok && count > record.count {
+			record.increasedAt = time.Now()
+		}
+		record.count = count
+		h.ioErrors[disk] = record
+		if !record.increasedAt.IsZero() && time.Since(record.increasedAt) < ioErrorWindow {
+			health = worseHealth(health, DiskHealth{localv1alpha1.StorageDegraded, fmt.Sprintf("I/O error counter increased to %d", count)})
+		}
+	}
+	h.last[disk] = health
+	return health
+}
+
+func (h *healthChecker) installed(tool string) bool {
+	installed, ok := h.tools[tool]
+	if !ok {
+		_, err := h.run("which " + tool)
+		installed = err == nil
+		h.tools[tool] = installed
+		log.Infof("%s installed: %t", tool, installed)
+	}
+	return installed
+}
+
+type smartctlOutput struct {
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	NVMeHealth *nvmeSmartLog `json:"nvme_smart_health_information_log"`
+	ATA        *struct {
+		Table []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Raw  struct {
+				Value uint64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+}
+
+type nvmeSmartLog struct {
+	CriticalWarning int `json:"critical_warning"`
+	PercentageUsed  int `json:"percentage_used"`
+	// PercentUsed is the name used by nvme-cli
+	PercentUsed int `json:"percent_used"`
+}
+
+// ataDegradedAttributes are the SMART attributes of failing sectors
+var ataDegradedAttributes = map[int]bool{
+	5:   true, // Reallocated_Sector_Ct
+	197: true, // Current_Pending_Sector
+	198: true, // Offline_Uncorrectable
+}
+
+func (h *healthChecker) checkSmartctl(disk string) DiskHealth {
+	// the exit status of smartctl is a bitmask of the problems found, the output is parsed anyway
+	out, _ := h.run(fmt.Sprintf("smartctl -H -A -j /dev/%s", disk))
+	return parseSmartctl(out)
+}
+
+func parseSmartctl(out []byte) DiskHealth {
+	health := DiskHealth{Condition: localv1alpha1.StorageReady}
+	var output smartctlOutput
+	if err := json.Unmarshal(out, &output); err != nil {
+		return health
+	}
+	if output.SmartStatus != nil && !output.SmartStatus.Passed {
+		return DiskHealth{localv1alpha1.StorageFailed, "SMART overall-health self-assessment test failed"}
+	}
+	if output.NVMeHealth != nil {
+		health = worseHealth(health, nvmeHealth(output.NVMeHealth))
+	}
+	if output.ATA != nil {
+		for _, attr := range output.ATA.Table {
+			if ataDegradedAttributes[attr.ID] && attr.Raw.Value > 0 {
+				health = worseHealth(health, DiskHealth{localv1alpha1.StorageDegraded, fmt.Sprintf("SMART attribute %s is %d", attr.Name, attr.Raw.Value)})
+			}
+		}
+	}
+	return health
+}
+
+func (h *healthChecker) checkNVMe(disk string) DiskHealth {
+	out, err := h.run(fmt.Sprintf("nvme smart-log -o json /dev/%s", disk))
+	if err != nil {
+		log.Warningf("get smart log of %s error: %s", disk, err.Error())
+		return DiskHealth{Condition: localv1alpha1.StorageReady}
+	}
+	var smartLog nvmeSmartLog
+	if err := json.Unmarshal(out, &smartLog); err != nil {
+		return DiskHealth{Condition: localv1alpha1.StorageReady}
+	}
+	return nvmeHealth(&smartLog)
+}
+
+func nvmeHealth(smartLog *nvmeSmartLog) DiskHealth {
+	if smartLog.CriticalWarning != 0 {
+		return DiskHealth{localv1alpha1.StorageDegraded, fmt.Sprintf("NVMe critical warning is %#x", smartLog.CriticalWarning)}
+	}
+	if used := smartLog.PercentageUsed + smartLog.PercentUsed; used >= 100 {
+		return DiskHealth{localv1alpha1.StorageDegraded, fmt.Sprintf("NVMe percentage used is %d%%", used)}
+	}
+	return DiskHealth{Condition: localv1alpha1.StorageReady}
+}
+
+// conditionSeverity orders the conditions, the condition of a vg is the worst one of its pvs
+var conditionSeverity = map[localv1alpha1.StorageConditionType]int{
+	localv1alpha1.StorageReady:    0,
+	localv1alpha1.StorageFull:     1,
+	localv1alpha1.StorageDegraded: 2,
+	localv1alpha1.StorageFault:    3,
+	localv1alpha1.StorageFailed:   3,
+}
+
+func worseHealth(a, b DiskHealth) DiskHealth {
+	if conditionSeverity[b.Condition] > conditionSeverity[a.Condition] {
+		return b
+	}
+	return a
+}
+
+func isUnhealthy(condition localv1alpha1.StorageConditionType) bool {
+	return conditionSeverity[condition] >= conditionSeverity[localv1alpha1.StorageDegraded]
+}
+
+// vgCondition returns the worst condition of the pvs, which are taken from the discovered devices
+func vgCondition(pvs []string, devices []localv1alpha1.DeviceInfo) localv1alpha1.StorageConditionType {
+	conditions := make(map[string]localv1alpha1.StorageConditionType, len(devices))
+	for _, device := range devices {
+		conditions[device.Name] = device.Condition
+	}
+	condition := localv1alpha1.StorageReady
+	for _, pv := range pvs {
+		if c, ok := conditions[pv]; ok && conditionSeverity[c] > conditionSeverity[condition] {
+			condition = c
+		}
+	}
+	return condition
+}
+
+// CheckHealth updates the conditions of the disks and the volume groups, for the health
+// of disks changes without uevents
+func (d *Discoverer) CheckHealth() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	nls, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().Get(context.Background(), d.Nodename, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("get NodeLocalStorages failed: %s", err.Error())
+		return
+	}
+	if nls.Status.NodeStorageInfo.Phase != localv1alpha1.NodeStorageRunning {
+		return
+	}
+	newStatus := nls.Status.DeepCopy()
+	changed := false
+	devices := newStatus.NodeStorageInfo.DeviceInfos
+	for i := range devices {
+		if devices[i].Parent != "" {
+			continue
+		}
+		condition := d.health.check(filepath.Base(devices[i].Name)).Condition
+		for j := range devices {
+			if (j == i || devices[j].Parent == devices[i].Name) && devices[j].Condition != condition {
+				devices[j].Condition = condition
+				changed = true
+			}
+		}
+	}
+	for i, vg := range newStatus.NodeStorageInfo.VolumeGroups {
+		condition := vgCondition(vg.PhysicalVolumes, devices)
+		if condition == localv1alpha1.StorageReady && vg.Available == 0 {
+			condition = localv1alpha1.StorageFull
+		}
+		if vg.Condition != condition {
+			newStatus.NodeStorageInfo.VolumeGroups[i].Condition = condition
+			changed = true
+		}
+	}
+	if changed {
+		d.updateStatus(nls, newStatus)
+	}
+}
+
+// recordHealthEvents emits events for the disks and the volume groups whose health changes
+func (d *Discoverer) recordHealthEvents(nls *localv1alpha1.NodeLocalStorage, newStatus *localv1alpha1.NodeLocalStorageStatus) {
+	oldConditions := make(map[string]localv1alpha1.StorageConditionType)
+	for _, device := range nls.Status.NodeStorageInfo.DeviceInfos {
+		oldConditions[device.Name] = device.Condition
+	}
+	for _, vg := range nls.Status.NodeStorageInfo.VolumeGroups {
+		oldConditions["vg/"+vg.Name] = vg.Condition
+	}
+	record := func(kind, name, key string, condition localv1alpha1.StorageConditionType, reason string) {
+		old := oldConditions[key]
+		if isUnhealthy(condition) && old != condition {
+			eventReason := localtype.EventStorageDegraded
+			if condition != localv1alpha1.StorageDegraded {
+				eventReason = localtype.EventStorageFailed
+			}
+			msg := fmt.Sprintf("%s %s is %s", kind, name, condition)
+			if reason != "" {
+				msg = fmt.Sprintf("%s: %s", msg, reason)
+			}
+			log.Warning(msg)
+			d.recorder.Event(nls, corev1.EventTypeWarning, eventReason, msg)
+		} else if isUnhealthy(old) && !isUnhealthy(condition) {
+			msg := fmt.Sprintf("%s %s is recovered from %s", kind, name, old)
+			log.Info(msg)
+			d.recorder.Event(nls, corev1.EventTypeNormal, localtype.EventStorageRecovered, msg)
+		}
+	}
+	// partitions share the health of their disks
+	for _, device := range newStatus.NodeStorageInfo.DeviceInfos {
+		if device.Parent == "" {
+			record("disk", device.Name, device.Name, device.Condition, d.health.last[filepath.Base(device.Name)].Reason)
+		}
+	}
+	for _, vg := range newStatus.NodeStorageInfo.VolumeGroups {
+		record("vg", vg.Name, "vg/"+vg.Name, vg.Condition, "")
+	}
+}
+
+func readSysfs(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parseSysfsCounter parses the counters like ioerr_cnt, which are in hex
+func parseSysfsCounter(value string) (uint64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("empty counter")
+	}
+	return strconv.ParseUint(value, 0, 64)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+)
+
+func TestParseSmartctl(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   localv1alpha1.StorageConditionType
+	}{
+		{"passed", `{"smart_status":{"passed":true},"ata_smart_attributes":{"table":[{"id":5,"name":"Reallocated_Sector_Ct","raw":{"value":0}}]}}`, localv1alpha1.StorageReady},
+		{"failed", `{"smart_status":{"passed":false}}`, localv1alpha1.StorageFailed},
+		{"reallocated sectors", `{"smart_status":{"passed":true},"ata_smart_attributes":{"table":[{"id":5,"name":"Reallocated_Sector_Ct","raw":{"value":8}}]}}`, localv1alpha1.StorageDegraded},
+		{"nvme critical warning", `{"smart_status":{"passed":true},"nvme_smart_health_information_log":{"critical_warning":4,"percentage_used":3}}`, localv1alpha1.StorageDegraded},
+		{"nvme worn out", `{"smart_status":{"passed":true},"nvme_smart_health_information_log":{"critical_warning":0,"percentage_used":100}}`, localv1alpha1.StorageDegraded},
+		{"not json", `smartctl: command failed`, localv1alpha1.StorageReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSmartctl([]byte(tt.output)); got.Condition != tt.want {
+				t.Errorf("parseSmartctl() = %+v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerCheck(t *testing.T) {
+	sysPath, err := ioutil.TempDir("", "sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sysPath)
+	writeSysfs := func(disk, name, value string) {
+		dir := filepath.Join(sysPath, "block", disk, "device")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// neither smartctl nor nvme is installed
+	h := newHealthChecker(sysPath)
+	h.run = func(cmd string) ([]byte, error) {
+		return nil, fmt.Errorf("%s: not found", strings.Fields(cmd)[0])
+	}
+	writeSysfs("sdb", "state", "running")
+	writeSysfs("sdb", "ioerr_cnt", "0x2")
+	writeSysfs("sdc", "state", "offline")
+	if got := h.check("sdb"); got.Condition != localv1alpha1.StorageReady {
+		t.Errorf("expect sdb ready, got %+v", got)
+	}
+	if got := h.check("sdc"); got.Condition != localv1alpha1.StorageFailed {
+		t.Errorf("expect sdc failed, got %+v", got)
+	}
+	// new I/O errors degrade the disk
+	writeSysfs("sdb", "ioerr_cnt", "0x5")
+	if got := h.check("sdb"); got.Condition != localv1alpha1.StorageDegraded {
+		t.Errorf("expect sdb degraded, got %+v", got)
+	}
+}
+
+func TestVGCondition(t *testing.T) {
+	devices := []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb", Condition: localv1alpha1.StorageDegraded},
+		{Name: "/dev/sdb1", Parent: "/dev/sdb", Condition: localv1alpha1.StorageDegraded},
+		{Name: "/dev/sdc", Condition: localv1alpha1.StorageReady},
+		{Name: "/dev/sdd", Condition: localv1alpha1.StorageFailed},
+	}
+	tests := []struct {
+		pvs  []string
+		want localv1alpha1.StorageConditionType
+	}{
+		{[]string{"/dev/sdc"}, localv1alpha1.StorageReady},
+		{[]string{"/dev/sdc", "/dev/sdb1"}, localv1alpha1.StorageDegraded},
+		{[]string{"/dev/sdb1", "/dev/sdd"}, localv1alpha1.StorageFailed},
+		{[]string{"/dev/mapper/unknown"}, localv1alpha1.StorageReady},
+	}
+	for _, tt := range tests {
+		if got := vgCondition(tt.pvs, devices); got != tt.want {
+			t.Errorf("vgCondition(%v) = %s, want %s", tt.pvs, got, tt.want)
+		}
+	}
+}
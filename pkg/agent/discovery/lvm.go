@@ -22,6 +22,7 @@ import (
 
 	localtype "github.com/alibaba/open-local/pkg"
 	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	deviceutil "github.com/alibaba/open-local/pkg/utils/device"
 	"github.com/alibaba/open-local/pkg/utils/lvm"
 	log "github.com/sirupsen/logrus"
 )
@@ -34,79 +35,130 @@ func (d *Discoverer) discoverVGs(newStatus *localv1alpha1.NodeLocalStorageStatus
 	}
 
 	for _, vgname := range vgnames {
-		var vgCrd localv1alpha1.VolumeGroup
-		vgCrd.Condition = localv1alpha1.StorageReady
-		// Name
-		vg, err := lvm.LookupVolumeGroup(vgname)
+		vgCrd, err := d.discoverVG(vgname, newStatus.NodeStorageInfo.DeviceInfos, reservedVGInfo)
 		if err != nil {
-			log.Errorf("Look up volume group %s error: %s", vgname, err.Error())
+			log.Error(err.Error())
 			continue
 		}
-		vgCrd.Name = vg.Name()
+		newStatus.NodeStorageInfo.VolumeGroups = append(newStatus.NodeStorageInfo.VolumeGroups, *vgCrd)
+	}
+
+	return nil
+}
+
+// discoverVG returns the info of the vg, whose media type is taken from devices
+func (d *Discoverer) discoverVG(vgname string, devices []localv1alpha1.DeviceInfo, reservedVGInfo map[string]ReservedVGInfo) (*localv1alpha1.VolumeGroup, error) {
+	var vgCrd localv1alpha1.VolumeGroup
+	// Name
+	vg, err := lvm.LookupVolumeGroup(vgname)
+	if err != nil {
+		return nil, fmt.Errorf("Look up volume group %s error: %s", vgname, err.Error())
+	}
+	vgCrd.Name = vg.Name()
+
+	// PV
+	vgCrd.PhysicalVolumes, err = vg.ListPhysicalVolumeNames()
+	if err != nil {
+		return nil, fmt.Errorf("List physical volume %s error: %s", vgname, err.Error())
+	}
+	vgCrd.MediaType = d.vgMediaType(vgCrd.PhysicalVolumes, devices)
+	// the vg is as healthy as its worst pv
+	vgCrd.Condition = vgCondition(vgCrd.PhysicalVolumes, devices)
+	// total & available
+	vgCrd.Total, _ = vg.BytesTotal()
+	vgCrd.Available, _ = vg.BytesFree()
+	if vgCrd.Condition == localv1alpha1.StorageReady && vgCrd.Available == 0 {
+		vgCrd.Condition = localv1alpha1.StorageFull
+	}
 
-		// PV
-		vgCrd.PhysicalVolumes, err = vg.ListPhysicalVolumeNames()
+	// LogicalVolumes
+	logicalVolumeNames, err := vg.ListLogicalVolumeNames()
+	if err != nil {
+		return nil, fmt.Errorf("List volume group %s error: %s", vgname, err.Error())
+	}
+	vgCrd.Allocatable = vgCrd.Total
+	for _, lvname := range logicalVolumeNames {
+		var lv localv1alpha1.LogicalVolume
+		lv.Name = lvname
+		lv.VGName = vgname
+		tmplv, err := vg.LookupLogicalVolume(lvname)
 		if err != nil {
-			log.Errorf("List physical volume %s error: %s", vgname, err.Error())
+			log.Errorf("List logical volume %s error: %s", lvname, err.Error())
 			continue
 		}
-		// total & available
-		vgCrd.Total, _ = vg.BytesTotal()
-		vgCrd.Available, _ = vg.BytesFree()
-		if vgCrd.Available == 0 {
-			vgCrd.Condition = localv1alpha1.StorageFull
+		lv.Total = tmplv.SizeInBytes()
+		// thin pool is carved out of vg, so it is not allocatable for thick volumes
+		if !d.isLocalLV(lvname) || lvname == localtype.ThinPoolName {
+			vgCrd.Allocatable -= lv.Total
 		}
+		lv.Condition = localv1alpha1.StorageReady
+		vgCrd.LogicalVolumes = append(vgCrd.LogicalVolumes, lv)
+	}
 
-		// LogicalVolumes
-		logicalVolumeNames, err := vg.ListLogicalVolumeNames()
-		if err != nil {
-			log.Errorf("List volume group %s error: %s", vgname, err.Error())
-			continue
-		}
-		vgCrd.Allocatable = vgCrd.Total
-		for _, lvname := range logicalVolumeNames {
-			var lv localv1alpha1.LogicalVolume
-			lv.Name = lvname
-			lv.VGName = vgname
-			tmplv, err := vg.LookupLogicalVolume(lvname)
-			if err != nil {
-				log.Errorf("List logical volume %s error: %s", lvname, err.Error())
-				continue
-			}
-			lv.Total = tmplv.SizeInBytes()
-			if !d.isLocalLV(lvname) {
-				vgCrd.Allocatable -= lv.Total
-			}
-			lv.Condition = localv1alpha1.StorageReady
-			vgCrd.LogicalVolumes = append(vgCrd.LogicalVolumes, lv)
+	// ThinPool
+	pool, err := vg.LookupThinPool(localtype.ThinPoolName)
+	if err == nil {
+		// the metadata of thin pool is a hidden lv
+		vgCrd.Allocatable -= pool.MetadataSizeInBytes()
+		vgCrd.ThinPool = &localv1alpha1.ThinPool{
+			Name:             pool.Name(),
+			Total:            pool.SizeInBytes(),
+			DataUsed:         uint64(float64(pool.SizeInBytes()) * pool.DataUsage()),
+			MetadataTotal:    pool.MetadataSizeInBytes(),
+			MetadataUsed:     uint64(float64(pool.MetadataSizeInBytes()) * pool.MetadataUsage()),
+			VirtualAllocated: pool.VirtualSizeInBytes(),
 		}
+	} else if err != lvm.ErrLogicalVolumeNotFound {
+		log.Errorf("Look up thin pool of volume group %s error: %s", vgname, err.Error())
+	}
 
-		// check if vgCrd.Allocatable is correct
-		if info, exist := reservedVGInfo[vg.Name()]; exist {
-			// reservedPercent
-			var reservedSize uint64
-			if info.reservedSize != 0 {
-				reservedSize = info.reservedSize
-			} else {
-				reservedSize = uint64(float64(vgCrd.Total) * info.reservedPercent)
-			}
+	// check if vgCrd.Allocatable is correct
+	if info, exist := reservedVGInfo[vg.Name()]; exist {
+		// reservedPercent
+		var reservedSize uint64
+		if info.reservedSize != 0 {
+			reservedSize = info.reservedSize
+		} else {
+			reservedSize = uint64(float64(vgCrd.Total) * info.reservedPercent)
+		}
 
-			if vgCrd.Allocatable > vgCrd.Total-reservedSize {
-				vgCrd.Allocatable = vgCrd.Total - info.reservedSize
-			}
+		if vgCrd.Allocatable > vgCrd.Total-reservedSize {
+			vgCrd.Allocatable = vgCrd.Total - info.reservedSize
 		}
+	}
 
-		// Todo(huizhi.szh): vg.Check(): Failed to connect to lvmetad. Falling back to device scanning.
-		// if err = vg.Check(); err != nil {
-		// 	log.Errorf("volume %s check error: %s", vgname, err.Error())
-		// 	vgCrd.Condition = lssv1alpha1.StorageFault
-		// }
-		vgCrd.Condition = localv1alpha1.StorageReady
+	// Todo(huizhi.szh): vg.Check(): Failed to connect to lvmetad. Falling back to device scanning.
+	// if err = vg.Check(); err != nil {
+	// 	log.Errorf("volume %s check error: %s", vgname, err.Error())
+	// 	vgCrd.Condition = lssv1alpha1.StorageFault
+	// }
 
-		newStatus.NodeStorageInfo.VolumeGroups = append(newStatus.NodeStorageInfo.VolumeGroups, vgCrd)
-	}
+	return &vgCrd, nil
+}
 
-	return nil
+// vgMediaType returns the media type shared by all the pvs, which is taken from the discovered devices
+// or sysfs, it is empty if the pvs are of different or unknown media types
+func (d *Discoverer) vgMediaType(pvs []string, devices []localv1alpha1.DeviceInfo) string {
+	deviceMediaTypes := make(map[string]string, len(devices))
+	for _, device := range devices {
+		deviceMediaTypes[device.Name] = device.MediaType
+	}
+	mediaType := ""
+	for i, pv := range pvs {
+		pvMediaType, ok := deviceMediaTypes[pv]
+		if !ok {
+			var err error
+			if pvMediaType, err = deviceutil.GetMediaType(d.SysPath, pv); err != nil {
+				log.Warningf("get media type of pv %s error: %s", pv, err.Error())
+				return ""
+			}
+		}
+		if i > 0 && pvMediaType != mediaType {
+			return ""
+		}
+		mediaType = pvMediaType
+	}
+	return mediaType
 }
 
 func (d *Discoverer) createVG(vgname string, devices []string) error {
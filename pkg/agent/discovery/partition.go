@@ -0,0 +1,218 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"github.com/alibaba/open-local/pkg/utils/partition"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// reconcilePartitions creates the partitions in ResourceToBeInited, it returns true if any partition is created
+func (d *Discoverer) reconcilePartitions(nls *localv1alpha1.NodeLocalStorage) bool {
+	if len(nls.Spec.ResourceToBeInited.Partitions) == 0 {
+		return false
+	}
+	claimed, err := d.claimedDevices(nls)
+	if err != nil {
+		log.Errorf("get devices claimed by pvs failed: %s", err.Error())
+		return false
+	}
+	created := false
+	for _, spec := range nls.Spec.ResourceToBeInited.Partitions {
+		ok, err := d.partitionDisk(nls, claimed, spec)
+		if err != nil {
+			// the same error is only reported once
+			msg := fmt.Sprintf("partition %s failed: %s", spec.Device, err.Error())
+			if d.partitionErrors[spec.Device] != msg {
+				log.Error(msg)
+				d.recorder.Event(nls, corev1.EventTypeWarning, localtype.EventPartitionFailed, msg)
+				d.partitionErrors[spec.Device] = msg
+			}
+			continue
+		}
+		delete(d.partitionErrors, spec.Device)
+		created = created || ok
+	}
+	return created
+}
+
+// partitionDisk creates the partitions missing on the disk, it returns true if any partition is created.
+// The disk is never wiped if it or any of its partitions is in use, even if the spec is forced.
+func (d *Discoverer) partitionDisk(nls *localv1alpha1.NodeLocalStorage, claimed map[string]string, spec localv1alpha1.PartitionsToBeInited) (bool, error) {
+	devices := nls.Status.NodeStorageInfo.DeviceInfos
+	name := resolveDevice(devices, spec.Device)
+	var disk *localv1alpha1.DeviceInfo
+	for i := range devices {
+		if devices[i].Name == name {
+			disk = &devices[i]
+			break
+		}
+	}
+	if disk == nil {
+		return false, fmt.Errorf("disk is not found")
+	}
+	if disk.Parent != "" {
+		return false, fmt.Errorf("%s is a partition of %s", name, disk.Parent)
+	}
+
+	table, err := partition.ReadTable(name)
+	if err != nil {
+		return false, err
+	}
+	var signatures []string
+	if table == nil {
+		if signatures, err = partition.Signatures(name); err != nil {
+			return false, err
+		}
+	}
+	specs, appendOnly, wipe, err := planPartitions(spec, disk.Total, table, signatures)
+	if err != nil || len(specs) == 0 {
+		return false, err
+	}
+	if wipe {
+		if err := diskInUse(devices, nls.Status.NodeStorageInfo.VolumeGroups, claimed, name); err != nil {
+			return false, fmt.Errorf("refuse to repartition the disk: %s", err.Error())
+		}
+	}
+	if err := partition.Create(name, specs, appendOnly, wipe); err != nil {
+		return false, err
+	}
+	log.Infof("partitions %v are created on %s", specs, name)
+	return true, nil
+}
+
+// planPartitions returns the partitions to be created on the disk, which are appended to the table
+// if its partitions are the leading ones of the spec. Otherwise the disk is partitioned from scratch,
+// which wipes the disk and is only allowed if the disk has no signatures or the spec is forced.
+func planPartitions(spec localv1alpha1.PartitionsToBeInited, total uint64, table *partition.Table, signatures []string) (specs []partition.Spec, appendOnly, wipe bool, err error) {
+	wanted, err := partitionSpecs(spec.Partitions, total)
+	if err != nil {
+		return nil, false, false, err
+	}
+	if table != nil && table.Label == "gpt" && len(table.Partitions) <= len(wanted) {
+		matched := true
+		for i, p := range table.Partitions {
+			if p.Name != wanted[i].Name {
+				matched = false
+				break
+			}
+		}
+		if matched && len(table.Partitions) == len(wanted) {
+			return nil, false, false, nil
+		}
+		if matched {
+			return wanted[len(table.Partitions):], true, false, nil
+		}
+	}
+	if table == nil && len(signatures) == 0 {
+		return wanted, false, false, nil
+	}
+	if !spec.Force {
+		if table != nil {
+			var names []string
+			for _, p := range table.Partitions {
+				names = append(names, p.Name)
+			}
+			return nil, false, false, fmt.Errorf("the %s partition table with partitions %v does not match, set force to repartition the disk", table.Label, names)
+		}
+		return nil, false, false, fmt.Errorf("the disk has signatures %v, set force to wipe the disk", signatures)
+	}
+	return wanted, false, true, nil
+}
+
+// diskInUse returns an error if the disk or any of its partitions is claimed by a pv or is a physical volume of a volume group
+func diskInUse(devices []localv1alpha1.DeviceInfo, vgs []localv1alpha1.VolumeGroup, claimed map[string]string, disk string) error {
+	onDisk := map[string]bool{disk: true}
+	for _, info := range devices {
+		if info.Parent == disk {
+			onDisk[info.Name] = true
+		}
+	}
+	for _, info := range devices {
+		if !onDisk[info.Name] {
+			continue
+		}
+		if pv := claimedBy(claimed, devices, info.Name); pv != "" {
+			return fmt.Errorf("%s is claimed by pv %s", info.Name, pv)
+		}
+	}
+	for _, vg := range vgs {
+		for _, pv := range vg.PhysicalVolumes {
+			if onDisk[pv] {
+				return fmt.Errorf("%s is a physical volume of vg %s", pv, vg.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// partitionSpecs returns the partitions with their sizes in bytes
+func partitionSpecs(partitions []localv1alpha1.PartitionToBeInited, total uint64) ([]partition.Spec, error) {
+	var specs []partition.Spec
+	for i, p := range partitions {
+		spec := partition.Spec{Name: p.Name}
+		if p.Size == "" {
+			if i != len(partitions)-1 {
+				return nil, fmt.Errorf("size of partition %s is empty, which is only allowed for the last partition", p.Name)
+			}
+		} else {
+			size, err := parsePartitionSize(p.Size, total)
+			if err != nil {
+				return nil, fmt.Errorf("size of partition %s is invalid: %s", p.Name, err.Error())
+			}
+			spec.Size = size
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// parsePartitionSize parses the size like 100Gi, or the percentage of the disk like 30%
+func parsePartitionSize(size string, total uint64) (uint64, error) {
+	var bytes uint64
+	if strings.HasSuffix(size, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(size, "%"), 64)
+		if err != nil {
+			return 0, err
+		}
+		if percent <= 0 || percent > 100 {
+			return 0, fmt.Errorf("percentage %s is not in (0, 100]", size)
+		}
+		bytes = uint64(float64(total) * percent / 100)
+	} else {
+		quantity, err := resource.ParseQuantity(size)
+		if err != nil {
+			return 0, err
+		}
+		if quantity.Sign() <= 0 {
+			return 0, fmt.Errorf("size %s is not positive", size)
+		}
+		bytes = uint64(quantity.Value())
+	}
+	if bytes < partition.MiB {
+		return 0, fmt.Errorf("size %s is less than 1Mi", size)
+	}
+	return bytes, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"github.com/alibaba/open-local/pkg/utils/partition"
+)
+
+func TestParsePartitionSize(t *testing.T) {
+	total := uint64(1000 << 30)
+	tests := []struct {
+		size  string
+		bytes uint64
+		ok    bool
+	}{
+		{"100Gi", 100 << 30, true},
+		{"30%", 300 << 30, true},
+		{"12.5%", 125 << 30, true},
+		{"0%", 0, false},
+		{"120%", 0, false},
+		{"1Ki", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		bytes, err := parsePartitionSize(tt.size, total)
+		if bytes != tt.bytes || (err == nil) != tt.ok {
+			t.Errorf("parsePartitionSize(%q) = %d, %v, want %d, ok %t", tt.size, bytes, err, tt.bytes, tt.ok)
+		}
+	}
+}
+
+func TestPlanPartitions(t *testing.T) {
+	total := uint64(1000 << 30)
+	spec := localv1alpha1.PartitionsToBeInited{
+		Device: "/dev/nvme0n1",
+		Partitions: []localv1alpha1.PartitionToBeInited{
+			{Name: "open-local-pool-0", Size: "50%"},
+			{Name: "mnt-0", Size: "100Gi"},
+			{Name: "raw-0"},
+		},
+	}
+	wanted := []partition.Spec{{Name: "open-local-pool-0", Size: 500 << 30}, {Name: "mnt-0", Size: 100 << 30}, {Name: "raw-0"}}
+	forced := spec
+	forced.Force = true
+	tests := []struct {
+		name       string
+		spec       localv1alpha1.PartitionsToBeInited
+		table      *partition.Table
+		signatures []string
+		specs      []partition.Spec
+		appendOnly bool
+		wipe       bool
+		ok         bool
+	}{
+		{"blank disk", spec, nil, nil, wanted, false, false, true},
+		{"partitioned", spec, &partition.Table{Label: "gpt", Partitions: []partition.Partition{{Name: "open-local-pool-0"}, {Name: "mnt-0"}, {Name: "raw-0"}}}, nil, nil, false, false, true},
+		{"partly partitioned", spec, &partition.Table{Label: "gpt", Partitions: []partition.Partition{{Name: "open-local-pool-0"}}}, nil, wanted[1:], true, false, true},
+		{"other partitions", spec, &partition.Table{Label: "gpt", Partitions: []partition.Partition{{Name: "data"}}}, nil, nil, false, false, false},
+		{"dos partition table", spec, &partition.Table{Label: "dos"}, nil, nil, false, false, false},
+		{"filesystem", spec, nil, []string{"ext4"}, nil, false, false, false},
+		{"forced", forced, &partition.Table{Label: "gpt", Partitions: []partition.Partition{{Name: "data"}}}, nil, wanted, false, true, true},
+		{"size omitted", localv1alpha1.PartitionsToBeInited{Partitions: []localv1alpha1.PartitionToBeInited{{Name: "a"}, {Name: "b"}}}, nil, nil, nil, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, appendOnly, wipe, err := planPartitions(tt.spec, total, tt.table, tt.signatures)
+			if !reflect.DeepEqual(specs, tt.specs) || appendOnly != tt.appendOnly || wipe != tt.wipe || (err == nil) != tt.ok {
+				t.Errorf("planPartitions() = %v, %t, %t, %v, want %v, %t, %t, ok %t", specs, appendOnly, wipe, err, tt.specs, tt.appendOnly, tt.wipe, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDiskInUse(t *testing.T) {
+	devices := []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb"},
+		{Name: "/dev/sdb1", Parent: "/dev/sdb"},
+		{Name: "/dev/sdc"},
+		{Name: "/dev/sdc1", Parent: "/dev/sdc"},
+		{Name: "/dev/sdd"},
+		{Name: "/dev/sde"},
+	}
+	vgs := []localv1alpha1.VolumeGroup{{Name: "share", PhysicalVolumes: []string{"/dev/sdc1"}}}
+	claimed := map[string]string{"/dev/sdb1": "pv-1", "/dev/sdd": "pv-2"}
+	for disk, inUse := range map[string]bool{
+		"/dev/sdb": true,
+		"/dev/sdc": true,
+		"/dev/sdd": true,
+		"/dev/sde": false,
+	} {
+		if err := diskInUse(devices, vgs, claimed, disk); (err != nil) != inUse {
+			t.Errorf("expected disk %s in use %t, got %v", disk, inUse, err)
+		}
+	}
+}
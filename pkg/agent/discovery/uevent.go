@@ -0,0 +1,351 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	deviceutil "github.com/alibaba/open-local/pkg/utils/device"
+	"github.com/alibaba/open-local/pkg/utils/lvm"
+	log "github.com/sirupsen/logrus"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// debounceInterval is the duration that the changes are collected before rediscovery,
+	// so that a burst of uevents is rediscovered at one time
+	debounceInterval = time.Second
+	// mountInfoPath is polled for the changes of the mount table
+	mountInfoPath = "/proc/self/mounts"
+)
+
+// Uevent is a kernel uevent of the form "ACTION@DEVPATH\0KEY=VALUE\0..."
+type Uevent struct {
+	Action  string
+	DevPath string
+	Env     map[string]string
+}
+
+// ParseUevent parses a kernel uevent message received from netlink
+func ParseUevent(msg []byte) (*Uevent, error) {
+	fields := bytes.Split(msg, []byte{0})
+	header := strings.SplitN(string(fields[0]), "@", 2)
+	if len(header) != 2 {
+		return nil, fmt.Errorf("invalid uevent header %q", string(fields[0]))
+	}
+	event := &Uevent{
+		Action:  header[0],
+		DevPath: header[1],
+		Env:     make(map[string]string),
+	}
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(string(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		event.Env[kv[0]] = kv[1]
+	}
+	return event, nil
+}
+
+// Changes records the storage affected by uevents and mount table changes
+type Changes struct {
+	// Disks are the names of the disks whose own or partitions' uevents are received, e.g. sdb
+	Disks map[string]struct{}
+	// VGs are the names of the volume groups whose logical volumes are changed
+	VGs map[string]struct{}
+	// AllVGs is set when the changed volume groups are unknown
+	AllVGs bool
+	// MountPoints is set when the mount table is changed
+	MountPoints bool
+}
+
+// NewChanges returns empty Changes
+func NewChanges() *Changes {
+	return &Changes{
+		Disks: make(map[string]struct{}),
+		VGs:   make(map[string]struct{}),
+	}
+}
+
+// Empty returns true if nothing is changed
+func (c *Changes) Empty() bool {
+	return len(c.Disks) == 0 && len(c.VGs) == 0 && !c.AllVGs && !c.MountPoints
+}
+
+// AddUevent records the storage affected by the uevent, uevents of the disks not matching diskRegExp are ignored
+func (c *Changes) AddUevent(event *Uevent, diskRegExp *regexp.Regexp) {
+	if event.Env["SUBSYSTEM"] != "block" {
+		return
+	}
+	name := filepath.Base(event.DevPath)
+	if strings.HasPrefix(name, "dm-") {
+		// DM_NAME is only carried by the change uevents of device mapper
+		if vgName, ok := vgOfDMName(event.Env["DM_NAME"]); ok {
+			c.VGs[vgName] = struct{}{}
+		} else {
+			c.AllVGs = true
+		}
+		return
+	}
+	disk := name
+	if event.Env["DEVTYPE"] == "partition" {
+		disk = filepath.Base(filepath.Dir(event.DevPath))
+	}
+	if diskRegExp.MatchString(disk) {
+		c.Disks[disk] = struct{}{}
+	}
+}
+
+// vgOfDMName returns the volume group of the device mapper name "vg-lv",
+// in which the dashes of vg and lv are escaped as "--"
+func vgOfDMName(dmName string) (string, bool) {
+	for i := 0; i < len(dmName); i++ {
+		if dmName[i] != '-' {
+			continue
+		}
+		if i+1 < len(dmName) && dmName[i+1] == '-' {
+			i++
+			continue
+		}
+		if i == 0 {
+			return "", false
+		}
+		return strings.ReplaceAll(dmName[:i], "--", "-"), true
+	}
+	return "", false
+}
+
+// Watch rediscovers the storage affected by kernel uevents and mount table changes until stopCh is closed,
+// it returns error if uevents or the mount table can not be watched
+func (d *Discoverer) Watch(stopCh <-chan struct{}) error {
+	diskRegExp, err := regexp.Compile(d.RegExp)
+	if err != nil {
+		return err
+	}
+	events, err := watchUevents(stopCh)
+	if err != nil {
+		return fmt.Errorf("watch uevents error: %s", err.Error())
+	}
+	mounts, err := watchMounts(mountInfoPath, stopCh)
+	if err != nil {
+		return fmt.Errorf("watch mount table error: %s", err.Error())
+	}
+
+	go func() {
+		changes := NewChanges()
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-stopCh:
+				return
+			case event := <-events:
+				log.Debugf("receive uevent %s@%s", event.Action, event.DevPath)
+				changes.AddUevent(event, diskRegExp)
+			case <-mounts:
+				log.Debugf("mount table changed")
+				changes.MountPoints = true
+			case <-debounce:
+				debounce = nil
+				d.DiscoverChanges(changes)
+				changes = NewChanges()
+				continue
+			}
+			if debounce == nil && !changes.Empty() {
+				debounce = time.After(debounceInterval)
+			}
+		}
+	}()
+	return nil
+}
+
+// DiscoverChanges rediscovers only the changed storage and keeps the rest of the status
+func (d *Discoverer) DiscoverChanges(changes *Changes) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	nls, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().Get(context.Background(), d.Nodename, metav1.GetOptions{})
+	if err != nil {
+		if !k8serr.IsNotFound(err) {
+			log.Errorf("get NodeLocalStorages failed: %s", err.Error())
+		}
+		return
+	}
+	if nls.Status.NodeStorageInfo.Phase != localv1alpha1.NodeStorageRunning {
+		// nothing to be kept, the storage will be discovered fully
+		return
+	}
+	reservedVGInfos, err := d.reservedVGInfos(nls)
+	if err != nil {
+		log.Errorf("get reserved vg info failed: %s, but we ignore...", err.Error())
+		return
+	}
+	log.Infof("discover changes of disks %v, vgs %v, all vgs %t, mountpoints %t", setKeys(changes.Disks), setKeys(changes.VGs), changes.AllVGs, changes.MountPoints)
+
+	newStatus := nls.Status.DeepCopy()
+	for disk := range changes.Disks {
+		if err := d.rediscoverDisk(newStatus, disk, changes); err != nil {
+			log.Errorf("discover disk %s error: %s", disk, err.Error())
+			return
+		}
+	}
+	if changes.AllVGs {
+		newStatus.NodeStorageInfo.VolumeGroups = nil
+		if err := d.discoverVGs(newStatus, reservedVGInfos); err != nil {
+			log.Errorf("discover VG error: %s", err.Error())
+			return
+		}
+	} else {
+		for vgName := range changes.VGs {
+			d.rediscoverVG(newStatus, vgName, reservedVGInfos)
+		}
+	}
+	if changes.MountPoints {
+		newStatus.NodeStorageInfo.MountPoints = nil
+		if err := d.discoverMountPoints(newStatus); err != nil {
+			log.Errorf("discover MountPoint error: %s", err.Error())
+			return
+		}
+	}
+	d.updateStatus(nls, newStatus)
+}
+
+// rediscoverDisk replaces the infos of the disk and its partitions, the volume groups on the disk
+// are recorded into changes to be rediscovered as well
+func (d *Discoverer) rediscoverDisk(newStatus *localv1alpha1.NodeLocalStorageStatus, disk string, changes *Changes) error {
+	devName := fmt.Sprintf("/dev/%s", disk)
+	onDisk := make(map[string]struct{})
+	var deviceInfos []localv1alpha1.DeviceInfo
+	for _, info := range newStatus.NodeStorageInfo.DeviceInfos {
+		if info.Name == devName || info.Parent == devName {
+			onDisk[info.Name] = struct{}{}
+			continue
+		}
+		deviceInfos = append(deviceInfos, info)
+	}
+	if _, err := os.Stat(filepath.Join(d.SysPath, "/block", disk)); err == nil {
+		links, err := deviceutil.GetByIDLinks(d.byIDPath)
+		if err != nil {
+			return err
+		}
+		diskInfos, err := d.discoverDisk(disk, links)
+		if err != nil {
+			return err
+		}
+		for _, info := range diskInfos {
+			onDisk[info.Name] = struct{}{}
+		}
+		deviceInfos = append(deviceInfos, diskInfos...)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	newStatus.NodeStorageInfo.DeviceInfos = deviceInfos
+
+	// the disk may be added to or removed from any volume group
+	inVG := false
+	for _, vg := range newStatus.NodeStorageInfo.VolumeGroups {
+		for _, pv := range vg.PhysicalVolumes {
+			if _, ok := onDisk[pv]; ok {
+				changes.VGs[vg.Name] = struct{}{}
+				inVG = true
+			}
+		}
+	}
+	if !inVG {
+		changes.AllVGs = true
+	}
+	return nil
+}
+
+// rediscoverVG replaces the info of the volume group, which is removed if the volume group is not found
+func (d *Discoverer) rediscoverVG(newStatus *localv1alpha1.NodeLocalStorageStatus, vgName string, reservedVGInfos map[string]ReservedVGInfo) {
+	vgs := newStatus.NodeStorageInfo.VolumeGroups
+	index := -1
+	for i, vg := range vgs {
+		if vg.Name == vgName {
+			index = i
+			break
+		}
+	}
+	if _, err := lvm.LookupVolumeGroup(vgName); err == lvm.ErrVolumeGroupNotFound {
+		if index >= 0 {
+			newStatus.NodeStorageInfo.VolumeGroups = append(vgs[:index], vgs[index+1:]...)
+		}
+		return
+	}
+	vg, err := d.discoverVG(vgName, newStatus.NodeStorageInfo.DeviceInfos, reservedVGInfos)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	if index >= 0 {
+		vgs[index] = *vg
+	} else {
+		newStatus.NodeStorageInfo.VolumeGroups = append(vgs, *vg)
+	}
+}
+
+// Refilter filters the storage again if the list config of the node local storage is changed,
+// which is not watched by uevents
+func (d *Discoverer) Refilter() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	nls, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().Get(context.Background(), d.Nodename, metav1.GetOptions{})
+	if err != nil {
+		if !k8serr.IsNotFound(err) {
+			log.Errorf("get NodeLocalStorages failed: %s", err.Error())
+		}
+		return
+	}
+	if nls.Status.NodeStorageInfo.Phase != localv1alpha1.NodeStorageRunning {
+		return
+	}
+	filtered := nls.Status.FilteredStorageInfo
+	if sameSet(filtered.VolumeGroups, FilterVGInfo(nls)) && sameSet(filtered.MountPoints, FilterMPInfo(nls)) && sameSet(filtered.Devices, FilterDeviceInfo(nls)) {
+		return
+	}
+	d.updateStatus(nls, nls.Status.DeepCopy())
+}
+
+func sameSet(a, b []string) bool {
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return len(a) == len(b) && (len(a) == 0 || reflect.DeepEqual(a, b))
+}
+
+func setKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
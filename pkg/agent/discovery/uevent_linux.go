@@ -0,0 +1,113 @@
+// +build linux
+
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// watchUevents receives the kernel uevents from netlink until stopCh is closed
+func watchUevents(stopCh <-chan struct{}) (<-chan *Uevent, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, err
+	}
+	// group 1 is the kernel uevents, and the receive timeout lets stopCh be checked
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: 1}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	timeout := unix.NsecToTimeval(time.Second.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	events := make(chan *Uevent, 1024)
+	go func() {
+		defer unix.Close(fd)
+		buf := make([]byte, os.Getpagesize()*2)
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			n, _, err := unix.Recvfrom(fd, buf, 0)
+			if err != nil {
+				if err != unix.EAGAIN && err != unix.EINTR {
+					log.Errorf("receive uevent error: %s", err.Error())
+				}
+				continue
+			}
+			event, err := ParseUevent(buf[:n])
+			if err != nil {
+				log.Warningf("parse uevent error: %s", err.Error())
+				continue
+			}
+			select {
+			case events <- event:
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// watchMounts notifies the changes of the mount table until stopCh is closed,
+// the kernel reports them as POLLPRI|POLLERR on the mounts file
+func watchMounts(path string, stopCh <-chan struct{}) (<-chan struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer file.Close()
+		fds := []unix.PollFd{{Fd: int32(file.Fd()), Events: unix.POLLPRI | unix.POLLERR}}
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			n, err := unix.Poll(fds, int(time.Second/time.Millisecond))
+			if err != nil {
+				if err != unix.EINTR {
+					log.Errorf("poll %s error: %s", path, err.Error())
+					time.Sleep(time.Second)
+				}
+				continue
+			}
+			if n > 0 && fds[0].Revents&(unix.POLLPRI|unix.POLLERR) != 0 {
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
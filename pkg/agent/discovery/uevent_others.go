@@ -0,0 +1,32 @@
+// +build !linux
+
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func watchUevents(stopCh <-chan struct{}) (<-chan *Uevent, error) {
+	return nil, fmt.Errorf("uevents are not supported on %s", runtime.GOOS)
+}
+
+func watchMounts(path string, stopCh <-chan struct{}) (<-chan struct{}, error) {
+	return nil, fmt.Errorf("watching mount table is not supported on %s", runtime.GOOS)
+}
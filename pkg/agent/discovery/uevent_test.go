@@ -0,0 +1,149 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/alibaba/open-local/pkg/agent/common"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+)
+
+func TestParseUevent(t *testing.T) {
+	msg := []byte("change@/devices/virtual/block/dm-0\x00ACTION=change\x00SUBSYSTEM=block\x00DM_NAME=open--local-local--pv--1\x00")
+	event, err := ParseUevent(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Uevent{
+		Action:  "change",
+		DevPath: "/devices/virtual/block/dm-0",
+		Env:     map[string]string{"ACTION": "change", "SUBSYSTEM": "block", "DM_NAME": "open--local-local--pv--1"},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("ParseUevent() = %v, want %v", event, want)
+	}
+	if _, err := ParseUevent([]byte("libudev\x00")); err == nil {
+		t.Errorf("expected error of invalid header")
+	}
+}
+
+func TestChangesAddUevent(t *testing.T) {
+	diskRegExp := regexp.MustCompile(common.DefaultRegExp)
+	tests := []struct {
+		name  string
+		event *Uevent
+		want  *Changes
+	}{
+		{
+			"lv of escaped vg",
+			&Uevent{DevPath: "/devices/virtual/block/dm-0", Env: map[string]string{"SUBSYSTEM": "block", "DM_NAME": "open--local-local--pv--1"}},
+			&Changes{Disks: map[string]struct{}{}, VGs: map[string]struct{}{"open-local": {}}},
+		},
+		{
+			"dm without name",
+			&Uevent{DevPath: "/devices/virtual/block/dm-1", Env: map[string]string{"SUBSYSTEM": "block"}},
+			&Changes{Disks: map[string]struct{}{}, VGs: map[string]struct{}{}, AllVGs: true},
+		},
+		{
+			"partition",
+			&Uevent{DevPath: "/devices/pci0000:00/block/sdb/sdb1", Env: map[string]string{"SUBSYSTEM": "block", "DEVTYPE": "partition"}},
+			&Changes{Disks: map[string]struct{}{"sdb": {}}, VGs: map[string]struct{}{}},
+		},
+		{
+			"nvme partition",
+			&Uevent{DevPath: "/devices/pci0000:00/nvme/nvme0/nvme0n1/nvme0n1p1", Env: map[string]string{"SUBSYSTEM": "block", "DEVTYPE": "partition"}},
+			&Changes{Disks: map[string]struct{}{"nvme0n1": {}}, VGs: map[string]struct{}{}},
+		},
+		{
+			"nvme multipath path",
+			&Uevent{DevPath: "/devices/pci0000:00/nvme/nvme0/nvme0c0n1", Env: map[string]string{"SUBSYSTEM": "block", "DEVTYPE": "disk"}},
+			NewChanges(),
+		},
+		{
+			"filtered disk",
+			&Uevent{DevPath: "/devices/virtual/block/loop0", Env: map[string]string{"SUBSYSTEM": "block", "DEVTYPE": "disk"}},
+			NewChanges(),
+		},
+		{
+			"other subsystem",
+			&Uevent{DevPath: "/devices/virtual/net/eth0", Env: map[string]string{"SUBSYSTEM": "net"}},
+			NewChanges(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := NewChanges()
+			changes.AddUevent(tt.event, diskRegExp)
+			if !reflect.DeepEqual(changes, tt.want) {
+				t.Errorf("AddUevent() = %v, want %v", changes, tt.want)
+			}
+		})
+	}
+}
+
+func TestRediscoverRemovedDisk(t *testing.T) {
+	sysPath, err := ioutil.TempDir("", "sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sysPath)
+
+	d := &Discoverer{Configuration: &common.Configuration{SysPath: sysPath}}
+	status := &localv1alpha1.NodeLocalStorageStatus{}
+	status.NodeStorageInfo.DeviceInfos = []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb"},
+		{Name: "/dev/sdb1", Parent: "/dev/sdb"},
+		{Name: "/dev/sdc"},
+	}
+	status.NodeStorageInfo.VolumeGroups = []localv1alpha1.VolumeGroup{
+		{Name: "share", PhysicalVolumes: []string{"/dev/sdb1"}},
+		{Name: "paas", PhysicalVolumes: []string{"/dev/sdc"}},
+	}
+	changes := NewChanges()
+	if err := d.rediscoverDisk(status, "sdb", changes); err != nil {
+		t.Fatal(err)
+	}
+	if want := []localv1alpha1.DeviceInfo{{Name: "/dev/sdc"}}; !reflect.DeepEqual(status.NodeStorageInfo.DeviceInfos, want) {
+		t.Errorf("expected devices %v, got %v", want, status.NodeStorageInfo.DeviceInfos)
+	}
+	if want := map[string]struct{}{"share": {}}; !reflect.DeepEqual(changes.VGs, want) || changes.AllVGs {
+		t.Errorf("expected changed vgs %v, got %v, all vgs %t", want, changes.VGs, changes.AllVGs)
+	}
+}
+
+func TestVGOfDMName(t *testing.T) {
+	tests := []struct {
+		dmName string
+		vg     string
+		ok     bool
+	}{
+		{"share-local--pv--1", "share", true},
+		{"open--local-pool-tpool", "open-local", true},
+		{"open--local", "", false},
+		{"-lv", "", false},
+	}
+	for _, tt := range tests {
+		if vg, ok := vgOfDMName(tt.dmName); vg != tt.vg || ok != tt.ok {
+			t.Errorf("vgOfDMName(%q) = %q, %t, want %q, %t", tt.dmName, vg, ok, tt.vg, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"github.com/alibaba/open-local/pkg/utils"
+	"github.com/alibaba/open-local/pkg/utils/lvm"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileVGs creates the volume groups in ResourceToBeInited, and reconciles the ones opted in
+// to their devices. The operations are recorded to the status of NodeLocalStorage
+func (d *Discoverer) reconcileVGs(nls *localv1alpha1.NodeLocalStorage) {
+	claimed, err := d.claimedDevices(nls)
+	if err != nil {
+		log.Errorf("get devices claimed by pvs failed: %s", err.Error())
+		return
+	}
+	ops := make(map[string]*localv1alpha1.VGOperation)
+	for _, vg := range nls.Spec.ResourceToBeInited.VGs {
+		if op := d.reconcileVG(vg, nls.Status.NodeStorageInfo.DeviceInfos, claimed); op != nil {
+			ops[vg.Name] = op
+		}
+	}
+	d.updateVGOperations(ops)
+}
+
+// reconcileVG creates the volume group if it does not exist, otherwise runs one operation to
+// reconcile the volume group to the devices if reconcile is set
+func (d *Discoverer) reconcileVG(spec localv1alpha1.VGToBeInited, infos []localv1alpha1.DeviceInfo, claimed map[string]string) *localv1alpha1.VGOperation {
+	vg, err := lvm.LookupVolumeGroup(spec.Name)
+	if err == lvm.ErrVolumeGroupNotFound {
+		// devices may be given by their stable identities
+		devices := make([]string, 0, len(spec.Devices))
+		for _, dev := range spec.Devices {
+			devices = append(devices, resolveDevice(infos, dev))
+		}
+		op := newVGOperation(spec.Name, localv1alpha1.VGOperationCreate, devices)
+		for _, dev := range devices {
+			if pv := claimedBy(claimed, infos, dev); pv != "" {
+				return failVGOperation(op, fmt.Sprintf("device %s is claimed by pv %s", dev, pv))
+			}
+		}
+		if err := d.createVG(spec.Name, devices); err != nil {
+			return failVGOperation(op, fmt.Sprintf("%s. you can try command \"vgcreate %s %v --force\" manually on this node", err.Error(), spec.Name, strings.Join(devices, " ")))
+		}
+		return succeedVGOperation(op)
+	} else if err != nil {
+		log.Errorf("look up vg %s failed: %s", spec.Name, err.Error())
+		return nil
+	}
+	if !spec.Reconcile {
+		return nil
+	}
+	return d.updateVG(lvmVolumeGroup{vg}, spec.Devices, infos, claimed)
+}
+
+// updateVG extends the volume group with the new devices, or reduces it by one removed device at a time.
+// Only devices with by-id links are acted on, as kernel names may change across reboots
+func (d *Discoverer) updateVG(vg volumeGroup, ids []string, infos []localv1alpha1.DeviceInfo, claimed map[string]string) *localv1alpha1.VGOperation {
+	usages, err := vg.PhysicalVolumeUsages()
+	if err != nil {
+		return nil
+	}
+	wanted := make([]localv1alpha1.DeviceInfo, 0, len(ids))
+	for _, id := range ids {
+		info, err := stableDevice(infos, id)
+		if err != nil {
+			return failVGOperation(newVGOperation(vg.Name(), localv1alpha1.VGOperationExtend, []string{id}), err.Error())
+		}
+		wanted = append(wanted, *info)
+	}
+	current := make([]localv1alpha1.DeviceInfo, 0, len(usages))
+	for name := range usages {
+		info, err := stableDevice(infos, name)
+		if err != nil {
+			return failVGOperation(newVGOperation(vg.Name(), localv1alpha1.VGOperationReduce, []string{name}), err.Error())
+		}
+		current = append(current, *info)
+	}
+
+	added, removed := diffPhysicalVolumes(wanted, current)
+	if len(added) > 0 {
+		for _, dev := range added {
+			if pv := claimedBy(claimed, infos, dev); pv != "" {
+				return failVGOperation(newVGOperation(vg.Name(), localv1alpha1.VGOperationExtend, added), fmt.Sprintf("device %s is claimed by pv %s", dev, pv))
+			}
+		}
+		return d.extendVG(vg, added)
+	}
+	devices := make([]string, 0, len(wanted))
+	for _, info := range wanted {
+		devices = append(devices, info.Name)
+	}
+	return d.reduceVG(vg, devices, removed, usages)
+}
+
+func (d *Discoverer) extendVG(vg volumeGroup, devices []string) *localv1alpha1.VGOperation {
+	op := newVGOperation(vg.Name(), localv1alpha1.VGOperationExtend, devices)
+	force := false
+	forceCreateVG := os.Getenv(localtype.EnvForceCreateVG)
+	if forceCreateVG == "true" {
+		force = true
+	}
+	if err := vg.Extend(devices, force); err != nil {
+		return failVGOperation(op, err.Error())
+	}
+	log.Infof("vg %s is extended with %v", vg.Name(), devices)
+	return succeedVGOperation(op)
+}
+
+// reduceVG removes the first removed device from the volume group, whose extents are moved
+// to the other devices by pvmove in the background first
+func (d *Discoverer) reduceVG(vg volumeGroup, devices, removed []string, usages map[string]lvm.PhysicalVolumeUsage) *localv1alpha1.VGOperation {
+	moving, percent, err := vg.MoveProgress()
+	if err != nil {
+		return nil
+	}
+	if !moving {
+		// a device may be added back to the spec while it was being removed
+		for _, dev := range devices {
+			if usage, ok := usages[dev]; ok && !usage.Allocatable {
+				_ = vg.SetAllocatable(dev, true)
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	dev := removed[0]
+	op := newVGOperation(vg.Name(), localv1alpha1.VGOperationReduce, []string{dev})
+	if moving {
+		op.Progress = fmt.Sprintf("%.2f%%", percent)
+		return op
+	}
+	var remaining []string
+	var free uint64
+	for _, device := range devices {
+		if usage, ok := usages[device]; ok {
+			remaining = append(remaining, device)
+			free += usage.Free
+		}
+	}
+	if len(remaining) == 0 {
+		return failVGOperation(op, "all the devices of the vg can not be removed, remove the vg manually")
+	}
+
+	usage := usages[dev]
+	if usage.Free == usage.Size {
+		if err := vg.Reduce(dev); err != nil {
+			return failVGOperation(op, err.Error())
+		}
+		// the device is wiped to be used by others
+		if err := vg.RemovePhysicalVolume(dev); err != nil {
+			log.Errorf("remove pv %s failed: %s", dev, err.Error())
+		}
+		log.Infof("%s is removed from vg %s", dev, vg.Name())
+		op.Progress = "100.00%"
+		return succeedVGOperation(op)
+	}
+	if used := usage.Size - usage.Free; used > free {
+		return failVGOperation(op, fmt.Sprintf("%d bytes used on %s exceeds %d bytes free on the other devices", used, dev, free))
+	}
+	// no new volumes are allocated on the device while it is being moved
+	if err := vg.SetAllocatable(dev, false); err != nil {
+		return failVGOperation(op, err.Error())
+	}
+	if err := vg.MovePhysicalVolume(dev, remaining); err != nil {
+		return failVGOperation(op, err.Error())
+	}
+	log.Infof("start to move %s of vg %s to %v", dev, vg.Name(), remaining)
+	op.Progress = "0.00%"
+	return op
+}
+
+// claimedDevices returns the devices claimed by the Device and MountPoint pvs on the node,
+// and the disks of the claimed partitions, to the names of the pvs
+func (d *Discoverer) claimedDevices(nls *localv1alpha1.NodeLocalStorage) (map[string]string, error) {
+	pvs, err := d.kubeclientset.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]string)
+	parents := make(map[string]string)
+	for _, info := range nls.Status.NodeStorageInfo.DeviceInfos {
+		if info.ByID != "" {
+			byID[info.ByID] = info.Name
+		}
+		parents[info.Name] = info.Parent
+	}
+	mountPoints := make(map[string]string)
+	for _, mp := range nls.Status.NodeStorageInfo.MountPoints {
+		mountPoints[mp.Name] = mp.Device
+	}
+	claimed := make(map[string]string)
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Spec.CSI == nil || !utils.ContainsProvisioner(pv.Spec.CSI.Driver) {
+			continue
+		}
+		if _, node := utils.IsLocalPV(pv); node != d.Nodename {
+			continue
+		}
+		attributes := pv.Spec.CSI.VolumeAttributes
+		var dev string
+		switch localtype.VolumeType(attributes[localtype.VolumeTypeKey]) {
+		case localtype.VolumeTypeDevice:
+			dev = attributes[localtype.DeviceName]
+			if name, ok := byID[attributes[localtype.DeviceID]]; ok {
+				dev = name
+			}
+		case localtype.VolumeTypeMountPoint:
+			dev = mountPoints[attributes[localtype.MPName]]
+		}
+		if dev == "" {
+			continue
+		}
+		claimed[dev] = pv.Name
+		if parent := parents[dev]; parent != "" {
+			claimed[parent] = pv.Name
+		}
+	}
+	return claimed, nil
+}
+
+// claimedBy returns the pv claiming the device or the disk of the device, empty if not claimed
+func claimedBy(claimed map[string]string, infos []localv1alpha1.DeviceInfo, dev string) string {
+	if pv, ok := claimed[dev]; ok {
+		return pv
+	}
+	for _, info := range infos {
+		if info.Name == dev && info.Parent != "" {
+			return claimed[info.Parent]
+		}
+	}
+	return ""
+}
+
+// stableDevice returns the device identified by id, it fails if the device is not found or has no by-id link
+func stableDevice(infos []localv1alpha1.DeviceInfo, id string) (*localv1alpha1.DeviceInfo, error) {
+	for i := range infos {
+		if utils.ContainsString(deviceIdentities(infos[i]), id) {
+			if infos[i].ByID == "" {
+				return nil, fmt.Errorf("device %s has no by-id link", id)
+			}
+			return &infos[i], nil
+		}
+	}
+	return nil, fmt.Errorf("device %s is not found on the node", id)
+}
+
+// updateVGOperations writes the changed operations to the status, and records events of the failed ones
+func (d *Discoverer) updateVGOperations(ops map[string]*localv1alpha1.VGOperation) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	nls, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().Get(context.Background(), d.Nodename, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("get NodeLocalStorages failed: %s", err.Error())
+		return
+	}
+	newOps, failed := mergeVGOperations(nls.Spec.ResourceToBeInited.VGs, nls.Status.VGOperations, ops)
+	for _, op := range failed {
+		reason := localtype.EventUpdateVGFailed
+		if op.Type == localv1alpha1.VGOperationCreate {
+			reason = localtype.EventCreateVGFailed
+		}
+		msg := fmt.Sprintf("%s vg %s with devices %v failed: %s", strings.ToLower(string(op.Type)), op.Name, op.Devices, op.Reason)
+		log.Error(msg)
+		d.recorder.Event(nls, corev1.EventTypeWarning, reason, msg)
+	}
+	if reflect.DeepEqual(newOps, nls.Status.VGOperations) {
+		return
+	}
+	nlsCopy := nls.DeepCopy()
+	nlsCopy.Status.VGOperations = newOps
+	if _, err := d.localclientset.CsiV1alpha1().NodeLocalStorages().UpdateStatus(context.Background(), nlsCopy, metav1.UpdateOptions{}); err != nil {
+		log.Errorf("local storage CRD updateStatus error: %s", err.Error())
+	}
+}
+
+// mergeVGOperations returns the operations of the volume groups in the spec, an operation replaces
+// the old one only if it changes. The operations newly failed are returned as well.
+func mergeVGOperations(vgs []localv1alpha1.VGToBeInited, oldOps []localv1alpha1.VGOperation, ops map[string]*localv1alpha1.VGOperation) (newOps, failed []localv1alpha1.VGOperation) {
+	old := make(map[string]localv1alpha1.VGOperation, len(oldOps))
+	for _, op := range oldOps {
+		old[op.Name] = op
+	}
+	for _, vg := range vgs {
+		oldOp, exist := old[vg.Name]
+		op, ok := ops[vg.Name]
+		if !ok || (exist && sameVGOperation(oldOp, *op)) {
+			if exist {
+				newOps = append(newOps, oldOp)
+			}
+			continue
+		}
+		newOps = append(newOps, *op)
+		if op.Phase == localv1alpha1.VGOperationFailed {
+			failed = append(failed, *op)
+		}
+	}
+	return newOps, failed
+}
+
+// sameVGOperation compares the operations regardless of the update time
+func sameVGOperation(a, b localv1alpha1.VGOperation) bool {
+	a.LastUpdateTime = b.LastUpdateTime
+	return reflect.DeepEqual(a, b)
+}
+
+// diffPhysicalVolumes returns the names of the devices to be added to and removed from the volume group,
+// the devices are compared by their by-id links
+func diffPhysicalVolumes(wanted, current []localv1alpha1.DeviceInfo) (added, removed []string) {
+	existing := make(map[string]bool, len(current))
+	for _, info := range current {
+		existing[info.ByID] = true
+	}
+	kept := make(map[string]bool, len(wanted))
+	for _, info := range wanted {
+		kept[info.ByID] = true
+		if !existing[info.ByID] {
+			added = append(added, info.Name)
+		}
+	}
+	for _, info := range current {
+		if !kept[info.ByID] {
+			removed = append(removed, info.Name)
+		}
+	}
+	sort.Strings(removed)
+	return added, removed
+}
+
+func newVGOperation(name string, opType localv1alpha1.VGOperationType, devices []string) *localv1alpha1.VGOperation {
+	return &localv1alpha1.VGOperation{
+		Name:           name,
+		Type:           opType,
+		Devices:        devices,
+		Phase:          localv1alpha1.VGOperationRunning,
+		LastUpdateTime: metav1.Now(),
+	}
+}
+
+func succeedVGOperation(op *localv1alpha1.VGOperation) *localv1alpha1.VGOperation {
+	op.Phase = localv1alpha1.VGOperationSucceeded
+	return op
+}
+
+func failVGOperation(op *localv1alpha1.VGOperation, reason string) *localv1alpha1.VGOperation {
+	op.Phase = localv1alpha1.VGOperationFailed
+	op.Reason = reason
+	return op
+}
+
+// volumeGroup runs the lvm commands reconciling a volume group, it is stubbed in tests
+type volumeGroup interface {
+	Name() string
+	PhysicalVolumeUsages() (map[string]lvm.PhysicalVolumeUsage, error)
+	// Extend creates physical volumes on the devices and adds them to the volume group
+	Extend(devices []string, force bool) error
+	Reduce(dev string) error
+	MoveProgress() (bool, float64, error)
+	MovePhysicalVolume(dev string, dests []string) error
+	SetAllocatable(dev string, allocatable bool) error
+	// RemovePhysicalVolume wipes the physical volume label of the device
+	RemovePhysicalVolume(dev string) error
+}
+
+type lvmVolumeGroup struct {
+	*lvm.VolumeGroup
+}
+
+func (vg lvmVolumeGroup) Extend(devices []string, force bool) error {
+	var pvs []*lvm.PhysicalVolume
+	for _, dev := range devices {
+		pv, err := lvm.CreatePhysicalVolume(dev, force)
+		if err != nil {
+			return err
+		}
+		pvs = append(pvs, pv)
+	}
+	return vg.VolumeGroup.Extend(pvs)
+}
+
+func (vg lvmVolumeGroup) SetAllocatable(dev string, allocatable bool) error {
+	pv, err := lvm.LookupPhysicalVolume(dev)
+	if err != nil {
+		return err
+	}
+	return pv.SetAllocatable(allocatable)
+}
+
+func (vg lvmVolumeGroup) RemovePhysicalVolume(dev string) error {
+	pv, err := lvm.LookupPhysicalVolume(dev)
+	if err != nil {
+		return err
+	}
+	return pv.Remove()
+}
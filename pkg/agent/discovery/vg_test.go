@@ -0,0 +1,335 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/agent/common"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"github.com/alibaba/open-local/pkg/utils/lvm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiffPhysicalVolumes(t *testing.T) {
+	// sdb and sdc swapped their names across a reboot
+	current := []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdc", ByID: "/dev/disk/by-id/wwn-b"},
+		{Name: "/dev/sdb", ByID: "/dev/disk/by-id/wwn-c"},
+		{Name: "/dev/sdd", ByID: "/dev/disk/by-id/wwn-d"},
+	}
+	wanted := []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdc", ByID: "/dev/disk/by-id/wwn-b"},
+		{Name: "/dev/sde", ByID: "/dev/disk/by-id/wwn-e"},
+	}
+	added, removed := diffPhysicalVolumes(wanted, current)
+	if want := []string{"/dev/sde"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("expected added %v, got %v", want, added)
+	}
+	if want := []string{"/dev/sdb", "/dev/sdd"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("expected removed %v, got %v", want, removed)
+	}
+	added, removed = diffPhysicalVolumes(current, current)
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("expected nothing to change, got added %v, removed %v", added, removed)
+	}
+}
+
+// fakeVolumeGroup records the lvm commands run on the volume group
+type fakeVolumeGroup struct {
+	usages  map[string]lvm.PhysicalVolumeUsage
+	moving  bool
+	percent float64
+	cmds    []string
+}
+
+func (vg *fakeVolumeGroup) Name() string {
+	return "vg"
+}
+
+func (vg *fakeVolumeGroup) PhysicalVolumeUsages() (map[string]lvm.PhysicalVolumeUsage, error) {
+	return vg.usages, nil
+}
+
+func (vg *fakeVolumeGroup) Extend(devices []string, _ bool) error {
+	vg.cmds = append(vg.cmds, fmt.Sprintf("vgextend %v", devices))
+	return nil
+}
+
+func (vg *fakeVolumeGroup) Reduce(dev string) error {
+	vg.cmds = append(vg.cmds, "vgreduce "+dev)
+	return nil
+}
+
+func (vg *fakeVolumeGroup) MoveProgress() (bool, float64, error) {
+	return vg.moving, vg.percent, nil
+}
+
+func (vg *fakeVolumeGroup) MovePhysicalVolume(dev string, dests []string) error {
+	vg.cmds = append(vg.cmds, fmt.Sprintf("pvmove %s %v", dev, dests))
+	return nil
+}
+
+func (vg *fakeVolumeGroup) SetAllocatable(dev string, allocatable bool) error {
+	vg.cmds = append(vg.cmds, fmt.Sprintf("pvchange %s %t", dev, allocatable))
+	return nil
+}
+
+func (vg *fakeVolumeGroup) RemovePhysicalVolume(dev string) error {
+	vg.cmds = append(vg.cmds, "pvremove "+dev)
+	return nil
+}
+
+func TestReduceVG(t *testing.T) {
+	d := &Discoverer{}
+	tests := []struct {
+		name     string
+		vg       *fakeVolumeGroup
+		devices  []string
+		removed  []string
+		phase    localv1alpha1.VGOperationPhase
+		progress string
+		cmds     []string
+	}{
+		{
+			name:    "nothing to remove",
+			vg:      &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{"/dev/sdb": {Size: 100, Free: 50, Allocatable: true}}},
+			devices: []string{"/dev/sdb"},
+		},
+		{
+			name:    "device added back after being removed",
+			vg:      &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{"/dev/sdb": {Size: 100, Free: 50}}},
+			devices: []string{"/dev/sdb"},
+			cmds:    []string{"pvchange /dev/sdb true"},
+		},
+		{
+			name: "free device is removed",
+			vg: &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{
+				"/dev/sdb": {Size: 100, Free: 50, Allocatable: true},
+				"/dev/sdc": {Size: 100, Free: 100, Allocatable: true},
+			}},
+			devices:  []string{"/dev/sdb"},
+			removed:  []string{"/dev/sdc"},
+			phase:    localv1alpha1.VGOperationSucceeded,
+			progress: "100.00%",
+			cmds:     []string{"vgreduce /dev/sdc", "pvremove /dev/sdc"},
+		},
+		{
+			name: "used device is moved",
+			vg: &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{
+				"/dev/sdb": {Size: 100, Free: 50, Allocatable: true},
+				"/dev/sdc": {Size: 100, Free: 60, Allocatable: true},
+			}},
+			devices:  []string{"/dev/sdb"},
+			removed:  []string{"/dev/sdc"},
+			phase:    localv1alpha1.VGOperationRunning,
+			progress: "0.00%",
+			cmds:     []string{"pvchange /dev/sdc false", "pvmove /dev/sdc [/dev/sdb]"},
+		},
+		{
+			name: "moving in progress",
+			vg: &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{
+				"/dev/sdb": {Size: 100, Free: 10, Allocatable: true},
+				"/dev/sdc": {Size: 100, Free: 60},
+			}, moving: true, percent: 42},
+			devices:  []string{"/dev/sdb"},
+			removed:  []string{"/dev/sdc"},
+			phase:    localv1alpha1.VGOperationRunning,
+			progress: "42.00%",
+		},
+		{
+			name: "not enough space on the others",
+			vg: &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{
+				"/dev/sdb": {Size: 100, Free: 10, Allocatable: true},
+				"/dev/sdc": {Size: 100, Free: 60, Allocatable: true},
+			}},
+			devices: []string{"/dev/sdb"},
+			removed: []string{"/dev/sdc"},
+			phase:   localv1alpha1.VGOperationFailed,
+		},
+		{
+			name: "all devices removed",
+			vg: &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{
+				"/dev/sdb": {Size: 100, Free: 100, Allocatable: true},
+			}},
+			removed: []string{"/dev/sdb"},
+			phase:   localv1alpha1.VGOperationFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := d.reduceVG(test.vg, test.devices, test.removed, test.vg.usages)
+			if test.phase == "" {
+				if op != nil {
+					t.Errorf("expected no operation, got %+v", op)
+				}
+			} else if op == nil || op.Type != localv1alpha1.VGOperationReduce || op.Phase != test.phase || op.Progress != test.progress {
+				t.Errorf("expected reduce %s with progress %q, got %+v", test.phase, test.progress, op)
+			}
+			if !reflect.DeepEqual(test.vg.cmds, test.cmds) {
+				t.Errorf("expected commands %v, got %v", test.cmds, test.vg.cmds)
+			}
+		})
+	}
+}
+
+func TestUpdateVG(t *testing.T) {
+	d := &Discoverer{}
+	infos := []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb", ByID: "/dev/disk/by-id/wwn-b"},
+		{Name: "/dev/sdc", ByID: "/dev/disk/by-id/wwn-c"},
+		{Name: "/dev/sdd", ByID: "/dev/disk/by-id/wwn-d"},
+		{Name: "/dev/sdd1", ByID: "/dev/disk/by-id/wwn-d-part1", Parent: "/dev/sdd"},
+		{Name: "/dev/vdb"},
+	}
+	claimed := map[string]string{"/dev/sdd": "pv-device"}
+	tests := []struct {
+		name   string
+		ids    []string
+		phase  localv1alpha1.VGOperationPhase
+		opType localv1alpha1.VGOperationType
+		cmds   []string
+	}{
+		{
+			name:   "extend by by-id link",
+			ids:    []string{"/dev/disk/by-id/wwn-b", "/dev/disk/by-id/wwn-c"},
+			phase:  localv1alpha1.VGOperationSucceeded,
+			opType: localv1alpha1.VGOperationExtend,
+			cmds:   []string{"vgextend [/dev/sdc]"},
+		},
+		{
+			name:   "device claimed by pv",
+			ids:    []string{"/dev/sdb", "/dev/sdd"},
+			phase:  localv1alpha1.VGOperationFailed,
+			opType: localv1alpha1.VGOperationExtend,
+		},
+		{
+			name:   "partition of disk claimed by pv",
+			ids:    []string{"/dev/sdb", "/dev/sdd1"},
+			phase:  localv1alpha1.VGOperationFailed,
+			opType: localv1alpha1.VGOperationExtend,
+		},
+		{
+			name:   "device without by-id link",
+			ids:    []string{"/dev/sdb", "/dev/vdb"},
+			phase:  localv1alpha1.VGOperationFailed,
+			opType: localv1alpha1.VGOperationExtend,
+		},
+		{
+			name:   "device not found",
+			ids:    []string{"/dev/sdb", "/dev/sdz"},
+			phase:  localv1alpha1.VGOperationFailed,
+			opType: localv1alpha1.VGOperationExtend,
+		},
+		{
+			name: "unchanged",
+			ids:  []string{"/dev/disk/by-id/wwn-b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vg := &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{"/dev/sdb": {Size: 100, Free: 50, Allocatable: true}}}
+			op := d.updateVG(vg, test.ids, infos, claimed)
+			if test.phase == "" {
+				if op != nil {
+					t.Errorf("expected no operation, got %+v", op)
+				}
+			} else if op == nil || op.Type != test.opType || op.Phase != test.phase {
+				t.Errorf("expected %s %s, got %+v", test.opType, test.phase, op)
+			}
+			if !reflect.DeepEqual(vg.cmds, test.cmds) {
+				t.Errorf("expected commands %v, got %v", test.cmds, vg.cmds)
+			}
+		})
+	}
+
+	// the pv of the vg can not be resolved by its by-id link
+	vg := &fakeVolumeGroup{usages: map[string]lvm.PhysicalVolumeUsage{"/dev/vdb": {Size: 100, Free: 100, Allocatable: true}}}
+	if op := d.updateVG(vg, []string{"/dev/sdb"}, infos, claimed); op == nil || op.Phase != localv1alpha1.VGOperationFailed || len(vg.cmds) != 0 {
+		t.Errorf("expected failed operation without commands, got %+v, %v", op, vg.cmds)
+	}
+}
+
+func TestMergeVGOperations(t *testing.T) {
+	vgs := []localv1alpha1.VGToBeInited{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	lastTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	oldOps := []localv1alpha1.VGOperation{
+		{Name: "a", Type: localv1alpha1.VGOperationReduce, Devices: []string{"/dev/sdb"}, Phase: localv1alpha1.VGOperationRunning, Progress: "10.00%", LastUpdateTime: lastTime},
+		{Name: "b", Type: localv1alpha1.VGOperationExtend, Devices: []string{"/dev/sdc"}, Phase: localv1alpha1.VGOperationFailed, Reason: "device busy", LastUpdateTime: lastTime},
+		{Name: "removed", Type: localv1alpha1.VGOperationCreate, Phase: localv1alpha1.VGOperationSucceeded, LastUpdateTime: lastTime},
+	}
+	// the progress of a changes, b fails again, and c fails to be created
+	ops := map[string]*localv1alpha1.VGOperation{
+		"a": {Name: "a", Type: localv1alpha1.VGOperationReduce, Devices: []string{"/dev/sdb"}, Phase: localv1alpha1.VGOperationRunning, Progress: "55.00%", LastUpdateTime: metav1.Now()},
+		"b": {Name: "b", Type: localv1alpha1.VGOperationExtend, Devices: []string{"/dev/sdc"}, Phase: localv1alpha1.VGOperationFailed, Reason: "device busy", LastUpdateTime: metav1.Now()},
+		"c": {Name: "c", Type: localv1alpha1.VGOperationCreate, Devices: []string{"/dev/sdd"}, Phase: localv1alpha1.VGOperationFailed, Reason: "no such device", LastUpdateTime: metav1.Now()},
+	}
+	newOps, failed := mergeVGOperations(vgs, oldOps, ops)
+	if want := []localv1alpha1.VGOperation{*ops["a"], oldOps[1], *ops["c"]}; !reflect.DeepEqual(newOps, want) {
+		t.Errorf("expected operations %+v, got %+v", want, newOps)
+	}
+	if want := []localv1alpha1.VGOperation{*ops["c"]}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("expected failed operations %+v, got %+v", want, failed)
+	}
+}
+
+func TestClaimedDevices(t *testing.T) {
+	nls := &localv1alpha1.NodeLocalStorage{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nls.Status.NodeStorageInfo.DeviceInfos = []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb", ByID: "/dev/disk/by-id/wwn-b"},
+		{Name: "/dev/sdc"},
+		{Name: "/dev/sdc1", Parent: "/dev/sdc"},
+	}
+	nls.Status.NodeStorageInfo.MountPoints = []localv1alpha1.MountPoint{{Name: "/mnt/disk-1", Device: "/dev/sdc1"}}
+	newPV := func(name, node string, attributes map[string]string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+					Driver: localtype.ProvisionerName, VolumeAttributes: attributes,
+				}},
+				NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key: localtype.KubernetesNodeIdentityKey, Operator: corev1.NodeSelectorOpIn, Values: []string{node},
+					}},
+				}}}},
+			},
+		}
+	}
+	d := &Discoverer{
+		Configuration: &common.Configuration{Nodename: "node-1"},
+		kubeclientset: k8sfake.NewSimpleClientset(
+			// the device was named sdd when the pv was created
+			newPV("pv-device", "node-1", map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeDevice), localtype.DeviceName: "/dev/sdd", localtype.DeviceID: "/dev/disk/by-id/wwn-b"}),
+			newPV("pv-mp", "node-1", map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeMountPoint), localtype.MPName: "/mnt/disk-1"}),
+			newPV("pv-other", "node-2", map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeDevice), localtype.DeviceName: "/dev/sde"}),
+		),
+	}
+	claimed, err := d.claimedDevices(nls)
+	if err != nil {
+		t.Fatalf("failed to get claimed devices: %v", err)
+	}
+	if want := map[string]string{"/dev/sdb": "pv-device", "/dev/sdc1": "pv-mp", "/dev/sdc": "pv-mp"}; !reflect.DeepEqual(claimed, want) {
+		t.Errorf("expected claimed devices %v, got %v", want, claimed)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:scope=Cluster,shortName=lvmdreq
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.nodeName`,name="Node",type=string
+// +kubebuilder:printcolumn:JSONPath=`.spec.method`,name="Method",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.completed`,name="Completed",type=boolean
+
+// LvmdRequest is a lvmd call of csi controller served by the node, which only csi controller is allowed to create
+type LvmdRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LvmdRequestSpec   `json:"spec,omitempty"`
+	Status LvmdRequestStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// LvmdRequestList contains a list of LvmdRequest
+type LvmdRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LvmdRequest `json:"items"`
+}
+
+// LvmdRequestSpec is the lvmd call
+type LvmdRequestSpec struct {
+	// NodeName is the node to serve the call
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:MinLength=1
+	NodeName string `json:"nodeName"`
+	// Method is the full grpc method name, e.g. /proto.LVM/CreateLV
+	Method string `json:"method"`
+	// Request is the request of the method in json
+	Request string `json:"request,omitempty"`
+	// Nonce is the random value of the request, which the token is bound to
+	Nonce string `json:"nonce"`
+	// Token is the service account token of csi controller bound to the audience of lvmd for the node and the nonce,
+	// which the node reviews to authenticate the caller
+	Token string `json:"token"`
+}
+
+// LvmdRequestStatus is the result of the lvmd call written back by the node
+type LvmdRequestStatus struct {
+	// Completed is set once the call is served
+	Completed bool `json:"completed,omitempty"`
+	// Reply is the reply of the method in json
+	Reply string `json:"reply,omitempty"`
+	// Code and Message are the grpc status of the call
+	Code    uint32 `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Token is the service account token of the pod serving the call bound to the audience of lvmd replies,
+	// which csi controller reviews to check that the result is written by the node
+	Token string `json:"token,omitempty"`
+}
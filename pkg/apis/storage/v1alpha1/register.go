@@ -49,6 +49,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&NodeLocalStorageList{},
 		&NodeLocalStorageInitConfig{},
 		&NodeLocalStorageInitConfigList{},
+		&LvmdRequest{},
+		&LvmdRequestList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
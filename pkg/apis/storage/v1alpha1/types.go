@@ -67,6 +67,9 @@ type NodeLocalStorageStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 	NodeStorageInfo     NodeStorageInfo     `json:"nodeStorageInfo,omitempty"`
 	FilteredStorageInfo FilteredStorageInfo `json:"filteredStorageInfo,omitempty"`
+	// VGOperations is the last operation of each volume group in ResourceToBeInited,
+	// which reconciles the volume group to its devices
+	VGOperations []VGOperation `json:"vgOperations,omitempty"`
 }
 
 type ListConfig struct {
@@ -76,7 +79,8 @@ type ListConfig struct {
 	// BlacklistMountPoints defines the user specified mount points which are not allowed for scheduling
 	MountPoints MountPointList `json:"mountPoints,omitempty"`
 	// Devices defines the user specified Devices to be scheduled,
-	// only raw device specified here can be picked by scheduler
+	// only raw device specified here can be picked by scheduler,
+	// a device is matched by its name, by-id link, serial or wwn
 	Devices DeviceList `json:"devices,omitempty"`
 }
 
@@ -96,6 +100,11 @@ type MountPointList struct {
 	// +kubebuilder:validation:MaxItems=50
 	// +kubebuilder:validation:UniqueItems=false
 	Exclude []string `json:"exclude,omitempty"`
+	// Quota defines the mount points shared by Quota volumes instead of being allocated exclusively,
+	// which must be mounted with prjquota or pquota option
+	// +kubebuilder:validation:MaxItems=50
+	// +kubebuilder:validation:UniqueItems=false
+	Quota []string `json:"quota,omitempty"`
 }
 
 type DeviceList struct {
@@ -118,6 +127,39 @@ type ResourceToBeInited struct {
 	// +kubebuilder:validation:MaxItems=50
 	// +kubebuilder:validation:UniqueItems=false
 	MountPoints []MountPointToBeInited `json:"mountpoints,omitempty"`
+	// Partitions defines the user specified GPT partitions of disks,
+	// which will be created by Filtered Agent before VGs and MountPoints
+	// +kubebuilder:validation:MaxItems=50
+	// +kubebuilder:validation:UniqueItems=false
+	Partitions []PartitionsToBeInited `json:"partitions,omitempty"`
+}
+
+type PartitionsToBeInited struct {
+	// Device is the disk to be partitioned,
+	// it is the name, by-id link, serial or wwn of the disk
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:MinLength=1
+	Device string `json:"device"`
+	// Partitions are created in order on the disk, and are referenced
+	// by VGs and MountPoints as /dev/disk/by-partlabel/<name>
+	// +kubebuilder:validation:MaxItems=128
+	// +kubebuilder:validation:MinItems=1
+	Partitions []PartitionToBeInited `json:"partitions"`
+	// Force wipes the signatures of the disk, e.g. filesystems, physical volumes
+	// or partition tables not matching Partitions, which are never touched otherwise
+	Force bool `json:"force,omitempty"`
+}
+
+type PartitionToBeInited struct {
+	// Name is the name of the GPT partition, which should be unique on the node
+	// +kubebuilder:validation:MaxLength=36
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_.-]+$`
+	Name string `json:"name"`
+	// Size is the size of the partition like 100Gi, or the percentage of the disk like 30%,
+	// the last partition takes the rest of the disk if its size is empty
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?%|[0-9]+(Ki|Mi|Gi|Ti|Pi)?)$`
+	Size string `json:"size,omitempty"`
 }
 
 type VGToBeInited struct {
@@ -126,10 +168,15 @@ type VGToBeInited struct {
 	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 	// Device can be whole disk or disk partition
-	// which will be initialized as Physical Volume
+	// which will be initialized as Physical Volume,
+	// it is the name, by-id link, serial or wwn of the device,
+	// or the by-partlabel link of the partition
 	// +kubebuilder:validation:MaxItems=50
 	// +kubebuilder:validation:UniqueItems=false
 	Devices []string `json:"devices"`
+	// Reconcile extends or reduces the existing volume group to the devices,
+	// which must have by-id links and must not be claimed by Device or MountPoint PVs
+	Reconcile bool `json:"reconcile,omitempty"`
 }
 
 type MountPointToBeInited struct {
@@ -138,7 +185,7 @@ type MountPointToBeInited struct {
 	// +kubebuilder:validation:MinLength=1
 	// +kubebuilder:validation:Pattern=`^(/[^/ ]*)+/?$`
 	Path string `json:"path"`
-	// Device is the device underlying the mount point
+	// Device is the device underlying the mount point, which can be its by-id or by-partlabel link
 	// +kubebuilder:validation:MaxLength=128
 	// +kubebuilder:validation:MinLength=1
 	// +kubebuilder:validation:Pattern=`^(/[^/ ]*)+/?$`
@@ -194,6 +241,42 @@ type FilteredStorageInfo struct {
 	UpdateStatus UpdateStatusInfo `json:"updateStatusInfo,omitempty"`
 }
 
+type VGOperationType string
+
+const (
+	// VGOperationCreate creates the volume group with its devices
+	VGOperationCreate VGOperationType = "Create"
+	// VGOperationExtend adds the new devices to the volume group
+	VGOperationExtend VGOperationType = "Extend"
+	// VGOperationReduce moves the extents off the removed device and removes it from the volume group
+	VGOperationReduce VGOperationType = "Reduce"
+)
+
+type VGOperationPhase string
+
+const (
+	VGOperationRunning   VGOperationPhase = "Running"
+	VGOperationSucceeded VGOperationPhase = "Succeeded"
+	VGOperationFailed    VGOperationPhase = "Failed"
+)
+
+// VGOperation is the status of an operation reconciling a volume group to ResourceToBeInited
+type VGOperation struct {
+	// Name is the name of volume group
+	Name string `json:"name"`
+	// Type is Create, Extend or Reduce
+	Type VGOperationType `json:"type"`
+	// Devices are the devices created with, added to or removed from the volume group
+	Devices []string `json:"devices,omitempty"`
+	// Phase is Running, Succeeded or Failed
+	Phase VGOperationPhase `json:"phase"`
+	// Progress is the percentage of the extents moved off the removed device
+	Progress string `json:"progress,omitempty"`
+	// Reason is why the operation failed
+	Reason         string      `json:"reason,omitempty"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
 type StoragePhase string
 
 // These are the valid phases of node.
@@ -220,10 +303,30 @@ type VolumeGroup struct {
 	Available uint64 `json:"available"`
 	// Allocatable is the free size for Filtered
 	Allocatable uint64 `json:"allocatable"`
+	// ThinPool is the thin pool in this VG, if any
+	ThinPool *ThinPool `json:"thinPool,omitempty"`
+	// MediaType is the media type like ssd/hdd of all the PVs, empty if unknown or mixed
+	MediaType string `json:"mediaType,omitempty"` /*ssd,hdd*/
 	// Condition is the condition for Volume group
 	Condition StorageConditionType `json:"condition,omitempty"`
 }
 
+// ThinPool is an alias for LVM thin pool LV
+type ThinPool struct {
+	// Name is the thin pool LV name
+	Name string `json:"name"`
+	// Total is the data size of thin pool
+	Total uint64 `json:"total"`
+	// DataUsed is the used data size of thin pool
+	DataUsed uint64 `json:"dataUsed"`
+	// MetadataTotal is the metadata size of thin pool
+	MetadataTotal uint64 `json:"metadataTotal"`
+	// MetadataUsed is the used metadata size of thin pool
+	MetadataUsed uint64 `json:"metadataUsed"`
+	// VirtualAllocated is the sum of virtual size of thin LVs in this pool
+	VirtualAllocated uint64 `json:"virtualAllocated"`
+}
+
 // LogicalVolume is an alias for LVM LV
 type LogicalVolume struct {
 	// Name is the LV name
@@ -266,6 +369,18 @@ type DeviceInfo struct {
 	Name string `json:"name,omitempty"` /* /dev/sda*/
 	// MediaType is the media type like ssd/hdd
 	MediaType string `json:"mediaType,omitempty"` /*ssd,hdd*/
+	// Parent is the disk of the partition, empty for disks
+	Parent string `json:"parent,omitempty"` /* /dev/sda*/
+	// ByID is the by-id link of the device, which is stable across reboots
+	ByID string `json:"byID,omitempty"` /* /dev/disk/by-id/wwn-0x5000c500a0b1c2d3*/
+	// Serial is the serial number of the disk
+	Serial string `json:"serial,omitempty"`
+	// WWN is the world wide name of the disk
+	WWN string `json:"wwn,omitempty"`
+	// Model is the model of the disk
+	Model string `json:"model,omitempty"`
+	// PartLabel is the name of the GPT partition, whose link is /dev/disk/by-partlabel/<partLabel>
+	PartLabel string `json:"partLabel,omitempty"`
 	// Total is the raw block device size
 	Total uint64 `json:"total"` /**/
 	// ReadOnly indicates whether the device is ready-only
@@ -288,6 +403,10 @@ const (
 
 	// StorageFault means some disks are under disk failure
 	StorageFault StorageConditionType = "DiskFault"
+	// StorageDegraded means the disk still services IO but reports SMART warnings or I/O errors
+	StorageDegraded StorageConditionType = "DiskDegraded"
+	// StorageFailed means the disk fails its health check or is offline
+	StorageFailed StorageConditionType = "DiskFailed"
 )
 
 // The below types are used by kube_client and api_server.
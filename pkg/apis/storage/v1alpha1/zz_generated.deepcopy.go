@@ -152,6 +152,99 @@ func (in *LogicalVolume) DeepCopy() *LogicalVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LvmdRequest) DeepCopyInto(out *LvmdRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LvmdRequest.
+func (in *LvmdRequest) DeepCopy() *LvmdRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(LvmdRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LvmdRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LvmdRequestList) DeepCopyInto(out *LvmdRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LvmdRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LvmdRequestList.
+func (in *LvmdRequestList) DeepCopy() *LvmdRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(LvmdRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LvmdRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LvmdRequestSpec) DeepCopyInto(out *LvmdRequestSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LvmdRequestSpec.
+func (in *LvmdRequestSpec) DeepCopy() *LvmdRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LvmdRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LvmdRequestStatus) DeepCopyInto(out *LvmdRequestStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LvmdRequestStatus.
+func (in *LvmdRequestStatus) DeepCopy() *LvmdRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LvmdRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MountPoint) DeepCopyInto(out *MountPoint) {
 	*out = *in
@@ -186,6 +279,11 @@ func (in *MountPointList) DeepCopyInto(out *MountPointList) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -411,6 +509,13 @@ func (in *NodeLocalStorageStatus) DeepCopyInto(out *NodeLocalStorageStatus) {
 	*out = *in
 	in.NodeStorageInfo.DeepCopyInto(&out.NodeStorageInfo)
 	in.FilteredStorageInfo.DeepCopyInto(&out.FilteredStorageInfo)
+	if in.VGOperations != nil {
+		in, out := &in.VGOperations, &out.VGOperations
+		*out = make([]VGOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -460,6 +565,43 @@ func (in *NodeStorageInfo) DeepCopy() *NodeStorageInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionToBeInited) DeepCopyInto(out *PartitionToBeInited) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionToBeInited.
+func (in *PartitionToBeInited) DeepCopy() *PartitionToBeInited {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionToBeInited)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionsToBeInited) DeepCopyInto(out *PartitionsToBeInited) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]PartitionToBeInited, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionsToBeInited.
+func (in *PartitionsToBeInited) DeepCopy() *PartitionsToBeInited {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionsToBeInited)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceToBeInited) DeepCopyInto(out *ResourceToBeInited) {
 	*out = *in
@@ -477,6 +619,13 @@ func (in *ResourceToBeInited) DeepCopyInto(out *ResourceToBeInited) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]PartitionsToBeInited, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -508,6 +657,22 @@ func (in *StorageState) DeepCopy() *StorageState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThinPool) DeepCopyInto(out *ThinPool) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThinPool.
+func (in *ThinPool) DeepCopy() *ThinPool {
+	if in == nil {
+		return nil
+	}
+	out := new(ThinPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpdateStatusInfo) DeepCopyInto(out *UpdateStatusInfo) {
 	*out = *in
@@ -551,6 +716,28 @@ func (in *VGList) DeepCopy() *VGList {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VGOperation) DeepCopyInto(out *VGOperation) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VGOperation.
+func (in *VGOperation) DeepCopy() *VGOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(VGOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VGToBeInited) DeepCopyInto(out *VGToBeInited) {
 	*out = *in
@@ -585,6 +772,11 @@ func (in *VolumeGroup) DeepCopyInto(out *VolumeGroup) {
 		*out = make([]LogicalVolume, len(*in))
 		copy(*out, *in)
 	}
+	if in.ThinPool != nil {
+		in, out := &in.ThinPool, &out.ThinPool
+		*out = new(ThinPool)
+		**out = **in
+	}
 	return
 }
 
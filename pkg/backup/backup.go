@@ -0,0 +1,245 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+const (
+	// DefaultChunkSize is the size of the chunks a volume is split into
+	DefaultChunkSize = 4 * 1024 * 1024
+
+	chunksDir    = "chunks"
+	manifestsDir = "manifests"
+	volumesDir   = "volumes"
+	latestFile   = "latest"
+)
+
+// Manifest describes a backup of a snapshot, the data are stored as content addressed chunks
+// so that chunks unchanged since the previous backup of the same volume are not uploaded again
+type Manifest struct {
+	Name      string `json:"name"`
+	Volume    string `json:"volume"`
+	Snapshot  string `json:"snapshot"`
+	Size      int64  `json:"size"`
+	ChunkSize int64  `json:"chunkSize"`
+	// Chunks are the sha256 of the chunks, empty for chunks of zeros which are not stored
+	Chunks    []string  `json:"chunks"`
+	Base      string    `json:"base,omitempty"`
+	Owner     Owner     `json:"owner"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Owner is the pvc whose snapshot is backed up, only pvcs of the same namespace may restore the backup
+type Owner struct {
+	Namespace string `json:"namespace"`
+	PVC       string `json:"pvc"`
+}
+
+// CheckOwner returns error if the backup may not be restored to a pvc of namespace
+func (m *Manifest) CheckOwner(namespace string) error {
+	if m.Owner.Namespace == "" || m.Owner.PVC == "" {
+		return fmt.Errorf("backup %s records no owner", m.Name)
+	}
+	if m.Owner.Namespace != namespace {
+		return fmt.Errorf("backup %s of pvc %s/%s can not be restored to namespace %s", m.Name, m.Owner.Namespace, m.Owner.PVC, namespace)
+	}
+	return nil
+}
+
+// Stats is the result of a backup
+type Stats struct {
+	Chunks         int
+	UploadedChunks int
+	UploadedBytes  int64
+}
+
+// Progress is called with the bytes processed
+type Progress func(done, total int64)
+
+// ChunkKey returns the key of the chunk with sha256 sum
+func ChunkKey(sum string) string {
+	return path.Join(chunksDir, sum)
+}
+
+// ManifestKey returns the key of the manifest of backup name
+func ManifestKey(name string) string {
+	return path.Join(manifestsDir, name+".json")
+}
+
+// LatestKey returns the key of the pointer to the latest backup of volume
+func LatestKey(volume string) string {
+	return path.Join(volumesDir, volume, latestFile)
+}
+
+// GetManifest returns the manifest of backup name
+func GetManifest(ctx context.Context, target Target, name string) (*Manifest, error) {
+	data, err := target.Get(ctx, ManifestKey(name))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest of backup %s: %v", name, err)
+	}
+	return manifest, nil
+}
+
+// latestManifest returns the manifest of the latest backup of volume, nil if there is none
+func latestManifest(ctx context.Context, target Target, volume string) (*Manifest, error) {
+	data, err := target.Get(ctx, LatestKey(volume))
+	if err == ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	manifest, err := GetManifest(ctx, target, string(bytes.TrimSpace(data)))
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	return manifest, err
+}
+
+// Backup reads size bytes of the snapshot device of owner and stores them in target as backup name.
+// Only chunks not in the latest backup of volume are checked against and uploaded to the target,
+// the manifest is written last so that a backup is never seen before all its chunks.
+func Backup(ctx context.Context, target Target, src io.Reader, size int64, name, volume, snapshot string, owner Owner, progress Progress) (*Manifest, *Stats, error) {
+	base, err := latestManifest(ctx, target, volume)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get latest backup of volume %s: %v", volume, err)
+	}
+	if base != nil && base.ChunkSize != DefaultChunkSize {
+		base = nil
+	}
+
+	manifest := &Manifest{
+		Name:      name,
+		Volume:    volume,
+		Snapshot:  snapshot,
+		Size:      size,
+		ChunkSize: DefaultChunkSize,
+		Owner:     owner,
+		CreatedAt: time.Now().UTC(),
+	}
+	if base != nil {
+		manifest.Base = base.Name
+	}
+	stats := &Stats{}
+	buf := make([]byte, DefaultChunkSize)
+	for offset := int64(0); offset < size; offset += DefaultChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		n := DefaultChunkSize
+		if size-offset < int64(n) {
+			n = int(size - offset)
+		}
+		chunk := buf[:n]
+		if _, err := io.ReadFull(src, chunk); err != nil {
+			return nil, nil, fmt.Errorf("failed to read at offset %d: %v", offset, err)
+		}
+		sum := ""
+		if !isZero(chunk) {
+			sum = sha256Hex(chunk)
+		}
+		index := len(manifest.Chunks)
+		manifest.Chunks = append(manifest.Chunks, sum)
+		stats.Chunks++
+		if progress != nil {
+			progress(offset+int64(n), size)
+		}
+		if sum == "" || (base != nil && index < len(base.Chunks) && base.Chunks[index] == sum) {
+			continue
+		}
+		exist, err := target.Exists(ctx, ChunkKey(sum))
+		if err != nil {
+			return nil, nil, err
+		}
+		if exist {
+			continue
+		}
+		if err := target.Put(ctx, ChunkKey(sum), chunk); err != nil {
+			return nil, nil, fmt.Errorf("failed to upload chunk at offset %d: %v", offset, err)
+		}
+		stats.UploadedChunks++
+		stats.UploadedBytes += int64(n)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := target.Put(ctx, ManifestKey(name), data); err != nil {
+		return nil, nil, fmt.Errorf("failed to upload manifest: %v", err)
+	}
+	if err := target.Put(ctx, LatestKey(volume), []byte(name)); err != nil {
+		return nil, nil, fmt.Errorf("failed to update latest backup of volume %s: %v", volume, err)
+	}
+	return manifest, stats, nil
+}
+
+// Restore writes the data of backup name into dst, which must be at least as large as the backup
+func Restore(ctx context.Context, target Target, dst io.Writer, name string, progress Progress) (*Manifest, error) {
+	manifest, err := GetManifest(ctx, target, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest of backup %s: %v", name, err)
+	}
+	zeros := make([]byte, manifest.ChunkSize)
+	for i, sum := range manifest.Chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		offset := int64(i) * manifest.ChunkSize
+		n := manifest.ChunkSize
+		if manifest.Size-offset < n {
+			n = manifest.Size - offset
+		}
+		chunk := zeros[:n]
+		if sum != "" {
+			chunk, err = target.Get(ctx, ChunkKey(sum))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get chunk %s: %v", sum, err)
+			}
+			if int64(len(chunk)) != n || sha256Hex(chunk) != sum {
+				return nil, fmt.Errorf("chunk %s of backup %s is corrupted", sum, name)
+			}
+		}
+		if _, err := dst.Write(chunk); err != nil {
+			return nil, fmt.Errorf("failed to write at offset %d: %v", offset, err)
+		}
+		if progress != nil {
+			progress(offset+n, manifest.Size)
+		}
+	}
+	return manifest, nil
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestBackupRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	target, err := NewTarget("file://"+dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	owner := Owner{Namespace: "ns-1", PVC: "pvc-1"}
+
+	// 3 chunks of data, 1 chunk of zeros and a partial chunk
+	size := int64(4*DefaultChunkSize + 1024)
+	data := make([]byte, size)
+	for i := 0; i < 3*DefaultChunkSize; i++ {
+		data[i] = byte(i % 251)
+	}
+	copy(data[4*DefaultChunkSize:], bytes.Repeat([]byte{1}, 1024))
+
+	manifest, stats, err := Backup(ctx, target, bytes.NewReader(data), size, "backup-1", "pv-1", "snap-1", owner, nil)
+	if err != nil {
+		t.Fatalf("full backup: %v", err)
+	}
+	if len(manifest.Chunks) != 5 || manifest.Chunks[3] != "" || manifest.Base != "" {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	// chunks of zeros are not uploaded
+	if stats.UploadedChunks != 4 {
+		t.Fatalf("expect 4 chunks uploaded, got %d", stats.UploadedChunks)
+	}
+
+	data[DefaultChunkSize] ^= 0xff
+	manifest, stats, err = Backup(ctx, target, bytes.NewReader(data), size, "backup-2", "pv-1", "snap-2", owner, nil)
+	if err != nil {
+		t.Fatalf("incremental backup: %v", err)
+	}
+	if manifest.Base != "backup-1" || stats.UploadedChunks != 1 || stats.UploadedBytes != DefaultChunkSize {
+		t.Fatalf("unexpected incremental backup %+v, %+v", manifest, stats)
+	}
+
+	out := &bytes.Buffer{}
+	var done int64
+	if _, err := Restore(ctx, target, out, "backup-2", func(d, total int64) { done = d }); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) || done != size {
+		t.Fatalf("restored data mismatch")
+	}
+
+	got, err := GetManifest(ctx, target, "backup-2")
+	if err != nil {
+		t.Fatalf("get manifest: %v", err)
+	}
+	if err := got.CheckOwner("ns-1"); err != nil {
+		t.Fatalf("expect backup restorable to namespace of owner: %v", err)
+	}
+	if err := got.CheckOwner("ns-2"); err == nil {
+		t.Fatalf("expect error restoring backup to another namespace")
+	}
+	if err := (&Manifest{Name: "backup-0"}).CheckOwner("ns-1"); err == nil {
+		t.Fatalf("expect error restoring backup without owner")
+	}
+
+	if _, err := Restore(ctx, target, ioutil.Discard, "backup-3", nil); err == nil {
+		t.Fatalf("expect error restoring unknown backup")
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewTargetFromParameters returns the target of the class parameters, the credentials are read from the secret referred
+func NewTargetFromParameters(client kubernetes.Interface, params map[string]string) (Target, error) {
+	targetURL := params[localtype.ParamBackupTarget]
+	if targetURL == "" {
+		return nil, fmt.Errorf("parameter %s is not set", localtype.ParamBackupTarget)
+	}
+	credentials := map[string]string{}
+	if name := params[localtype.ParamBackupSecretName]; name != "" {
+		namespace := params[localtype.ParamBackupSecretNamespace]
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get backup secret %s/%s failed: %s", namespace, name, err.Error())
+		}
+		for key, value := range secret.Data {
+			credentials[key] = string(value)
+		}
+	}
+	return NewTarget(targetURL, credentials)
+}
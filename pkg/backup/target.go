@@ -0,0 +1,133 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SchemeFile is the scheme of targets on the filesystem, e.g. file:///var/lib/open-local/backup
+	SchemeFile = "file"
+	// SchemeS3 is the scheme of targets on s3 compatible object stores, e.g. s3://bucket/prefix?endpoint=http://minio:9000
+	SchemeS3 = "s3"
+
+	// CredentialAccessKeyID and CredentialSecretAccessKey are the keys of the s3 credentials in the secret
+	CredentialAccessKeyID     = "accessKeyID"
+	CredentialSecretAccessKey = "secretAccessKey"
+)
+
+// ErrNotFound is returned when the object does not exist in the target
+var ErrNotFound = errors.New("object not found")
+
+// Target is where the backups are stored, objects are addressed by slash separated keys
+type Target interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns ErrNotFound if the object does not exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// NewTarget returns the target of the url, credentials are needed by s3 targets
+func NewTarget(targetURL string, credentials map[string]string) (Target, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup target %s: %v", targetURL, err)
+	}
+	switch u.Scheme {
+	case SchemeFile:
+		if u.Path == "" {
+			return nil, fmt.Errorf("invalid backup target %s: empty path", targetURL)
+		}
+		return &FileTarget{Root: u.Path}, nil
+	case SchemeS3:
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid backup target %s: empty bucket", targetURL)
+		}
+		endpoint := u.Query().Get("endpoint")
+		if endpoint == "" {
+			return nil, fmt.Errorf("invalid backup target %s: endpoint must be set", targetURL)
+		}
+		region := u.Query().Get("region")
+		if region == "" {
+			region = DefaultS3Region
+		}
+		return &S3Target{
+			Endpoint:        strings.TrimSuffix(endpoint, "/"),
+			Region:          region,
+			Bucket:          u.Host,
+			Prefix:          strings.Trim(u.Path, "/"),
+			AccessKeyID:     credentials[CredentialAccessKeyID],
+			SecretAccessKey: credentials[CredentialSecretAccessKey],
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid backup target %s: unsupported scheme %q", targetURL, u.Scheme)
+	}
+}
+
+// FileTarget stores objects as files under Root
+type FileTarget struct {
+	Root string
+}
+
+// Put writes the object to a temporary file first, so that a partial object is never seen
+func (t *FileTarget) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(t.Root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (t *FileTarget) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(t.Root, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (t *FileTarget) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := os.Stat(filepath.Join(t.Root, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,462 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	kubeinformers "k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	storageinformers "k8s.io/client-go/informers/storage/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	informers "github.com/alibaba/open-local/pkg/generated/informers/externalversions/storage/v1alpha1"
+	listers "github.com/alibaba/open-local/pkg/generated/listers/storage/v1alpha1"
+	localcache "github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/utils"
+)
+
+// CapacityResource returns the resource of CSIStorageCapacity in the api version, e.g. v1 or v1beta1
+func CapacityResource(version string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: storagev1.GroupName, Version: version, Resource: "csistoragecapacities"}
+}
+
+// CapacityController maintains a CSIStorageCapacity object for every node and open-local storage class,
+// so that kube-scheduler and cluster-autoscaler are aware of open-local storage without the extender
+type CapacityController struct {
+	dynamicClient dynamic.Interface
+	resource      schema.GroupVersionResource
+	namespace     string
+	// thinOvercommitRatio is the ratio of the virtual size of thin volumes to the size of the thin pool
+	thinOvercommitRatio float64
+
+	nlsLister      listers.NodeLocalStorageLister
+	nlsSynced      cache.InformerSynced
+	scLister       storagelisters.StorageClassLister
+	scSynced       cache.InformerSynced
+	pvLister       corelisters.PersistentVolumeLister
+	pvSynced       cache.InformerSynced
+	capacityLister cache.GenericLister
+	capacitySynced cache.InformerSynced
+
+	storageInformers storageinformers.Interface
+	coreInformers    coreinformers.Interface
+
+	// workqueue is keyed by node name
+	workqueue workqueue.RateLimitingInterface
+}
+
+// NewCapacityController returns a CapacityController publishing CSIStorageCapacity objects in namespace,
+// capacityInformer must watch the objects labeled as managed by open-local in namespace
+func NewCapacityController(
+	dynamicClient dynamic.Interface,
+	resource schema.GroupVersionResource,
+	namespace string,
+	thinOvercommitRatio float64,
+	nlsInformer informers.NodeLocalStorageInformer,
+	storageInformers storageinformers.Interface,
+	coreInformers coreinformers.Interface,
+	capacityInformer kubeinformers.GenericInformer) *CapacityController {
+
+	c := &CapacityController{
+		dynamicClient:       dynamicClient,
+		resource:            resource,
+		namespace:           namespace,
+		thinOvercommitRatio: thinOvercommitRatio,
+		nlsLister:           nlsInformer.Lister(),
+		nlsSynced:           nlsInformer.Informer().HasSynced,
+		scLister:            storageInformers.StorageClasses().Lister(),
+		scSynced:            storageInformers.StorageClasses().Informer().HasSynced,
+		pvLister:            coreInformers.PersistentVolumes().Lister(),
+		pvSynced:            coreInformers.PersistentVolumes().Informer().HasSynced,
+		capacityLister:      capacityInformer.Lister(),
+		capacitySynced:      capacityInformer.Informer().HasSynced,
+		storageInformers:    storageInformers,
+		coreInformers:       coreInformers,
+		workqueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "CSIStorageCapacity"),
+	}
+
+	nlsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			c.handleObject(new)
+		},
+		DeleteFunc: c.handleObject,
+	})
+	storageInformers.StorageClasses().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleStorageClass,
+		UpdateFunc: func(old, new interface{}) {
+			c.handleStorageClass(new)
+		},
+		DeleteFunc: c.handleStorageClass,
+	})
+	coreInformers.PersistentVolumes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handlePV,
+		UpdateFunc: func(old, new interface{}) {
+			c.handlePV(new)
+		},
+		DeleteFunc: c.handlePV,
+	})
+	// objects modified or deleted by others are restored
+	capacityInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			c.handleCapacity(new)
+		},
+		DeleteFunc: c.handleCapacity,
+	})
+
+	return c
+}
+
+func (c *CapacityController) Run(workers int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	log.Info("Waiting for capacity informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh, c.nlsSynced, c.scSynced, c.pvSynced, c.capacitySynced); !ok {
+		return fmt.Errorf("failed to wait for capacity caches to sync")
+	}
+
+	// objects of nodes deleted while controller was down
+	if err := c.enqueueCapacityNodes(); err != nil {
+		return err
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	log.Infof("Started capacity controller, publishing %s in namespace %s", c.resource.String(), c.namespace)
+	<-stopCh
+	log.Info("Shutting down capacity controller")
+
+	return nil
+}
+
+func (c *CapacityController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *CapacityController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	nodeName, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncNode(nodeName); err != nil {
+		c.workqueue.AddRateLimited(nodeName)
+		utilruntime.HandleError(fmt.Errorf("error syncing capacity of node %s: %s, requeuing", nodeName, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	return true
+}
+
+// syncNode makes the CSIStorageCapacity objects of the node consistent with its NodeLocalStorage,
+// all of them are deleted if the NodeLocalStorage is not found
+func (c *CapacityController) syncNode(nodeName string) error {
+	desired := map[string]*unstructured.Unstructured{}
+	nls, err := c.nlsLister.Get(nodeName)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		nodeCache, err := c.nodeCache(nls)
+		if err != nil {
+			return err
+		}
+		scs, err := c.scLister.List(labels.Everything())
+		if err != nil {
+			return err
+		}
+		for _, sc := range scs {
+			if !utils.ContainsProvisioner(sc.Provisioner) {
+				continue
+			}
+			capacity, maximumVolumeSize, ok := StorageClassCapacity(nodeCache, sc, c.thinOvercommitRatio)
+			if !ok {
+				continue
+			}
+			obj := c.newCapacity(nodeName, sc.Name, capacity, maximumVolumeSize)
+			desired[obj.GetName()] = obj
+		}
+	}
+
+	existing, err := c.listCapacities(nodeName)
+	if err != nil {
+		return err
+	}
+	client := c.dynamicClient.Resource(c.resource).Namespace(c.namespace)
+	for _, obj := range existing {
+		want, ok := desired[obj.GetName()]
+		if !ok {
+			log.Infof("deleting CSIStorageCapacity %s of node %s", obj.GetName(), nodeName)
+			if err := client.Delete(context.Background(), obj.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		delete(desired, obj.GetName())
+		if capacityEqual(obj, want) {
+			continue
+		}
+		objCopy := obj.DeepCopy()
+		objCopy.Object["storageClassName"] = want.Object["storageClassName"]
+		objCopy.Object["nodeTopology"] = want.Object["nodeTopology"]
+		objCopy.Object["capacity"] = want.Object["capacity"]
+		objCopy.Object["maximumVolumeSize"] = want.Object["maximumVolumeSize"]
+		log.Debugf("updating CSIStorageCapacity %s of node %s: capacity %v, maximumVolumeSize %v", obj.GetName(), nodeName, want.Object["capacity"], want.Object["maximumVolumeSize"])
+		if _, err := client.Update(context.Background(), objCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	for name, obj := range desired {
+		log.Infof("creating CSIStorageCapacity %s of node %s, storage class %s", name, nodeName, obj.Object["storageClassName"])
+		if _, err := client.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeCache returns the storage of the node with the open-local pvs allocated
+func (c *CapacityController) nodeCache(nls *localv1alpha1.NodeLocalStorage) (*localcache.NodeCache, error) {
+	nodeCache := localcache.NewNodeCacheFromStorage(nls)
+	pvs, err := c.pvLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, pv := range pvs {
+		if pv.Status.Phase == corev1.VolumePending {
+			continue
+		}
+		if _, node := utils.IsLocalPV(pv); node != nls.Name {
+			continue
+		}
+		containReadonlySnapshot := false
+		isOpenLocalPV, pvType := utils.IsOpenLocalPV(pv, c.storageInformers, c.coreInformers, containReadonlySnapshot)
+		if !isOpenLocalPV {
+			continue
+		}
+		if err := nodeCache.AddLocalPV(pv, pvType); err != nil {
+			log.Errorf("[nodeCache]failed to add local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nls.Name, err.Error())
+		}
+	}
+	return nodeCache, nil
+}
+
+// StorageClassCapacity returns the free storage of the node for volumes of the storage class,
+// and the size of the largest volume that can be created. ok is false if sc is not of open-local types
+func StorageClassCapacity(nodeCache *localcache.NodeCache, sc *storagev1.StorageClass, thinOvercommitRatio float64) (capacity, maximumVolumeSize int64, ok bool) {
+	switch utils.LocalPVType(sc) {
+	case localtype.VolumeTypeLVM:
+		vgName := sc.Parameters[localtype.ParamVGName]
+		thin := sc.Parameters[localtype.ParamLVMType] == localtype.LVMTypeThin
+		class := localcache.NewThinClass(sc.Parameters, thinOvercommitRatio)
+		mediaType := localtype.MediaType(sc.Parameters[localtype.VolumeMediaType])
+		for _, vg := range nodeCache.VGs {
+			if vgName != "" && vg.Name != vgName {
+				continue
+			}
+			if (mediaType != "" && vg.MediaType != mediaType) || vg.Unhealthy() {
+				continue
+			}
+			capacity, maximumVolumeSize = addFree(capacity, maximumVolumeSize, localcache.VGFree(vg, thin, class))
+		}
+	case localtype.VolumeTypeQuota:
+		for _, quota := range nodeCache.Quotas {
+			capacity, maximumVolumeSize = addFree(capacity, maximumVolumeSize, quota.Capacity-quota.Requested)
+		}
+	case localtype.VolumeTypeMountPoint, localtype.VolumeTypeDevice:
+		resources := nodeCache.MountPoints
+		if utils.LocalPVType(sc) == localtype.VolumeTypeDevice {
+			resources = nodeCache.Devices
+		}
+		// exclusive volumes are only scheduled to the disks of the media type of storage class
+		mediaType := localtype.MediaType(sc.Parameters[localtype.VolumeMediaType])
+		for _, r := range resources {
+			if r.IsAllocated || r.MediaType != mediaType || r.Unhealthy() {
+				continue
+			}
+			capacity, maximumVolumeSize = addFree(capacity, maximumVolumeSize, r.Capacity)
+		}
+	default:
+		return 0, 0, false
+	}
+	return capacity, maximumVolumeSize, true
+}
+
+func addFree(capacity, maximumVolumeSize, free int64) (int64, int64) {
+	if free <= 0 {
+		return capacity, maximumVolumeSize
+	}
+	if free > maximumVolumeSize {
+		maximumVolumeSize = free
+	}
+	return capacity + free, maximumVolumeSize
+}
+
+// capacityName is deterministic for the node and storage class, whose names may be too long to be joined
+func capacityName(nodeName, scName string) string {
+	sum := sha256.Sum256([]byte(nodeName + "/" + scName))
+	return fmt.Sprintf("%s%x", localtype.CapacityNamePrefix, sum[:8])
+}
+
+func (c *CapacityController) newCapacity(nodeName, scName string, capacity, maximumVolumeSize int64) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"storageClassName": scName,
+		"nodeTopology": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				localtype.KubernetesNodeIdentityKey: nodeName,
+			},
+		},
+		"capacity":          resource.NewQuantity(capacity, resource.BinarySI).String(),
+		"maximumVolumeSize": resource.NewQuantity(maximumVolumeSize, resource.BinarySI).String(),
+	}}
+	obj.SetAPIVersion(c.resource.GroupVersion().String())
+	obj.SetKind("CSIStorageCapacity")
+	obj.SetNamespace(c.namespace)
+	obj.SetName(capacityName(nodeName, scName))
+	obj.SetLabels(map[string]string{
+		localtype.LabelCapacityDriverName: localtype.ProvisionerName,
+		localtype.LabelCapacityManagedBy:  localtype.CapacityManagedBy,
+	})
+	return obj
+}
+
+func capacityEqual(obj, want *unstructured.Unstructured) bool {
+	if nodeOfCapacity(obj) != nodeOfCapacity(want) || obj.Object["storageClassName"] != want.Object["storageClassName"] {
+		return false
+	}
+	for _, field := range []string{"capacity", "maximumVolumeSize"} {
+		value, _, _ := unstructured.NestedString(obj.Object, field)
+		wantValue, _, _ := unstructured.NestedString(want.Object, field)
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil || quantity.Cmp(resource.MustParse(wantValue)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeOfCapacity returns the node name in the node topology of the object
+func nodeOfCapacity(obj *unstructured.Unstructured) string {
+	nodeName, _, _ := unstructured.NestedString(obj.Object, "nodeTopology", "matchLabels", localtype.KubernetesNodeIdentityKey)
+	return nodeName
+}
+
+func (c *CapacityController) listCapacities(nodeName string) ([]*unstructured.Unstructured, error) {
+	objs, err := c.capacityLister.ByNamespace(c.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var capacities []*unstructured.Unstructured
+	for _, o := range objs {
+		obj, ok := o.(*unstructured.Unstructured)
+		if !ok || nodeOfCapacity(obj) != nodeName {
+			continue
+		}
+		capacities = append(capacities, obj)
+	}
+	return capacities, nil
+}
+
+// enqueueCapacityNodes enqueues the nodes of all existing objects
+func (c *CapacityController) enqueueCapacityNodes() error {
+	objs, err := c.capacityLister.ByNamespace(c.namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		c.handleCapacity(o)
+	}
+	return nil
+}
+
+func (c *CapacityController) handleObject(obj interface{}) {
+	nodeName, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(nodeName)
+}
+
+func (c *CapacityController) handleStorageClass(obj interface{}) {
+	nlsList, err := c.nlsLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, nls := range nlsList {
+		c.workqueue.Add(nls.Name)
+	}
+}
+
+func (c *CapacityController) handlePV(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok {
+		return
+	}
+	if _, nodeName := utils.IsLocalPV(pv); nodeName != "" {
+		c.workqueue.Add(nodeName)
+	}
+}
+
+func (c *CapacityController) handleCapacity(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	capacity, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	if nodeName := nodeOfCapacity(capacity); nodeName != "" {
+		c.workqueue.Add(nodeName)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	informers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	localcache "github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+)
+
+func newStorageClass(name string, params map[string]string) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: name},
+		Provisioner: localtype.ProvisionerName,
+		Parameters:  params,
+	}
+}
+
+func TestStorageClassCapacity(t *testing.T) {
+	nc := localcache.NewNodeCache("node-1")
+	nc.VGs["share"] = localcache.SharedResource{Name: "share", Capacity: 100, Requested: 30, MediaType: localtype.MediaTypeHDD}
+	nc.VGs["paas"] = localcache.SharedResource{Name: "paas", Capacity: 50, Requested: 50}
+	nc.Devices["/dev/sdb"] = localcache.ExclusiveResource{Name: "/dev/sdb", Capacity: 40, MediaType: localtype.MediaTypeSSD}
+	nc.Devices["/dev/sdc"] = localcache.ExclusiveResource{Name: "/dev/sdc", Capacity: 80, MediaType: localtype.MediaTypeSSD, IsAllocated: true}
+	nc.Devices["/dev/sdd"] = localcache.ExclusiveResource{Name: "/dev/sdd", Capacity: 60, MediaType: localtype.MediaTypeHDD}
+	// unhealthy storage has no capacity
+	nc.VGs["broken"] = localcache.SharedResource{Name: "broken", Capacity: 200, MediaType: localtype.MediaTypeHDD, Condition: localv1alpha1.StorageDegraded}
+	nc.Devices["/dev/sde"] = localcache.ExclusiveResource{Name: "/dev/sde", Capacity: 90, MediaType: localtype.MediaTypeSSD, Condition: localv1alpha1.StorageFailed}
+
+	tests := []struct {
+		name              string
+		params            map[string]string
+		capacity          int64
+		maximumVolumeSize int64
+		ok                bool
+	}{
+		{"lvm of any vg", map[string]string{localtype.VolumeTypeKey: "LVM"}, 70, 70, true},
+		{"lvm of hdd vg", map[string]string{localtype.VolumeTypeKey: "LVM", localtype.VolumeMediaType: "hdd"}, 70, 70, true},
+		{"lvm of ssd vg", map[string]string{localtype.VolumeTypeKey: "LVM", localtype.VolumeMediaType: "ssd"}, 0, 0, true},
+		{"lvm of full vg", map[string]string{localtype.VolumeTypeKey: "LVM", localtype.ParamVGName: "paas"}, 0, 0, true},
+		{"ssd devices", map[string]string{localtype.VolumeTypeKey: "Device", localtype.VolumeMediaType: "ssd"}, 40, 40, true},
+		{"devices without media type", map[string]string{localtype.VolumeTypeKey: "Device"}, 0, 0, true},
+		{"unknown type", map[string]string{}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacity, maximumVolumeSize, ok := StorageClassCapacity(nc, newStorageClass("sc", tt.params), localtype.DefaultThinOvercommitRatio)
+			if capacity != tt.capacity || maximumVolumeSize != tt.maximumVolumeSize || ok != tt.ok {
+				t.Errorf("StorageClassCapacity() = %d, %d, %t, want %d, %d, %t", capacity, maximumVolumeSize, ok, tt.capacity, tt.maximumVolumeSize, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCapacityControllerSyncNode(t *testing.T) {
+	nls := &localv1alpha1.NodeLocalStorage{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nls.Status.NodeStorageInfo.VolumeGroups = []localv1alpha1.VolumeGroup{{Name: "share", Total: 100 << 30, Allocatable: 100 << 30}}
+	nls.Status.FilteredStorageInfo.VolumeGroups = []string{"share"}
+	sc := newStorageClass("open-local-lvm", map[string]string{localtype.VolumeTypeKey: "LVM", localtype.ParamVGName: "share"})
+	otherSC := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "other"}, Provisioner: "other.csi.io"}
+
+	kubeclient := k8sfake.NewSimpleClientset(sc, otherSC)
+	localclient := localfake.NewSimpleClientset(nls)
+	resource := CapacityResource("v1")
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{resource: "CSIStorageCapacityList"})
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclient, noResyncPeriodFunc())
+	localInformerFactory := informers.NewSharedInformerFactory(localclient, noResyncPeriodFunc())
+	dynamicInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, noResyncPeriodFunc())
+	c := NewCapacityController(dynamicClient, resource, "kube-system", localtype.DefaultThinOvercommitRatio, localInformerFactory.Csi().V1alpha1().NodeLocalStorages(),
+		kubeInformerFactory.Storage().V1(), kubeInformerFactory.Core().V1(), dynamicInformerFactory.ForResource(resource))
+	_ = localInformerFactory.Csi().V1alpha1().NodeLocalStorages().Informer().GetIndexer().Add(nls)
+	_ = kubeInformerFactory.Storage().V1().StorageClasses().Informer().GetIndexer().Add(sc)
+	_ = kubeInformerFactory.Storage().V1().StorageClasses().Informer().GetIndexer().Add(otherSC)
+
+	if err := c.syncNode("node-1"); err != nil {
+		t.Fatalf("syncNode() error = %v", err)
+	}
+	client := dynamicClient.Resource(resource).Namespace("kube-system")
+	list, err := client.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("expected 1 CSIStorageCapacity, got %d", len(list.Items))
+	}
+	obj := list.Items[0]
+	if obj.GetName() != capacityName("node-1", sc.Name) || obj.Object["storageClassName"] != sc.Name || nodeOfCapacity(&obj) != "node-1" {
+		t.Errorf("unexpected CSIStorageCapacity %v", obj.Object)
+	}
+	if obj.Object["capacity"] != "100Gi" || obj.Object["maximumVolumeSize"] != "100Gi" {
+		t.Errorf("expected capacity 100Gi, got %v, maximumVolumeSize %v", obj.Object["capacity"], obj.Object["maximumVolumeSize"])
+	}
+
+	// objects are deleted with the NodeLocalStorage
+	_ = dynamicInformerFactory.ForResource(resource).Informer().GetIndexer().Add(&obj)
+	_ = localInformerFactory.Csi().V1alpha1().NodeLocalStorages().Informer().GetIndexer().Delete(nls)
+	if err := c.syncNode("node-1"); err != nil {
+		t.Fatalf("syncNode() error = %v", err)
+	}
+	list, err = client.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("expected CSIStorageCapacity deleted, got %d", len(list.Items))
+	}
+}
@@ -18,7 +18,9 @@ package adapter
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/alibaba/open-local/pkg/csi/client"
@@ -86,6 +88,15 @@ func ScheduleVolume(volumeType, pvcName, pvcNamespace, vgName, nodeID string) (*
 	return bindingInfo, nil
 }
 
+// ExpansionRejectedError means the scheduler rejects the expansion as the storage of the volume has no room for the new size
+type ExpansionRejectedError struct {
+	Reason string
+}
+
+func (e *ExpansionRejectedError) Error() string {
+	return e.Reason
+}
+
 // ExpandVolume do volume capacity check
 func ExpandVolume(pvcNameSpace, pvcName string, newSize int) error {
 	urlPath := fmt.Sprintf("/apis/expand/%s/persistentvolumeclaims/%s?newSize=%d", pvcNameSpace, pvcName, newSize)
@@ -99,6 +110,10 @@ func ExpandVolume(pvcNameSpace, pvcName string, newSize int) error {
 	respBody, err := client.DoRequest(url)
 	if err != nil {
 		log.Errorf("Volume Expand with Url(%s) get error: %s", url, err.Error())
+		var respErr *client.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusUnprocessableEntity {
+			return &ExpansionRejectedError{Reason: string(respErr.Body)}
+		}
 		return err
 	}
 
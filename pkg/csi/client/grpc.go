@@ -18,7 +18,9 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
@@ -28,11 +30,15 @@ import (
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 )
 
 // Connection lvm connection interface
 type Connection interface {
 	GetLvm(ctx context.Context, volGroup string, volumeID string) (string, error)
+	ListLvm(ctx context.Context, volGroup string) ([]*lib.LogicalVolume, error)
+	ListVG(ctx context.Context) ([]*lib.VolumeGroup, error)
+	AddTagLvm(ctx context.Context, volGroup string, volumeID string, tags []string) error
 	CreateLvm(ctx context.Context, opt *LVMOptions) (string, error)
 	DeleteLvm(ctx context.Context, volGroup string, volumeID string) error
 	CreateSnapshot(ctx context.Context, volGroup string, snapVolumeID string, volumeID string, size uint64) (string, error)
@@ -40,30 +46,37 @@ type Connection interface {
 	ExpandLvm(ctx context.Context, volGroup string, volumeID string, size uint64) error
 	CleanPath(ctx context.Context, path string) error
 	CleanDevice(ctx context.Context, device string) error
+	SetProjQuota(ctx context.Context, quotaSubpath string, blockHardlimit, blockSoftlimit uint64) (string, error)
+	RemoveProjQuota(ctx context.Context, quotaSubpath string) error
+	ClaimStorage(ctx context.Context, owner string, volumeType string, storage string) (string, error)
+	GetClaimedStorage(ctx context.Context, owner string) (string, error)
+	ReleaseStorage(ctx context.Context, owner string) error
 	Close() error
 }
 
 // LVMOptions lvm options
 type LVMOptions struct {
-	VolumeGroup string   `json:"volumeGroup,omitempty"`
-	Name        string   `json:"name,omitempty"`
-	Size        uint64   `json:"size,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
-	Striping    bool     `json:"striping,omitempty"`
+	VolumeGroup     string   `json:"volumeGroup,omitempty"`
+	Name            string   `json:"name,omitempty"`
+	Size            uint64   `json:"size,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	Striping        bool     `json:"striping,omitempty"`
+	Thin            bool     `json:"thin,omitempty"`
+	ThinPoolPercent uint32   `json:"thinPoolPercent,omitempty"`
 }
 
 //
 type workerConnection struct {
-	conn *grpc.ClientConn
+	conn grpc.ClientConnInterface
 }
 
 var (
 	_ Connection = &workerConnection{}
 )
 
-// NewGrpcConnection lvm connection
-func NewGrpcConnection(address string, timeout time.Duration) (Connection, error) {
-	conn, err := connect(address, timeout)
+// NewGrpcConnection lvm connection, the connection is insecure if tlsConfig is nil
+func NewGrpcConnection(address string, timeout time.Duration, tlsConfig *tls.Config) (Connection, error) {
+	conn, err := connect(address, timeout, tlsConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -73,16 +86,23 @@ func NewGrpcConnection(address string, timeout time.Duration) (Connection, error
 }
 
 func (c *workerConnection) Close() error {
-	return c.conn.Close()
+	if closer, ok := c.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
 }
 
-func connect(address string, timeout time.Duration) (*grpc.ClientConn, error) {
+func connect(address string, timeout time.Duration, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
 	log.Debugf("New Connecting to %s", address)
 	dialOptions := []grpc.DialOption{
-		grpc.WithInsecure(),
 		// grpc.WithBackoffMaxDelay(time.Second),
 		grpc.WithUnaryInterceptor(logGRPC),
 	}
+	if tlsConfig != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
 	// if strings.HasPrefix(address, "/") {
 	// 	dialOptions = append(dialOptions, grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
 	// 		return net.DialTimeout("unix", addr, timeout)
@@ -118,11 +138,13 @@ func connect(address string, timeout time.Duration) (*grpc.ClientConn, error) {
 func (c *workerConnection) CreateLvm(ctx context.Context, opt *LVMOptions) (string, error) {
 	client := lib.NewLVMClient(c.conn)
 	req := lib.CreateLVRequest{
-		VolumeGroup: opt.VolumeGroup,
-		Name:        opt.Name,
-		Size:        opt.Size,
-		Tags:        opt.Tags,
-		Striping:    opt.Striping,
+		VolumeGroup:     opt.VolumeGroup,
+		Name:            opt.Name,
+		Size:            opt.Size,
+		Tags:            opt.Tags,
+		Striping:        opt.Striping,
+		Thin:            opt.Thin,
+		ThinPoolPercent: opt.ThinPoolPercent,
 	}
 
 	rsp, err := client.CreateLV(ctx, &req)
@@ -171,6 +193,50 @@ func (c *workerConnection) GetLvm(ctx context.Context, volGroup string, volumeID
 	return rsp.GetVolumes()[0].String(), nil
 }
 
+func (c *workerConnection) ListLvm(ctx context.Context, volGroup string) ([]*lib.LogicalVolume, error) {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.ListLVRequest{
+		VolumeGroup: volGroup,
+	}
+
+	rsp, err := client.ListLV(ctx, &req)
+	if err != nil {
+		log.Errorf("List Lvm with error: %s", err.Error())
+		return nil, err
+	}
+	log.Debugf("List Lvm with result: %+v", rsp.Volumes)
+	return rsp.GetVolumes(), nil
+}
+
+func (c *workerConnection) ListVG(ctx context.Context) ([]*lib.VolumeGroup, error) {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.ListVGRequest{}
+
+	rsp, err := client.ListVG(ctx, &req)
+	if err != nil {
+		log.Errorf("List VG with error: %s", err.Error())
+		return nil, err
+	}
+	log.Debugf("List VG with result: %+v", rsp.VolumeGroups)
+	return rsp.GetVolumeGroups(), nil
+}
+
+func (c *workerConnection) AddTagLvm(ctx context.Context, volGroup string, volumeID string, tags []string) error {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.AddTagLVRequest{
+		VolumeGroup: volGroup,
+		Name:        volumeID,
+		Tags:        tags,
+	}
+	response, err := client.AddTagLV(ctx, &req)
+	if err != nil {
+		log.Errorf("Add tag to Lvm with error: %v", err.Error())
+		return err
+	}
+	log.Debugf("Add tag to Lvm with result: %v", response.GetCommandOutput())
+	return err
+}
+
 func (c *workerConnection) DeleteLvm(ctx context.Context, volGroup, volumeID string) error {
 	client := lib.NewLVMClient(c.conn)
 	req := lib.RemoveLVRequest{
@@ -245,6 +311,80 @@ func (c *workerConnection) ExpandLvm(ctx context.Context, volGroup string, volum
 	return err
 }
 
+func (c *workerConnection) SetProjQuota(ctx context.Context, quotaSubpath string, blockHardlimit, blockSoftlimit uint64) (string, error) {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.SetProjQuotaRequest{
+		QuotaSubpath:   quotaSubpath,
+		BlockHardlimit: blockHardlimit,
+		BlockSoftlimit: blockSoftlimit,
+	}
+	rsp, err := client.SetProjQuota(ctx, &req)
+	if err != nil {
+		log.Errorf("Set project quota with error: %v", err.Error())
+		return "", err
+	}
+	log.Debugf("Set project quota with result: %v", rsp.GetProjectId())
+	return rsp.GetProjectId(), nil
+}
+
+func (c *workerConnection) RemoveProjQuota(ctx context.Context, quotaSubpath string) error {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.RemoveProjQuotaRequest{
+		QuotaSubpath: quotaSubpath,
+	}
+	response, err := client.RemoveProjQuota(ctx, &req)
+	if err != nil {
+		log.Errorf("Remove project quota with error: %v", err.Error())
+		return err
+	}
+	log.Debugf("Remove project quota with result: %v", response.GetCommandOutput())
+	return err
+}
+
+func (c *workerConnection) ClaimStorage(ctx context.Context, owner string, volumeType string, storage string) (string, error) {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.ClaimStorageRequest{
+		Owner:      owner,
+		VolumeType: volumeType,
+		Storage:    storage,
+	}
+	rsp, err := client.ClaimStorage(ctx, &req)
+	if err != nil {
+		log.Errorf("Claim storage with error: %v", err.Error())
+		return "", err
+	}
+	log.Debugf("Claim storage with result: %v", rsp.GetStorage())
+	return rsp.GetStorage(), nil
+}
+
+func (c *workerConnection) GetClaimedStorage(ctx context.Context, owner string) (string, error) {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.GetClaimedStorageRequest{
+		Owner: owner,
+	}
+	rsp, err := client.GetClaimedStorage(ctx, &req)
+	if err != nil {
+		log.Errorf("Get claimed storage with error: %v", err.Error())
+		return "", err
+	}
+	log.Debugf("Get claimed storage with result: %v", rsp.GetStorage())
+	return rsp.GetStorage(), nil
+}
+
+func (c *workerConnection) ReleaseStorage(ctx context.Context, owner string) error {
+	client := lib.NewLVMClient(c.conn)
+	req := lib.ReleaseStorageRequest{
+		Owner: owner,
+	}
+	_, err := client.ReleaseStorage(ctx, &req)
+	if err != nil {
+		log.Errorf("Release storage with error: %v", err.Error())
+		return err
+	}
+	log.Debugf("Release storage of volume %s successfully", owner)
+	return err
+}
+
 func logGRPC(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 	log.Debugf("GRPC request: %s, %+v", method, req)
 	err := invoker(ctx, method, req, reply, cc, opts...)
@@ -1,13 +1,22 @@
 package client
 
 import (
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 )
 
+// ResponseError is returned by DoRequest when the status of the response is not 200
+type ResponseError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("Get Response StatusCode %d, Response: %s", e.StatusCode, e.Body)
+}
+
 // DoRequest Http Post Request
 func DoRequest(url string) ([]byte, error) {
 	req, err := http.NewRequest("POST", url, nil)
@@ -31,8 +40,7 @@ func DoRequest(url string) ([]byte, error) {
 	}
 
 	if resp.StatusCode != 200 {
-		msg := fmt.Sprintf("Get Response StatusCode %d, Response: %s", resp.StatusCode, body)
-		return nil, errors.New(msg)
+		return nil, &ResponseError{StatusCode: resp.StatusCode, Body: body}
 	}
 
 	return body, nil
@@ -0,0 +1,233 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
+	"github.com/alibaba/open-local/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultRequestCallTimeout is the timeout of a LvmdRequest call if the context has no deadline
+	DefaultRequestCallTimeout = 2 * time.Minute
+	requestPollInterval       = 500 * time.Millisecond
+
+	serviceAccountUserPrefix = "system:serviceaccount:"
+	podNameExtra             = "authentication.kubernetes.io/pod-name"
+	podUIDExtra              = "authentication.kubernetes.io/pod-uid"
+)
+
+// RequestOptions is how csi controller authenticates to the nodes and authenticates the nodes through LvmdRequests
+type RequestOptions struct {
+	// ServiceAccount is the namespace/name of the service account of csi controller,
+	// a token bound to the node and the nonce of every request is issued for it
+	ServiceAccount string
+	// NodeIdentities are the service account users of the node plugins allowed to serve the requests
+	NodeIdentities []string
+}
+
+// requestClientConn delegates lvmd calls to the node plugin through LvmdRequest objects,
+// so that lvmd needs no network port
+type requestClientConn struct {
+	client     clientset.Interface
+	kubeClient kubernetes.Interface
+	nodeName   string
+	options    RequestOptions
+}
+
+var (
+	_ grpc.ClientConnInterface = &requestClientConn{}
+)
+
+// NewRequestConnection returns a Connection to the lvmd of nodeName through LvmdRequest objects,
+// which are authenticated by the service account tokens issued by kubeClient
+func NewRequestConnection(client clientset.Interface, kubeClient kubernetes.Interface, nodeName string, options RequestOptions) Connection {
+	return &workerConnection{
+		conn: &requestClientConn{
+			client:     client,
+			kubeClient: kubeClient,
+			nodeName:   nodeName,
+			options:    options,
+		},
+	}
+}
+
+// Invoke creates the request and waits for the node plugin to write back the result
+func (c *requestClientConn) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultRequestCallTimeout)
+		defer cancel()
+	}
+	log.Debugf("LvmdRequest to node %s: %s, %+v", c.nodeName, method, args)
+
+	body, err := json.Marshal(args)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to marshal request of %s: %v", method, err)
+	}
+	nonce, err := newNonce()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to generate nonce of %s: %v", method, err)
+	}
+	// the token is only valid for this node and this request, so the nodes watching requests can not replay it
+	token, err := c.issueToken(ctx, localtype.LvmdRequestAudience(c.nodeName, nonce))
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "failed to issue token of %s to node %s: %v", method, c.nodeName, err)
+	}
+	request, err := c.client.CsiV1alpha1().LvmdRequests().Create(ctx, &v1alpha1.LvmdRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: c.nodeName + "-",
+			Labels:       map[string]string{localtype.LabelLvmdRequestNode: c.nodeName},
+		},
+		Spec: v1alpha1.LvmdRequestSpec{
+			NodeName: c.nodeName,
+			Method:   method,
+			Request:  string(body),
+			Nonce:    nonce,
+			Token:    token,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to create request %s to node %s: %v", method, c.nodeName, err)
+	}
+	defer func() {
+		if err := c.client.CsiV1alpha1().LvmdRequests().Delete(context.Background(), request.Name, metav1.DeleteOptions{}); err != nil {
+			log.Warningf("failed to remove request %s of node %s: %v", request.Name, c.nodeName, err)
+		}
+	}()
+
+	err = wait.PollImmediateUntil(requestPollInterval, func() (bool, error) {
+		got, err := c.client.CsiV1alpha1().LvmdRequests().Get(ctx, request.Name, metav1.GetOptions{})
+		if err != nil {
+			log.Warningf("failed to get request %s: %v", request.Name, err)
+			return false, nil
+		}
+		request = got
+		return request.Status.Completed, nil
+	}, ctx.Done())
+	if err != nil {
+		return status.Errorf(codes.DeadlineExceeded, "failed to wait for result of %s from node %s: %v", method, c.nodeName, err)
+	}
+	if err := c.verifyReply(ctx, request); err != nil {
+		log.Warningf("reject result of %s from node %s: %s", request.Name, c.nodeName, err.Error())
+		return err
+	}
+	if codes.Code(request.Status.Code) != codes.OK {
+		return status.Error(codes.Code(request.Status.Code), request.Status.Message)
+	}
+	if err := json.Unmarshal([]byte(request.Status.Reply), reply); err != nil {
+		return status.Errorf(codes.Internal, "failed to unmarshal reply of %s: %v", method, err)
+	}
+	log.Debugf("LvmdRequest reply from node %s: %+v", c.nodeName, reply)
+	return nil
+}
+
+// issueToken requests a token of the service account of csi controller for audience
+func (c *requestClientConn) issueToken(ctx context.Context, audience string) (string, error) {
+	namespace, name, err := splitServiceAccount(c.options.ServiceAccount)
+	if err != nil {
+		return "", err
+	}
+	expiration := int64(localtype.DefaultLvmdRequestTokenExpiration)
+	tr, err := c.kubeClient.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         []string{audience},
+			ExpirationSeconds: &expiration,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return tr.Status.Token, nil
+}
+
+// verifyReply checks that the result of request is written by the node plugin running on the node of the request:
+// the token in the status must be of a node identity and bound to a pod scheduled to the node
+func (c *requestClientConn) verifyReply(ctx context.Context, request *v1alpha1.LvmdRequest) error {
+	if request.Status.Token == "" {
+		return status.Errorf(codes.Unauthenticated, "no token in result of %s", request.Name)
+	}
+	review, err := c.kubeClient.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     request.Status.Token,
+			Audiences: []string{localtype.LvmdReplyAudience},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to review token of result of %s: %v", request.Name, err)
+	}
+	if !review.Status.Authenticated || !utils.ContainsString(review.Status.Audiences, localtype.LvmdReplyAudience) {
+		return status.Errorf(codes.Unauthenticated, "token of result of %s is not authenticated: %s", request.Name, review.Status.Error)
+	}
+	user := review.Status.User
+	if !utils.ContainsString(c.options.NodeIdentities, user.Username) {
+		return status.Errorf(codes.PermissionDenied, "result of %s is written by %s, which is not a node identity", request.Name, user.Username)
+	}
+	podName, podUID := user.Extra[podNameExtra], user.Extra[podUIDExtra]
+	if len(podName) != 1 || len(podUID) != 1 {
+		return status.Errorf(codes.Unauthenticated, "token of result of %s is not bound to a pod", request.Name)
+	}
+	namespace := strings.SplitN(strings.TrimPrefix(user.Username, serviceAccountUserPrefix), ":", 2)[0]
+	pod, err := c.kubeClient.CoreV1().Pods(namespace).Get(ctx, podName[0], metav1.GetOptions{})
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to get pod %s/%s writing result of %s: %v", namespace, podName[0], request.Name, err)
+	}
+	if string(pod.UID) != podUID[0] || pod.Spec.NodeName != c.nodeName {
+		return status.Errorf(codes.PermissionDenied, "result of %s is written by pod %s/%s, which is not on node %s", request.Name, namespace, podName[0], c.nodeName)
+	}
+	return nil
+}
+
+// splitServiceAccount splits namespace/name of a service account
+func splitServiceAccount(sa string) (string, string, error) {
+	parts := strings.Split(sa, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("service account %q is not in the form of namespace/name", sa)
+	}
+	return parts[0], parts[1], nil
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewStream is not supported as lvmd has no streaming method
+func (c *requestClientConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "streaming method %s is not supported through LvmdRequest", method)
+}
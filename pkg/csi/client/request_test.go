@@ -0,0 +1,84 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const agentUser = "system:serviceaccount:kube-system:open-local-agent"
+
+func TestVerifyReply(t *testing.T) {
+	newPod := func(name, uid, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", UID: types.UID(uid)},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(newPod("agent-1", "uid-1", "node-1"), newPod("agent-2", "uid-2", "node-2"))
+	// the tokens and the users and pods they are bound to
+	tokens := map[string]authenticationv1.UserInfo{
+		"agent-1-token": {Username: agentUser, Extra: map[string]authenticationv1.ExtraValue{podNameExtra: {"agent-1"}, podUIDExtra: {"uid-1"}}},
+		"agent-2-token": {Username: agentUser, Extra: map[string]authenticationv1.ExtraValue{podNameExtra: {"agent-2"}, podUIDExtra: {"uid-2"}}},
+		"stale-token":   {Username: agentUser, Extra: map[string]authenticationv1.ExtraValue{podNameExtra: {"agent-1"}, podUIDExtra: {"uid-0"}}},
+		"unbound-token": {Username: agentUser},
+		"other-token":   {Username: "system:serviceaccount:default:other", Extra: map[string]authenticationv1.ExtraValue{podNameExtra: {"agent-1"}, podUIDExtra: {"uid-1"}}},
+	}
+	kubeClient.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		if user, ok := tokens[review.Spec.Token]; ok && len(review.Spec.Audiences) == 1 && review.Spec.Audiences[0] == localtype.LvmdReplyAudience {
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: user, Audiences: review.Spec.Audiences}
+		}
+		return true, review, nil
+	})
+	conn := &requestClientConn{
+		kubeClient: kubeClient,
+		nodeName:   "node-1",
+		options:    RequestOptions{NodeIdentities: []string{agentUser}},
+	}
+
+	for token, code := range map[string]codes.Code{
+		"agent-1-token": codes.OK,
+		"agent-2-token": codes.PermissionDenied,
+		"stale-token":   codes.PermissionDenied,
+		"unbound-token": codes.Unauthenticated,
+		"other-token":   codes.PermissionDenied,
+		"forged-token":  codes.Unauthenticated,
+		"":              codes.Unauthenticated,
+	} {
+		request := &v1alpha1.LvmdRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "request"},
+			Status:     v1alpha1.LvmdRequestStatus{Completed: true, Token: token},
+		}
+		if got := status.Code(conn.verifyReply(context.Background(), request)); got != code {
+			t.Errorf("expect %s of reply token %q, got %s", code, token, got)
+		}
+	}
+}
@@ -17,19 +17,25 @@ limitations under the License.
 package csi
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"github.com/alibaba/open-local/pkg/backup"
 	"github.com/alibaba/open-local/pkg/csi/adapter"
 	"github.com/alibaba/open-local/pkg/csi/client"
 	"github.com/alibaba/open-local/pkg/csi/server"
+	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
+	"github.com/alibaba/open-local/pkg/utils"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	csilib "github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/docker/go-units"
@@ -44,7 +50,10 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	utiltrace "k8s.io/utils/trace"
 )
 
@@ -57,6 +66,8 @@ const (
 	MountPointType = "MountPoint"
 	// DeviceVolumeType type
 	DeviceVolumeType = "Device"
+	// QuotaVolumeType type
+	QuotaVolumeType = "Quota"
 	// PvcNameTag in annotations
 	PvcNameTag = "csi.storage.k8s.io/pvc/name"
 	// PvcNsTag in annotations
@@ -71,6 +82,8 @@ const (
 	CsiProvisionerTag = "volume.beta.kubernetes.io/storage-provisioner"
 	// StripingType striping type
 	StripingType = "striping"
+	// ThinType thin provisioning type
+	ThinType = localtype.LVMTypeThin
 	// connection timeout
 	DefaultConnectTimeout = 3
 
@@ -84,11 +97,18 @@ type controllerServer struct {
 	snapclient            snapshot.Interface
 	driverName            string
 	grpcConnectionTimeout time.Duration
+	volumeLocks           *VolumeLocks
+	lvmdTLS               *utils.TLSReloader
+	lvmdServerName        string
+	localclient           clientset.Interface
+	lvmdTransport         string
+	lvmdRequest           client.RequestOptions
+	recorder              record.EventRecorder
 }
 
-var supportVolumeTypes = []string{LvmVolumeType, MountPointType, DeviceVolumeType}
+var supportVolumeTypes = []string{LvmVolumeType, MountPointType, DeviceVolumeType, QuotaVolumeType}
 
-func newControllerServer(d *csicommon.CSIDriver, grpcConnectionTimeout int) *controllerServer {
+func newControllerServer(d *csicommon.CSIDriver, dName string, grpcConnectionTimeout int, lvmdOpt *LvmdOptions) *controllerServer {
 	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
 	if err != nil {
 		log.Fatalf("Error building kubeconfig: %s", err.Error())
@@ -102,17 +122,40 @@ func newControllerServer(d *csicommon.CSIDriver, grpcConnectionTimeout int) *con
 		log.Fatalf("Error building snapshot clientset: %s", err.Error())
 	}
 
+	localClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error building local clientset: %s", err.Error())
+	}
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: dName})
+
+	var lvmdTLS *utils.TLSReloader
+	if lvmdOpt.Transport == localtype.LvmdTransportCRD {
+		log.Infof("Connecting lvmd through LvmdRequests")
+	} else if lvmdOpt.ClientTLS.Enabled() {
+		lvmdTLS = utils.NewTLSReloader(lvmdOpt.ClientTLS)
+	} else {
+		log.Warningf("Connecting lvmd without tls")
+	}
+
 	return &controllerServer{
 		DefaultControllerServer: csicommon.NewDefaultControllerServer(d),
 		client:                  kubeClient,
 		snapclient:              snapClient,
+		driverName:              dName,
 		grpcConnectionTimeout:   time.Duration(grpcConnectionTimeout * int(time.Second)),
+		volumeLocks:             NewVolumeLocks(),
+		lvmdTLS:                 lvmdTLS,
+		lvmdServerName:          lvmdOpt.ServerName,
+		localclient:             localClient,
+		lvmdTransport:           lvmdOpt.Transport,
+		lvmdRequest:             lvmdOpt.Request,
+		recorder:                recorder,
 	}
 }
 
-// the map of req.Name and csi.Volume
-var createdVolumeMap = map[string]*csi.Volume{}
-
 // CreateVolume csi interface
 func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.CreateVolumeRequest) (*csilib.CreateVolumeResponse, error) {
 	volumeID := req.GetName()
@@ -131,10 +174,13 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 		log.Errorf("CreateVolume: local Volume Capabilities cannot be empty")
 		return nil, status.Error(codes.InvalidArgument, "Volume Capabilities cannot be empty")
 	}
-	if value, ok := createdVolumeMap[req.Name]; ok {
-		log.Infof("CreateVolume: local volume already be created, pvName: %s, VolumeId: %s", req.Name, value.VolumeId)
-		return &csi.CreateVolumeResponse{Volume: value}, nil
+	// serialize the retries of the same volume, the volume created by a previous attempt
+	// is recovered from the node in Step 3
+	if acquired := cs.volumeLocks.TryAcquire(req.Name); !acquired {
+		log.Errorf("CreateVolume: an operation with the given volume %s already exists", req.Name)
+		return nil, status.Errorf(codes.Aborted, "an operation with the given volume %s already exists", req.Name)
 	}
+	defer cs.volumeLocks.Release(req.Name)
 	trace.Step("Step 1: Validate Request done")
 	// Step 2: get necessary info
 	pvcName, pvcNameSpace, volumeType, nodeSelected, storageSelected := "", "", "", "", ""
@@ -149,7 +195,7 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 	}
 	if volumeType == "" {
 		log.Errorf("CreateVolume: Create volume %s with error volumeType %v", volumeID, parameters)
-		return nil, status.Errorf(codes.InvalidArgument, "Local driver only support LVM/MountPoint/Device volume type, no type %s", volumeType)
+		return nil, status.Errorf(codes.InvalidArgument, "Local driver only support LVM/MountPoint/Device/Quota volume type, no type %s", volumeType)
 	}
 	if value, ok := parameters[PvcNameTag]; ok {
 		pvcName = value
@@ -166,12 +212,22 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 
 	// Step 3: Storage schedule
 	isSnapshot := false
+	cloneSource, restoreSnapshot, restoreBackup := "", "", ""
 	paraList := map[string]string{}
 	switch volumeType {
 	case LvmVolumeType:
 		var err error
-		// check volume content source is snapshot
-		if volumeSource := req.GetVolumeContentSource(); volumeSource != nil {
+		sourceVG := ""
+		// check volume content source is volume or snapshot
+		if volumeSource := req.GetVolumeContentSource(); volumeSource != nil && volumeSource.GetVolume() != nil {
+			srcVolumeID := volumeSource.GetVolume().GetVolumeId()
+			log.Infof("CreateVolume: volume %s is cloned from volume %s", volumeID, srcVolumeID)
+			if nodeSelected, sourceVG, err = cs.getSourceVolume(srcVolumeID, nodeSelected, req.GetCapacityRange().GetRequiredBytes()); err != nil {
+				log.Errorf("CreateVolume: check clone source %s of volume %s failed: %s", srcVolumeID, volumeID, err.Error())
+				return nil, err
+			}
+			cloneSource = srcVolumeID
+		} else if volumeSource != nil {
 			// validate
 			if _, ok := volumeSource.GetType().(*csi.VolumeContentSource_Snapshot); !ok {
 				log.Errorf("CreateVolume: unsupported volumeContentSource type")
@@ -200,30 +256,46 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 				log.Errorf("get snapshot class failed: %s", err.Error())
 				return nil, status.Errorf(codes.InvalidArgument, "get snapshot class failed: %s", err.Error())
 			}
-			ro, exist := class.Parameters[localtype.ParamSnapshotReadonly]
-			if !exist || ro != "true" {
-				log.Errorf("CreateVolume: only support readonly snapshot now, you must set %s parameter in volumesnapshotclass", localtype.ParamSnapshotReadonly)
-				return nil, status.Errorf(codes.Unimplemented, "CreateVolume: only support readonly snapshot now, you must set %s parameter in volumesnapshotclass", localtype.ParamSnapshotReadonly)
+			if ro, exist := class.Parameters[localtype.ParamSnapshotReadonly]; exist && ro == "true" {
+				// get node name and vg name from src volume
+				nodeSelected, storageSelected, _, err := getPvSpec(cs.client, srcVolumeID, cs.driverName)
+				if err != nil {
+					log.Errorf("CreateVolume: get pv spec failed: %s", err.Error())
+					return nil, status.Errorf(codes.Internal, "CreateVolume: get pv spec failed: %s", err.Error())
+				}
+				// set paraList for NodeStageVolume and NodePublishVolume
+				parameters[NodeSchedueTag] = nodeSelected
+				paraList[VgNameTag] = storageSelected
+				paraList[localtype.ParamSnapshotName] = snapshotID
+				paraList[localtype.ParamSnapshotReadonly] = "true"
+				isSnapshot = true
+				log.Infof("CreateVolume: get snapshot volume %s info: node(%s) vg(%s)", volumeID, nodeSelected, storageSelected)
+				// break switch
+				break
+			}
+			// restore the snapshot into an independent writable lv, which is allocated as a normal lvm volume
+			log.Infof("CreateVolume: volume %s is restored from snapshot %s", volumeID, snapshotID)
+			if nodeSelected, sourceVG, err = cs.getSourceVolume(srcVolumeID, nodeSelected, req.GetCapacityRange().GetRequiredBytes()); err != nil {
+				log.Errorf("CreateVolume: check source volume %s of snapshot %s failed: %s", srcVolumeID, snapshotID, err.Error())
+				return nil, err
 			}
-			// get node name and vg name from src volume
-			nodeSelected, storageSelected, _, err := getPvSpec(cs.client, srcVolumeID, cs.driverName)
-			if err != nil {
-				log.Errorf("CreateVolume: get pv spec failed: %s", err.Error())
-				return nil, status.Errorf(codes.Internal, "CreateVolume: get pv spec failed: %s", err.Error())
-			}
-			// set paraList for NodeStageVolume and NodePublishVolume
-			parameters[NodeSchedueTag] = nodeSelected
-			paraList[VgNameTag] = storageSelected
-			paraList[localtype.ParamSnapshotName] = snapshotID
-			paraList[localtype.ParamSnapshotReadonly] = "true"
-			isSnapshot = true
-			log.Infof("CreateVolume: get snapshot volume %s info: node(%s) vg(%s)", volumeID, nodeSelected, storageSelected)
-			// break switch
-			break
+			restoreSnapshot = snapshotID
+		} else if restoreBackup, err = cs.getRestoreBackup(ctx, pvcName, pvcNameSpace, parameters, req.GetCapacityRange().GetRequiredBytes()); err != nil {
+			log.Errorf("CreateVolume: check backup to restore volume %s from failed: %s", volumeID, err.Error())
+			return nil, err
 		}
 
-		// Node and Storage have been scheduled (select volumeGroup)
-		if storageSelected != "" && nodeSelected != "" {
+		// the lv may have been created by a previous attempt
+		createdVG, err := cs.getCreatedStorage(ctx, volumeType, volumeID, nodeSelected)
+		if err != nil {
+			log.Errorf("CreateVolume: get created lvm %s at node %s with error: %s", volumeID, nodeSelected, err.Error())
+			return nil, status.Errorf(codes.Internal, "Get created lvm error: %s", err.Error())
+		}
+		if createdVG != "" {
+			paraList[VgNameTag] = createdVG
+			log.Infof("CreateVolume: lvm volume %s is already created in %s at node %s", volumeID, createdVG, nodeSelected)
+		} else if storageSelected != "" && nodeSelected != "" {
+			// Node and Storage have been scheduled (select volumeGroup)
 			paraList, err = lvmScheduled(storageSelected, parameters)
 			if err != nil {
 				log.Errorf("CreateVolume: lvm all scheduled volume %s with error: %s", volumeID, err.Error())
@@ -270,6 +342,21 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 		if value, ok := paraList[VgNameTag]; ok && value != "" {
 			storageSelected = value
 		}
+		// the node copies the data of the source volume or snapshot when publishing the volume
+		if cloneSource != "" {
+			paraList[localtype.ParamSourceVolumeID] = cloneSource
+			paraList[localtype.ParamSourceVGName] = sourceVG
+		} else if restoreSnapshot != "" {
+			paraList[localtype.ParamSourceSnapshotID] = restoreSnapshot
+			paraList[localtype.ParamSourceVGName] = sourceVG
+		} else if restoreBackup != "" {
+			paraList[localtype.ParamRestoreBackupName] = restoreBackup
+			for _, key := range []string{localtype.ParamBackupTarget, localtype.ParamBackupSecretName, localtype.ParamBackupSecretNamespace} {
+				if value, exist := parameters[key]; exist {
+					paraList[key] = value
+				}
+			}
+		}
 
 		// Volume Options
 		options := &client.LVMOptions{}
@@ -277,8 +364,14 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 		options.VolumeGroup = storageSelected
 		if value, ok := parameters[LvmTypeTag]; ok && value == StripingType {
 			options.Striping = true
+		} else if ok && value == ThinType {
+			options.Thin = true
+			if options.ThinPoolPercent, err = utils.GetThinPoolPercent(parameters); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
 		}
 		options.Size = uint64(req.GetCapacityRange().GetRequiredBytes())
+		options.Tags = []string{localtype.VolumeOwnerTagPrefix + volumeID}
 
 		if nodeSelected != "" && storageSelected != "" {
 			conn, err := cs.getNodeConn(nodeSelected)
@@ -303,8 +396,17 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 		}
 	case MountPointType:
 		var err error
-		// Node and Storage have been scheduled
-		if storageSelected != "" && nodeSelected != "" {
+		// the mountpoint may have been claimed by a previous attempt
+		createdMP, err := cs.getCreatedStorage(ctx, volumeType, volumeID, nodeSelected)
+		if err != nil {
+			log.Errorf("CreateVolume: get created mountpoint volume %s at node %s error: %s", req.Name, nodeSelected, err.Error())
+			return nil, status.Errorf(codes.Internal, "Get created mountpoint error: %s", err.Error())
+		}
+		if createdMP != "" {
+			paraList[MountPointType] = createdMP
+			log.Infof("CreateVolume: mountpoint volume %s is already created with %s at node %s", req.Name, createdMP, nodeSelected)
+		} else if storageSelected != "" && nodeSelected != "" {
+			// Node and Storage have been scheduled
 			paraList, err = mountpointScheduled(storageSelected, parameters)
 			if err != nil {
 				log.Errorf("CreateVolume: create mountpoint volume %s/%s at node %s error: %s", storageSelected, req.Name, nodeSelected, err.Error())
@@ -337,11 +439,26 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 			}
 			nodeSelected = nodeID
 		}
+		if nodeSelected != "" {
+			if err := cs.claimStorage(ctx, nodeSelected, volumeType, volumeID, paraList[MountPointType]); err != nil {
+				log.Errorf("CreateVolume: claim mountpoint %s for volume %s at node %s error: %s", paraList[MountPointType], req.Name, nodeSelected, err.Error())
+				return nil, status.Errorf(codes.Internal, "Claim mountpoint error: %s", err.Error())
+			}
+		}
 		log.Infof("CreateVolume: Successful create mountpoint volume %s/%s at node %s", storageSelected, req.Name, nodeSelected)
 	case DeviceVolumeType:
 		var err error
-		// Node and Storage have been scheduled
-		if storageSelected != "" && nodeSelected != "" {
+		// the device may have been claimed by a previous attempt
+		createdDevice, err := cs.getCreatedStorage(ctx, volumeType, volumeID, nodeSelected)
+		if err != nil {
+			log.Errorf("CreateVolume: get created device volume %s at node %s error: %s", req.Name, nodeSelected, err.Error())
+			return nil, status.Errorf(codes.Internal, "Get created device error: %s", err.Error())
+		}
+		if createdDevice != "" {
+			paraList[DeviceVolumeType] = createdDevice
+			log.Infof("CreateVolume: device volume %s is already created with %s at node %s", req.Name, createdDevice, nodeSelected)
+		} else if storageSelected != "" && nodeSelected != "" {
+			// Node and Storage have been scheduled
 			paraList, err = deviceScheduled(storageSelected, parameters)
 			if err != nil {
 				log.Errorf("CreateVolume: create device volume %s/%s at node %s error: %s", storageSelected, req.Name, nodeSelected, err.Error())
@@ -374,7 +491,102 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 			}
 			nodeSelected = nodeID
 		}
+		if nodeSelected != "" {
+			// bind the volume to the by-id link of the device, which survives the reordering of device names
+			storage := paraList[DeviceVolumeType]
+			info, err := cs.getDeviceInfo(ctx, nodeSelected, storage)
+			if err != nil {
+				log.Errorf("CreateVolume: get device %s of volume %s at node %s error: %s", storage, req.Name, nodeSelected, err.Error())
+				return nil, status.Errorf(codes.Internal, "Get device error: %s", err.Error())
+			}
+			if info != nil {
+				paraList[DeviceVolumeType] = info.Name
+				if info.ByID != "" {
+					paraList[localtype.DeviceID] = info.ByID
+					storage = info.ByID
+				}
+			}
+			if err := cs.claimStorage(ctx, nodeSelected, volumeType, volumeID, storage); err != nil {
+				log.Errorf("CreateVolume: claim device %s for volume %s at node %s error: %s", storage, req.Name, nodeSelected, err.Error())
+				// the device is owned by another volume
+				if status.Code(err) == codes.FailedPrecondition {
+					return nil, err
+				}
+				return nil, status.Errorf(codes.Internal, "Claim device error: %s", err.Error())
+			}
+		}
 		log.Infof("CreateVolume: Successful create device volume %s/%s at node %s", storageSelected, req.Name, nodeSelected)
+	case QuotaVolumeType:
+		var err error
+		// the quota mountpoint may have been claimed by a previous attempt
+		createdQuota, err := cs.getCreatedStorage(ctx, volumeType, volumeID, nodeSelected)
+		if err != nil {
+			log.Errorf("CreateVolume: get created quota volume %s at node %s error: %s", req.Name, nodeSelected, err.Error())
+			return nil, status.Errorf(codes.Internal, "Get created quota error: %s", err.Error())
+		}
+		if createdQuota != "" {
+			paraList[QuotaVolumeType] = createdQuota
+			log.Infof("CreateVolume: quota volume %s is already created in %s at node %s", req.Name, createdQuota, nodeSelected)
+		} else if storageSelected != "" && nodeSelected != "" {
+			// Node and Storage have been scheduled
+			paraList, err = quotaScheduled(storageSelected, parameters)
+			if err != nil {
+				log.Errorf("CreateVolume: create quota volume %s/%s at node %s error: %s", storageSelected, req.Name, nodeSelected, err.Error())
+				code := codes.Internal
+				if strings.Contains(err.Error(), "Insufficient") {
+					code = codes.ResourceExhausted
+				}
+				return nil, status.Errorf(code, "Parse quota all scheduled info error: %s", err.Error())
+			}
+		} else if nodeSelected != "" {
+			paraList, err = quotaPartScheduled(nodeSelected, pvcName, pvcNameSpace, parameters)
+			if err != nil {
+				log.Errorf("CreateVolume: part schedule quota volume %s at node %s error: %s", req.Name, nodeSelected, err.Error())
+				code := codes.Internal
+				if strings.Contains(err.Error(), "Insufficient") {
+					code = codes.ResourceExhausted
+				}
+				return nil, status.Errorf(code, "Parse quota part schedule info error: %s", err.Error())
+			}
+		} else {
+			nodeID := ""
+			nodeID, paraList, err = quotaNoScheduled(parameters)
+			if err != nil {
+				log.Errorf("CreateVolume: schedule quota volume %s error: %s", req.Name, err.Error())
+				code := codes.Internal
+				if strings.Contains(err.Error(), "Insufficient") {
+					code = codes.ResourceExhausted
+				}
+				return nil, status.Errorf(code, "Parse quota schedule info error: %s", err.Error())
+			}
+			nodeSelected = nodeID
+		}
+		if value, ok := paraList[QuotaVolumeType]; ok && value != "" {
+			storageSelected = value
+		}
+
+		// carve a project quota subdirectory out of the mount point
+		if nodeSelected != "" && storageSelected != "" {
+			if err := cs.claimStorage(ctx, nodeSelected, volumeType, volumeID, storageSelected); err != nil {
+				log.Errorf("CreateVolume: claim quota mountpoint %s for volume %s at node %s error: %s", storageSelected, req.Name, nodeSelected, err.Error())
+				return nil, status.Errorf(codes.Internal, "Claim quota mountpoint error: %s", err.Error())
+			}
+			conn, err := cs.getNodeConn(nodeSelected)
+			if err != nil {
+				log.Errorf("CreateVolume: New quota %s Connection to node %s with error: %s", req.Name, nodeSelected, err.Error())
+				return nil, err
+			}
+			defer conn.Close()
+			quotaSubpath := filepath.Join(storageSelected, volumeID)
+			blockLimit := getQuotaBlockLimit(req.GetCapacityRange().GetRequiredBytes())
+			projectID, err := conn.SetProjQuota(ctx, quotaSubpath, blockLimit, blockLimit)
+			if err != nil {
+				log.Errorf("CreateVolume: Set project quota to %s at node %s with error: %s", quotaSubpath, nodeSelected, err.Error())
+				return nil, errors.New("Set project quota with error " + err.Error())
+			}
+			log.Infof("CreateVolume: Successful set project quota %s(project id %s) in node %s", quotaSubpath, projectID, nodeSelected)
+		}
+		log.Infof("CreateVolume: Successful create quota volume %s/%s at node %s", storageSelected, req.Name, nodeSelected)
 	default:
 		log.Errorf("CreateVolume: Create with no support volume type %s", volumeType)
 		return nil, status.Error(codes.InvalidArgument, "Create with no support type "+volumeType)
@@ -423,6 +635,15 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 	}
 
 	// add volume content source info if needed
+	if cloneSource != "" {
+		response.Volume.ContentSource = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Volume{
+				Volume: &csi.VolumeContentSource_VolumeSource{
+					VolumeId: cloneSource,
+				},
+			},
+		}
+	}
 	if isSnapshot {
 		response.Volume.ContentSource = &csi.VolumeContentSource{
 			Type: &csi.VolumeContentSource_Snapshot{
@@ -431,9 +652,16 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 				},
 			},
 		}
+	} else if restoreSnapshot != "" {
+		response.Volume.ContentSource = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Snapshot{
+				Snapshot: &csi.VolumeContentSource_SnapshotSource{
+					SnapshotId: restoreSnapshot,
+				},
+			},
+		}
 	}
 
-	createdVolumeMap[req.Name] = response.Volume
 	log.Infof("Success create Volume: %s, Size: %d", volumeID, req.GetCapacityRange().GetRequiredBytes())
 	trace.Step(fmt.Sprintf("Step 4: create volume %s done", volumeID))
 	return response, nil
@@ -442,6 +670,11 @@ func (cs *controllerServer) CreateVolume(ctx context.Context, req *csilib.Create
 func (server *controllerServer) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
 	log.Infof("DeleteVolume: deleting local volume %s", req.GetVolumeId())
 	volumeID := req.GetVolumeId()
+	if acquired := server.volumeLocks.TryAcquire(volumeID); !acquired {
+		log.Errorf("DeleteVolume: an operation with the given volume %s already exists", volumeID)
+		return nil, status.Errorf(codes.Aborted, "an operation with the given volume %s already exists", volumeID)
+	}
+	defer server.volumeLocks.Release(volumeID)
 	nodeName, vgName, pvObj, err := getPvSpec(server.client, volumeID, server.driverName)
 	if err != nil {
 		log.Errorf("DeleteVolume: get pv spec %s with error: %s", volumeID, err.Error())
@@ -547,6 +780,10 @@ func (server *controllerServer) DeleteVolume(ctx context.Context, req *csi.Delet
 				log.Errorf("DeleteVolume: Remove mountpoint for %s with error: %s", req.GetVolumeId(), err.Error())
 				return nil, errors.New("DeleteVolume: Delete mountpoint Failed: " + err.Error())
 			}
+			if err := conn.ReleaseStorage(ctx, volumeID); err != nil {
+				log.Errorf("DeleteVolume: Release mountpoint for %s with error: %s", req.GetVolumeId(), err.Error())
+				return nil, errors.New("DeleteVolume: Release mountpoint Failed: " + err.Error())
+			}
 		}
 		log.Infof("DeleteVolume: successful delete MountPoint volume(%s)", volumeID)
 	case DeviceVolumeType:
@@ -588,17 +825,50 @@ func (server *controllerServer) DeleteVolume(ctx context.Context, req *csi.Delet
 				log.Errorf("DeleteVolume: Get Device Path for volume %s, with empty", volumeID)
 				return nil, errors.New("Device Path is empty")
 			}
+			// never wipe another disk which takes the name of the device
+			if value, ok := pvObj.Spec.CSI.VolumeAttributes[localtype.DeviceID]; ok && value != "" {
+				device = value
+			}
 			if err := conn.CleanDevice(ctx, device); err != nil {
 				log.Errorf("DeleteVolume: Remove device for %s with error: %s", req.GetVolumeId(), err.Error())
 				return nil, errors.New("DeleteVolume: Delete device Failed: " + err.Error())
 			}
+			if err := conn.ReleaseStorage(ctx, volumeID); err != nil {
+				log.Errorf("DeleteVolume: Release device for %s with error: %s", req.GetVolumeId(), err.Error())
+				return nil, errors.New("DeleteVolume: Release device Failed: " + err.Error())
+			}
 		}
 		log.Infof("DeleteVolume: successful delete Device volume(%s)", volumeID)
+	case QuotaVolumeType:
+		if pvObj.Spec.PersistentVolumeReclaimPolicy == v1.PersistentVolumeReclaimDelete {
+			mountpoint := ""
+			if value, ok := pvObj.Spec.CSI.VolumeAttributes[QuotaVolumeType]; ok {
+				mountpoint = value
+			}
+			if mountpoint == "" {
+				log.Errorf("DeleteVolume: Get Quota MountPoint for volume %s, with empty", volumeID)
+				return nil, errors.New("Quota MountPoint is empty")
+			}
+			conn, err := server.getNodeConn(nodeName)
+			if err != nil {
+				log.Errorf("DeleteVolume: New quota %s Connection error: %s", req.GetVolumeId(), err.Error())
+				return nil, err
+			}
+			defer conn.Close()
+			if err := conn.RemoveProjQuota(ctx, filepath.Join(mountpoint, volumeID)); err != nil {
+				log.Errorf("DeleteVolume: Remove project quota for %s with error: %s", req.GetVolumeId(), err.Error())
+				return nil, errors.New("DeleteVolume: Delete quota Failed: " + err.Error())
+			}
+			if err := conn.ReleaseStorage(ctx, volumeID); err != nil {
+				log.Errorf("DeleteVolume: Release quota mountpoint for %s with error: %s", req.GetVolumeId(), err.Error())
+				return nil, errors.New("DeleteVolume: Release quota mountpoint Failed: " + err.Error())
+			}
+		}
+		log.Infof("DeleteVolume: successful delete Quota volume(%s)", volumeID)
 	default:
 		log.Errorf("DeleteVolume: volumeType %s not supported %s", volumeType, volumeID)
 		return nil, status.Errorf(codes.InvalidArgument, "Local driver only support LVM volume type, no type %s", volumeType)
 	}
-	delete(createdVolumeMap, req.VolumeId)
 	log.Infof("DeleteVolume: successful delete local volume %s", volumeID)
 	return &csi.DeleteVolumeResponse{}, nil
 }
@@ -701,7 +971,13 @@ func (cs *controllerServer) DeleteSnapshot(ctx context.Context, req *csi.DeleteS
 	}
 	defer conn.Close()
 
-	// Step 5: delete lvm snapshot
+	// Step 5: check no volume is being restored from the snapshot
+	if err := cs.checkSnapshotCopying(ctx, conn, snapshotName); err != nil {
+		log.Errorf("DeleteSnapshot: %s", err.Error())
+		return nil, err
+	}
+
+	// Step 6: delete lvm snapshot
 	var lvmName string
 	if lvmName, err = conn.GetLvm(ctx, vgName, snapshotName); err != nil {
 		log.Errorf("DeleteSnapshot: get lvm snapshot %s failed: %s", snapshotName, err.Error())
@@ -723,12 +999,43 @@ func (cs *controllerServer) DeleteSnapshot(ctx context.Context, req *csi.DeleteS
 	return &csi.DeleteSnapshotResponse{}, nil
 }
 
+// checkSnapshotCopying returns FailedPrecondition while the data of the snapshot is being copied to any volume restored from it,
+// volumes whose copy has not started will fail to be restored once the snapshot is deleted
+func (cs *controllerServer) checkSnapshotCopying(ctx context.Context, conn client.Connection, snapshotName string) error {
+	pvs, err := cs.client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return status.Errorf(codes.Internal, "list pvs error: %s", err.Error())
+	}
+	for _, pv := range pvs.Items {
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != cs.driverName || pv.Spec.CSI.VolumeAttributes[localtype.ParamSourceSnapshotID] != snapshotName {
+			continue
+		}
+		vgName := pv.Spec.CSI.VolumeAttributes[VgNameTag]
+		lvs, err := conn.ListLvm(ctx, fmt.Sprintf("%s/%s", vgName, pv.Spec.CSI.VolumeHandle))
+		if err != nil {
+			return status.Errorf(codes.Internal, "list lvm of volume %s restored from snapshot %s error: %s", pv.Name, snapshotName, err.Error())
+		}
+		if len(lvs) == 0 {
+			continue
+		}
+		if utils.ContainsString(lvs[0].Tags, localtype.CopyingLVTag) {
+			return status.Errorf(codes.FailedPrecondition, "volume %s is being restored from snapshot %s", pv.Name, snapshotName)
+		}
+	}
+	return nil
+}
+
 // ControllerExpandVolume expand volume
 func (cs *controllerServer) ControllerExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
 	log.Infof("ControllerExpandVolume: Starting Expand Volume %s with response: %v", req.VolumeId, req)
 
 	// Step 1: get nodeName
 	volumeID := req.GetVolumeId()
+	if acquired := cs.volumeLocks.TryAcquire(volumeID); !acquired {
+		log.Errorf("ControllerExpandVolume: an operation with the given volume %s already exists", volumeID)
+		return nil, status.Errorf(codes.Aborted, "an operation with the given volume %s already exists", volumeID)
+	}
+	defer cs.volumeLocks.Release(volumeID)
 	nodeName, vgName, pvObj, err := getPvSpec(cs.client, volumeID, cs.driverName)
 	if err != nil {
 		log.Errorf("ControllerExpandVolume: get pv %s error: %s", volumeID, err.Error())
@@ -748,8 +1055,22 @@ func (cs *controllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 	}
 	if err := adapter.ExpandVolume(pvcNameSpace, pvcName, volSizeGB); err != nil {
 		log.Errorf("ControllerExpandVolume: expand volume %s to size %d meet error: %v", volumeID, volSizeGB, err)
+		updateResizeCondition(cs.client, pvObj, localtype.ReasonControllerExpandFailed, err)
+		var rejected *adapter.ExpansionRejectedError
+		if errors.As(err, &rejected) {
+			if claim := pvObj.Spec.ClaimRef; claim != nil {
+				cs.recorder.Eventf(claim, v1.EventTypeWarning, localtype.EventExpansionRejected, "expansion to %d bytes is rejected: %s", volSizeBytes, rejected.Reason)
+			}
+			return nil, status.Errorf(codes.OutOfRange, "ControllerExpandVolume: expansion of volume %s is rejected: %s", volumeID, rejected.Reason)
+		}
 		return nil, errors.New("ControllerExpandVolume: expand volume error " + err.Error())
 	}
+	// devices and mount points are allocated as a whole, the scheduler has checked they have room for the new size
+	if attributes[VolumeTypeKey] == DeviceVolumeType || attributes[VolumeTypeKey] == MountPointType {
+		updateResizeCondition(cs.client, pvObj, "", nil)
+		log.Infof("ControllerExpandVolume: Successful expand %s volume %s in node %s", attributes[VolumeTypeKey], volumeID, nodeName)
+		return &csi.ControllerExpandVolumeResponse{CapacityBytes: volSizeBytes, NodeExpansionRequired: false}, nil
+	}
 
 	// Step 3: get grpc client
 	conn, err := cs.getNodeConn(nodeName)
@@ -760,11 +1081,26 @@ func (cs *controllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 	defer conn.Close()
 
 	// Step 4: expand volume
+	if attributes[VolumeTypeKey] == QuotaVolumeType {
+		quotaSubpath := filepath.Join(attributes[QuotaVolumeType], volumeID)
+		blockLimit := getQuotaBlockLimit(volSizeBytes)
+		if _, err := conn.SetProjQuota(ctx, quotaSubpath, blockLimit, blockLimit); err != nil {
+			log.Errorf("ControllerExpandVolume: set project quota to %s with error: %s", quotaSubpath, err.Error())
+			updateResizeCondition(cs.client, pvObj, localtype.ReasonControllerExpandFailed, err)
+			return nil, errors.New("Set project quota with error " + err.Error())
+		}
+		// project quota takes effect immediately, no node expansion is needed
+		updateResizeCondition(cs.client, pvObj, "", nil)
+		log.Infof("ControllerExpandVolume: Successful expand quota %s in node %s", quotaSubpath, nodeName)
+		return &csi.ControllerExpandVolumeResponse{CapacityBytes: volSizeBytes, NodeExpansionRequired: false}, nil
+	}
 	if err := conn.ExpandLvm(ctx, vgName, volumeID, uint64(volSizeBytes)); err != nil {
 		log.Errorf("ControllerExpandVolume: expand lvm %s/%s with error: %s", vgName, volumeID, err.Error())
-		return nil, errors.New("Create Lvm with error " + err.Error())
+		updateResizeCondition(cs.client, pvObj, localtype.ReasonControllerExpandFailed, err)
+		return nil, errors.New("Expand Lvm with error " + err.Error())
 	}
 
+	updateResizeCondition(cs.client, pvObj, "", nil)
 	log.Infof("ControllerExpandVolume: Successful expand lvm %s/%s in node %s", vgName, volumeID, nodeName)
 	return &csi.ControllerExpandVolumeResponse{CapacityBytes: volSizeBytes, NodeExpansionRequired: true}, nil
 }
@@ -804,16 +1140,160 @@ func (cs *controllerServer) newCreateSnapshotResponse(req *csi.CreateSnapshotReq
 	}, nil
 }
 
+// getSourceVolume returns the node and vg of the source lvm volume whose data is copied to a volume of size requested,
+// the volume must be at the node of the source volume
+func (cs *controllerServer) getSourceVolume(srcVolumeID, nodeSelected string, requested int64) (string, string, error) {
+	srcNode, srcVG, srcPV, err := getPvSpec(cs.client, srcVolumeID, cs.driverName)
+	if err != nil {
+		if srcPV == nil {
+			return "", "", status.Errorf(codes.NotFound, "source volume %s not found: %s", srcVolumeID, err.Error())
+		}
+		return "", "", status.Errorf(codes.Internal, "get spec of source volume %s failed: %s", srcVolumeID, err.Error())
+	}
+	if srcPV.Spec.CSI.VolumeAttributes[VolumeTypeKey] != LvmVolumeType {
+		return "", "", status.Errorf(codes.InvalidArgument, "source volume %s is not %s volume", srcVolumeID, LvmVolumeType)
+	}
+	if _, isSnapshot := srcPV.Spec.CSI.VolumeAttributes[localtype.ParamSnapshotName]; isSnapshot {
+		return "", "", status.Errorf(codes.InvalidArgument, "source volume %s is a readonly snapshot", srcVolumeID)
+	}
+	if srcVG == "" {
+		return "", "", status.Errorf(codes.Internal, "source volume %s with vgName empty", srcVolumeID)
+	}
+	srcSize := srcPV.Spec.Capacity[v1.ResourceStorage]
+	if requested < srcSize.Value() {
+		return "", "", status.Errorf(codes.OutOfRange, "requested size %d is smaller than size %d of source volume %s", requested, srcSize.Value(), srcVolumeID)
+	}
+	if nodeSelected != "" && nodeSelected != srcNode {
+		return "", "", status.Errorf(codes.InvalidArgument, "volume must be at node %s of source volume %s, not %s", srcNode, srcVolumeID, nodeSelected)
+	}
+	return srcNode, srcVG, nil
+}
+
+// getRestoreBackup returns the backup named in the annotation of the pvc to restore the volume from, or empty if not set.
+// The backup target is configured in the storage class, the backup must be owned by a pvc of the same namespace
+// and fit in the requested size.
+func (cs *controllerServer) getRestoreBackup(ctx context.Context, pvcName, pvcNameSpace string, parameters map[string]string, requested int64) (string, error) {
+	pvc, err := cs.client.CoreV1().PersistentVolumeClaims(pvcNameSpace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "get pvc %s/%s failed: %s", pvcNameSpace, pvcName, err.Error())
+	}
+	backupName := pvc.Annotations[localtype.AnnotationRestoreFromBackup]
+	if backupName == "" {
+		return "", nil
+	}
+	target, err := backup.NewTargetFromParameters(cs.client, parameters)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid backup target of storage class: %s", err.Error())
+	}
+	manifest, err := backup.GetManifest(ctx, target, backupName)
+	if err == backup.ErrNotFound {
+		return "", status.Errorf(codes.NotFound, "backup %s not found", backupName)
+	} else if err != nil {
+		return "", status.Errorf(codes.Unavailable, "get backup %s failed: %s", backupName, err.Error())
+	}
+	if err := manifest.CheckOwner(pvcNameSpace); err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "pvc %s/%s is not allowed to restore: %s", pvcNameSpace, pvcName, err.Error())
+	}
+	if requested < manifest.Size {
+		return "", status.Errorf(codes.OutOfRange, "requested size %d is smaller than size %d of backup %s", requested, manifest.Size, backupName)
+	}
+	return backupName, nil
+}
+
 func (cs *controllerServer) getNodeConn(nodeSelected string) (client.Connection, error) {
+	if cs.lvmdTransport == localtype.LvmdTransportCRD {
+		return client.NewRequestConnection(cs.localclient, cs.client, nodeSelected, cs.lvmdRequest), nil
+	}
 	addr, err := getNodeAddr(cs.client, nodeSelected)
 	if err != nil {
 		log.Errorf("CreateVolume: Get node %s address with error: %s", nodeSelected, err.Error())
 		return nil, err
 	}
-	conn, err := client.NewGrpcConnection(addr, cs.grpcConnectionTimeout)
+	var tlsConfig *tls.Config
+	if cs.lvmdTLS != nil {
+		// certificates are reloaded once rotated
+		if tlsConfig, err = cs.lvmdTLS.ClientConfig(cs.lvmdServerName); err != nil {
+			log.Errorf("CreateVolume: Load lvmd tls config with error: %s", err.Error())
+			return nil, err
+		}
+	}
+	conn, err := client.NewGrpcConnection(addr, cs.grpcConnectionTimeout, tlsConfig)
 	return conn, err
 }
 
+// getCreatedStorage returns the storage allocated to the volume at the node by a previous CreateVolume,
+// lvm volumes are found by the owner tag, others by the claim records persisted on the node
+func (cs *controllerServer) getCreatedStorage(ctx context.Context, volumeType, volumeID, nodeSelected string) (string, error) {
+	if nodeSelected == "" {
+		return "", nil
+	}
+	conn, err := cs.getNodeConn(nodeSelected)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if volumeType != LvmVolumeType {
+		return conn.GetClaimedStorage(ctx, volumeID)
+	}
+	ownerTag := localtype.VolumeOwnerTagPrefix + volumeID
+	vgs, err := conn.ListVG(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, vg := range vgs {
+		lvs, err := conn.ListLvm(ctx, vg.Name)
+		if err != nil {
+			return "", err
+		}
+		for _, lv := range lvs {
+			if utils.ContainsString(lv.Tags, ownerTag) {
+				return vg.Name, nil
+			}
+			// adopt the lv created without owner tag
+			if lv.Name == volumeID {
+				if err := conn.AddTagLvm(ctx, vg.Name, volumeID, []string{ownerTag}); err != nil {
+					return "", err
+				}
+				return vg.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// getDeviceInfo returns the info of the device on the node, which is identified by its name or by-id link,
+// nil if the device is not found. The ownership of the device is checked when it is claimed at the node
+func (cs *controllerServer) getDeviceInfo(ctx context.Context, nodeName, device string) (*localv1alpha1.DeviceInfo, error) {
+	nls, err := cs.localclient.CsiV1alpha1().NodeLocalStorages().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range nls.Status.NodeStorageInfo.DeviceInfos {
+		if info.Name == device || (info.ByID != "" && info.ByID == device) {
+			return &nls.Status.NodeStorageInfo.DeviceInfos[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// claimStorage records the storage as owned by the volume at the node
+func (cs *controllerServer) claimStorage(ctx context.Context, nodeSelected, volumeType, volumeID, storage string) error {
+	conn, err := cs.getNodeConn(nodeSelected)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	claimed, err := conn.ClaimStorage(ctx, volumeID, volumeType, storage)
+	if err != nil {
+		return err
+	}
+	if claimed != storage {
+		return fmt.Errorf("volume %s has already claimed %s %s", volumeID, volumeType, claimed)
+	}
+	return nil
+}
+
 func getNodeName(client kubernetes.Interface, pvcName string, pvcNameSpace string) (nodeName string, err error) {
 	pvc, err := client.CoreV1().PersistentVolumeClaims(pvcNameSpace).Get(context.Background(), pvcName, metav1.GetOptions{})
 
@@ -829,6 +1309,25 @@ func getNodeName(client kubernetes.Interface, pvcName string, pvcNameSpace strin
 	return nodeName, nil
 }
 
+// updateResizeCondition reports the failure of expanding the volume in the condition of its pvc, the condition is removed if err is nil
+func updateResizeCondition(client kubernetes.Interface, pv *v1.PersistentVolume, reason string, err error) {
+	if pv == nil || pv.Spec.ClaimRef == nil {
+		return
+	}
+	var condition *v1.PersistentVolumeClaimCondition
+	if err != nil {
+		condition = &v1.PersistentVolumeClaimCondition{
+			Status:  v1.ConditionTrue,
+			Reason:  reason,
+			Message: err.Error(),
+		}
+	}
+	claim := pv.Spec.ClaimRef
+	if updateErr := utils.UpdatePVCCondition(client, claim.Namespace, claim.Name, localtype.PVCConditionVolumeResizeFailed, condition); updateErr != nil {
+		log.Warningf("update condition %s of pvc %s/%s failed: %s", localtype.PVCConditionVolumeResizeFailed, claim.Namespace, claim.Name, updateErr.Error())
+	}
+}
+
 func getVolumeSnapshotClass(snapclient snapshot.Interface, className string) (*snapshotapi.VolumeSnapshotClass, error) {
 	return snapclient.SnapshotV1().VolumeSnapshotClasses().Get(context.Background(), className, metav1.GetOptions{})
 }
@@ -1072,6 +1571,50 @@ func deviceNoScheduled(parameters map[string]string) (string, map[string]string,
 	return "", paraList, nil
 }
 
+func quotaScheduled(storageSelected string, parameters map[string]string) (map[string]string, error) {
+	mountpoint := ""
+	paraList := map[string]string{}
+	if storageSelected != "" {
+		storageMap := map[string]string{}
+		err := json.Unmarshal([]byte(storageSelected), &storageMap)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Scheduler provide error storage format: "+err.Error())
+		}
+		if value, ok := storageMap[QuotaVolumeType]; ok {
+			paraList[QuotaVolumeType] = value
+			mountpoint = value
+		}
+	}
+	if mountpoint == "" {
+		return nil, status.Error(codes.InvalidArgument, "Quota Schedule failed "+mountpoint)
+	}
+	return paraList, nil
+}
+
+func quotaPartScheduled(nodeSelected, pvcName, pvcNameSpace string, parameters map[string]string) (map[string]string, error) {
+	paraList := map[string]string{}
+	volumeInfo, err := adapter.ScheduleVolume(QuotaVolumeType, pvcName, pvcNameSpace, "", nodeSelected)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "quota schedule with error "+err.Error())
+	}
+	if volumeInfo.Disk == "" {
+		log.Errorf("Quota Schedule finished, but get empty Disk: %v", volumeInfo)
+		return nil, status.Error(codes.InvalidArgument, "quota schedule finish but Disk empty")
+	}
+	paraList[QuotaVolumeType] = volumeInfo.Disk
+	return paraList, nil
+}
+
+func quotaNoScheduled(parameters map[string]string) (string, map[string]string, error) {
+	paraList := map[string]string{}
+	return "", paraList, nil
+}
+
+// getQuotaBlockLimit converts volume size in bytes to quota block limit in KiB
+func getQuotaBlockLimit(sizeBytes int64) uint64 {
+	return uint64((sizeBytes + 1024 - 1) / 1024)
+}
+
 func getPvObj(client kubernetes.Interface, volumeID string) (*v1.PersistentVolume, error) {
 	return client.CoreV1().PersistentVolumes().Get(context.Background(), volumeID, metav1.GetOptions{})
 }
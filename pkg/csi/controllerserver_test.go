@@ -0,0 +1,49 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"testing"
+
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetDeviceInfo(t *testing.T) {
+	byID := "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4"
+	nls := &localv1alpha1.NodeLocalStorage{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nls.Status.NodeStorageInfo.DeviceInfos = []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb"},
+		{Name: "/dev/sdc", ByID: byID},
+	}
+	cs := &controllerServer{localclient: localfake.NewSimpleClientset(nls)}
+	ctx := context.Background()
+
+	info, err := cs.getDeviceInfo(ctx, "node-1", byID)
+	if err != nil || info == nil || info.Name != "/dev/sdc" {
+		t.Errorf("expect /dev/sdc of the by-id link, got %+v, %v", info, err)
+	}
+	info, err = cs.getDeviceInfo(ctx, "node-1", "/dev/sdb")
+	if err != nil || info == nil || info.ByID != "" {
+		t.Errorf("expect /dev/sdb without by-id link, got %+v, %v", info, err)
+	}
+	if info, err := cs.getDeviceInfo(ctx, "node-1", "/dev/sdd"); err != nil || info != nil {
+		t.Errorf("expect nil of the missing device, got %+v, %v", info, err)
+	}
+}
@@ -22,7 +22,7 @@ import (
 	csicommon "github.com/kubernetes-csi/drivers/pkg/csi-common"
 )
 
-func NewDriver(driverName, nodeID, endpoint, sysPath string, grpcConnectionTimeout int) *CSIPlugin {
+func NewDriver(driverName, nodeID, endpoint, sysPath, mode string, grpcConnectionTimeout int, lvmdOpt *LvmdOptions) *CSIPlugin {
 	plugin := &CSIPlugin{}
 	plugin.endpoint = endpoint
 
@@ -33,18 +33,28 @@ func NewDriver(driverName, nodeID, endpoint, sysPath string, grpcConnectionTimeo
 		csilib.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 		csilib.ControllerServiceCapability_RPC_EXPAND_VOLUME,
 		csilib.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
+		csilib.ControllerServiceCapability_RPC_CLONE_VOLUME,
 	})
 	plugin.driver.AddVolumeCapabilityAccessModes([]csilib.VolumeCapability_AccessMode_Mode{csilib.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER})
 
 	plugin.idServer = newIdentityServer(csiDriver)
-	plugin.nodeServer = newNodeServer(csiDriver, driverName, nodeID, sysPath)
-	plugin.controllerServer = newControllerServer(csiDriver, grpcConnectionTimeout)
+	if mode != ModeController {
+		plugin.nodeServer = newNodeServer(csiDriver, driverName, nodeID, sysPath, lvmdOpt)
+	}
+	if mode != ModeNode {
+		plugin.controllerServer = newControllerServer(csiDriver, driverName, grpcConnectionTimeout, lvmdOpt)
+	}
 
 	return plugin
 }
 
 func (plugin *CSIPlugin) Run() {
+	// register the servers of the mode only, a nil *controllerServer is not a nil interface
+	var cs csilib.ControllerServer
+	if plugin.controllerServer != nil {
+		cs = plugin.controllerServer
+	}
 	server := csicommon.NewNonBlockingGRPCServer()
-	server.Start(plugin.endpoint, plugin.idServer, plugin.controllerServer, plugin.nodeServer)
+	server.Start(plugin.endpoint, plugin.idServer, cs, plugin.nodeServer)
 	server.Wait()
 }
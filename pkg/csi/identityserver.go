@@ -56,7 +56,7 @@ func (is *identityServer) GetPluginCapabilities(ctx context.Context, req *csilib
 			{
 				Type: &csilib.PluginCapability_VolumeExpansion_{
 					VolumeExpansion: &csilib.PluginCapability_VolumeExpansion{
-						Type: csilib.PluginCapability_VolumeExpansion_OFFLINE,
+						Type: csilib.PluginCapability_VolumeExpansion_ONLINE,
 					},
 				},
 			},
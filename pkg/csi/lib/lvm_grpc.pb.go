@@ -32,6 +32,11 @@ type LVMClient interface {
 	RemoveVG(ctx context.Context, in *CreateVGRequest, opts ...grpc.CallOption) (*RemoveVGReply, error)
 	CleanPath(ctx context.Context, in *CleanPathRequest, opts ...grpc.CallOption) (*CleanPathReply, error)
 	CleanDevice(ctx context.Context, in *CleanDeviceRequest, opts ...grpc.CallOption) (*CleanDeviceReply, error)
+	SetProjQuota(ctx context.Context, in *SetProjQuotaRequest, opts ...grpc.CallOption) (*SetProjQuotaReply, error)
+	RemoveProjQuota(ctx context.Context, in *RemoveProjQuotaRequest, opts ...grpc.CallOption) (*RemoveProjQuotaReply, error)
+	ClaimStorage(ctx context.Context, in *ClaimStorageRequest, opts ...grpc.CallOption) (*ClaimStorageReply, error)
+	GetClaimedStorage(ctx context.Context, in *GetClaimedStorageRequest, opts ...grpc.CallOption) (*GetClaimedStorageReply, error)
+	ReleaseStorage(ctx context.Context, in *ReleaseStorageRequest, opts ...grpc.CallOption) (*ReleaseStorageReply, error)
 }
 
 type lVMClient struct {
@@ -168,6 +173,51 @@ func (c *lVMClient) CleanDevice(ctx context.Context, in *CleanDeviceRequest, opt
 	return out, nil
 }
 
+func (c *lVMClient) SetProjQuota(ctx context.Context, in *SetProjQuotaRequest, opts ...grpc.CallOption) (*SetProjQuotaReply, error) {
+	out := new(SetProjQuotaReply)
+	err := c.cc.Invoke(ctx, "/proto.LVM/SetProjQuota", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVMClient) RemoveProjQuota(ctx context.Context, in *RemoveProjQuotaRequest, opts ...grpc.CallOption) (*RemoveProjQuotaReply, error) {
+	out := new(RemoveProjQuotaReply)
+	err := c.cc.Invoke(ctx, "/proto.LVM/RemoveProjQuota", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVMClient) ClaimStorage(ctx context.Context, in *ClaimStorageRequest, opts ...grpc.CallOption) (*ClaimStorageReply, error) {
+	out := new(ClaimStorageReply)
+	err := c.cc.Invoke(ctx, "/proto.LVM/ClaimStorage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVMClient) GetClaimedStorage(ctx context.Context, in *GetClaimedStorageRequest, opts ...grpc.CallOption) (*GetClaimedStorageReply, error) {
+	out := new(GetClaimedStorageReply)
+	err := c.cc.Invoke(ctx, "/proto.LVM/GetClaimedStorage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVMClient) ReleaseStorage(ctx context.Context, in *ReleaseStorageRequest, opts ...grpc.CallOption) (*ReleaseStorageReply, error) {
+	out := new(ReleaseStorageReply)
+	err := c.cc.Invoke(ctx, "/proto.LVM/ReleaseStorage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LVMServer is the server API for LVM service.
 // All implementations must embed UnimplementedLVMServer
 // for forward compatibility
@@ -186,6 +236,11 @@ type LVMServer interface {
 	RemoveVG(context.Context, *CreateVGRequest) (*RemoveVGReply, error)
 	CleanPath(context.Context, *CleanPathRequest) (*CleanPathReply, error)
 	CleanDevice(context.Context, *CleanDeviceRequest) (*CleanDeviceReply, error)
+	SetProjQuota(context.Context, *SetProjQuotaRequest) (*SetProjQuotaReply, error)
+	RemoveProjQuota(context.Context, *RemoveProjQuotaRequest) (*RemoveProjQuotaReply, error)
+	ClaimStorage(context.Context, *ClaimStorageRequest) (*ClaimStorageReply, error)
+	GetClaimedStorage(context.Context, *GetClaimedStorageRequest) (*GetClaimedStorageReply, error)
+	ReleaseStorage(context.Context, *ReleaseStorageRequest) (*ReleaseStorageReply, error)
 	mustEmbedUnimplementedLVMServer()
 }
 
@@ -235,6 +290,21 @@ func (UnimplementedLVMServer) CleanPath(context.Context, *CleanPathRequest) (*Cl
 func (UnimplementedLVMServer) CleanDevice(context.Context, *CleanDeviceRequest) (*CleanDeviceReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CleanDevice not implemented")
 }
+func (UnimplementedLVMServer) SetProjQuota(context.Context, *SetProjQuotaRequest) (*SetProjQuotaReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetProjQuota not implemented")
+}
+func (UnimplementedLVMServer) RemoveProjQuota(context.Context, *RemoveProjQuotaRequest) (*RemoveProjQuotaReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveProjQuota not implemented")
+}
+func (UnimplementedLVMServer) ClaimStorage(context.Context, *ClaimStorageRequest) (*ClaimStorageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimStorage not implemented")
+}
+func (UnimplementedLVMServer) GetClaimedStorage(context.Context, *GetClaimedStorageRequest) (*GetClaimedStorageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClaimedStorage not implemented")
+}
+func (UnimplementedLVMServer) ReleaseStorage(context.Context, *ReleaseStorageRequest) (*ReleaseStorageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseStorage not implemented")
+}
 func (UnimplementedLVMServer) mustEmbedUnimplementedLVMServer() {}
 
 // UnsafeLVMServer may be embedded to opt out of forward compatibility for this service.
@@ -500,6 +570,96 @@ func _LVM_CleanDevice_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LVM_SetProjQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetProjQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVMServer).SetProjQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVM/SetProjQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVMServer).SetProjQuota(ctx, req.(*SetProjQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVM_RemoveProjQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveProjQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVMServer).RemoveProjQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVM/RemoveProjQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVMServer).RemoveProjQuota(ctx, req.(*RemoveProjQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVM_ClaimStorage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimStorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVMServer).ClaimStorage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVM/ClaimStorage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVMServer).ClaimStorage(ctx, req.(*ClaimStorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVM_GetClaimedStorage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClaimedStorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVMServer).GetClaimedStorage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVM/GetClaimedStorage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVMServer).GetClaimedStorage(ctx, req.(*GetClaimedStorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVM_ReleaseStorage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseStorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVMServer).ReleaseStorage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVM/ReleaseStorage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVMServer).ReleaseStorage(ctx, req.(*ReleaseStorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LVM_ServiceDesc is the grpc.ServiceDesc for LVM service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -563,6 +723,26 @@ var LVM_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CleanDevice",
 			Handler:    _LVM_CleanDevice_Handler,
 		},
+		{
+			MethodName: "SetProjQuota",
+			Handler:    _LVM_SetProjQuota_Handler,
+		},
+		{
+			MethodName: "RemoveProjQuota",
+			Handler:    _LVM_RemoveProjQuota_Handler,
+		},
+		{
+			MethodName: "ClaimStorage",
+			Handler:    _LVM_ClaimStorage_Handler,
+		},
+		{
+			MethodName: "GetClaimedStorage",
+			Handler:    _LVM_GetClaimedStorage_Handler,
+		},
+		{
+			MethodName: "ReleaseStorage",
+			Handler:    _LVM_ReleaseStorage_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "lvm.proto",
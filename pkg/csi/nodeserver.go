@@ -23,10 +23,12 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 
 	localtype "github.com/alibaba/open-local/pkg"
 	"github.com/alibaba/open-local/pkg/csi/server"
+	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
 	"github.com/alibaba/open-local/pkg/utils"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	csicommon "github.com/kubernetes-csi/drivers/pkg/csi-common"
@@ -37,8 +39,12 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	mountutils "k8s.io/mount-utils"
 	utilexec "k8s.io/utils/exec"
 	k8smount "k8s.io/utils/mount"
@@ -73,6 +79,11 @@ type nodeServer struct {
 	client     kubernetes.Interface
 	k8smounter k8smount.Interface
 	sysPath    string
+	recorder   record.EventRecorder
+	// volumeLocks prevents copying data to a clone concurrently
+	volumeLocks *VolumeLocks
+	// copyErrors is the error of the last background copy of each volume
+	copyErrors sync.Map
 }
 
 var (
@@ -80,7 +91,7 @@ var (
 	kubeconfig string
 )
 
-func newNodeServer(d *csicommon.CSIDriver, dName, nodeID, sysPath string) csi.NodeServer {
+func newNodeServer(d *csicommon.CSIDriver, dName, nodeID, sysPath string, lvmdOpt *LvmdOptions) csi.NodeServer {
 	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
 	if err != nil {
 		log.Fatalf("Error building kubeconfig: %s", err.Error())
@@ -93,9 +104,22 @@ func newNodeServer(d *csicommon.CSIDriver, dName, nodeID, sysPath string) csi.No
 
 	mounter := k8smount.New("")
 
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: dName})
+
 	// local volume daemon
-	// GRPC server to provide volume manage
-	go server.Start()
+	if lvmdOpt.Transport == localtype.LvmdTransportCRD {
+		// serve the LvmdRequests of csi controller, so that no port is listened on the node
+		localClient, err := clientset.NewForConfig(cfg)
+		if err != nil {
+			log.Fatalf("Error building local clientset: %s", err.Error())
+		}
+		go server.NewRequestReconciler(nodeID, localClient, kubeClient, lvmdOpt.Server.ControllerIdentities, lvmdOpt.ReplyTokenFile).Run(wait.NeverStop)
+	} else {
+		// GRPC server to provide volume manage
+		go server.Start(&lvmdOpt.Server)
+	}
 
 	return &nodeServer{
 		DefaultNodeServer: csicommon.NewDefaultNodeServer(d),
@@ -105,6 +129,8 @@ func newNodeServer(d *csicommon.CSIDriver, dName, nodeID, sysPath string) csi.No
 		client:            kubeClient,
 		driverName:        dName,
 		sysPath:           sysPath,
+		recorder:          recorder,
+		volumeLocks:       NewVolumeLocks(),
 	}
 }
 
@@ -141,11 +167,19 @@ func (ns *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		switch volCap.GetAccessType().(type) {
 		case *csi.VolumeCapability_Block:
 			err := ns.mountLvmBlock(ctx, req)
+			if status.Code(err) == codes.Unavailable {
+				// data is being copied to the volume
+				return nil, err
+			}
 			if err != nil {
 				return nil, status.Errorf(codes.Internal, "NodePublishVolume(mountLvmBlock): mount lvm volume %s with path %s with error: %s", req.VolumeId, targetPath, err.Error())
 			}
 		case *csi.VolumeCapability_Mount:
 			err := ns.mountLvmFS(ctx, req)
+			if status.Code(err) == codes.Unavailable {
+				// data is being copied to the volume
+				return nil, err
+			}
 			if err != nil {
 				return nil, status.Errorf(codes.Internal, "NodePublishVolume(mountLvmFS): mount lvm volume %s with path %s with error: %s", req.VolumeId, targetPath, err.Error())
 			}
@@ -158,6 +192,11 @@ func (ns *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "NodePublishVolume: mount mountpoint volume %s with path %s with error: %s", req.VolumeId, targetPath, err.Error())
 		}
+	case QuotaVolumeType:
+		err := ns.mountQuotaVolume(ctx, req)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "NodePublishVolume: mount quota volume %s with path %s with error: %s", req.VolumeId, targetPath, err.Error())
+		}
 	case DeviceVolumeType:
 		switch volCap.GetAccessType().(type) {
 		case *csi.VolumeCapability_Block:
@@ -263,16 +302,37 @@ func (ns *nodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 func (ns *nodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (
 	*csi.NodeExpandVolumeResponse, error) {
 	log.Debugf("NodeExpandVolume: local node expand volume with: %v", req)
-	volumeID := req.VolumeId
-	targetPath := req.VolumePath
-	expectSize := req.CapacityRange.RequiredBytes
-	if err := ns.resizeVolume(ctx, volumeID, targetPath); err != nil {
-		log.Errorf("NodePublishVolume: Resize local volume %s with error: %s", volumeID, err.Error())
+	volumeID := req.GetVolumeId()
+	targetPath := req.GetVolumePath()
+	if volumeID == "" || targetPath == "" {
+		return nil, status.Error(codes.InvalidArgument, "NodeExpandVolume: volume id and volume path must be provided")
+	}
+	expectSize := req.GetCapacityRange().GetRequiredBytes()
+	if _, err := os.Stat(targetPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, status.Errorf(codes.NotFound, "NodeExpandVolume: volume path %s not found", targetPath)
+		}
+		return nil, status.Errorf(codes.Internal, "NodeExpandVolume: stat volume path %s failed: %s", targetPath, err.Error())
+	}
+	// a block volume is expanded along with its lv, there is no filesystem to resize
+	if isBlock, _ := IsBlockDevice(targetPath); isBlock || req.GetVolumeCapability().GetBlock() != nil {
+		log.Infof("NodeExpandVolume: block volume %s needs no filesystem resize", volumeID)
+		return &csi.NodeExpandVolumeResponse{CapacityBytes: expectSize}, nil
+	}
+
+	_, _, pv := getPvInfo(ns.client, volumeID)
+	if err := ns.resizeVolume(ctx, volumeID, targetPath, pv); err != nil {
+		log.Errorf("NodeExpandVolume: Resize local volume %s with error: %s", volumeID, err.Error())
+		updateResizeCondition(ns.client, pv, localtype.ReasonNodeExpandFailed, err)
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	updateResizeCondition(ns.client, pv, "", nil)
 
 	log.Infof("NodeExpandVolume: Successful expand local volume: %v to %d", req.VolumeId, expectSize)
-	return &csi.NodeExpandVolumeResponse{}, nil
+	return &csi.NodeExpandVolumeResponse{CapacityBytes: expectSize}, nil
 }
 
 func (ns *nodeServer) GetNodeID() string {
@@ -331,15 +391,16 @@ func (ns *nodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	// statfs on a Quota volume reports the limits of its project quota
 	return utils.GetMetrics(targetPath)
 }
 
-func (ns *nodeServer) resizeVolume(ctx context.Context, volumeID, targetPath string) error {
+// resizeVolume grows the filesystem of the volume in place, ext4 and xfs are resized while mounted
+func (ns *nodeServer) resizeVolume(ctx context.Context, volumeID, targetPath string, pv *v1.PersistentVolume) error {
 	vgName := ""
 
 	// Get volumeType
 	volumeType := LvmVolumeType
-	_, _, pv := getPvInfo(ns.client, volumeID)
 	if pv != nil && pv.Spec.CSI != nil {
 		if value, ok := pv.Spec.CSI.VolumeAttributes["volumeType"]; ok {
 			volumeType = value
@@ -360,6 +421,11 @@ func (ns *nodeServer) resizeVolume(ctx context.Context, volumeID, targetPath str
 		}
 
 		devicePath := filepath.Join("/dev", vgName, volumeID)
+		if notMnt, err := ns.k8smounter.IsLikelyNotMountPoint(targetPath); err != nil {
+			return status.Errorf(codes.Internal, "resizeVolume: check mount point %s failed: %s", targetPath, err.Error())
+		} else if notMnt {
+			return status.Errorf(codes.FailedPrecondition, "resizeVolume: volume %s is not mounted at %s", volumeID, targetPath)
+		}
 
 		log.Infof("NodeExpandVolume:: volumeId: %s, devicePath: %s", volumeID, devicePath)
 
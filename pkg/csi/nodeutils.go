@@ -3,10 +3,13 @@ package csi
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/backup"
+	"github.com/alibaba/open-local/pkg/csi/lib"
 	"github.com/alibaba/open-local/pkg/csi/server"
 	"github.com/alibaba/open-local/pkg/utils"
 	"github.com/container-storage-interface/spec/lib/go/csi"
@@ -15,11 +18,16 @@ import (
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	mountutils "k8s.io/mount-utils"
 	utilexec "k8s.io/utils/exec"
 	k8smount "k8s.io/utils/mount"
 )
 
+// copyBufferSize is the size of each read and write when copying data to a clone
+const copyBufferSize = 4 * 1024 * 1024
+
 func (ns *nodeServer) createLV(ctx context.Context, req *csi.NodePublishVolumeRequest) (string, error) {
 	// parse vgname, consider invalid if empty
 	vgName := ""
@@ -58,10 +66,296 @@ func (ns *nodeServer) createLV(ctx context.Context, req *csi.NodePublishVolumeRe
 			return "", status.Error(codes.Internal, err.Error())
 		}
 	}
+	var err error
+	if srcVolumeID := req.VolumeContext[localtype.ParamSourceVolumeID]; srcVolumeID != "" {
+		err = ns.cloneLV(req.VolumeContext[localtype.ParamSourceVGName], srcVolumeID, vgName, volumeID)
+	} else if snapshotID := req.VolumeContext[localtype.ParamSourceSnapshotID]; snapshotID != "" {
+		err = ns.restoreLV(req.VolumeContext[localtype.ParamSourceVGName], snapshotID, vgName, volumeID)
+	} else if backupName := req.VolumeContext[localtype.ParamRestoreBackupName]; backupName != "" {
+		err = ns.restoreBackupLV(req.VolumeContext, backupName, vgName, volumeID)
+	}
+	if err != nil {
+		log.Errorf("createLV: copy data to volume %s with error: %s", volumeID, err.Error())
+		if _, ok := status.FromError(err); ok {
+			return "", err
+		}
+		return "", status.Error(codes.Internal, err.Error())
+	}
 
 	return devicePath, nil
 }
 
+// cloneLV copies the data of the source volume to the volume in background, publishing the volume
+// reports Unavailable until the copy finishes
+func (ns *nodeServer) cloneLV(srcVGName, srcVolumeID, vgName, volumeID string) error {
+	return ns.copyInBackground(volumeID, fmt.Sprintf("volume %s", srcVolumeID), func() (bool, error) {
+		return isLVCopied(vgName, volumeID)
+	}, func(ctx context.Context) error {
+		return ns.copyFromVolume(ctx, srcVGName, srcVolumeID, vgName, volumeID)
+	})
+}
+
+// copyFromVolume copies the data of the source volume to the volume through a temporary snapshot of the source,
+// so that the source can be used during the copy
+func (ns *nodeServer) copyFromVolume(ctx context.Context, srcVGName, srcVolumeID, vgName, volumeID string) error {
+	// the snapshot may be left by a previous attempt
+	snapName := fmt.Sprintf("clone-%s", volumeID)
+	if _, err := server.RemoveSnapshot(ctx, srcVGName, snapName); err != nil {
+		return fmt.Errorf("remove snapshot %s/%s left before failed: %s", srcVGName, snapName, err.Error())
+	}
+	srcLVs, err := server.ListLV(fmt.Sprintf("%s/%s", srcVGName, srcVolumeID))
+	if err != nil {
+		return err
+	}
+	if len(srcLVs) != 1 {
+		return fmt.Errorf("expected 1 source LV %s/%s, got %d", srcVGName, srcVolumeID, len(srcLVs))
+	}
+	// the snapshot is as large as the source, so that it never overflows however the source is written during the copy
+	if _, err := server.CreateSnapshot(ctx, srcVGName, snapName, srcVolumeID, srcLVs[0].Size); err != nil {
+		return fmt.Errorf("create snapshot %s/%s of source failed: %s", srcVGName, snapName, err.Error())
+	}
+	defer func() {
+		if _, err := server.RemoveSnapshot(context.Background(), srcVGName, snapName); err != nil {
+			log.Warningf("cloneLV: remove snapshot %s/%s with error: %s", srcVGName, snapName, err.Error())
+		}
+	}()
+
+	return ns.copyLV(ctx, srcVGName, snapName, fmt.Sprintf("volume %s", srcVolumeID), vgName, volumeID)
+}
+
+// restoreLV copies the data of the snapshot to the volume in background, so that the volume is independent of the snapshot
+func (ns *nodeServer) restoreLV(srcVGName, snapshotID, vgName, volumeID string) error {
+	source := fmt.Sprintf("snapshot %s", snapshotID)
+	return ns.copyInBackground(volumeID, source, func() (bool, error) {
+		return isLVCopied(vgName, volumeID)
+	}, func(ctx context.Context) error {
+		return ns.copyLV(ctx, srcVGName, snapshotID, source, vgName, volumeID)
+	})
+}
+
+// restoreBackupLV downloads the backup to the volume in background, the backup target is set in the volume context
+func (ns *nodeServer) restoreBackupLV(volumeContext map[string]string, backupName, vgName, volumeID string) error {
+	return ns.copyInBackground(volumeID, fmt.Sprintf("backup %s", backupName), func() (bool, error) {
+		return isLVCopied(vgName, volumeID)
+	}, func(ctx context.Context) error {
+		return ns.copyFromBackup(ctx, volumeContext, backupName, vgName, volumeID)
+	})
+}
+
+// copyFromBackup writes the data of the backup to the lv of the volume and tags it
+func (ns *nodeServer) copyFromBackup(ctx context.Context, volumeContext map[string]string, backupName, vgName, volumeID string) error {
+	target, err := backup.NewTargetFromParameters(ns.client, volumeContext)
+	if err != nil {
+		return err
+	}
+	destPath := filepath.Join("/dev", vgName, volumeID)
+	out, err := os.OpenFile(destPath, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	source := fmt.Sprintf("backup %s", backupName)
+	ref := ns.getVolumeEventRef(volumeID)
+	ns.recordEvent(ref, v1.EventTypeNormal, localtype.EventCloneStarted, "copying data from %s at node %s", source, ns.nodeID)
+	reported := 0
+	manifest, err := backup.Restore(ctx, target, out, backupName, func(done, total int64) {
+		if percent := int(done * 100 / total); percent/10 > reported/10 {
+			reported = percent
+			ns.recordEvent(ref, v1.EventTypeNormal, localtype.EventCloneProgress, "copied %d%% of %s", percent, source)
+		}
+	})
+	if err == nil {
+		err = out.Sync()
+	}
+	if err != nil {
+		ns.recordEvent(ref, v1.EventTypeWarning, localtype.EventCloneFailed, "copying data from %s failed: %s", source, err.Error())
+		return err
+	}
+	if _, err := server.AddTagLV(ctx, vgName, volumeID, []string{localtype.ClonedLVTag}); err != nil {
+		return fmt.Errorf("tag copied LV %s/%s failed: %s", vgName, volumeID, err.Error())
+	}
+	ns.recordEvent(ref, v1.EventTypeNormal, localtype.EventCloneSucceeded, "copied data from %s", source)
+	log.Infof("copyFromBackup: restored %d bytes from %s to %s", manifest.Size, source, destPath)
+	return nil
+}
+
+// isLVCopied returns true if the data has been copied to the lv, which is tagged once copied
+func isLVCopied(vgName, volumeID string) (bool, error) {
+	lvs, err := server.ListLV(fmt.Sprintf("%s/%s", vgName, volumeID))
+	if err != nil {
+		return false, err
+	}
+	if len(lvs) != 1 {
+		return false, fmt.Errorf("expected 1 LV %s/%s, got %d", vgName, volumeID, len(lvs))
+	}
+	return utils.ContainsString(lvs[0].Tags, localtype.ClonedLVTag), nil
+}
+
+// copyLV copies the whole source lv to the lv of the volume and tags it, events of the progress are recorded on its pvc.
+// The lv is tagged as copying during the copy, so that the source snapshot is not deleted meanwhile
+func (ns *nodeServer) copyLV(ctx context.Context, srcVGName, srcLVName, source, vgName, volumeID string) error {
+	srcPath, destPath := filepath.Join("/dev", srcVGName, srcLVName), filepath.Join("/dev", vgName, volumeID)
+	srcSize, err := getDeviceSize(srcPath)
+	if err != nil {
+		return err
+	}
+	destSize, err := getDeviceSize(destPath)
+	if err != nil {
+		return err
+	}
+	if srcSize > destSize {
+		return fmt.Errorf("size %d of %s is smaller than size %d of %s", destSize, destPath, srcSize, source)
+	}
+
+	if _, err := server.AddTagLV(ctx, vgName, volumeID, []string{localtype.CopyingLVTag}); err != nil {
+		return fmt.Errorf("tag copying LV %s/%s failed: %s", vgName, volumeID, err.Error())
+	}
+	defer func() {
+		if _, err := server.RemoveTagLV(context.Background(), vgName, volumeID, []string{localtype.CopyingLVTag}); err != nil {
+			log.Warningf("copyLV: remove tag %s of LV %s/%s with error: %s", localtype.CopyingLVTag, vgName, volumeID, err.Error())
+		}
+	}()
+
+	ref := ns.getVolumeEventRef(volumeID)
+	ns.recordEvent(ref, v1.EventTypeNormal, localtype.EventCloneStarted, "copying data from %s at node %s", source, ns.nodeID)
+	err = copyDevice(srcPath, destPath, srcSize, func(percent int) {
+		ns.recordEvent(ref, v1.EventTypeNormal, localtype.EventCloneProgress, "copied %d%% of %s", percent, source)
+	})
+	if err == nil {
+		// an invalidated snapshot reads inconsistent data
+		err = checkSnapshotValid(srcVGName, srcLVName)
+	}
+	if err != nil {
+		ns.recordEvent(ref, v1.EventTypeWarning, localtype.EventCloneFailed, "copying data from %s failed: %s", source, err.Error())
+		return err
+	}
+	if _, err := server.AddTagLV(ctx, vgName, volumeID, []string{localtype.ClonedLVTag}); err != nil {
+		return fmt.Errorf("tag copied LV %s/%s failed: %s", vgName, volumeID, err.Error())
+	}
+	ns.recordEvent(ref, v1.EventTypeNormal, localtype.EventCloneSucceeded, "copied data from %s", source)
+	log.Infof("copyLV: copied %d bytes from %s to %s", srcSize, srcPath, destPath)
+	return nil
+}
+
+// checkSnapshotValid returns an error if the snapshot is invalidated, e.g. its copy-on-write space is exhausted
+func checkSnapshotValid(vgName, snapName string) error {
+	lvs, err := server.ListLV(fmt.Sprintf("%s/%s", vgName, snapName))
+	if err != nil {
+		return err
+	}
+	if len(lvs) != 1 {
+		return fmt.Errorf("expected 1 snapshot %s/%s, got %d", vgName, snapName, len(lvs))
+	}
+	switch lvs[0].Attributes.State {
+	case lib.VolumeStateInvalidSnapshot, lib.VolumeStateInvalidSuspendedSnapshot:
+		return fmt.Errorf("snapshot %s/%s is invalidated during the copy", vgName, snapName)
+	}
+	return nil
+}
+
+// copyInBackground runs copyFunc in background unless isCopied, so that publishing the volume is not blocked
+// by the copy. It returns nil once the data is copied, Unavailable while the copy is in progress, or the error
+// of the last copy which is retried by the next call
+func (ns *nodeServer) copyInBackground(volumeID, source string, isCopied func() (bool, error), copyFunc func(ctx context.Context) error) error {
+	if acquired := ns.volumeLocks.TryAcquire(volumeID); !acquired {
+		return status.Errorf(codes.Unavailable, "copy of volume %s from %s is in progress", volumeID, source)
+	}
+	copied, err := isCopied()
+	if err != nil || copied {
+		ns.volumeLocks.Release(volumeID)
+		return err
+	}
+	if lastErr, failed := ns.copyErrors.LoadAndDelete(volumeID); failed {
+		ns.volumeLocks.Release(volumeID)
+		return lastErr.(error)
+	}
+	go func() {
+		defer ns.volumeLocks.Release(volumeID)
+		if err := copyFunc(context.Background()); err != nil {
+			log.Errorf("copyInBackground: copy volume %s from %s with error: %s", volumeID, source, err.Error())
+			ns.copyErrors.Store(volumeID, err)
+		}
+	}()
+	return status.Errorf(codes.Unavailable, "copy of volume %s from %s is started", volumeID, source)
+}
+
+// getDeviceSize returns the size of the block device
+func getDeviceSize(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("get size of %s failed: %s", path, err.Error())
+	}
+	return uint64(size), nil
+}
+
+// isCopiedVolume returns true if the data of the volume is copied from another volume, a snapshot or a backup
+func isCopiedVolume(volumeContext map[string]string) bool {
+	return volumeContext[localtype.ParamSourceVolumeID] != "" || volumeContext[localtype.ParamSourceSnapshotID] != "" ||
+		volumeContext[localtype.ParamRestoreBackupName] != ""
+}
+
+// copyDevice copies size bytes from src to dest, progress is called each time another tenth is copied
+func copyDevice(src, dest string, size uint64, progress func(percent int)) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	buf := make([]byte, copyBufferSize)
+	var copied uint64
+	reported := 0
+	for copied < size {
+		chunk := buf
+		if left := size - copied; left < uint64(len(chunk)) {
+			chunk = chunk[:left]
+		}
+		n, err := io.ReadFull(in, chunk)
+		if err != nil {
+			return fmt.Errorf("read %s at %d failed: %s", src, copied, err.Error())
+		}
+		if _, err := out.Write(chunk[:n]); err != nil {
+			return fmt.Errorf("write %s at %d failed: %s", dest, copied, err.Error())
+		}
+		copied += uint64(n)
+		if percent := int(copied * 100 / size); percent/10 > reported/10 {
+			reported = percent
+			progress(percent)
+		}
+	}
+	return out.Sync()
+}
+
+// getVolumeEventRef returns the pvc of the volume to record events on, or the pv if unbound
+func (ns *nodeServer) getVolumeEventRef(volumeID string) *v1.ObjectReference {
+	_, _, pv := getPvInfo(ns.client, volumeID)
+	if pv == nil {
+		return nil
+	}
+	if pv.Spec.ClaimRef != nil {
+		return pv.Spec.ClaimRef
+	}
+	return &v1.ObjectReference{Kind: "PersistentVolume", Name: pv.Name, UID: pv.UID, APIVersion: "v1"}
+}
+
+func (ns *nodeServer) recordEvent(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+	if ref == nil {
+		return
+	}
+	ns.recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
+
 // include normal lvm & aep lvm type
 func (ns *nodeServer) mountLvmFS(ctx context.Context, req *csi.NodePublishVolumeRequest) error {
 	// target path
@@ -105,12 +399,23 @@ func (ns *nodeServer) mountLvmFS(ctx context.Context, req *csi.NodePublishVolume
 		}
 		mountFlags := req.GetVolumeCapability().GetMount().GetMountFlags()
 		options = append(options, mountFlags...)
+		// xfs refuses to mount the copy along with its source, for they share the same uuid
+		if isCopiedVolume(req.VolumeContext) && fsType == "xfs" {
+			options = append(options, "nouuid")
+		}
 
 		diskMounter := &k8smount.SafeFormatAndMount{Interface: ns.k8smounter, Exec: utilexec.New()}
 		if err := diskMounter.FormatAndMount(devicePath, targetPath, fsType, options); err != nil {
 			log.Errorf("mountLvmFS: Volume: %s, Device: %s, FormatAndMount error: %s", req.VolumeId, devicePath, err.Error())
 			return status.Error(codes.Internal, err.Error())
 		}
+		// the filesystem copied from the source may be smaller than the volume
+		if isCopiedVolume(req.VolumeContext) && !req.GetReadonly() {
+			if _, err := mountutils.NewResizeFs(utilexec.New()).Resize(devicePath, targetPath); err != nil {
+				log.Errorf("mountLvmFS: Volume: %s, Device: %s, resize fs of clone error: %s", req.VolumeId, devicePath, err.Error())
+				return status.Error(codes.Internal, err.Error())
+			}
+		}
 		log.Infof("mountLvmFS:: mount successful devicePath: %s, targetPath: %s, options: %v", devicePath, targetPath, options)
 	}
 	return nil
@@ -171,7 +476,6 @@ func (ns *nodeServer) mountLvmBlock(ctx context.Context, req *csi.NodePublishVol
 
 func (ns *nodeServer) mountMountPointVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) error {
 	sourcePath := ""
-	targetPath := req.TargetPath
 	if value, ok := req.VolumeContext[MountPointType]; ok {
 		sourcePath = value
 	}
@@ -180,6 +484,25 @@ func (ns *nodeServer) mountMountPointVolume(ctx context.Context, req *csi.NodePu
 		return status.Error(codes.Internal, "Mount LocalVolume with empty source path "+req.VolumeId)
 	}
 
+	return ns.bindMountVolume(req, sourcePath)
+}
+
+func (ns *nodeServer) mountQuotaVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) error {
+	mountpoint := ""
+	if value, ok := req.VolumeContext[QuotaVolumeType]; ok {
+		mountpoint = value
+	}
+	if mountpoint == "" {
+		log.Errorf("mountQuotaVolume: volume: %s, quota mountpoint empty", req.VolumeId)
+		return status.Error(codes.Internal, "Mount QuotaVolume with empty mountpoint "+req.VolumeId)
+	}
+
+	// the project quota subdirectory is carved out when the volume is created
+	return ns.bindMountVolume(req, filepath.Join(mountpoint, req.VolumeId))
+}
+
+func (ns *nodeServer) bindMountVolume(req *csi.NodePublishVolumeRequest, sourcePath string) error {
+	targetPath := req.TargetPath
 	notmounted, err := ns.k8smounter.IsLikelyNotMountPoint(targetPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -215,11 +538,30 @@ func (ns *nodeServer) mountMountPointVolume(ctx context.Context, req *csi.NodePu
 	return nil
 }
 
+// getDeviceOfVolume returns the device of the device volume, which is resolved from its by-id link
+// if the volume is bound to one, so that another disk taking the recorded name is never used
+func getDeviceOfVolume(volumeID string, volumeContext map[string]string) (string, error) {
+	device := volumeContext[DeviceVolumeType]
+	id := volumeContext[localtype.DeviceID]
+	if id == "" {
+		return device, nil
+	}
+	resolved, err := filepath.EvalSymlinks(id)
+	if err != nil {
+		return "", status.Errorf(codes.FailedPrecondition, "device %s of volume %s not found: %v", id, volumeID, err)
+	}
+	if resolved != device {
+		log.Warningf("device %s of volume %s is %s now instead of %s", id, volumeID, resolved, device)
+	}
+	return resolved, nil
+}
+
 func (ns *nodeServer) mountDeviceVolumeFS(ctx context.Context, req *csi.NodePublishVolumeRequest) error {
-	sourceDevice := ""
 	targetPath := req.TargetPath
-	if value, ok := req.VolumeContext[DeviceVolumeType]; ok {
-		sourceDevice = value
+	sourceDevice, err := getDeviceOfVolume(req.VolumeId, req.VolumeContext)
+	if err != nil {
+		log.Errorf("mountDeviceVolume: device volume: %s, %s", req.VolumeId, err.Error())
+		return err
 	}
 	if sourceDevice == "" {
 		log.Errorf("mountDeviceVolume: device volume: %s, sourcePath empty", req.VolumeId)
@@ -266,15 +608,17 @@ func (ns *nodeServer) mountDeviceVolumeFS(ctx context.Context, req *csi.NodePubl
 func (ns *nodeServer) mountDeviceVolumeBlock(ctx context.Context, req *csi.NodePublishVolumeRequest) error {
 	// Step 1: get targetPath and sourceDevice
 	targetPath := req.GetTargetPath()
-	sourceDevice, exists := req.VolumeContext[DeviceVolumeType]
-	if !exists {
+	sourceDevice, err := getDeviceOfVolume(req.VolumeId, req.VolumeContext)
+	if err != nil {
+		return err
+	}
+	if sourceDevice == "" {
 		return status.Error(codes.InvalidArgument, "Device path not provided")
 	}
 	log.Infof("mountDeviceVolumeBlock: targetPath %s, sourceDevice %s", targetPath, sourceDevice)
 
 	// Step 2: check if sourceDevice is block device
 	var isBlock bool
-	var err error
 	if isBlock, err = IsBlockDevice(sourceDevice); err != nil {
 		if removeErr := os.Remove(targetPath); removeErr != nil {
 			return status.Errorf(codes.Internal, "mountDeviceVolumeBlock: Could not remove mount target %q: %v", targetPath, removeErr)
@@ -350,14 +694,14 @@ func (ns *nodeServer) createVolume(volumeContext map[string]string, volumeID, vg
 	}
 
 	// Create lvm volume
-	if err := createLvm(vgName, volumeID, lvmType, unit, pvSize); err != nil {
+	if err := createLvm(vgName, volumeID, lvmType, unit, pvSize, volumeContext); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func createLvm(vgName, volumeID, lvmType, unit string, pvSize int64) error {
+func createLvm(vgName, volumeID, lvmType, unit string, pvSize int64, volumeContext map[string]string) error {
 	// Create lvm volume
 	if lvmType == StripingType {
 		pvNumber := getPVNumber(vgName)
@@ -380,6 +724,23 @@ func createLvm(vgName, volumeID, lvmType, unit string, pvSize int64) error {
 			return err
 		}
 		log.Infof("Successful Create Linear LVM volume: %s, with command: %s", volumeID, cmd)
+	} else if lvmType == ThinType {
+		thinPoolPercent, err := utils.GetThinPoolPercent(volumeContext)
+		if err != nil {
+			log.Errorf("createVolume:: parse thin pool percentage of vg %s error: %v", vgName, err)
+			return err
+		}
+		if err := server.EnsureThinPool(vgName, thinPoolPercent); err != nil {
+			log.Errorf("createVolume:: ensure thin pool of vg %s error: %v", vgName, err)
+			return err
+		}
+		cmd := fmt.Sprintf("%s lvcreate -n %s -V %d%s --thinpool %s/%s", localtype.NsenterCmd, volumeID, pvSize, unit, vgName, localtype.ThinPoolName)
+		_, err = utils.Run(cmd)
+		if err != nil {
+			log.Errorf("createVolume:: lvcreate thin command %s error: %v", cmd, err)
+			return err
+		}
+		log.Infof("Successful Create Thin LVM volume: %s, with command: %s", volumeID, cmd)
 	}
 	return nil
 }
@@ -0,0 +1,151 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestCopyDevice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clone")
+	if err != nil {
+		t.Fatalf("fail to create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	// the source is not a multiple of the buffer size, the dest is larger than the source
+	data := make([]byte, 2*copyBufferSize+1024)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	src, dest := filepath.Join(dir, "src"), filepath.Join(dir, "dest")
+	if err := ioutil.WriteFile(src, data, 0644); err != nil {
+		t.Fatalf("fail to write src: %s", err.Error())
+	}
+	if err := ioutil.WriteFile(dest, make([]byte, len(data)*2), 0644); err != nil {
+		t.Fatalf("fail to write dest: %s", err.Error())
+	}
+
+	reported := []int{}
+	if err := copyDevice(src, dest, uint64(len(data)), func(percent int) {
+		reported = append(reported, percent)
+	}); err != nil {
+		t.Fatalf("fail to copy: %s", err.Error())
+	}
+	copied, err := ioutil.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("fail to read dest: %s", err.Error())
+	}
+	if !bytes.Equal(copied[:len(data)], data) || len(copied) != len(data)*2 {
+		t.Fatalf("unexpected data copied to dest")
+	}
+	if len(reported) == 0 || reported[len(reported)-1] != 100 {
+		t.Fatalf("expect progress reported up to 100%%, got %v", reported)
+	}
+
+	// copying more than the source has fails
+	if err := copyDevice(src, dest, uint64(len(data)+1), func(int) {}); err == nil {
+		t.Fatalf("expect error when source is shorter than size")
+	}
+}
+
+func TestGetDeviceOfVolume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dev")
+	if err != nil {
+		t.Fatalf("fail to create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	// the disk bound to the volume is named sdc after reboot
+	sdc, link := filepath.Join(dir, "sdc"), filepath.Join(dir, "wwn-0x5000c500a0b1c2d3")
+	if err := ioutil.WriteFile(sdc, nil, 0644); err != nil {
+		t.Fatalf("fail to write device: %s", err.Error())
+	}
+	if err := os.Symlink(sdc, link); err != nil {
+		t.Fatalf("fail to create link: %s", err.Error())
+	}
+	sdc, _ = filepath.EvalSymlinks(sdc)
+
+	device, err := getDeviceOfVolume("pv-1", map[string]string{DeviceVolumeType: filepath.Join(dir, "sdb"), localtype.DeviceID: link})
+	if err != nil || device != sdc {
+		t.Errorf("expect device %s, got %s, %v", sdc, device, err)
+	}
+	if _, err := getDeviceOfVolume("pv-1", map[string]string{DeviceVolumeType: sdc, localtype.DeviceID: filepath.Join(dir, "wwn-missing")}); err == nil {
+		t.Errorf("expect error when the device of the volume is missing")
+	}
+	// volumes created before are bound to the names
+	if device, err := getDeviceOfVolume("pv-1", map[string]string{DeviceVolumeType: "/dev/sdb"}); err != nil || device != "/dev/sdb" {
+		t.Errorf("expect device /dev/sdb, got %s, %v", device, err)
+	}
+}
+
+func TestCopyInBackground(t *testing.T) {
+	ns := &nodeServer{volumeLocks: NewVolumeLocks()}
+	copied := false
+	release, failure := make(chan error), errors.New("source is gone")
+	isCopied := func() (bool, error) { return copied, nil }
+	copyFunc := func(ctx context.Context) error {
+		err := <-release
+		copied = err == nil
+		return err
+	}
+	waitReleased := func() {
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			if ns.volumeLocks.TryAcquire("pv-1") {
+				ns.volumeLocks.Release("pv-1")
+				return true, nil
+			}
+			return false, nil
+		}); err != nil {
+			t.Fatalf("copy is not finished")
+		}
+	}
+
+	// publishing reports in progress until the copy finishes
+	if err := ns.copyInBackground("pv-1", "volume pv-0", isCopied, copyFunc); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expect Unavailable when the copy is started, got %v", err)
+	}
+	if err := ns.copyInBackground("pv-1", "volume pv-0", isCopied, copyFunc); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expect Unavailable when the copy is in progress, got %v", err)
+	}
+	// the failure is reported once, and the copy is retried by the next call
+	release <- failure
+	waitReleased()
+	if err := ns.copyInBackground("pv-1", "volume pv-0", isCopied, copyFunc); err != failure {
+		t.Fatalf("expect the failure of the last copy, got %v", err)
+	}
+	if err := ns.copyInBackground("pv-1", "volume pv-0", isCopied, copyFunc); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expect Unavailable when the copy is retried, got %v", err)
+	}
+	release <- nil
+	waitReleased()
+	if err := ns.copyInBackground("pv-1", "volume pv-0", isCopied, copyFunc); err != nil {
+		t.Fatalf("expect no error once copied, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"path"
+
+	"github.com/alibaba/open-local/pkg/csi/lib"
+	"github.com/alibaba/open-local/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultControllerIdentity is the common name of the certificate used by csi controller
+const DefaultControllerIdentity = "open-local-controller"
+
+// readOnlyMethods are the lvmd methods which change nothing on the node,
+// every other method is only allowed for the controller identities
+var readOnlyMethods = []string{
+	"ListLV",
+	"ListVG",
+	"GetClaimedStorage",
+}
+
+// Options is the options of lvmd
+type Options struct {
+	// TLS enables mutual tls if set
+	TLS utils.TLSOptions
+	// ControllerIdentities are the common names allowed to invoke the methods which are not read only
+	ControllerIdentities []string
+}
+
+// NewMethodAllowlist returns the allowlist of method names and the identities allowed to invoke them,
+// which covers every lvmd method except the read only ones
+func NewMethodAllowlist(controllerIdentities []string) map[string][]string {
+	allowlist := make(map[string][]string, len(lib.LVM_ServiceDesc.Methods))
+	for _, method := range lib.LVM_ServiceDesc.Methods {
+		if !utils.ContainsString(readOnlyMethods, method.MethodName) {
+			allowlist[method.MethodName] = controllerIdentities
+		}
+	}
+	return allowlist
+}
+
+// NewAuthInterceptor returns an interceptor which rejects calls from identities not in the allowlist of the method
+func NewAuthInterceptor(allowlist map[string][]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, allowlist, path.Base(info.FullMethod)); err != nil {
+			log.Warningf("reject %s: %s", info.FullMethod, err.Error())
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func authorize(ctx context.Context, allowlist map[string][]string, method string) error {
+	return authorizeIdentity(allowlist, method, getPeerIdentity(ctx))
+}
+
+// authorizeIdentity checks the allowlist of method for the authenticated identity, which is empty if unauthenticated.
+// Read only methods are open to any client, and methods not in the allowlist are denied
+func authorizeIdentity(allowlist map[string][]string, method, identity string) error {
+	if utils.ContainsString(readOnlyMethods, method) {
+		return nil
+	}
+	identities := allowlist[method]
+	if identity == "" {
+		return status.Errorf(codes.Unauthenticated, "no verified client certificate to invoke %s", method)
+	}
+	if !utils.ContainsString(identities, identity) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to invoke %s", identity, method)
+	}
+	return nil
+}
+
+// getPeerIdentity returns the common name of the verified client certificate
+func getPeerIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+	for _, chain := range tlsInfo.State.VerifiedChains {
+		if len(chain) != 0 {
+			return chain[0].Subject.CommonName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alibaba/open-local/pkg/csi/lib"
+	"github.com/alibaba/open-local/pkg/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+func TestAuthorize(t *testing.T) {
+	allowlist := NewMethodAllowlist([]string{DefaultControllerIdentity})
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{"controller removes vg", peerContext(DefaultControllerIdentity), "RemoveVG", codes.OK},
+		{"node removes vg", peerContext("open-local-node"), "RemoveVG", codes.PermissionDenied},
+		{"anonymous cleans device", context.Background(), "CleanDevice", codes.Unauthenticated},
+		{"node lists lv", peerContext("open-local-node"), "ListLV", codes.OK},
+		{"anonymous gets claimed storage", context.Background(), "GetClaimedStorage", codes.OK},
+		{"node claims storage", peerContext("open-local-node"), "ClaimStorage", codes.PermissionDenied},
+		{"node creates lv", peerContext("open-local-node"), "CreateLV", codes.PermissionDenied},
+		{"anonymous sets quota", context.Background(), "SetProjQuota", codes.Unauthenticated},
+		{"controller expands lv", peerContext(DefaultControllerIdentity), "ExpandLV", codes.OK},
+		{"controller invokes unknown method", peerContext(DefaultControllerIdentity), "Unknown", codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		err := authorize(test.ctx, allowlist, test.method)
+		if status.Code(err) != test.code {
+			t.Errorf("%s: expect code %s, got %v", test.name, test.code, err)
+		}
+	}
+}
+
+// testLVMServer passes the authorized calls of RemoveVG and ListLV
+type testLVMServer struct {
+	lib.UnimplementedLVMServer
+}
+
+func (testLVMServer) RemoveVG(context.Context, *lib.CreateVGRequest) (*lib.RemoveVGReply, error) {
+	return &lib.RemoveVGReply{}, nil
+}
+
+func (testLVMServer) ListLV(context.Context, *lib.ListLVRequest) (*lib.ListLVReply, error) {
+	return &lib.ListLVReply{}, nil
+}
+
+// writeCert issues a certificate of commonName signed by ca, or a self signed ca if ca is nil,
+// and writes it with its key into dir
+func writeCert(t *testing.T, dir, commonName string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		ca, caKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate %s: %v", commonName, err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key %s: %v", commonName, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, commonName+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, commonName+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestTLSServerRejectsNodeIdentity(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeCert(t, dir, "ca", nil, nil)
+	writeCert(t, dir, "open-local-lvmd", ca, caKey)
+	writeCert(t, dir, DefaultControllerIdentity, ca, caKey)
+	writeCert(t, dir, "open-local-node", ca, caKey)
+	tlsOptions := func(name string) utils.TLSOptions {
+		return utils.TLSOptions{CertFile: filepath.Join(dir, name+".crt"), KeyFile: filepath.Join(dir, name+".key"), CAFile: filepath.Join(dir, "ca.crt")}
+	}
+
+	grpcServer, err := newTLSServer(testLVMServer{}, &Options{TLS: tlsOptions("open-local-lvmd"), ControllerIdentities: []string{DefaultControllerIdentity}})
+	if err != nil {
+		t.Fatalf("failed to create tls server: %v", err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	dial := func(identity string) lib.LVMClient {
+		tlsConfig, err := utils.NewTLSReloader(tlsOptions(identity)).ClientConfig("open-local-lvmd")
+		if err != nil {
+			t.Fatalf("failed to load client certificate %s: %v", identity, err)
+		}
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		if err != nil {
+			t.Fatalf("failed to dial as %s: %v", identity, err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return lib.NewLVMClient(conn)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	node := dial("open-local-node")
+	if _, err := node.RemoveVG(ctx, &lib.CreateVGRequest{Name: "open-local-pool-0"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expect node identity denied to remove vg, got %v", err)
+	}
+	if _, err := node.ListLV(ctx, &lib.ListLVRequest{}); err != nil {
+		t.Errorf("expect node identity allowed to list lv, got %v", err)
+	}
+	controller := dial(DefaultControllerIdentity)
+	if _, err := controller.RemoveVG(ctx, &lib.CreateVGRequest{Name: "open-local-pool-0"}); err != nil {
+		t.Errorf("expect controller identity allowed to remove vg, got %v", err)
+	}
+}
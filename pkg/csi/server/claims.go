@@ -0,0 +1,155 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	localtype "github.com/alibaba/open-local/pkg"
+)
+
+// claimLock serializes the access to claim records on this node
+var claimLock sync.Mutex
+
+// StorageClaim records the mount point or device owned by a volume,
+// it is persisted on the node so that it survives restarts of the controller
+type StorageClaim struct {
+	VolumeType string `json:"volumeType"`
+	Storage    string `json:"storage"`
+}
+
+// GetClaimDir returns the directory where claim records are persisted
+func GetClaimDir() string {
+	dir := localtype.DefaultStorageClaimDir
+	if value := os.Getenv(localtype.EnvStorageClaimDir); value != "" {
+		dir = value
+	}
+	return dir
+}
+
+// validateClaimOwner checks that owner names a claim record in the claim dir,
+// it must be a single path element which is neither hidden nor . or ..
+func validateClaimOwner(owner string) error {
+	if owner == "" || strings.ContainsRune(owner, '/') || owner != filepath.Base(owner) || strings.HasPrefix(owner, ".") {
+		return fmt.Errorf("invalid owner %q, it must be a volume name", owner)
+	}
+	return nil
+}
+
+// ClaimStorage records storage as owned by owner and returns the storage owned by owner,
+// which differs from the requested one if owner has already claimed another storage
+func ClaimStorage(dir, owner, volumeType, storage string) (string, error) {
+	claimLock.Lock()
+	defer claimLock.Unlock()
+
+	claims, err := listStorageClaims(dir)
+	if err != nil {
+		return "", err
+	}
+	if claim, ok := claims[owner]; ok {
+		return claim.Storage, nil
+	}
+	// mount point and device are exclusive resources
+	if volumeType == string(localtype.VolumeTypeMountPoint) || volumeType == string(localtype.VolumeTypeDevice) {
+		for o, claim := range claims {
+			if claim.VolumeType == volumeType && claim.Storage == storage {
+				return "", fmt.Errorf("%s %s is already claimed by volume %s", volumeType, storage, o)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create claim dir %s: %v", dir, err)
+	}
+	data, err := json.Marshal(StorageClaim{VolumeType: volumeType, Storage: storage})
+	if err != nil {
+		return "", err
+	}
+	// write to a temporary file first to keep the claim record complete
+	tmpFile := filepath.Join(dir, "."+owner+".tmp")
+	if err := ioutil.WriteFile(tmpFile, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write claim of volume %s: %v", owner, err)
+	}
+	if err := os.Rename(tmpFile, filepath.Join(dir, owner)); err != nil {
+		return "", fmt.Errorf("failed to write claim of volume %s: %v", owner, err)
+	}
+	return storage, nil
+}
+
+// GetClaimedStorage returns the claim of owner, nil if owner claims nothing
+func GetClaimedStorage(dir, owner string) (*StorageClaim, error) {
+	claimLock.Lock()
+	defer claimLock.Unlock()
+
+	return readStorageClaim(filepath.Join(dir, owner))
+}
+
+// ReleaseStorage removes the claim of owner
+func ReleaseStorage(dir, owner string) error {
+	claimLock.Lock()
+	defer claimLock.Unlock()
+
+	if err := os.Remove(filepath.Join(dir, owner)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release claim of volume %s: %v", owner, err)
+	}
+	return nil
+}
+
+func listStorageClaims(dir string) (map[string]*StorageClaim, error) {
+	claims := map[string]*StorageClaim{}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return claims, nil
+		}
+		return nil, fmt.Errorf("failed to list claims in %s: %v", dir, err)
+	}
+	for _, file := range files {
+		if file.IsDir() || file.Name()[0] == '.' {
+			continue
+		}
+		claim, err := readStorageClaim(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if claim != nil {
+			claims[file.Name()] = claim
+		}
+	}
+	return claims, nil
+}
+
+func readStorageClaim(file string) (*StorageClaim, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read claim %s: %v", file, err)
+	}
+	claim := &StorageClaim{}
+	if err := json.Unmarshal(data, claim); err != nil {
+		return nil, fmt.Errorf("failed to parse claim %s: %v", file, err)
+	}
+	return claim, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/csi/lib"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStorageClaim(t *testing.T) {
+	dir, err := ioutil.TempDir("", "claims")
+	if err != nil {
+		t.Fatalf("fail to create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	mpType := string(localtype.VolumeTypeMountPoint)
+
+	// claim a free mount point
+	storage, err := ClaimStorage(dir, "pv-1", mpType, "/mnt/disk-1")
+	if err != nil || storage != "/mnt/disk-1" {
+		t.Fatalf("expect to claim /mnt/disk-1, got %q, %v", storage, err)
+	}
+	// retry returns the storage claimed before
+	storage, err = ClaimStorage(dir, "pv-1", mpType, "/mnt/disk-2")
+	if err != nil || storage != "/mnt/disk-1" {
+		t.Fatalf("expect to get claimed /mnt/disk-1, got %q, %v", storage, err)
+	}
+	// mount point is exclusive
+	if _, err := ClaimStorage(dir, "pv-2", mpType, "/mnt/disk-1"); err == nil {
+		t.Fatalf("expect error when claiming mount point owned by another volume")
+	}
+	claim, err := GetClaimedStorage(dir, "pv-1")
+	if err != nil || claim == nil || claim.Storage != "/mnt/disk-1" || claim.VolumeType != mpType {
+		t.Fatalf("unexpected claim of pv-1: %#v, %v", claim, err)
+	}
+
+	if err := ReleaseStorage(dir, "pv-1"); err != nil {
+		t.Fatalf("fail to release claim of pv-1: %s", err.Error())
+	}
+	if claim, err := GetClaimedStorage(dir, "pv-1"); err != nil || claim != nil {
+		t.Fatalf("expect no claim of pv-1 after release, got %#v, %v", claim, err)
+	}
+	if _, err := ClaimStorage(dir, "pv-2", mpType, "/mnt/disk-1"); err != nil {
+		t.Fatalf("expect to claim released mount point: %s", err.Error())
+	}
+}
+
+func TestClaimOwnerValidated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "claims")
+	if err != nil {
+		t.Fatalf("fail to create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv(localtype.EnvStorageClaimDir, dir)
+	defer os.Unsetenv(localtype.EnvStorageClaimDir)
+
+	s := NewServer()
+	for _, owner := range []string{"", ".", "..", "/", "../pv-1", "/etc/passwd", "pv/1", ".pv-1.tmp"} {
+		if _, err := s.ClaimStorage(context.Background(), &lib.ClaimStorageRequest{Owner: owner, VolumeType: string(localtype.VolumeTypeMountPoint), Storage: "/mnt/disk-1"}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expect InvalidArgument to claim storage for owner %q, got %v", owner, err)
+		}
+		if _, err := s.GetClaimedStorage(context.Background(), &lib.GetClaimedStorageRequest{Owner: owner}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expect InvalidArgument to get claimed storage of owner %q, got %v", owner, err)
+		}
+		if _, err := s.ReleaseStorage(context.Background(), &lib.ReleaseStorageRequest{Owner: owner}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expect InvalidArgument to release storage of owner %q, got %v", owner, err)
+		}
+	}
+	if _, err := s.ClaimStorage(context.Background(), &lib.ClaimStorageRequest{Owner: "pv-1", VolumeType: string(localtype.VolumeTypeMountPoint), Storage: "/mnt/disk-1"}); err != nil {
+		t.Errorf("expect to claim storage for pv-1, got %v", err)
+	}
+}
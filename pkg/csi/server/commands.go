@@ -73,11 +73,22 @@ func ListLV(listspec string) ([]*lib.LV, error) {
 }
 
 // CreateLV creates a new volume
-func CreateLV(ctx context.Context, vg string, name string, size uint64, mirrors uint32, tags []string, striping bool) (string, error) {
+func CreateLV(ctx context.Context, vg string, name string, size uint64, mirrors uint32, tags []string, striping bool, thin bool, thinPoolPercent uint32) (string, error) {
 	if size == 0 {
 		return "", errors.New("size must be greater than 0")
 	}
-	args := []string{localtype.NsenterCmd, "lvcreate", "-n", name, "-L", fmt.Sprintf("%db", size), "-W", "y", "-y"}
+	if thin && (striping || mirrors > 0) {
+		return "", errors.New("thin volume can not be striping or mirrored")
+	}
+	var args []string
+	if thin {
+		if err := EnsureThinPool(vg, thinPoolPercent); err != nil {
+			return "", err
+		}
+		args = []string{localtype.NsenterCmd, "lvcreate", "-n", name, "-V", fmt.Sprintf("%db", size), "--thinpool", fmt.Sprintf("%s/%s", vg, localtype.ThinPoolName), "-y"}
+	} else {
+		args = []string{localtype.NsenterCmd, "lvcreate", "-n", name, "-L", fmt.Sprintf("%db", size), "-W", "y", "-y"}
+	}
 	if mirrors > 0 {
 		args = append(args, "-m", fmt.Sprintf("%d", mirrors), "--nosync")
 	}
@@ -95,12 +106,36 @@ func CreateLV(ctx context.Context, vg string, name string, size uint64, mirrors
 		args = append(args, "-i", strconv.Itoa(pvCount))
 	}
 
-	args = append(args, vg)
+	if !thin {
+		args = append(args, vg)
+	}
 	cmd := strings.Join(args, " ")
 	out, err := utils.Run(cmd)
 	return string(out), err
 }
 
+// EnsureThinPool creates the thin pool of vg with percent of the free size of vg if it does not exist,
+// so that it never takes the extents of thick volumes. The thin pool is carved out of vg and no longer
+// allocatable for thick volumes
+func EnsureThinPool(vg string, percent uint32) error {
+	if percent == 0 || percent > 100 {
+		return fmt.Errorf("invalid thin pool percentage %d of vg %s", percent, vg)
+	}
+	lvs, err := ListLV(fmt.Sprintf("%s/%s", vg, localtype.ThinPoolName))
+	if err != nil {
+		return fmt.Errorf("failed to list LVs: %v", err)
+	}
+	if len(lvs) != 0 {
+		return nil
+	}
+	args := []string{localtype.NsenterCmd, "lvcreate", "--type", "thin-pool", "-n", localtype.ThinPoolName, "-l", fmt.Sprintf("%d%%FREE", percent), "-y", vg}
+	cmd := strings.Join(args, " ")
+	if _, err := utils.Run(cmd); err != nil {
+		return fmt.Errorf("failed to create thin pool %s/%s: %v", vg, localtype.ThinPoolName, err)
+	}
+	return nil
+}
+
 func getRequiredPVNumber(vgName string, lvSize uint64) (int, error) {
 	pvs, err := ListPV(vgName)
 	if err != nil {
@@ -542,3 +577,26 @@ func RemoveProjQuotaSubpath(ctx context.Context, quotaSubpath string) (string, e
 	}
 	return out, nil
 }
+
+// CreateProjQuotaSubpath creates the subpath which project quota is set to
+func CreateProjQuotaSubpath(ctx context.Context, quotaSubpath string) (string, error) {
+	args := []string{localtype.NsenterCmd, "mkdir", "-p", quotaSubpath}
+	cmd := strings.Join(args, " ")
+	out, err := utils.Run(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to create proj quota subpath with error: %v", err)
+	}
+	return out, nil
+}
+
+// ResetSubpathProjQuota clears the block limits of the project the subpath belongs to
+func ResetSubpathProjQuota(ctx context.Context, projQuotaSubpath string) (string, error) {
+	projectID := ConvertString2int(filepath.Base(projQuotaSubpath))
+	args := []string{localtype.NsenterCmd, "setquota", "-P", fmt.Sprintf("%s 0 0 0 0 %s", projectID, filepath.Dir(projQuotaSubpath))}
+	cmd := strings.Join(args, " ")
+	out, err := utils.Run(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to reset quota of subpath with error: %v", err)
+	}
+	return out, nil
+}
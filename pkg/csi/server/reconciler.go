@@ -0,0 +1,209 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"github.com/alibaba/open-local/pkg/csi/lib"
+	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
+	informers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+)
+
+// RequestReconciler serves the LvmdRequests of this node, it replaces the grpc server of lvmd
+// so that no network port is needed on the node. Only csi controller is allowed to create LvmdRequests,
+// and every request is authenticated by the service account token it carries, which must be bound to
+// this node and the nonce of the request, and whose user is checked against the same allowlist of methods
+// as the client certificates of the grpc server. Results carry the token of this pod in turn,
+// so that csi controller can check they are written by this node.
+type RequestReconciler struct {
+	nodeName       string
+	client         clientset.Interface
+	kubeClient     kubernetes.Interface
+	server         lib.LVMServer
+	allowlist      map[string][]string
+	replyTokenFile string
+	lock           sync.Mutex
+	// handled records the requests served, until they are deleted
+	handled map[types.UID]bool
+}
+
+// NewRequestReconciler returns a RequestReconciler of nodeName, controllerIdentities are the
+// service account users allowed to invoke the methods which are not read only, and replyTokenFile is the
+// projected service account token of audience open-local-lvmd-reply results are written with
+func NewRequestReconciler(nodeName string, client clientset.Interface, kubeClient kubernetes.Interface, controllerIdentities []string, replyTokenFile string) *RequestReconciler {
+	svr := NewServer()
+	return &RequestReconciler{
+		nodeName:       nodeName,
+		client:         client,
+		kubeClient:     kubeClient,
+		server:         &svr,
+		allowlist:      NewMethodAllowlist(controllerIdentities),
+		replyTokenFile: replyTokenFile,
+		handled:        map[types.UID]bool{},
+	}
+}
+
+// Run watches the LvmdRequests of this node until stopCh is closed
+func (r *RequestReconciler) Run(stopCh <-chan struct{}) {
+	log.Infof("Lvmd serving LvmdRequests of node %s ...", r.nodeName)
+	factory := informers.NewSharedInformerFactoryWithOptions(r.client, 30*time.Second, informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+		opts.LabelSelector = labels.SelectorFromSet(labels.Set{localtype.LabelLvmdRequestNode: r.nodeName}).String()
+	}))
+	informer := factory.Csi().V1alpha1().LvmdRequests().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: r.onUpdate,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			r.onUpdate(newObj)
+		},
+		DeleteFunc: r.onDelete,
+	})
+	factory.Start(stopCh)
+	<-stopCh
+	log.Infof("Lvmd End ...")
+}
+
+func (r *RequestReconciler) onUpdate(obj interface{}) {
+	request, ok := obj.(*v1alpha1.LvmdRequest)
+	if !ok || request.Spec.NodeName != r.nodeName || request.Status.Completed {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.handled[request.UID] {
+		return
+	}
+	r.handled[request.UID] = true
+	go r.serve(request.DeepCopy())
+}
+
+func (r *RequestReconciler) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	request, ok := obj.(*v1alpha1.LvmdRequest)
+	if !ok {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.handled, request.UID)
+}
+
+func (r *RequestReconciler) serve(request *v1alpha1.LvmdRequest) {
+	result := r.handle(request)
+	result.Completed = true
+	// the token is rotated by kubelet
+	token, err := ioutil.ReadFile(r.replyTokenFile)
+	if err != nil {
+		log.Errorf("failed to read token %s to write result of %s: %v", r.replyTokenFile, request.Name, err)
+		return
+	}
+	result.Token = strings.TrimSpace(string(token))
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := r.client.CsiV1alpha1().LvmdRequests().Get(context.Background(), request.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if latest.UID != request.UID {
+			return nil
+		}
+		latest.Status = *result
+		_, err = r.client.CsiV1alpha1().LvmdRequests().UpdateStatus(context.Background(), latest, metav1.UpdateOptions{})
+		return err
+	}); err != nil {
+		log.Errorf("failed to write result of %s: %v", request.Name, err)
+	}
+}
+
+func (r *RequestReconciler) handle(request *v1alpha1.LvmdRequest) *v1alpha1.LvmdRequestStatus {
+	method := findMethod(request.Spec.Method)
+	if method == nil {
+		return &v1alpha1.LvmdRequestStatus{Code: uint32(codes.Unimplemented), Message: fmt.Sprintf("unknown method %s", request.Spec.Method)}
+	}
+	if err := r.authorize(request); err != nil {
+		log.Warningf("reject LvmdRequest %s of %s: %s", request.Name, request.Spec.Method, err.Error())
+		st := status.Convert(err)
+		return &v1alpha1.LvmdRequestStatus{Code: uint32(st.Code()), Message: st.Message()}
+	}
+	reply, err := method.Handler(r.server, context.Background(), func(in interface{}) error {
+		return json.Unmarshal([]byte(request.Spec.Request), in)
+	}, nil)
+	if err != nil {
+		st := status.Convert(err)
+		return &v1alpha1.LvmdRequestStatus{Code: uint32(st.Code()), Message: st.Message()}
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return &v1alpha1.LvmdRequestStatus{Code: uint32(codes.Internal), Message: fmt.Sprintf("failed to marshal reply: %v", err)}
+	}
+	return &v1alpha1.LvmdRequestStatus{Reply: string(data)}
+}
+
+// authorize authenticates the token of the request by TokenReview against the audience of this node and the nonce,
+// and checks the allowlist of the method for its user
+func (r *RequestReconciler) authorize(request *v1alpha1.LvmdRequest) error {
+	if request.Spec.Token == "" || request.Spec.Nonce == "" {
+		return status.Errorf(codes.Unauthenticated, "no token or nonce to invoke %s", request.Spec.Method)
+	}
+	audience := localtype.LvmdRequestAudience(r.nodeName, request.Spec.Nonce)
+	review, err := r.kubeClient.AuthenticationV1().TokenReviews().Create(context.Background(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     request.Spec.Token,
+			Audiences: []string{audience},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to review token: %v", err)
+	}
+	if !review.Status.Authenticated || !utils.ContainsString(review.Status.Audiences, audience) {
+		return status.Errorf(codes.Unauthenticated, "token to invoke %s is not authenticated: %s", request.Spec.Method, review.Status.Error)
+	}
+	return authorizeIdentity(r.allowlist, path.Base(request.Spec.Method), review.Status.User.Username)
+}
+
+// findMethod returns the description of the full grpc method name, e.g. /proto.LVM/CreateLV
+func findMethod(fullMethod string) *grpc.MethodDesc {
+	for i, method := range lib.LVM_ServiceDesc.Methods {
+		if fmt.Sprintf("/%s/%s", lib.LVM_ServiceDesc.ServiceName, method.MethodName) == fullMethod {
+			return &lib.LVM_ServiceDesc.Methods[i]
+		}
+	}
+	return nil
+}
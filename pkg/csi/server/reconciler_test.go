@@ -0,0 +1,152 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	"github.com/alibaba/open-local/pkg/csi/lib"
+	"github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	"google.golang.org/grpc/codes"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const controllerUser = "system:serviceaccount:kube-system:open-local-controller"
+
+func TestRequestReconciler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "claims")
+	if err != nil {
+		t.Fatalf("fail to create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv(localtype.EnvStorageClaimDir, dir)
+	defer os.Unsetenv(localtype.EnvStorageClaimDir)
+	tokenDir, err := ioutil.TempDir("", "token")
+	if err != nil {
+		t.Fatalf("fail to create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(tokenDir)
+	replyTokenFile := filepath.Join(tokenDir, "token")
+	if err := ioutil.WriteFile(replyTokenFile, []byte("reply-token\n"), 0600); err != nil {
+		t.Fatalf("fail to write reply token: %s", err.Error())
+	}
+
+	claim, _ := json.Marshal(&lib.ClaimStorageRequest{Owner: "pv-1", VolumeType: string(localtype.VolumeTypeMountPoint), Storage: "/mnt/disk-1"})
+	removeVG, _ := json.Marshal(&lib.CreateVGRequest{Name: "open-local-pool-0"})
+	newRequest := func(name, method, nonce, token string, request []byte) *v1alpha1.LvmdRequest {
+		return &v1alpha1.LvmdRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				UID:    types.UID(name),
+				Labels: map[string]string{localtype.LabelLvmdRequestNode: "node-1"},
+			},
+			Spec: v1alpha1.LvmdRequestSpec{NodeName: "node-1", Method: method, Nonce: nonce, Token: token, Request: string(request)},
+		}
+	}
+	requests := []*v1alpha1.LvmdRequest{
+		newRequest("claim", "/proto.LVM/ClaimStorage", "nonce-1", "controller-token", claim),
+		newRequest("unknown", "/proto.LVM/Unknown", "nonce-1", "controller-token", nil),
+		newRequest("forged", "/proto.LVM/ClaimStorage", "nonce-1", "forged-token", claim),
+		newRequest("replayed", "/proto.LVM/ClaimStorage", "nonce-2", "controller-token", claim),
+		newRequest("other-node", "/proto.LVM/ClaimStorage", "nonce-3", "other-node-token", claim),
+		newRequest("no-nonce", "/proto.LVM/ClaimStorage", "", "controller-token", claim),
+		newRequest("node", "/proto.LVM/RemoveVG", "nonce-4", "node-token", removeVG),
+	}
+	expectCodes := map[string]codes.Code{
+		"claim":      codes.OK,
+		"unknown":    codes.Unimplemented,
+		"forged":     codes.Unauthenticated,
+		"replayed":   codes.Unauthenticated,
+		"other-node": codes.Unauthenticated,
+		"no-nonce":   codes.Unauthenticated,
+		"node":       codes.PermissionDenied,
+	}
+
+	objects := []runtime.Object{}
+	for _, request := range requests {
+		objects = append(objects, request)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	kubeClient := kubefake.NewSimpleClientset()
+	// the tokens and the audiences they are bound to
+	tokens := map[string]struct{ user, audience string }{
+		"controller-token": {controllerUser, localtype.LvmdRequestAudience("node-1", "nonce-1")},
+		"other-node-token": {controllerUser, localtype.LvmdRequestAudience("node-2", "nonce-3")},
+		"node-token":       {"system:serviceaccount:kube-system:open-local-agent", localtype.LvmdRequestAudience("node-1", "nonce-4")},
+	}
+	kubeClient.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		if token, ok := tokens[review.Spec.Token]; ok && len(review.Spec.Audiences) == 1 && review.Spec.Audiences[0] == token.audience {
+			user := token.user
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: user},
+				Audiences:     review.Spec.Audiences,
+			}
+		}
+		return true, review, nil
+	})
+
+	r := NewRequestReconciler("node-1", client, kubeClient, []string{controllerUser}, replyTokenFile)
+	for _, request := range requests {
+		r.onUpdate(request)
+	}
+
+	results := map[string]v1alpha1.LvmdRequestStatus{}
+	if err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		for name := range expectCodes {
+			got, err := client.CsiV1alpha1().LvmdRequests().Get(context.Background(), name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			if !got.Status.Completed {
+				return false, nil
+			}
+			results[name] = got.Status
+		}
+		return true, nil
+	}); err != nil {
+		t.Fatalf("fail to wait for results: %s", err.Error())
+	}
+
+	for name, code := range expectCodes {
+		if codes.Code(results[name].Code) != code {
+			t.Errorf("expect %s of request %s, got %#v", code, name, results[name])
+		}
+		if results[name].Token != "reply-token" {
+			t.Errorf("expect reply token in result of request %s, got %q", name, results[name].Token)
+		}
+	}
+	reply := &lib.ClaimStorageReply{}
+	if err := json.Unmarshal([]byte(results["claim"].Reply), reply); err != nil || reply.Storage != "/mnt/disk-1" {
+		t.Errorf("unexpected reply of ClaimStorage: %#v", results["claim"])
+	}
+}
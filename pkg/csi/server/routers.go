@@ -18,8 +18,11 @@ package server
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
 
 	"github.com/alibaba/open-local/pkg/csi/lib"
+	"github.com/alibaba/open-local/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
@@ -56,7 +59,7 @@ func (s Server) ListLV(ctx context.Context, in *lib.ListLVRequest) (*lib.ListLVR
 // CreateLV create lvm volume
 func (s Server) CreateLV(ctx context.Context, in *lib.CreateLVRequest) (*lib.CreateLVReply, error) {
 	log.Debugf("Create LVM with: %+v", in)
-	out, err := CreateLV(ctx, in.VolumeGroup, in.Name, in.Size, in.Mirrors, in.Tags, in.Striping)
+	out, err := CreateLV(ctx, in.VolumeGroup, in.Name, in.Size, in.Mirrors, in.Tags, in.Striping, in.Thin, in.ThinPoolPercent)
 	if err != nil {
 		log.Errorf("Create LVM with error: %s", err.Error())
 		return nil, status.Errorf(codes.Internal, "failed to create lv: %v", err)
@@ -198,3 +201,86 @@ func (s Server) RemoveTagLV(ctx context.Context, in *lib.RemoveTagLVRequest) (*l
 	}
 	return &lib.RemoveTagLVReply{CommandOutput: log}, nil
 }
+
+// SetProjQuota creates the subpath and sets project quota to it
+func (s Server) SetProjQuota(ctx context.Context, in *lib.SetProjQuotaRequest) (*lib.SetProjQuotaReply, error) {
+	log.Debugf("Set project quota with: %+v", in)
+	if _, err := CreateProjQuotaSubpath(ctx, in.QuotaSubpath); err != nil {
+		log.Errorf("Set project quota with error: %s", err.Error())
+		return nil, status.Errorf(codes.Internal, "failed to create subpath %s: %v", in.QuotaSubpath, err)
+	}
+	projectID, err := SetProjectID2PVSubpath(filepath.Base(in.QuotaSubpath), in.QuotaSubpath, utils.Run)
+	if err != nil {
+		log.Errorf("Set project quota with error: %s", err.Error())
+		return nil, status.Errorf(codes.Internal, "failed to set project id to subpath %s: %v", in.QuotaSubpath, err)
+	}
+	blockHardlimit := strconv.FormatUint(in.BlockHardlimit, 10)
+	blockSoftlimit := strconv.FormatUint(in.BlockSoftlimit, 10)
+	if _, err := SetSubpathProjQuota(ctx, in.QuotaSubpath, blockHardlimit, blockSoftlimit); err != nil {
+		log.Errorf("Set project quota with error: %s", err.Error())
+		return nil, status.Errorf(codes.Internal, "failed to set project quota to subpath %s: %v", in.QuotaSubpath, err)
+	}
+	log.Debugf("Set project quota %s(project id %s) Successful", in.QuotaSubpath, projectID)
+	return &lib.SetProjQuotaReply{ProjectId: projectID}, nil
+}
+
+// RemoveProjQuota resets project quota of the subpath and removes it
+func (s Server) RemoveProjQuota(ctx context.Context, in *lib.RemoveProjQuotaRequest) (*lib.RemoveProjQuotaReply, error) {
+	log.Debugf("Remove project quota with: %+v", in)
+	if _, err := ResetSubpathProjQuota(ctx, in.QuotaSubpath); err != nil {
+		log.Errorf("Remove project quota with error: %s", err.Error())
+		return nil, status.Errorf(codes.Internal, "failed to reset project quota of subpath %s: %v", in.QuotaSubpath, err)
+	}
+	out, err := RemoveProjQuotaSubpath(ctx, in.QuotaSubpath)
+	if err != nil {
+		log.Errorf("Remove project quota with error: %s", err.Error())
+		return nil, status.Errorf(codes.Internal, "failed to remove subpath %s: %v", in.QuotaSubpath, err)
+	}
+	log.Debugf("Remove project quota with result: %+v", out)
+	return &lib.RemoveProjQuotaReply{CommandOutput: out}, nil
+}
+
+// ClaimStorage records the mount point or device as owned by the volume
+func (s Server) ClaimStorage(ctx context.Context, in *lib.ClaimStorageRequest) (*lib.ClaimStorageReply, error) {
+	log.Debugf("Claim storage with: %+v", in)
+	if err := validateClaimOwner(in.Owner); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	storage, err := ClaimStorage(GetClaimDir(), in.Owner, in.VolumeType, in.Storage)
+	if err != nil {
+		log.Errorf("Claim storage with error: %s", err.Error())
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to claim %s for volume %s: %v", in.Storage, in.Owner, err)
+	}
+	log.Debugf("Claim storage %s for volume %s Successful", storage, in.Owner)
+	return &lib.ClaimStorageReply{Storage: storage}, nil
+}
+
+// GetClaimedStorage returns the mount point or device owned by the volume
+func (s Server) GetClaimedStorage(ctx context.Context, in *lib.GetClaimedStorageRequest) (*lib.GetClaimedStorageReply, error) {
+	log.Debugf("Get claimed storage with: %+v", in)
+	if err := validateClaimOwner(in.Owner); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	claim, err := GetClaimedStorage(GetClaimDir(), in.Owner)
+	if err != nil {
+		log.Errorf("Get claimed storage with error: %s", err.Error())
+		return nil, status.Errorf(codes.Internal, "failed to get claimed storage of volume %s: %v", in.Owner, err)
+	}
+	if claim == nil {
+		return &lib.GetClaimedStorageReply{}, nil
+	}
+	return &lib.GetClaimedStorageReply{VolumeType: claim.VolumeType, Storage: claim.Storage}, nil
+}
+
+// ReleaseStorage removes the claim of the volume
+func (s Server) ReleaseStorage(ctx context.Context, in *lib.ReleaseStorageRequest) (*lib.ReleaseStorageReply, error) {
+	log.Debugf("Release storage with: %+v", in)
+	if err := validateClaimOwner(in.Owner); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := ReleaseStorage(GetClaimDir(), in.Owner); err != nil {
+		log.Errorf("Release storage with error: %s", err.Error())
+		return nil, status.Errorf(codes.Internal, "failed to release storage of volume %s: %v", in.Owner, err)
+	}
+	return &lib.ReleaseStorageReply{}, nil
+}
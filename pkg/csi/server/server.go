@@ -17,11 +17,15 @@ limitations under the License.
 package server
 
 import (
+	"net"
 	"os"
 
 	"github.com/alibaba/open-local/pkg/csi/lib"
+	"github.com/alibaba/open-local/pkg/utils"
 	serverhelpers "github.com/google/go-microservice-helpers/server"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -30,11 +34,16 @@ const (
 )
 
 // Start start lvmd
-func Start() {
+func Start(opt *Options) {
 	address := "0.0.0.0:" + GetLvmdPort()
 	log.Infof("Lvmd Starting with socket: %s ...", address)
 
 	svr := NewServer()
+	if opt != nil && opt.TLS.Enabled() {
+		startWithTLS(address, &svr, opt)
+		return
+	}
+	log.Warningf("Lvmd serving without tls, any client on the node network is able to invoke it")
 	serverhelpers.ListenAddress = &address
 	grpcServer, _, err := serverhelpers.NewServer()
 	if err != nil {
@@ -52,6 +61,42 @@ func Start() {
 	log.Infof("Lvmd End ...")
 }
 
+// startWithTLS serves lvmd with mutual tls, only the read only methods are allowed for identities other than the controller ones.
+// The node plugin exits if the certificates fail to load, instead of running without lvmd
+func startWithTLS(address string, svr *Server, opt *Options) {
+	grpcServer, err := newTLSServer(svr, opt)
+	if err != nil {
+		log.Fatalf("failed to load lvmd tls certificates, check --lvmd-tls-server-cert, --lvmd-tls-server-key and --lvmd-tls-client-ca: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Errorf("failed to listen: %v", err)
+		return
+	}
+	log.Infof("Lvmd serving with mutual tls, controller identities: %v", opt.ControllerIdentities)
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Errorf("failed to serve: %v", err)
+		return
+	}
+	log.Infof("Lvmd End ...")
+}
+
+// newTLSServer returns the grpc server of lvmd which requires client certificates
+// and authorizes the methods by the identities of the certificates
+func newTLSServer(svr lib.LVMServer, opt *Options) (*grpc.Server, error) {
+	reloader := utils.NewTLSReloader(opt.TLS)
+	if _, _, err := reloader.Load(); err != nil {
+		return nil, err
+	}
+	grpcServer := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(reloader.ServerConfig())),
+		grpc.UnaryInterceptor(NewAuthInterceptor(NewMethodAllowlist(opt.ControllerIdentities))),
+	)
+	lib.RegisterLVMServer(grpcServer, svr)
+	return grpcServer, nil
+}
+
 // GetLvmdPort get lvmd port
 func GetLvmdPort() string {
 	port := LvmdPort
@@ -17,13 +17,30 @@ limitations under the License.
 package csi
 
 import (
+	"github.com/alibaba/open-local/pkg/csi/client"
+	"github.com/alibaba/open-local/pkg/csi/server"
+	"github.com/alibaba/open-local/pkg/utils"
 	csivendor "github.com/container-storage-interface/spec/lib/go/csi"
 	csicommon "github.com/kubernetes-csi/drivers/pkg/csi-common"
 )
 
 const (
-	DefaultEndpoint   string = "unix://tmp/csi.sock"
-	DefaultDriverName string = "local.csi.aliyun.com"
+	DefaultEndpoint       string = "unix://tmp/csi.sock"
+	DefaultDriverName     string = "local.csi.aliyun.com"
+	DefaultLvmdServerName string = "open-local-lvmd"
+	// DefaultLvmdServiceAccount is the service account of csi controller LvmdRequest tokens are issued for
+	DefaultLvmdServiceAccount string = "kube-system/open-local-controller"
+	// DefaultLvmdNodeIdentity is the service account user of node plugins serving LvmdRequests
+	DefaultLvmdNodeIdentity string = "system:serviceaccount:kube-system:open-local-agent"
+)
+
+const (
+	// ModeAll serves both the controller and the node services
+	ModeAll string = "all"
+	// ModeController serves the controller service only and never starts lvmd
+	ModeController string = "controller"
+	// ModeNode serves the node service only and never dials lvmd
+	ModeNode string = "node"
 )
 
 type CSIPlugin struct {
@@ -33,3 +50,19 @@ type CSIPlugin struct {
 	nodeServer       csivendor.NodeServer
 	controllerServer *controllerServer
 }
+
+// LvmdOptions is the options of the grpc connection between csi controller and lvmd
+type LvmdOptions struct {
+	// Server is the options lvmd serves with
+	Server server.Options
+	// ClientTLS is the key pair and the ca csi controller dials lvmd with
+	ClientTLS utils.TLSOptions
+	// ServerName is the name verified against the lvmd server certificate
+	ServerName string
+	// Transport is how csi controller reaches lvmd, grpc or crd
+	Transport string
+	// Request is how csi controller and the nodes authenticate each other through LvmdRequests
+	Request client.RequestOptions
+	// ReplyTokenFile is the service account token the node plugin writes the results of LvmdRequests with
+	ReplyTokenFile string
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"sync"
+)
+
+// VolumeLocks makes sure only one operation is in flight for a volume name
+type VolumeLocks struct {
+	mux   sync.Mutex
+	locks map[string]struct{}
+}
+
+// NewVolumeLocks returns an empty VolumeLocks
+func NewVolumeLocks() *VolumeLocks {
+	return &VolumeLocks{
+		locks: make(map[string]struct{}),
+	}
+}
+
+// TryAcquire tries to acquire the lock of volumeID,
+// returns false if an operation on volumeID is already in flight
+func (vl *VolumeLocks) TryAcquire(volumeID string) bool {
+	vl.mux.Lock()
+	defer vl.mux.Unlock()
+	if _, exist := vl.locks[volumeID]; exist {
+		return false
+	}
+	vl.locks[volumeID] = struct{}{}
+	return true
+}
+
+// Release releases the lock of volumeID
+func (vl *VolumeLocks) Release(volumeID string) {
+	vl.mux.Lock()
+	defer vl.mux.Unlock()
+	delete(vl.locks, volumeID)
+}
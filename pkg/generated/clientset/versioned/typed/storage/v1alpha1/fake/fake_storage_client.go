@@ -28,6 +28,10 @@ type FakeCsiV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeCsiV1alpha1) LvmdRequests() v1alpha1.LvmdRequestInterface {
+	return &FakeLvmdRequests{c}
+}
+
 func (c *FakeCsiV1alpha1) NodeLocalStorages() v1alpha1.NodeLocalStorageInterface {
 	return &FakeNodeLocalStorages{c}
 }
@@ -18,6 +18,8 @@ limitations under the License.
 
 package v1alpha1
 
+type LvmdRequestExpansion interface{}
+
 type NodeLocalStorageExpansion interface{}
 
 type NodeLocalStorageInitConfigExpansion interface{}
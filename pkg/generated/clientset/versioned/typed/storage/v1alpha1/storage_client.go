@@ -26,6 +26,7 @@ import (
 
 type CsiV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	LvmdRequestsGetter
 	NodeLocalStoragesGetter
 	NodeLocalStorageInitConfigsGetter
 }
@@ -35,6 +36,10 @@ type CsiV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *CsiV1alpha1Client) LvmdRequests() LvmdRequestInterface {
+	return newLvmdRequests(c)
+}
+
 func (c *CsiV1alpha1Client) NodeLocalStorages() NodeLocalStorageInterface {
 	return newNodeLocalStorages(c)
 }
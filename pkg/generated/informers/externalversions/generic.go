@@ -53,6 +53,8 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=csi.aliyun.com, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("lvmdrequests"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Csi().V1alpha1().LvmdRequests().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("nodelocalstorages"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Csi().V1alpha1().NodeLocalStorages().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("nodelocalstorageinitconfigs"):
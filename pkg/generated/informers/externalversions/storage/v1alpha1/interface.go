@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// LvmdRequests returns a LvmdRequestInformer.
+	LvmdRequests() LvmdRequestInformer
 	// NodeLocalStorages returns a NodeLocalStorageInformer.
 	NodeLocalStorages() NodeLocalStorageInformer
 	// NodeLocalStorageInitConfigs returns a NodeLocalStorageInitConfigInformer.
@@ -41,6 +43,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// LvmdRequests returns a LvmdRequestInformer.
+func (v *version) LvmdRequests() LvmdRequestInformer {
+	return &lvmdRequestInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // NodeLocalStorages returns a NodeLocalStorageInformer.
 func (v *version) NodeLocalStorages() NodeLocalStorageInformer {
 	return &nodeLocalStorageInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
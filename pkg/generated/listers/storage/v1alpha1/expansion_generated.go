@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+// LvmdRequestListerExpansion allows custom methods to be added to
+// LvmdRequestLister.
+type LvmdRequestListerExpansion interface{}
+
 // NodeLocalStorageListerExpansion allows custom methods to be added to
 // NodeLocalStorageLister.
 type NodeLocalStorageListerExpansion interface{}
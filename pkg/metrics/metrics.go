@@ -69,6 +69,22 @@ var (
 		},
 		[]string{"nodename", "name"},
 	)
+	QuotaTotal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: Subsystem,
+			Name:      "quota_total",
+			Help:      "Total size of project quota enabled MountPoint.",
+		},
+		[]string{"nodename", "name"},
+	)
+	QuotaUsedByLocal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: Subsystem,
+			Name:      "quota_used",
+			Help:      "Size of project quota enabled MountPoint requested by Quota volumes.",
+		},
+		[]string{"nodename", "name"},
+	)
 	DeviceTotal = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: Subsystem,
@@ -105,6 +121,7 @@ var (
 	// LVM:         VG name
 	// MountPoint:  mount path
 	// Device:      device path
+	// Quota:       mount path
 	LocalPV = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: Subsystem,
@@ -121,6 +138,48 @@ var (
 		},
 		[]string{"pod_name", "pod_namespace", "nodename", "vgname", "volume_name"},
 	)
+	AssumedUnits = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: Subsystem,
+			Name:      "assumed_units",
+			Help:      "Number of allocated units assumed before their PVs are created.",
+		},
+		[]string{"nodename"},
+	)
+	AssumedReleasedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: Subsystem,
+			Name:      "assumed_released_total",
+			Help:      "Number of assumed units released, by reason.",
+		},
+		[]string{"reason"},
+	)
+	// value is the requested size for VG and Quota,
+	// or 1/0 whether allocated for MountPoint and Device
+	CacheDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: Subsystem,
+			Name:      "cache_drift",
+			Help:      "Difference between the scheduler cache and the one rebuilt from apiserver in the last reconciliation.",
+		},
+		[]string{"nodename", "type", "name"},
+	)
+	CacheDriftTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: Subsystem,
+			Name:      "cache_drift_total",
+			Help:      "Number of drifted resources corrected by reconciliation.",
+		},
+		[]string{"nodename", "type"},
+	)
+	PolicyReloadTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: Subsystem,
+			Name:      "policy_reload_total",
+			Help:      "Number of scheduler policy reloads, by result.",
+		},
+		[]string{"result"},
+	)
 )
 
 func UpdateMetrics(c *cache.ClusterNodeCache) {
@@ -132,10 +191,13 @@ func UpdateMetrics(c *cache.ClusterNodeCache) {
 	MountPointAvailable.Reset()
 	MountPointBind.Reset()
 	MountPointTotal.Reset()
+	QuotaTotal.Reset()
+	QuotaUsedByLocal.Reset()
 	VolumeGroupUsedByLocal.Reset()
 	VolumeGroupTotal.Reset()
 	LocalPV.Reset()
 	InlineVolume.Reset()
+	AssumedUnits.Reset()
 
 	// metrics update
 	for nodeName := range c.Nodes {
@@ -152,6 +214,10 @@ func UpdateMetrics(c *cache.ClusterNodeCache) {
 				MountPointBind.WithLabelValues(nodeName, string(mpname)).Set(0)
 			}
 		}
+		for mpname, info := range c.Nodes[nodeName].Quotas {
+			QuotaTotal.WithLabelValues(nodeName, string(mpname)).Set(float64(info.Capacity))
+			QuotaUsedByLocal.WithLabelValues(nodeName, string(mpname)).Set(float64(info.Requested))
+		}
 		for devicename, info := range c.Nodes[nodeName].Devices {
 			DeviceAvailable.WithLabelValues(nodeName, string(devicename), string(info.MediaType)).Set(float64(info.Capacity))
 			DeviceTotal.WithLabelValues(nodeName, string(devicename), string(info.MediaType)).Set(float64(info.Capacity))
@@ -173,6 +239,9 @@ func UpdateMetrics(c *cache.ClusterNodeCache) {
 			case string(pkg.VolumeTypeDevice):
 				pvType = string(pkg.VolumeTypeDevice)
 				storageName = pv.Spec.CSI.VolumeAttributes[pkg.DeviceName]
+			case string(pkg.VolumeTypeQuota):
+				pvType = string(pkg.VolumeTypeQuota)
+				storageName = pv.Spec.CSI.VolumeAttributes[pkg.QuotaName]
 			}
 			LocalPV.WithLabelValues(
 				nodeName,
@@ -197,5 +266,18 @@ func UpdateMetrics(c *cache.ClusterNodeCache) {
 		}
 
 		AllocatedNum.WithLabelValues(nodeName).Set(float64(c.Nodes[nodeName].AllocatedNum))
+		AssumedUnits.WithLabelValues(nodeName).Set(0)
+	}
+	for _, unit := range c.Assumed {
+		AssumedUnits.WithLabelValues(unit.NodeName).Inc()
+	}
+}
+
+// UpdateCacheDrift records the drifts found in the last reconciliation
+func UpdateCacheDrift(drifts []cache.Drift) {
+	CacheDrift.Reset()
+	for _, drift := range drifts {
+		CacheDrift.WithLabelValues(drift.NodeName, string(drift.VolumeType), drift.Name).Set(float64(drift.Cached - drift.Actual))
+		CacheDriftTotal.WithLabelValues(drift.NodeName, string(drift.VolumeType)).Inc()
 	}
 }
@@ -35,7 +35,7 @@ const MinScore int = 0
 const MaxScore int = 10
 
 // AllocateLVMVolume contains two policy: BINPACK/SPREAD
-func AllocateLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+func AllocateLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	if len(pvcs) <= 0 {
 		return
 	}
@@ -44,7 +44,7 @@ func AllocateLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, no
 	}
 	log.Infof("allocating lvm volume for pod %s/%s", pod.Namespace, pod.Name)
 
-	fits, units, err = ProcessLVMPVCPredicate(pvcs, node, ctx)
+	fits, units, err = ProcessLVMPVCPredicate(pvcs, node, ctx, constraints)
 	if err != nil {
 		return
 	}
@@ -57,7 +57,7 @@ func AllocateLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, no
 	return true, units, nil
 }
 
-func ProcessLVMPVCPredicate(pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+func ProcessLVMPVCPredicate(pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	pvcsWithVG, pvcsWithoutVG := DivideLVMPVCs(pvcs, ctx)
 	cacheVGsMap, err := GetNodeVGMap(node, ctx)
 	if err != nil {
@@ -68,21 +68,34 @@ func ProcessLVMPVCPredicate(pvcs []*corev1.PersistentVolumeClaim, node *corev1.N
 	for _, pvc := range pvcsWithVG {
 		vgName := utils.GetVGNameFromPVC(pvc, ctx.StorageV1Informers)
 		requestedSize := utils.GetPVCRequested(pvc)
+		thin := utils.IsThinLVMPVC(pvc, ctx.StorageV1Informers)
+		class := thinClassOfPVC(pvc, ctx)
 
 		vg, ok := cacheVGsMap[cache.ResourceName(vgName)]
 		if !ok {
 			return false, units, errors.NewNoSuchVGError(vgName, node.GetName())
 		}
+		if vg.Unhealthy() {
+			return false, units, errors.NewUnhealthyStorageError(localtype.VolumeTypeLVM, vgName, string(vg.Condition), node.GetName())
+		}
+		if mediaType := utils.GetMediaTypeFromPVC(pvc, ctx.StorageV1Informers); !vgOfMediaType(vg, mediaType) {
+			return false, units, errors.NewNoVGOfMediaTypeError(vgName, mediaType, node.GetName())
+		}
+		if !constraints.Allows(pvc, localtype.VolumeTypeLVM, vgName) {
+			return false, units, errors.NewVolumeConstraintError(localtype.VolumeTypeLVM, utils.PVCName(pvc), node.GetName())
+		}
 
-		freeSize := vg.Capacity - vg.Requested
-		log.Debugf("validating vg(name=%s,free=%d) for pvc(name=%s,requested=%d)", vgName, freeSize, pvc.Name, requestedSize)
+		freeSize := cache.VGFree(vg, thin, class)
+		needed := constraints.Requested(pvc)
+		log.Debugf("validating vg(name=%s,free=%d,thin=%t) for pvc(name=%s,requested=%d)", vgName, freeSize, thin, pvc.Name, needed)
 
-		if freeSize < requestedSize {
-			return false, units, errors.NewInsufficientLVMError(requestedSize, vg.Requested, vg.Capacity, vg.Name, node.GetName())
+		if freeSize < needed {
+			return false, units, errors.NewInsufficientLVMError(needed, cache.VGRequested(vg, thin), cache.VGCapacity(vg, thin, class), vg.Name, node.GetName())
 		}
 		tmp := cacheVGsMap[cache.ResourceName(vgName)]
-		tmp.Requested += requestedSize
+		cache.AddVGRequested(&tmp, requestedSize, thin)
 		cacheVGsMap[cache.ResourceName(vgName)] = tmp
+		constraints.Add(pvc, localtype.VolumeTypeLVM, vgName)
 		u := cache.AllocatedUnit{
 			NodeName:   node.Name,
 			VolumeType: localtype.VolumeTypeLVM,
@@ -92,39 +105,45 @@ func ProcessLVMPVCPredicate(pvcs []*corev1.PersistentVolumeClaim, node *corev1.N
 			Device:     "",
 			MountPoint: "",
 			PVCName:    utils.PVCName(pvc),
+			Thin:       thin,
+			ThinClass:  class,
 		}
 		units = append(units, u)
 	}
 
-	// make a copy slice of cacheVGsMap
-	cacheVGsSlice := make([]cache.SharedResource, len(cacheVGsMap))
-	for _, vg := range cacheVGsMap {
-		cacheVGsSlice = append(cacheVGsSlice, vg)
-	}
-
-	if len(cacheVGsSlice) <= 0 {
+	if len(cacheVGsMap) <= 0 {
 		return false, units, errors.NewNoAvailableVGError(node.Name)
 	}
 	// process pvcsWithoutVG
 	for _, pvc := range pvcsWithoutVG {
 		requestedSize := utils.GetPVCRequested(pvc)
+		thin := utils.IsThinLVMPVC(pvc, ctx.StorageV1Informers)
+		class := thinClassOfPVC(pvc, ctx)
+		mediaType := utils.GetMediaTypeFromPVC(pvc, ctx.StorageV1Informers)
+		needed := constraints.Requested(pvc)
 
+		cacheVGsSlice, err := candidateVGs(pvc, node, cacheVGsMap, mediaType, constraints)
+		if err != nil {
+			return false, units, err
+		}
 		// sort by available size
 		sort.Slice(cacheVGsSlice, func(i, j int) bool {
-			return (cacheVGsSlice[i].Capacity - cacheVGsSlice[i].Requested) < (cacheVGsSlice[j].Capacity - cacheVGsSlice[j].Requested)
+			return cache.VGFree(cacheVGsSlice[i], thin, class) < cache.VGFree(cacheVGsSlice[j], thin, class)
 		})
 
 		for i, vg := range cacheVGsSlice {
-			freeSize := vg.Capacity - vg.Requested
-			log.Debugf("validating vg(name=%s,free=%d) for pvc(name=%s,requested=%d)", vg.Name, freeSize, pvc.Name, requestedSize)
+			freeSize := cache.VGFree(vg, thin, class)
+			log.Debugf("validating vg(name=%s,free=%d,thin=%t) for pvc(name=%s,requested=%d)", vg.Name, freeSize, thin, pvc.Name, needed)
 
-			if freeSize < requestedSize {
+			if freeSize < needed {
 				if i == len(cacheVGsSlice)-1 {
-					return false, units, errors.NewInsufficientLVMError(requestedSize, vg.Requested, vg.Capacity, vg.Name, node.GetName())
+					return false, units, errors.NewInsufficientLVMError(needed, cache.VGRequested(vg, thin), cache.VGCapacity(vg, thin, class), vg.Name, node.GetName())
 				}
 				continue
 			}
-			cacheVGsSlice[i].Requested += requestedSize
+			cache.AddVGRequested(&vg, requestedSize, thin)
+			cacheVGsMap[cache.ResourceName(vg.Name)] = vg
+			constraints.Add(pvc, localtype.VolumeTypeLVM, vg.Name)
 			u := cache.AllocatedUnit{
 				NodeName:   node.Name,
 				VolumeType: localtype.VolumeTypeLVM,
@@ -134,6 +153,8 @@ func ProcessLVMPVCPredicate(pvcs []*corev1.PersistentVolumeClaim, node *corev1.N
 				Device:     "",
 				MountPoint: "",
 				PVCName:    utils.PVCName(pvc),
+				Thin:       thin,
+				ThinClass:  class,
 			}
 			units = append(units, u)
 			break
@@ -159,6 +180,9 @@ func HandleInlineLVMVolume(ctx *algorithm.SchedulingContext, node *corev1.Node,
 			if !ok {
 				return false, units, errors.NewNoSuchVGError(vgName, node.GetName())
 			}
+			if vg.Unhealthy() {
+				return false, units, errors.NewUnhealthyStorageError(localtype.VolumeTypeLVM, vgName, string(vg.Condition), node.GetName())
+			}
 
 			freeSize := vg.Capacity - vg.Requested
 
@@ -185,6 +209,49 @@ func HandleInlineLVMVolume(ctx *algorithm.SchedulingContext, node *corev1.Node,
 	return true, units, nil
 }
 
+// candidateVGs returns a copy slice of the healthy vgs of the media type that the constraints allow to allocate to the pvc
+func candidateVGs(pvc *corev1.PersistentVolumeClaim, node *corev1.Node, cacheVGsMap map[cache.ResourceName]cache.SharedResource, mediaType localtype.MediaType, constraints *VolumeConstraints) ([]cache.SharedResource, error) {
+	vgs := vgsOfMediaType(cacheVGsMap, mediaType)
+	if len(vgs) <= 0 {
+		return nil, errors.NewNoVGOfMediaTypeError("", mediaType, node.GetName())
+	}
+	healthy := vgs[:0]
+	for _, vg := range vgs {
+		if !vg.Unhealthy() {
+			healthy = append(healthy, vg)
+		}
+	}
+	if len(healthy) <= 0 {
+		return nil, errors.NewUnhealthyStorageError(localtype.VolumeTypeLVM, "", "", node.GetName())
+	}
+	allowed := healthy[:0]
+	for _, vg := range healthy {
+		if constraints.Allows(pvc, localtype.VolumeTypeLVM, vg.Name) {
+			allowed = append(allowed, vg)
+		}
+	}
+	if len(allowed) <= 0 {
+		return nil, errors.NewVolumeConstraintError(localtype.VolumeTypeLVM, utils.PVCName(pvc), node.GetName())
+	}
+	return allowed, nil
+}
+
+// vgsOfMediaType returns a copy slice of the vgs of the media type, or all the vgs if the media type is not specified
+func vgsOfMediaType(cacheVGsMap map[cache.ResourceName]cache.SharedResource, mediaType localtype.MediaType) []cache.SharedResource {
+	vgs := make([]cache.SharedResource, 0, len(cacheVGsMap))
+	for _, vg := range cacheVGsMap {
+		if vgOfMediaType(vg, mediaType) {
+			vgs = append(vgs, vg)
+		}
+	}
+	return vgs
+}
+
+// vgOfMediaType returns true if the media type is not specified or the vg is of the media type
+func vgOfMediaType(vg cache.SharedResource, mediaType localtype.MediaType) bool {
+	return mediaType == "" || vg.MediaType == mediaType
+}
+
 // DivideLVMPVCs divide pvcs into pvcsWithVG and pvcsWithoutVG
 func DivideLVMPVCs(pvcs []*corev1.PersistentVolumeClaim, ctx *algorithm.SchedulingContext) (pvcsWithVG, pvcsWithoutVG []*corev1.PersistentVolumeClaim) {
 	for _, pvc := range pvcs {
@@ -214,7 +281,7 @@ func GetNodeVGMap(node *corev1.Node, ctx *algorithm.SchedulingContext) (cacheVGs
 
 func AllocateMountPointVolume(
 	pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node,
-	ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+	ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 
 	if len(pvcs) <= 0 {
 		return
@@ -224,12 +291,12 @@ func AllocateMountPointVolume(
 	}
 
 	log.Debugf("pvcs: %#v, node: %#v", pvcs, node)
-	fits, units, err = ProcessMPPVC(pod, pvcs, node, ctx)
+	fits, units, err = ProcessMPPVC(pod, pvcs, node, ctx, constraints)
 
 	return fits, units, err
 }
 
-func ProcessMPPVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+func ProcessMPPVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	pvcsWithTypeSSD, pvcsWithTypeHDD := DividePVCAccordingToMediaType(pvcs, ctx)
 	freeMPSSD, freeMPHDD, err := GetFreeMP(node, ctx)
 	if err != nil {
@@ -257,7 +324,7 @@ func ProcessMPPVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *c
 			node.GetName(),
 		)
 	}
-	fits, rstUnits, err := CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeMountPoint, freeMPSSD, pvcsWithTypeSSD, node, ctx)
+	fits, rstUnits, err := CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeMountPoint, freeMPSSD, pvcsWithTypeSSD, node, constraints)
 	if err != nil {
 		return false, rstUnits, err
 	}
@@ -277,7 +344,7 @@ func ProcessMPPVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *c
 			node.GetName(),
 		)
 	}
-	fits, rstUnits, err = CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeMountPoint, freeMPHDD, pvcsWithTypeHDD, node, ctx)
+	fits, rstUnits, err = CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeMountPoint, freeMPHDD, pvcsWithTypeHDD, node, constraints)
 	if err != nil {
 		return false, rstUnits, err
 	}
@@ -334,7 +401,9 @@ func GetCacheMPCount(node *corev1.Node, ctx *algorithm.SchedulingContext) (count
 	return int64(len(nodeCache.MountPoints)), nil
 }
 
-func CheckExclusiveResourceMeetsPVCSize(resource localtype.VolumeType, ers []cache.ExclusiveResource, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+// CheckExclusiveResourceMeetsPVCSize allocates the smallest resource that fits and is allowed by the constraints to each pvc,
+// from the smallest pvc to the largest one
+func CheckExclusiveResourceMeetsPVCSize(resource localtype.VolumeType, ers []cache.ExclusiveResource, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	// sort from small to large
 	sort.Slice(pvcs, func(i, j int) bool {
 		return utils.GetPVCRequested(pvcs[i]) < utils.GetPVCRequested(pvcs[j])
@@ -343,34 +412,42 @@ func CheckExclusiveResourceMeetsPVCSize(resource localtype.VolumeType, ers []cac
 		return ers[i].Capacity < ers[j].Capacity
 	})
 
-	if err != nil {
-		return false, units, err
-	}
-	disksCount := int64(len(ers))
-	pvcsCount := int64(len(pvcs))
-
-	i := 0
-	var maxSize int64 = 0
-	for j, er := range ers {
-		if pvcsCount == 0 {
+	allocated := make([]bool, len(ers))
+	for _, pvc := range pvcs {
+		requestedSize := utils.GetPVCRequested(pvc)
+		found, constrained := -1, false
+		var maxSize int64 = 0
+		for j, er := range ers {
+			if allocated[j] {
+				continue
+			}
+			if er.Capacity > maxSize {
+				maxSize = er.Capacity
+			}
+			log.Debugf("[CheckExclusiveResourceMeetsPVCSize]%s(%s) capacity=%d, pvc requestedSize=%d", er.Name, string(er.MediaType), er.Capacity, requestedSize)
+			if er.Capacity < requestedSize {
+				continue
+			}
+			if !constraints.Allows(pvc, resource, er.Name) {
+				constrained = true
+				continue
+			}
+			found = j
 			break
 		}
-		pvc := pvcs[i]
-		requestedSize := utils.GetPVCRequested(pvc)
-		if requestedSize > int64(maxSize) {
-			maxSize = requestedSize
-		}
-		log.Debugf("[CheckExclusiveResourceMeetsPVCSize]%s(%s) capacity=%d, pvc requestedSize=%d", er.Name, string(er.MediaType), er.Capacity, requestedSize)
-		if er.Capacity < requestedSize {
-			if j == int(disksCount)-1 {
-				return false, units, errors.NewInsufficientExclusiveResourceError(
-					resource,
-					requestedSize,
-					maxSize)
+		if found < 0 {
+			if constrained {
+				return false, units, errors.NewVolumeConstraintError(resource, utils.PVCName(pvc), node.GetName())
 			}
-			continue
+			return false, units, errors.NewInsufficientExclusiveResourceError(
+				resource,
+				requestedSize,
+				maxSize)
 		}
 
+		er := ers[found]
+		allocated[found] = true
+		constraints.Add(pvc, resource, er.Name)
 		u := cache.AllocatedUnit{
 			NodeName:   node.Name,
 			VolumeType: resource,
@@ -384,17 +461,13 @@ func CheckExclusiveResourceMeetsPVCSize(resource localtype.VolumeType, ers []cac
 
 		log.Debugf("found unit: %#v for pvc %#v", u, pvc)
 		units = append(units, u)
-		i++
-		if i == int(pvcsCount) {
-			break
-		}
 	}
 
 	return true, units, nil
 }
 
 func AllocateDeviceVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node,
-	ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+	ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	if len(pvcs) <= 0 {
 		return
 	}
@@ -402,12 +475,12 @@ func AllocateDeviceVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim,
 		log.Infof("allocating device volume for pod %s/%s", pod.Namespace, pod.Name)
 	}
 	log.Debugf("pvcs: %#v, node: %#v", pvcs, node)
-	fits, units, err = ProcessDevicePVC(pod, pvcs, node, ctx)
+	fits, units, err = ProcessDevicePVC(pod, pvcs, node, ctx, constraints)
 
 	return fits, units, err
 }
 
-// GetFreeDevice divide nodeCache.Devices into freeDeviceSSD and freeDeviceHDD
+// GetFreeDevice divide the healthy nodeCache.Devices into freeDeviceSSD and freeDeviceHDD
 func GetFreeDevice(node *corev1.Node, ctx *algorithm.SchedulingContext) (freeDeviceSSD, freeDeviceHDD []cache.ExclusiveResource, err error) {
 	nodeCache := ctx.ClusterNodeCache.GetNodeCache(node.Name)
 	if nodeCache == nil {
@@ -415,6 +488,10 @@ func GetFreeDevice(node *corev1.Node, ctx *algorithm.SchedulingContext) (freeDev
 	}
 
 	for _, device := range nodeCache.Devices {
+		// unhealthy devices are never allocated
+		if device.Unhealthy() {
+			continue
+		}
 		if device.MediaType == localtype.MediaTypeSSD && !device.IsAllocated {
 			freeDeviceSSD = append(freeDeviceSSD, device)
 		} else if device.MediaType == localtype.MediaTypeHDD && !device.IsAllocated {
@@ -435,7 +512,7 @@ func GetCacheDeviceCount(node *corev1.Node, ctx *algorithm.SchedulingContext) (c
 	return int64(len(nodeCache.Devices)), nil
 }
 
-func ProcessDevicePVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+func ProcessDevicePVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	pvcsWithTypeSSD, pvcsWithTypeHDD := DividePVCAccordingToMediaType(pvcs, ctx)
 	freeDeviceSSD, freeDeviceHDD, err := GetFreeDevice(node, ctx)
 	if err != nil {
@@ -463,7 +540,7 @@ func ProcessDevicePVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, nod
 			node.GetName(),
 		)
 	}
-	fits, rstUnits, err := CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeDevice, freeDeviceSSD, pvcsWithTypeSSD, node, ctx)
+	fits, rstUnits, err := CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeDevice, freeDeviceSSD, pvcsWithTypeSSD, node, constraints)
 	if err != nil {
 		return false, rstUnits, err
 	}
@@ -482,7 +559,7 @@ func ProcessDevicePVC(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, nod
 			node.GetName(),
 		)
 	}
-	fits, rstUnits, err = CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeDevice, freeDeviceHDD, pvcsWithTypeHDD, node, ctx)
+	fits, rstUnits, err = CheckExclusiveResourceMeetsPVCSize(localtype.VolumeTypeDevice, freeDeviceHDD, pvcsWithTypeHDD, node, constraints)
 	if err != nil {
 		return false, rstUnits, err
 	}
@@ -532,6 +609,60 @@ func ProcessSnapshotPVC(pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node,
 	return true, nil
 }
 
+// ProcessClonePVC checks the source volume of each clone pvc is on the node,
+// if there is no clone pvc, just return true
+func ProcessClonePVC(pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, err error) {
+	nodeName := node.Name
+	for _, pvc := range pvcs {
+		if !utils.IsClonePVC(pvc) {
+			continue
+		}
+		log.Infof("[ProcessClonePVC]data source of pvc %s/%s is pvc %s", pvc.Namespace, pvc.Name, pvc.Spec.DataSource.Name)
+		srcPVC, err := ctx.CoreV1Informers.PersistentVolumeClaims().Lister().PersistentVolumeClaims(pvc.Namespace).Get(pvc.Spec.DataSource.Name)
+		if err != nil {
+			return false, fmt.Errorf("[ProcessClonePVC]get src pvc %s/%s failed: %s", pvc.Namespace, pvc.Spec.DataSource.Name, err.Error())
+		}
+		// the clone must not be smaller than its source
+		if requested, srcRequested := utils.GetPVCRequested(pvc), utils.GetPVCRequested(srcPVC); requested < srcRequested {
+			return false, fmt.Errorf("[ProcessClonePVC]pvc %s/%s requested %d is smaller than its source pvc %s requested %d", pvc.Namespace, pvc.Name, requested, srcPVC.Name, srcRequested)
+		}
+		srcNodeName, err := getPVCNodeName(srcPVC, ctx)
+		if err != nil {
+			return false, err
+		}
+		log.Infof("[ProcessClonePVC]source node is %s", srcNodeName)
+		if srcNodeName != nodeName {
+			return false, errors.NewCloneSourceNotOnNodeError(srcNodeName, nodeName)
+		}
+	}
+
+	return true, nil
+}
+
+// getPVCNodeName returns the node of the pvc, from the selected node annotation or the node affinity of the bound pv
+func getPVCNodeName(pvc *corev1.PersistentVolumeClaim, ctx *algorithm.SchedulingContext) (string, error) {
+	if nodeName := pvc.Annotations[localtype.AnnoSelectedNode]; nodeName != "" {
+		return nodeName, nil
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", fmt.Errorf("[getPVCNodeName]pvc %s/%s is neither scheduled nor bound", pvc.Namespace, pvc.Name)
+	}
+	pv, err := ctx.CoreV1Informers.PersistentVolumes().Lister().Get(pvc.Spec.VolumeName)
+	if err != nil {
+		return "", fmt.Errorf("[getPVCNodeName]get pv %s failed: %s", pvc.Spec.VolumeName, err.Error())
+	}
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				if expr.Key == localtype.KubernetesNodeIdentityKey && len(expr.Values) > 0 {
+					return expr.Values[0], nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("[getPVCNodeName]no node found in node affinity of pv %s", pv.Name)
+}
+
 func ScoreInlineLVMVolume(pod *corev1.Pod, node *corev1.Node, ctx *algorithm.SchedulingContext) (score int, units []cache.AllocatedUnit, err error) {
 	if pod != nil {
 		log.Infof("allocating lvm volume for pod %s/%s", pod.Namespace, pod.Name)
@@ -549,11 +680,11 @@ func ScoreInlineLVMVolume(pod *corev1.Pod, node *corev1.Node, ctx *algorithm.Sch
 	if err != nil {
 		return MinScore, units, err
 	}
-	score = ScoreLVM(units, cacheVGsMap)
+	score = ScoreLVM(units, cacheVGsMap, PVCStrategies(nil, ctx))
 	return score, units, nil
 }
 
-func ScoreLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (score int, units []cache.AllocatedUnit, err error) {
+func ScoreLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (score int, units []cache.AllocatedUnit, err error) {
 	if len(pvcs) <= 0 {
 		return
 	}
@@ -561,7 +692,7 @@ func ScoreLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node
 		log.Infof("allocating lvm volume for pod %s/%s", pod.Namespace, pod.Name)
 	}
 
-	fits, units, err := ProcessLVMPVCPriority(pod, pvcs, node, ctx)
+	fits, units, err := ProcessLVMPVCPriority(pod, pvcs, node, ctx, constraints)
 	if err != nil {
 		return MinScore, units, err
 	}
@@ -573,11 +704,27 @@ func ScoreLVMVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node
 	if err != nil {
 		return MinScore, units, err
 	}
-	score = ScoreLVM(units, cacheVGsMap)
+	score = ScoreLVM(units, cacheVGsMap, PVCStrategies(pvcs, ctx))
 	return score, units, nil
 }
 
-func ProcessLVMPVCPriority(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+// PVCStrategies returns the strategy of each pvc in the current policy,
+// the default strategy is returned for the others, e.g. inline volumes
+func PVCStrategies(pvcs []*corev1.PersistentVolumeClaim, ctx *algorithm.SchedulingContext) func(pvcName string) localtype.StrategyType {
+	schedulingPolicy := ctx.Policy.Get()
+	strategies := make(map[string]localtype.StrategyType, len(pvcs))
+	for _, pvc := range pvcs {
+		strategies[utils.PVCName(pvc)] = schedulingPolicy.PVCStrategy(pvc)
+	}
+	return func(pvcName string) localtype.StrategyType {
+		if strategy, ok := strategies[pvcName]; ok {
+			return strategy
+		}
+		return schedulingPolicy.Strategy
+	}
+}
+
+func ProcessLVMPVCPriority(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	pvcsWithVG, pvcsWithoutVG := DivideLVMPVCs(pvcs, ctx)
 	cacheVGsMap, err := GetNodeVGMap(node, ctx)
 	if err != nil {
@@ -587,15 +734,28 @@ func ProcessLVMPVCPriority(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim
 	// process pvcsWithVG first
 	for _, pvc := range pvcsWithVG {
 		vgName := utils.GetVGNameFromPVC(pvc, ctx.StorageV1Informers)
-		if _, ok := cacheVGsMap[cache.ResourceName(vgName)]; !ok {
+		vg, ok := cacheVGsMap[cache.ResourceName(vgName)]
+		if !ok {
 			return false, units, fmt.Errorf("no vg named %s on node %s", vgName, node.Name)
 		}
+		if vg.Unhealthy() {
+			return false, units, errors.NewUnhealthyStorageError(localtype.VolumeTypeLVM, vgName, string(vg.Condition), node.GetName())
+		}
+		if mediaType := utils.GetMediaTypeFromPVC(pvc, ctx.StorageV1Informers); !vgOfMediaType(vg, mediaType) {
+			return false, units, errors.NewNoVGOfMediaTypeError(vgName, mediaType, node.GetName())
+		}
+		if !constraints.Allows(pvc, localtype.VolumeTypeLVM, vgName) {
+			return false, units, errors.NewVolumeConstraintError(localtype.VolumeTypeLVM, utils.PVCName(pvc), node.GetName())
+		}
 
 		requestedSize := utils.GetPVCRequested(pvc)
-		freeSize := cacheVGsMap[cache.ResourceName(vgName)].Capacity - cacheVGsMap[cache.ResourceName(vgName)].Requested
+		thin := utils.IsThinLVMPVC(pvc, ctx.StorageV1Informers)
+		class := thinClassOfPVC(pvc, ctx)
+		needed := constraints.Requested(pvc)
+		freeSize := cache.VGFree(cacheVGsMap[cache.ResourceName(vgName)], thin, class)
 		quanFree := resource.NewQuantity(freeSize, resource.BinarySI)
-		quanReq := resource.NewQuantity(requestedSize, resource.BinarySI)
-		if freeSize < requestedSize {
+		quanReq := resource.NewQuantity(needed, resource.BinarySI)
+		if freeSize < needed {
 			if pod == nil {
 				return false, units, fmt.Errorf("not enough lv storage on %s/%s, requested size %s,  free size %s",
 					node.Name, vgName, quanReq.String(), quanFree.String())
@@ -604,8 +764,9 @@ func ProcessLVMPVCPriority(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim
 				node.Name, vgName, pod.Namespace, pod.Name, quanReq.String(), quanFree.String())
 		}
 		tmp := cacheVGsMap[cache.ResourceName(vgName)]
-		tmp.Requested += requestedSize
+		cache.AddVGRequested(&tmp, requestedSize, thin)
 		cacheVGsMap[cache.ResourceName(vgName)] = tmp
+		constraints.Add(pvc, localtype.VolumeTypeLVM, vgName)
 		u := cache.AllocatedUnit{
 			NodeName:   node.Name,
 			VolumeType: localtype.VolumeTypeLVM,
@@ -615,21 +776,28 @@ func ProcessLVMPVCPriority(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim
 			Device:     "",
 			MountPoint: "",
 			PVCName:    utils.PVCName(pvc),
+			Thin:       thin,
+			ThinClass:  class,
 		}
 		units = append(units, u)
 	}
 
-	// process pvcsWithoutVG(default strategy: Binpack)
+	// process pvcsWithoutVG according to the strategy of their storage classes
+	schedulingPolicy := ctx.Policy.Get()
 	for _, pvc := range pvcsWithoutVG {
-		switch localtype.SchedulerStrategy {
-		case localtype.StrategyBinpack:
-			fits, tmpunits, err := Binpack(pod, pvc, node, cacheVGsMap)
+		thin := utils.IsThinLVMPVC(pvc, ctx.StorageV1Informers)
+		class := thinClassOfPVC(pvc, ctx)
+		mediaType := utils.GetMediaTypeFromPVC(pvc, ctx.StorageV1Informers)
+		switch schedulingPolicy.PVCStrategy(pvc) {
+		// spread picks the VG with the most free space, which is what most-free-vg does too
+		case localtype.StrategySpread, localtype.StrategyMostFreeVG:
+			fits, tmpunits, err := Spread(pod, pvc, node, cacheVGsMap, thin, class, mediaType, constraints)
 			if !fits {
 				return false, units, err
 			}
 			units = append(units, tmpunits...)
-		case localtype.StrategySpread:
-			fits, tmpunits, err := Spread(pod, pvc, node, cacheVGsMap)
+		default:
+			fits, tmpunits, err := Binpack(pod, pvc, node, cacheVGsMap, thin, class, mediaType, constraints)
 			if !fits {
 				return false, units, err
 			}
@@ -642,38 +810,50 @@ func ProcessLVMPVCPriority(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim
 	return true, units, nil
 }
 
-func Binpack(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, node *corev1.Node, cacheVGsMap map[cache.ResourceName]cache.SharedResource) (fits bool, units []cache.AllocatedUnit, err error) {
+// thinClassOfPVC returns how the pvc is provisioned if it is a thin LVM volume
+func thinClassOfPVC(pvc *corev1.PersistentVolumeClaim, ctx *algorithm.SchedulingContext) cache.ThinClass {
+	var parameters map[string]string
+	if sc := utils.GetStorageClassFromPVC(pvc, ctx.StorageV1Informers); sc != nil {
+		parameters = sc.Parameters
+	}
+	return cache.NewThinClass(parameters, ctx.Policy.Get().PVCThinOvercommitRatio(pvc))
+}
+
+// Binpack allocates the pvc from the vg of the media type with the least free size, any vg if the media type is empty,
+// among the vgs allowed by the constraints
+func Binpack(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, node *corev1.Node, cacheVGsMap map[cache.ResourceName]cache.SharedResource, thin bool, class cache.ThinClass, mediaType localtype.MediaType, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	requestedSize := utils.GetPVCRequested(pvc)
+	needed := constraints.Requested(pvc)
 
-	// make a copy slice of cacheVGsMap
-	cacheVGsSlice := make([]cache.SharedResource, len(cacheVGsMap))
-	for _, vg := range cacheVGsMap {
-		cacheVGsSlice = append(cacheVGsSlice, vg)
+	cacheVGsSlice, err := candidateVGs(pvc, node, cacheVGsMap, mediaType, constraints)
+	if err != nil {
+		return false, units, err
 	}
 
 	// sort from small to large according to free size
 	sort.Slice(cacheVGsSlice, func(i, j int) bool {
-		return (cacheVGsSlice[i].Capacity - cacheVGsSlice[i].Requested) < (cacheVGsSlice[j].Capacity - cacheVGsSlice[j].Requested)
+		return cache.VGFree(cacheVGsSlice[i], thin, class) < cache.VGFree(cacheVGsSlice[j], thin, class)
 	})
 	for i, vg := range cacheVGsSlice {
-		freeSize := vg.Capacity - vg.Requested
+		freeSize := cache.VGFree(vg, thin, class)
 		quanFree := resource.NewQuantity(freeSize, resource.BinarySI)
-		quanReq := resource.NewQuantity(requestedSize, resource.BinarySI)
-		if freeSize < requestedSize {
+		quanReq := resource.NewQuantity(needed, resource.BinarySI)
+		if freeSize < needed {
 			if i == len(cacheVGsSlice)-1 {
 				if pod == nil {
 					return false, units, fmt.Errorf("[multipleVGs]not enough lv storage on %s, requested size %s, max free size[VG: %s] %s, strategiy %s. you need to expand the vg",
-						node.Name, quanReq.String(), vg.Name, quanFree.String(), localtype.SchedulerStrategy)
+						node.Name, quanReq.String(), vg.Name, quanFree.String(), localtype.StrategyBinpack)
 
 				}
 				return false, units, fmt.Errorf("[multipleVGs]not enough lv storage on %s for pod %s/%s, requested size %s, max free size[VG: %s] %s, strategiy %s. you need to expand the vg",
-					node.Name, pod.Namespace, pod.Name, quanReq.String(), vg.Name, quanFree.String(), localtype.SchedulerStrategy)
+					node.Name, pod.Namespace, pod.Name, quanReq.String(), vg.Name, quanFree.String(), localtype.StrategyBinpack)
 			}
 			continue
 		}
 		tmp := cacheVGsMap[cache.ResourceName(vg.Name)]
-		tmp.Requested += requestedSize
+		cache.AddVGRequested(&tmp, requestedSize, thin)
 		cacheVGsMap[cache.ResourceName(vg.Name)] = tmp
+		constraints.Add(pvc, localtype.VolumeTypeLVM, vg.Name)
 		u := cache.AllocatedUnit{
 			NodeName:   node.Name,
 			VolumeType: localtype.VolumeTypeLVM,
@@ -683,6 +863,8 @@ func Binpack(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, node *corev1.No
 			Device:     "",
 			MountPoint: "",
 			PVCName:    utils.PVCName(pvc),
+			Thin:       thin,
+			ThinClass:  class,
 		}
 		units = append(units, u)
 		break
@@ -691,32 +873,36 @@ func Binpack(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, node *corev1.No
 	return true, units, nil
 }
 
-func Spread(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, node *corev1.Node, cacheVGsMap map[cache.ResourceName]cache.SharedResource) (fits bool, units []cache.AllocatedUnit, err error) {
+// Spread allocates the pvc from the vg of the media type with the most free size, any vg if the media type is empty,
+// among the vgs allowed by the constraints
+func Spread(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, node *corev1.Node, cacheVGsMap map[cache.ResourceName]cache.SharedResource, thin bool, class cache.ThinClass, mediaType localtype.MediaType, constraints *VolumeConstraints) (fits bool, units []cache.AllocatedUnit, err error) {
 	requestedSize := utils.GetPVCRequested(pvc)
+	needed := constraints.Requested(pvc)
 
-	// make a copy slice of cacheVGsMap
-	cacheVGsSlice := make([]cache.SharedResource, len(cacheVGsMap))
-	for _, vg := range cacheVGsMap {
-		cacheVGsSlice = append(cacheVGsSlice, vg)
+	cacheVGsSlice, err := candidateVGs(pvc, node, cacheVGsMap, mediaType, constraints)
+	if err != nil {
+		return false, units, err
 	}
 
 	// sort from large to small according to free size
 	sort.Slice(cacheVGsSlice, func(i, j int) bool {
-		return (cacheVGsSlice[i].Capacity - cacheVGsSlice[i].Requested) > (cacheVGsSlice[j].Capacity - cacheVGsSlice[j].Requested)
+		return cache.VGFree(cacheVGsSlice[i], thin, class) > cache.VGFree(cacheVGsSlice[j], thin, class)
 	})
 	// the free size of cacheVGsSlice[0] is largest
-	freeSize := cacheVGsSlice[0].Capacity - cacheVGsSlice[0].Requested
+	freeSize := cache.VGFree(cacheVGsSlice[0], thin, class)
 	quanFree := resource.NewQuantity(freeSize, resource.BinarySI)
-	quanReq := resource.NewQuantity(requestedSize, resource.BinarySI)
-	if freeSize < requestedSize {
+	quanReq := resource.NewQuantity(needed, resource.BinarySI)
+	if freeSize < needed {
 		if pod == nil {
 			return false, units, fmt.Errorf("[multipleVGs]not enough lv storage on %s/%s, requested size %s,  free size %s, strategiy %s. you need to expand the vg",
-				node.Name, cacheVGsSlice[0].Name, quanReq.String(), quanFree.String(), localtype.SchedulerStrategy)
+				node.Name, cacheVGsSlice[0].Name, quanReq.String(), quanFree.String(), localtype.StrategySpread)
 		}
 		return false, units, fmt.Errorf("[multipleVGs]not enough lv storage on %s/%s for pod %s/%s, requested size %s,  free size %s, strategiy %s. you need to expand the vg",
-			node.Name, cacheVGsSlice[0].Name, pod.Namespace, pod.Name, quanReq.String(), quanFree.String(), localtype.SchedulerStrategy)
+			node.Name, cacheVGsSlice[0].Name, pod.Namespace, pod.Name, quanReq.String(), quanFree.String(), localtype.StrategySpread)
 	}
-	cacheVGsSlice[0].Requested += requestedSize
+	cache.AddVGRequested(&cacheVGsSlice[0], requestedSize, thin)
+	cacheVGsMap[cache.ResourceName(cacheVGsSlice[0].Name)] = cacheVGsSlice[0]
+	constraints.Add(pvc, localtype.VolumeTypeLVM, cacheVGsSlice[0].Name)
 	u := cache.AllocatedUnit{
 		NodeName:   node.Name,
 		VolumeType: localtype.VolumeTypeLVM,
@@ -726,52 +912,73 @@ func Spread(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, node *corev1.Nod
 		Device:     "",
 		MountPoint: "",
 		PVCName:    utils.PVCName(pvc),
+		Thin:       thin,
+		ThinClass:  class,
 	}
 	units = append(units, u)
 
 	return true, units, nil
 }
 
-func ScoreLVM(units []cache.AllocatedUnit, cacheVGsMap map[cache.ResourceName]cache.SharedResource) (score int) {
+// ScoreLVM scores the units by the strategy of their pvcs returned by strategyOf
+func ScoreLVM(units []cache.AllocatedUnit, cacheVGsMap map[cache.ResourceName]cache.SharedResource, strategyOf func(pvcName string) localtype.StrategyType) (score int) {
 	if len(units) == 0 {
 		return MinScore
 	}
 	// make a map store VG size pvcs used
-	// key: VG name
+	// key: VG name and strategy
 	// value: used size
-	scoreMap := make(map[string]int64)
+	scoreMap := make(map[strategyResource]int64)
 	for _, unit := range units {
-		if size, ok := scoreMap[unit.VgName]; ok {
-			size += unit.Allocated
-			scoreMap[unit.VgName] = size
-		} else {
-			scoreMap[unit.VgName] = unit.Allocated
-		}
+		scoreMap[strategyResource{name: unit.VgName, strategy: strategyOf(unit.PVCName), thin: unit.Thin, class: unit.ThinClass}] += unit.Allocated
 	}
 
 	// score
 	var scoref float64 = 0
 	count := 0
-	switch localtype.SchedulerStrategy {
-	case localtype.StrategyBinpack:
-		for vg, used := range scoreMap {
-			scoref += float64(used) / float64(cacheVGsMap[cache.ResourceName(vg)].Capacity)
-			count++
-		}
-	case localtype.StrategySpread:
-		for vg, used := range scoreMap {
-			scoref += (1.0 - float64(used)/float64(cacheVGsMap[cache.ResourceName(vg)].Capacity))
-			count++
-		}
+	for key, used := range scoreMap {
+		vg := cacheVGsMap[cache.ResourceName(key.name)]
+		// thin volumes are scored by the thin pool of vg
+		scoref += strategyScore(key.strategy, used, cache.SharedResource{Name: vg.Name, Capacity: cache.VGCapacity(vg, key.thin, key.class), Requested: cache.VGRequested(vg, key.thin)})
+		count++
 	}
 	score = int(scoref / float64(count) * float64(MaxScore))
 
 	return
 }
 
+// strategyResource is a shared resource allocated to pvcs of a strategy
+type strategyResource struct {
+	name     string
+	strategy localtype.StrategyType
+	thin     bool
+	class    cache.ThinClass
+}
+
+// strategyScore returns the score in [0, 1] of allocating used size from the shared resource:
+// binpack prefers the resource mostly filled by the allocation, spread prefers the one least filled,
+// and most-free-vg prefers the one left with the most free space
+func strategyScore(strategy localtype.StrategyType, used int64, resource cache.SharedResource) float64 {
+	if resource.Capacity <= 0 {
+		return 0
+	}
+	switch strategy {
+	case localtype.StrategySpread:
+		return 1.0 - float64(used)/float64(resource.Capacity)
+	case localtype.StrategyMostFreeVG:
+		free := resource.Capacity - resource.Requested - used
+		if free < 0 {
+			return 0
+		}
+		return float64(free) / float64(resource.Capacity)
+	default:
+		return float64(used) / float64(resource.Capacity)
+	}
+}
+
 func ScoreMountPointVolume(
 	pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node,
-	ctx *algorithm.SchedulingContext) (score int, units []cache.AllocatedUnit, err error) {
+	ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (score int, units []cache.AllocatedUnit, err error) {
 
 	if len(pvcs) <= 0 {
 		return
@@ -781,7 +988,7 @@ func ScoreMountPointVolume(
 	}
 
 	log.Debugf("pvcs: %#v, node: %#v", pvcs, node)
-	fits, units, err := ProcessMPPVC(pod, pvcs, node, ctx)
+	fits, units, err := ProcessMPPVC(pod, pvcs, node, ctx, constraints)
 	if err != nil {
 		return MinScore, units, err
 	}
@@ -808,7 +1015,7 @@ func ScoreMP(units []cache.AllocatedUnit) (score int) {
 
 func ScoreDeviceVolume(
 	pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node,
-	ctx *algorithm.SchedulingContext) (score int, units []cache.AllocatedUnit, err error) {
+	ctx *algorithm.SchedulingContext, constraints *VolumeConstraints) (score int, units []cache.AllocatedUnit, err error) {
 	if len(pvcs) <= 0 {
 		return
 	}
@@ -818,7 +1025,7 @@ func ScoreDeviceVolume(
 
 	log.Debugf("pvcs: %#v, node: %#v", pvcs, node)
 
-	fits, units, err := ProcessDevicePVC(pod, pvcs, node, ctx)
+	fits, units, err := ProcessDevicePVC(pod, pvcs, node, ctx, constraints)
 	if err != nil {
 		return MinScore, units, err
 	}
@@ -844,3 +1051,153 @@ func ScoreDevice(units []cache.AllocatedUnit) (score int) {
 	score = int(scoref / float64(len(units)) * float64(MaxScore))
 	return score
 }
+
+// AllocateQuotaVolume contains two policy: BINPACK/SPREAD
+func AllocateQuotaVolume(pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node,
+	ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+	if len(pvcs) <= 0 {
+		return
+	}
+	if pod != nil {
+		log.Infof("allocating quota volume for pod %s/%s", pod.Namespace, pod.Name)
+	}
+	log.Debugf("pvcs: %#v, node: %#v", pvcs, node)
+	fits, units, err = ProcessQuotaPVC(pvcs, node, ctx)
+
+	return fits, units, err
+}
+
+// ProcessQuotaPVC picks a quota mount point for each pvc according to the strategy of its storage class
+func ProcessQuotaPVC(pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) (fits bool, units []cache.AllocatedUnit, err error) {
+	cacheQuotasMap, err := GetNodeQuotaMap(node, ctx)
+	if err != nil {
+		return false, units, err
+	}
+
+	schedulingPolicy := ctx.Policy.Get()
+	for _, pvc := range pvcs {
+		requestedSize := utils.GetPVCRequested(pvc)
+
+		cacheQuotasSlice := make([]cache.SharedResource, 0, len(cacheQuotasMap))
+		for _, quota := range cacheQuotasMap {
+			cacheQuotasSlice = append(cacheQuotasSlice, quota)
+		}
+		switch schedulingPolicy.PVCStrategy(pvc) {
+		case localtype.StrategySpread, localtype.StrategyMostFreeVG:
+			// sort from large to small according to free size
+			sort.Slice(cacheQuotasSlice, func(i, j int) bool {
+				return (cacheQuotasSlice[i].Capacity - cacheQuotasSlice[i].Requested) > (cacheQuotasSlice[j].Capacity - cacheQuotasSlice[j].Requested)
+			})
+		default:
+			// sort from small to large according to free size
+			sort.Slice(cacheQuotasSlice, func(i, j int) bool {
+				return (cacheQuotasSlice[i].Capacity - cacheQuotasSlice[i].Requested) < (cacheQuotasSlice[j].Capacity - cacheQuotasSlice[j].Requested)
+			})
+		}
+
+		var maxFree int64 = 0
+		var selected *cache.SharedResource
+		for i, quota := range cacheQuotasSlice {
+			freeSize := quota.Capacity - quota.Requested
+			log.Debugf("validating quota mount point(name=%s,free=%d) for pvc(name=%s,requested=%d)", quota.Name, freeSize, pvc.Name, requestedSize)
+			if freeSize > maxFree {
+				maxFree = freeSize
+			}
+			if freeSize < requestedSize {
+				continue
+			}
+			selected = &cacheQuotasSlice[i]
+			break
+		}
+		if selected == nil {
+			return false, units, errors.NewInsufficientQuotaError(requestedSize, maxFree, node.GetName())
+		}
+
+		tmp := cacheQuotasMap[cache.ResourceName(selected.Name)]
+		tmp.Requested += requestedSize
+		cacheQuotasMap[cache.ResourceName(selected.Name)] = tmp
+		u := cache.AllocatedUnit{
+			NodeName:   node.Name,
+			VolumeType: localtype.VolumeTypeQuota,
+			Requested:  requestedSize,
+			Allocated:  requestedSize, // for Quota requested is always equal to allocated
+			VgName:     "",
+			Device:     "",
+			MountPoint: selected.Name,
+			PVCName:    utils.PVCName(pvc),
+		}
+		units = append(units, u)
+	}
+
+	log.Debugf("node %s is capable of quota %d pvcs", node.Name, len(pvcs))
+	return true, units, nil
+}
+
+// GetNodeQuotaMap make a copy map of NodeCache Quotas
+func GetNodeQuotaMap(node *corev1.Node, ctx *algorithm.SchedulingContext) (cacheQuotasMap map[cache.ResourceName]cache.SharedResource, err error) {
+	nodeCache := ctx.ClusterNodeCache.GetNodeCache(node.Name)
+	if nodeCache == nil {
+		return nil, fmt.Errorf("node %s not found from cache", node.Name)
+	}
+
+	cacheQuotasMap = make(map[cache.ResourceName]cache.SharedResource, len(nodeCache.Quotas))
+	for k, v := range nodeCache.Quotas {
+		cacheQuotasMap[k] = v
+	}
+
+	return
+}
+
+func ScoreQuotaVolume(
+	pod *corev1.Pod, pvcs []*corev1.PersistentVolumeClaim, node *corev1.Node,
+	ctx *algorithm.SchedulingContext) (score int, units []cache.AllocatedUnit, err error) {
+	if len(pvcs) <= 0 {
+		return
+	}
+	if pod != nil {
+		log.Infof("allocating quota volume for pod %s/%s", pod.Namespace, pod.Name)
+	}
+
+	fits, units, err := ProcessQuotaPVC(pvcs, node, ctx)
+	if err != nil {
+		return MinScore, units, err
+	}
+	if !fits {
+		return MinScore, units, nil
+	}
+
+	cacheQuotasMap, err := GetNodeQuotaMap(node, ctx)
+	if err != nil {
+		return MinScore, units, err
+	}
+	score = ScoreQuota(units, cacheQuotasMap, PVCStrategies(pvcs, ctx))
+	return score, units, nil
+}
+
+// ScoreQuota scores the units by the strategy of their pvcs returned by strategyOf
+func ScoreQuota(units []cache.AllocatedUnit, cacheQuotasMap map[cache.ResourceName]cache.SharedResource, strategyOf func(pvcName string) localtype.StrategyType) (score int) {
+	if len(units) == 0 {
+		return MinScore
+	}
+	// make a map store quota mount point size pvcs used
+	// key: mount point name and strategy
+	// value: used size
+	scoreMap := make(map[strategyResource]int64)
+	for _, unit := range units {
+		scoreMap[strategyResource{name: unit.MountPoint, strategy: strategyOf(unit.PVCName)}] += unit.Allocated
+	}
+
+	// score
+	var scoref float64 = 0
+	count := 0
+	for key, used := range scoreMap {
+		scoref += strategyScore(key.strategy, used, cacheQuotasMap[cache.ResourceName(key.name)])
+		count++
+	}
+	if count == 0 {
+		return MinScore
+	}
+	score = int(scoref / float64(count) * float64(MaxScore))
+
+	return
+}
@@ -0,0 +1,386 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algo
+
+import (
+	"reflect"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	localinformers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/errors"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	volumesnapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLVMMediaType(t *testing.T) {
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0)
+	localInformerFactory := localinformers.NewSharedInformerFactory(localfake.NewSimpleClientset(), 0)
+	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(volumesnapshotfake.NewSimpleClientset(), 0)
+	ctx := algorithm.NewSchedulingContext(kubeInformerFactory.Core().V1(), kubeInformerFactory.Storage().V1(),
+		localInformerFactory.Csi().V1alpha1(), snapshotInformerFactory.Snapshot().V1beta1(), policy.NewDefaultPolicy(localtype.StrategyBinpack))
+
+	storageClasses := map[string]map[string]string{
+		"lvm":        {localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM)},
+		"lvm-ssd":    {localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VolumeMediaType: string(localtype.MediaTypeSSD)},
+		"lvm-hdd":    {localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VolumeMediaType: string(localtype.MediaTypeHDD)},
+		"lvm-vg-ssd": {localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VolumeMediaType: string(localtype.MediaTypeSSD), localtype.VGName: "hdd"},
+	}
+	for name, params := range storageClasses {
+		_ = ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: localtype.ProvisionerName,
+			Parameters:  params,
+		})
+	}
+	// the hdd vg has the least free size to be picked by binpack
+	nc := cache.NewNodeCache("node1")
+	nc.VGs["ssd"] = cache.SharedResource{Name: "ssd", Capacity: 100 << 30, MediaType: localtype.MediaTypeSSD}
+	nc.VGs["hdd"] = cache.SharedResource{Name: "hdd", Capacity: 50 << 30, MediaType: localtype.MediaTypeHDD}
+	ctx.ClusterNodeCache.SetNodeCache(nc)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	tests := []struct {
+		storageClass string
+		vg           string
+		fits         bool
+	}{
+		{"lvm", "hdd", true},
+		{"lvm-ssd", "ssd", true},
+		{"lvm-hdd", "hdd", true},
+		{"lvm-vg-ssd", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.storageClass, func(t *testing.T) {
+			scName := tt.storageClass
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					StorageClassName: &scName,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				},
+			}
+			pvcs := []*corev1.PersistentVolumeClaim{pvc}
+			fits, units, err := ProcessLVMPVCPredicate(pvcs, node, ctx, NewVolumeConstraints(pvcs, nil, nil, node, ctx))
+			if fits != tt.fits || (tt.fits && (len(units) != 1 || units[0].VgName != tt.vg)) {
+				t.Errorf("ProcessLVMPVCPredicate() = %t, %+v, %v, want vg %q", fits, units, err, tt.vg)
+			}
+			if !tt.fits {
+				if _, ok := err.(*errors.NoVGOfMediaTypeError); !ok {
+					t.Errorf("expect NoVGOfMediaTypeError, got %v", err)
+				}
+			}
+			fits, units, err = ProcessLVMPVCPriority(pod, pvcs, node, ctx, NewVolumeConstraints(pvcs, nil, nil, node, ctx))
+			if fits != tt.fits || (tt.fits && (len(units) != 1 || units[0].VgName != tt.vg)) {
+				t.Errorf("ProcessLVMPVCPriority() = %t, %+v, %v, want vg %q", fits, units, err, tt.vg)
+			}
+		})
+	}
+
+	// no hdd vg on node2
+	nc = cache.NewNodeCache("node2")
+	nc.VGs["ssd"] = cache.SharedResource{Name: "ssd", Capacity: 100 << 30, MediaType: localtype.MediaTypeSSD}
+	ctx.ClusterNodeCache.SetNodeCache(nc)
+	scName := "lvm-hdd"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &scName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+	}
+	node2 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}
+	pvcs := []*corev1.PersistentVolumeClaim{pvc}
+	if fits, _, err := ProcessLVMPVCPredicate(pvcs, node2, ctx, NewVolumeConstraints(pvcs, nil, nil, node2, ctx)); fits || err == nil {
+		t.Errorf("expect hdd pvc not fit node2, got %t, %v", fits, err)
+	}
+	if fits, _, err := ProcessLVMPVCPriority(pod, pvcs, node2, ctx, NewVolumeConstraints(pvcs, nil, nil, node2, ctx)); fits || err == nil {
+		t.Errorf("expect hdd pvc not fit node2, got %t, %v", fits, err)
+	}
+}
+
+func TestVolumeConstraints(t *testing.T) {
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0)
+	localInformerFactory := localinformers.NewSharedInformerFactory(localfake.NewSimpleClientset(), 0)
+	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(volumesnapshotfake.NewSimpleClientset(), 0)
+	ctx := algorithm.NewSchedulingContext(kubeInformerFactory.Core().V1(), kubeInformerFactory.Storage().V1(),
+		localInformerFactory.Csi().V1alpha1(), snapshotInformerFactory.Snapshot().V1beta1(), policy.NewDefaultPolicy(localtype.StrategyBinpack))
+
+	for name, params := range map[string]map[string]string{
+		"lvm":    {localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM)},
+		"device": {localtype.VolumeTypeKey: string(localtype.VolumeTypeDevice), localtype.VolumeMediaType: string(localtype.MediaTypeHDD)},
+	} {
+		_ = ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: localtype.ProvisionerName,
+			Parameters:  params,
+		})
+	}
+	// vg a and b are on partitions of disk sdb, and vg c is on disk sdc
+	nls := &localv1alpha1.NodeLocalStorage{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	nls.Status.NodeStorageInfo.DeviceInfos = []localv1alpha1.DeviceInfo{
+		{Name: "/dev/sdb"}, {Name: "/dev/sdb1", Parent: "/dev/sdb"}, {Name: "/dev/sdb2", Parent: "/dev/sdb"}, {Name: "/dev/sdc"},
+	}
+	nls.Status.NodeStorageInfo.VolumeGroups = []localv1alpha1.VolumeGroup{
+		{Name: "a", PhysicalVolumes: []string{"/dev/sdb1"}},
+		{Name: "b", PhysicalVolumes: []string{"/dev/sdb2"}},
+		{Name: "c", PhysicalVolumes: []string{"/dev/sdc"}},
+	}
+	_ = ctx.LocalStorageInformer.NodeLocalStorages().Informer().GetIndexer().Add(nls)
+	nc := cache.NewNodeCache("node1")
+	nc.VGs["a"] = cache.SharedResource{Name: "a", Capacity: 10 << 30}
+	nc.VGs["b"] = cache.SharedResource{Name: "b", Capacity: 20 << 30}
+	nc.VGs["c"] = cache.SharedResource{Name: "c", Capacity: 30 << 30}
+	nc.Devices["/dev/sdb1"] = cache.ExclusiveResource{Name: "/dev/sdb1", Capacity: 10 << 30, MediaType: localtype.MediaTypeHDD}
+	nc.Devices["/dev/sdb2"] = cache.ExclusiveResource{Name: "/dev/sdb2", Capacity: 10 << 30, MediaType: localtype.MediaTypeHDD}
+	nc.Devices["/dev/sdc"] = cache.ExclusiveResource{Name: "/dev/sdc", Capacity: 20 << 30, MediaType: localtype.MediaTypeHDD}
+	ctx.ClusterNodeCache.SetNodeCache(nc)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	newPVC := func(name, scName, size string, annotations map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &scName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+				},
+			},
+		}
+	}
+	colocation := map[string]string{localtype.AnnotationVolumeColocation: "db"}
+	separation := map[string]string{localtype.AnnotationVolumeSeparation: "db"}
+	deviceSeparation := map[string]string{localtype.AnnotationDeviceSeparation: "db"}
+	vgsOf := func(units []cache.AllocatedUnit) map[string]string {
+		vgs := make(map[string]string)
+		for _, u := range units {
+			vgs[u.PVCName] = u.VgName + u.Device
+		}
+		return vgs
+	}
+
+	tests := []struct {
+		name string
+		pvcs []*corev1.PersistentVolumeClaim
+		// want is the vg or device of each pvc, nil if not fits
+		want map[string]string
+	}{
+		{
+			name: "binpack without constraints",
+			pvcs: []*corev1.PersistentVolumeClaim{newPVC("data", "lvm", "5Gi", nil), newPVC("wal", "lvm", "5Gi", nil)},
+			want: map[string]string{"default/data": "a", "default/wal": "a"},
+		},
+		{
+			name: "co-location group fits in one vg",
+			pvcs: []*corev1.PersistentVolumeClaim{newPVC("data", "lvm", "8Gi", colocation), newPVC("wal", "lvm", "8Gi", colocation)},
+			want: map[string]string{"default/data": "b", "default/wal": "b"},
+		},
+		{
+			name: "separated vgs",
+			pvcs: []*corev1.PersistentVolumeClaim{newPVC("data", "lvm", "5Gi", separation), newPVC("wal", "lvm", "5Gi", separation)},
+			want: map[string]string{"default/data": "a", "default/wal": "b"},
+		},
+		{
+			name: "separated disks",
+			pvcs: []*corev1.PersistentVolumeClaim{newPVC("data", "lvm", "5Gi", deviceSeparation), newPVC("wal", "lvm", "5Gi", deviceSeparation)},
+			want: map[string]string{"default/data": "a", "default/wal": "c"},
+		},
+		{
+			name: "no more disks",
+			pvcs: []*corev1.PersistentVolumeClaim{newPVC("data", "lvm", "5Gi", deviceSeparation), newPVC("wal", "lvm", "5Gi", deviceSeparation), newPVC("log", "lvm", "5Gi", deviceSeparation)},
+		},
+		{
+			name: "devices on separated disks",
+			pvcs: []*corev1.PersistentVolumeClaim{newPVC("data", "device", "5Gi", deviceSeparation), newPVC("wal", "device", "6Gi", deviceSeparation)},
+			want: map[string]string{"default/data": "/dev/sdb1", "default/wal": "/dev/sdc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fits bool
+			var units []cache.AllocatedUnit
+			var err error
+			if *tt.pvcs[0].Spec.StorageClassName == "device" {
+				fits, units, err = ProcessDevicePVC(pod, tt.pvcs, node, ctx, NewVolumeConstraints(nil, nil, tt.pvcs, node, ctx))
+			} else {
+				fits, units, err = ProcessLVMPVCPredicate(tt.pvcs, node, ctx, NewVolumeConstraints(tt.pvcs, nil, nil, node, ctx))
+			}
+			if tt.want == nil {
+				if _, ok := err.(*errors.VolumeConstraintError); fits || !ok {
+					t.Errorf("expect VolumeConstraintError, got %t, %+v, %v", fits, units, err)
+				}
+				return
+			}
+			if got := vgsOf(units); !fits || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("predicate got %t, %v, %v, want %v", fits, got, err, tt.want)
+			}
+			if *tt.pvcs[0].Spec.StorageClassName == "device" {
+				return
+			}
+			fits, units, err = ProcessLVMPVCPriority(pod, tt.pvcs, node, ctx, NewVolumeConstraints(tt.pvcs, nil, nil, node, ctx))
+			if got := vgsOf(units); !fits || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("priority got %t, %v, %v, want %v", fits, got, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("lvm and device pvcs on separated disks", func(t *testing.T) {
+		lvm := []*corev1.PersistentVolumeClaim{newPVC("data", "lvm", "5Gi", deviceSeparation)}
+		devices := []*corev1.PersistentVolumeClaim{newPVC("wal", "device", "5Gi", deviceSeparation)}
+		constraints := NewVolumeConstraints(lvm, nil, devices, node, ctx)
+		fits, lvmUnits, err := ProcessLVMPVCPredicate(lvm, node, ctx, constraints)
+		if err != nil || !fits {
+			t.Fatalf("ProcessLVMPVCPredicate() = %t, %+v, %v", fits, lvmUnits, err)
+		}
+		fits, deviceUnits, err := ProcessDevicePVC(pod, devices, node, ctx, constraints)
+		want := map[string]string{"default/data": "a", "default/wal": "/dev/sdc"}
+		if got := vgsOf(append(lvmUnits, deviceUnits...)); !fits || !reflect.DeepEqual(got, want) {
+			t.Errorf("got %t, %v, %v, want %v", fits, got, err, want)
+		}
+	})
+
+	// the bound member of the groups holds vg a on disk sdb
+	bound := newPVC("data", "lvm", "5Gi", map[string]string{
+		localtype.AnnotationVolumeColocation: "db",
+		localtype.AnnotationDeviceSeparation: "db",
+	})
+	bound.Spec.VolumeName = "pv-data"
+	bound.Status.Phase = corev1.ClaimBound
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+		Spec: corev1.PersistentVolumeSpec{
+			ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "data"},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+				Driver:           localtype.ProvisionerName,
+				VolumeAttributes: map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VGName: "a"},
+			}},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: localtype.KubernetesNodeIdentityKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"node1"}}},
+			}}}},
+		},
+	}
+	memberPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "member", Namespace: "default"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+			{Name: "wal", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "wal"}}},
+		}},
+	}
+	_ = ctx.CoreV1Informers.PersistentVolumeClaims().Informer().GetIndexer().Add(bound)
+	_ = ctx.CoreV1Informers.PersistentVolumes().Informer().GetIndexer().Add(pv)
+	_ = ctx.CoreV1Informers.Pods().Informer().GetIndexer().Add(memberPod)
+	ctx.ClusterNodeCache.PvcMapping.PvcPod["default/wal"] = "default/member"
+	for name, annotations := range map[string]map[string]string{"colocation": colocation, "device separation": deviceSeparation} {
+		t.Run("bound member with "+name, func(t *testing.T) {
+			wal := newPVC("wal", "lvm", "5Gi", annotations)
+			constraints := NewVolumeConstraints([]*corev1.PersistentVolumeClaim{wal}, nil, nil, node, ctx)
+			for vg, allowed := range map[string]bool{"a": name == "colocation", "b": false, "c": name != "colocation"} {
+				if got := constraints.Allows(wal, localtype.VolumeTypeLVM, vg); got != allowed {
+					t.Errorf("expect vg %s allowed %t, got %t", vg, allowed, got)
+				}
+			}
+		})
+	}
+	// the bound lvm member keeps device pvcs of the group off disk sdb, co-location is of lvm pvcs only
+	for name, annotations := range map[string]map[string]string{"colocation": colocation, "device separation": deviceSeparation} {
+		t.Run("bound lvm member and device with "+name, func(t *testing.T) {
+			wal := newPVC("wal", "device", "5Gi", annotations)
+			constraints := NewVolumeConstraints(nil, nil, []*corev1.PersistentVolumeClaim{wal}, node, ctx)
+			for device, allowed := range map[string]bool{"/dev/sdb1": name == "colocation", "/dev/sdc": true} {
+				if got := constraints.Allows(wal, localtype.VolumeTypeDevice, device); got != allowed {
+					t.Errorf("expect device %s allowed %t, got %t", device, allowed, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUnhealthyStorage(t *testing.T) {
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0)
+	localInformerFactory := localinformers.NewSharedInformerFactory(localfake.NewSimpleClientset(), 0)
+	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(volumesnapshotfake.NewSimpleClientset(), 0)
+	ctx := algorithm.NewSchedulingContext(kubeInformerFactory.Core().V1(), kubeInformerFactory.Storage().V1(),
+		localInformerFactory.Csi().V1alpha1(), snapshotInformerFactory.Snapshot().V1beta1(), policy.NewDefaultPolicy(localtype.StrategyBinpack))
+
+	for name, params := range map[string]map[string]string{
+		"lvm":     {localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM)},
+		"lvm-hdd": {localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VolumeMediaType: string(localtype.MediaTypeHDD)},
+	} {
+		_ = ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: localtype.ProvisionerName,
+			Parameters:  params,
+		})
+	}
+	// the degraded hdd vg would be picked by binpack if it were healthy
+	nc := cache.NewNodeCache("node1")
+	nc.VGs["ssd"] = cache.SharedResource{Name: "ssd", Capacity: 100 << 30, MediaType: localtype.MediaTypeSSD}
+	nc.VGs["hdd"] = cache.SharedResource{Name: "hdd", Capacity: 50 << 30, MediaType: localtype.MediaTypeHDD, Condition: localv1alpha1.StorageDegraded}
+	nc.Devices["/dev/sdb"] = cache.ExclusiveResource{Name: "/dev/sdb", Capacity: 10 << 30, MediaType: localtype.MediaTypeHDD, Condition: localv1alpha1.StorageFailed}
+	nc.Devices["/dev/sdc"] = cache.ExclusiveResource{Name: "/dev/sdc", Capacity: 10 << 30, MediaType: localtype.MediaTypeHDD, Condition: localv1alpha1.StorageReady}
+	ctx.ClusterNodeCache.SetNodeCache(nc)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	newPVC := func(scName string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &scName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		}
+	}
+	lvm := []*corev1.PersistentVolumeClaim{newPVC("lvm")}
+	fits, units, err := ProcessLVMPVCPredicate(lvm, node, ctx, NewVolumeConstraints(lvm, nil, nil, node, ctx))
+	if !fits || len(units) != 1 || units[0].VgName != "ssd" {
+		t.Errorf("ProcessLVMPVCPredicate() = %t, %+v, %v, want vg ssd", fits, units, err)
+	}
+	fits, units, err = ProcessLVMPVCPriority(pod, lvm, node, ctx, NewVolumeConstraints(lvm, nil, nil, node, ctx))
+	if !fits || len(units) != 1 || units[0].VgName != "ssd" {
+		t.Errorf("ProcessLVMPVCPriority() = %t, %+v, %v, want vg ssd", fits, units, err)
+	}
+	hdd := []*corev1.PersistentVolumeClaim{newPVC("lvm-hdd")}
+	fits, _, err = ProcessLVMPVCPredicate(hdd, node, ctx, NewVolumeConstraints(hdd, nil, nil, node, ctx))
+	if _, ok := err.(*errors.UnhealthyStorageError); fits || !ok {
+		t.Errorf("expect UnhealthyStorageError, got %t, %v", fits, err)
+	}
+
+	_, freeHDD, err := GetFreeDevice(node, ctx)
+	if err != nil || len(freeHDD) != 1 || freeHDD[0].Name != "/dev/sdc" {
+		t.Errorf("GetFreeDevice() = %+v, %v, want /dev/sdc", freeHDD, err)
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algo
+
+import (
+	"strings"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// VolumeConstraints are the co-location and separation constraints among the pvcs of a pod allocated together,
+// declared by group names in annotations of the pvcs, and the resources allocated to each group so far.
+// Co-location and volume separation apply to LVM pvcs, while device separation applies to pvcs of every volume type
+type VolumeConstraints struct {
+	// disks of each vg, mount point and device on the node by volume type
+	disks map[localtype.VolumeType]map[string][]string
+	// colocated is the vg of each co-location group
+	colocated map[string]string
+	// colocationRequested is the total requested size of the pvcs of each co-location group not allocated yet
+	colocationRequested map[string]int64
+	// separated is the vgs of each separation group
+	separated map[string]map[string]bool
+	// deviceSeparated is the disks of each device separation group
+	deviceSeparated map[string]map[string]bool
+}
+
+// NewVolumeConstraints returns the constraints among the LVM, mount point and device pvcs of a pod on the node,
+// the disks of the resources are taken from the NodeLocalStorage of the node, and the resources of the members
+// bound before, e.g. when a pvc of the pod is recreated, are taken from their pvs
+func NewVolumeConstraints(lvmPVCs, mpPVCs, devicePVCs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext) *VolumeConstraints {
+	c := &VolumeConstraints{
+		disks:               nodeDisks(node, ctx),
+		colocated:           make(map[string]string),
+		colocationRequested: make(map[string]int64),
+		separated:           make(map[string]map[string]bool),
+		deviceSeparated:     make(map[string]map[string]bool),
+	}
+	for _, pvc := range lvmPVCs {
+		if group := pvc.Annotations[localtype.AnnotationVolumeColocation]; group != "" {
+			c.colocationRequested[group] += utils.GetPVCRequested(pvc)
+		}
+	}
+	pvcs := append(append(append([]*corev1.PersistentVolumeClaim{}, lvmPVCs...), mpPVCs...), devicePVCs...)
+	for _, pvc := range boundMembers(pvcs, ctx) {
+		pv, err := ctx.CoreV1Informers.PersistentVolumes().Lister().Get(pvc.Spec.VolumeName)
+		if err != nil {
+			log.Debugf("[NewVolumeConstraints]failed to get pv %s of pvc %s/%s: %s", pvc.Spec.VolumeName, pvc.Namespace, pvc.Name, err.Error())
+			continue
+		}
+		unit, err := algorithm.ConvertAUFromPV(pv, ctx.StorageV1Informers, ctx.CoreV1Informers)
+		if err != nil || unit.NodeName != node.Name {
+			continue
+		}
+		var resource string
+		switch unit.VolumeType {
+		case localtype.VolumeTypeLVM:
+			resource = unit.VgName
+		case localtype.VolumeTypeMountPoint:
+			resource = unit.MountPoint
+		case localtype.VolumeTypeDevice:
+			resource = unit.Device
+			if nc := ctx.ClusterNodeCache.GetNodeCache(node.Name); nc != nil {
+				resource = nc.DeviceOfPV(pv)
+			}
+		}
+		if resource != "" {
+			c.hold(pvc, unit.VolumeType, resource)
+		}
+	}
+	return c
+}
+
+// nodeDisks returns the disks of each vg, mount point and device on the node by volume type
+func nodeDisks(node *corev1.Node, ctx *algorithm.SchedulingContext) map[localtype.VolumeType]map[string][]string {
+	disks := map[localtype.VolumeType]map[string][]string{
+		localtype.VolumeTypeLVM:        make(map[string][]string),
+		localtype.VolumeTypeMountPoint: make(map[string][]string),
+		localtype.VolumeTypeDevice:     make(map[string][]string),
+	}
+	nls, err := ctx.LocalStorageInformer.NodeLocalStorages().Lister().Get(node.Name)
+	if err != nil {
+		log.Debugf("[NewVolumeConstraints]failed to get nls %s, resources are taken as disks: %s", node.Name, err.Error())
+		return disks
+	}
+	parents := make(map[string]string, len(nls.Status.NodeStorageInfo.DeviceInfos))
+	for _, device := range nls.Status.NodeStorageInfo.DeviceInfos {
+		parents[device.Name] = device.Parent
+	}
+	diskOf := func(device string) string {
+		if parent := parents[device]; parent != "" {
+			return parent
+		}
+		return device
+	}
+	vgs := disks[localtype.VolumeTypeLVM]
+	for _, vg := range nls.Status.NodeStorageInfo.VolumeGroups {
+		for _, pv := range vg.PhysicalVolumes {
+			if disk := diskOf(pv); !utils.ContainsString(vgs[vg.Name], disk) {
+				vgs[vg.Name] = append(vgs[vg.Name], disk)
+			}
+		}
+	}
+	for _, mp := range nls.Status.NodeStorageInfo.MountPoints {
+		disks[localtype.VolumeTypeMountPoint][mp.Name] = []string{diskOf(mp.Device)}
+	}
+	for _, device := range nls.Status.NodeStorageInfo.DeviceInfos {
+		disks[localtype.VolumeTypeDevice][device.Name] = []string{diskOf(device.Name)}
+	}
+	return disks
+}
+
+// boundMembers returns the bound pvcs in any group of the pod of pvcs, the pod is found by the pvc mapping
+func boundMembers(pvcs []*corev1.PersistentVolumeClaim, ctx *algorithm.SchedulingContext) []*corev1.PersistentVolumeClaim {
+	var podName string
+	for _, pvc := range pvcs {
+		if podName = ctx.ClusterNodeCache.PvcMapping.PvcPod[utils.PVCName(pvc)]; podName != "" {
+			break
+		}
+	}
+	names := strings.SplitN(podName, "/", 2)
+	if len(names) != 2 {
+		return nil
+	}
+	pod, err := ctx.CoreV1Informers.Pods().Lister().Pods(names[0]).Get(names[1])
+	if err != nil {
+		log.Debugf("[NewVolumeConstraints]failed to get pod %s: %s", podName, err.Error())
+		return nil
+	}
+	var members []*corev1.PersistentVolumeClaim
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := ctx.CoreV1Informers.PersistentVolumeClaims().Lister().PersistentVolumeClaims(pod.Namespace).Get(volume.PersistentVolumeClaim.ClaimName)
+		if err != nil || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		if pvc.Annotations[localtype.AnnotationVolumeColocation] != "" || pvc.Annotations[localtype.AnnotationVolumeSeparation] != "" ||
+			pvc.Annotations[localtype.AnnotationDeviceSeparation] != "" {
+			members = append(members, pvc)
+		}
+	}
+	return members
+}
+
+// Allows returns whether the resource of the volume type can be allocated to the pvc without breaking the constraints
+func (c *VolumeConstraints) Allows(pvc *corev1.PersistentVolumeClaim, volumeType localtype.VolumeType, resource string) bool {
+	if volumeType == localtype.VolumeTypeLVM {
+		if group := pvc.Annotations[localtype.AnnotationVolumeColocation]; group != "" {
+			if colocated, ok := c.colocated[group]; ok && colocated != resource {
+				return false
+			}
+		}
+		if group := pvc.Annotations[localtype.AnnotationVolumeSeparation]; group != "" && c.separated[group][resource] {
+			return false
+		}
+	}
+	if group := pvc.Annotations[localtype.AnnotationDeviceSeparation]; group != "" {
+		for _, disk := range c.disksOf(volumeType, resource) {
+			if c.deviceSeparated[group][disk] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Requested returns the size that the vg must have room for to be allocated to the LVM pvc,
+// which is the total size of its co-location group if the group is not allocated yet
+func (c *VolumeConstraints) Requested(pvc *corev1.PersistentVolumeClaim) int64 {
+	if group := pvc.Annotations[localtype.AnnotationVolumeColocation]; group != "" {
+		if _, ok := c.colocated[group]; !ok {
+			return c.colocationRequested[group]
+		}
+	}
+	return utils.GetPVCRequested(pvc)
+}
+
+// Add records the resource of the volume type allocated to the pvc
+func (c *VolumeConstraints) Add(pvc *corev1.PersistentVolumeClaim, volumeType localtype.VolumeType, resource string) {
+	if group := pvc.Annotations[localtype.AnnotationVolumeColocation]; group != "" && volumeType == localtype.VolumeTypeLVM {
+		c.colocationRequested[group] -= utils.GetPVCRequested(pvc)
+	}
+	c.hold(pvc, volumeType, resource)
+}
+
+// hold records the resource of the volume type held by the pvc in its groups
+func (c *VolumeConstraints) hold(pvc *corev1.PersistentVolumeClaim, volumeType localtype.VolumeType, resource string) {
+	if volumeType == localtype.VolumeTypeLVM {
+		if group := pvc.Annotations[localtype.AnnotationVolumeColocation]; group != "" {
+			c.colocated[group] = resource
+		}
+		if group := pvc.Annotations[localtype.AnnotationVolumeSeparation]; group != "" {
+			if c.separated[group] == nil {
+				c.separated[group] = make(map[string]bool)
+			}
+			c.separated[group][resource] = true
+		}
+	}
+	if group := pvc.Annotations[localtype.AnnotationDeviceSeparation]; group != "" {
+		if c.deviceSeparated[group] == nil {
+			c.deviceSeparated[group] = make(map[string]bool)
+		}
+		for _, disk := range c.disksOf(volumeType, resource) {
+			c.deviceSeparated[group][disk] = true
+		}
+	}
+}
+
+// disksOf returns the disks of the resource of the volume type, which is taken as a disk itself if unknown
+func (c *VolumeConstraints) disksOf(volumeType localtype.VolumeType, resource string) []string {
+	if disks, ok := c.disks[volumeType][resource]; ok && len(disks) > 0 {
+		return disks
+	}
+	return []string{resource}
+}
@@ -19,6 +19,7 @@ package cache
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/alibaba/open-local/pkg"
 	nodelocalstorage "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
@@ -33,12 +34,16 @@ type ClusterInfo struct {
 	BindingInfo BindingMap `json:"bindingInfo,omitempty"`
 	// PvcMapping records requested pod and pvc mapping
 	PvcMapping *PodPvcMapping `json:"pvcMapping"`
+	// Assumed records the units assumed before their pvs are created, keyed by pvc name
+	Assumed map[string]*AssumedUnit `json:"assumed,omitempty"`
 }
 
 // ClusterNodeCache maintains mapping of allocated local PVs and Nodes
 type ClusterNodeCache struct {
 	mu sync.RWMutex
 	ClusterInfo
+	// AssumeTTL is how long an assumed unit is kept before its pv is created
+	AssumeTTL time.Duration `json:"-"`
 }
 
 func NewClusterNodeCache() *ClusterNodeCache {
@@ -50,7 +55,10 @@ func NewClusterNodeCache() *ClusterNodeCache {
 			Nodes:       nodes,
 			BindingInfo: info,
 			PvcMapping:  pvcInfo,
-		}}
+			Assumed:     make(map[string]*AssumedUnit),
+		},
+		AssumeTTL: pkg.DefaultAssumeTTL,
+	}
 }
 
 func (c *ClusterNodeCache) AddNodeCache(nodeLocal *nodelocalstorage.NodeLocalStorage) *NodeCache {
@@ -111,7 +119,8 @@ func (c *ClusterNodeCache) GetNodeNameFromPV(pv *corev1.PersistentVolume) string
 }
 
 // Assume updates the allocated units into cache immediately
-// to avoid any potential resource over allocated
+// to avoid any potential resource over allocated, the units are tracked
+// until their pvs are created or they expire after AssumeTTL
 func (c *ClusterNodeCache) Assume(units []AllocatedUnit) (err error) {
 	// all pass, write cache now
 	//TODO(yuzhi.wx) we need to move it out, after all check pass
@@ -120,21 +129,32 @@ func (c *ClusterNodeCache) Assume(units []AllocatedUnit) (err error) {
 		if nodeCache == nil {
 			return fmt.Errorf("node %s not found from cache when assume", u.NodeName)
 		}
-		volumeType := u.VolumeType
-		switch volumeType {
-		case pkg.VolumeTypeLVM:
-			_, err = c.assumeLVMAllocatedUnit(u, nodeCache)
-		case pkg.VolumeTypeDevice:
-			_, err = c.assumeDeviceAllocatedUnit(u, nodeCache)
-		case pkg.VolumeTypeMountPoint:
-			_, err = c.assumeMountPointAllocatedUnit(u, nodeCache)
-		default:
-			err = fmt.Errorf("invalid volumeType %s", volumeType)
+		if err = c.assumeUnit(u, nodeCache); err == nil {
+			c.mu.Lock()
+			c.Assumed[u.PVCName] = &AssumedUnit{AllocatedUnit: u, ExpireAt: time.Now().Add(c.AssumeTTL)}
+			c.mu.Unlock()
 		}
 	}
 	return err
 }
 
+func (c *ClusterNodeCache) assumeUnit(u AllocatedUnit, nodeCache *NodeCache) (err error) {
+	volumeType := u.VolumeType
+	switch volumeType {
+	case pkg.VolumeTypeLVM:
+		_, err = c.assumeLVMAllocatedUnit(u, nodeCache)
+	case pkg.VolumeTypeDevice:
+		_, err = c.assumeDeviceAllocatedUnit(u, nodeCache)
+	case pkg.VolumeTypeMountPoint:
+		_, err = c.assumeMountPointAllocatedUnit(u, nodeCache)
+	case pkg.VolumeTypeQuota:
+		_, err = c.assumeQuotaAllocatedUnit(u, nodeCache)
+	default:
+		err = fmt.Errorf("invalid volumeType %s", volumeType)
+	}
+	return err
+}
+
 func (c *ClusterNodeCache) assumeMountPointAllocatedUnit(unit AllocatedUnit, nodeCache *NodeCache) (*NodeCache, error) {
 	nodeCache.AllocatedNum += 1
 
@@ -160,8 +180,8 @@ func (c *ClusterNodeCache) assumeMountPointAllocatedUnit(unit AllocatedUnit, nod
 func (c *ClusterNodeCache) assumeLVMAllocatedUnit(unit AllocatedUnit, nodeCache *NodeCache) (*NodeCache, error) {
 	vg, ok := nodeCache.VGs[ResourceName(unit.VgName)]
 	if ok {
-		if vg.Requested+unit.Requested > vg.Capacity {
-			return nil, fmt.Errorf("VG %s resource is not enough, requested = %d, actual left = %d", vg.Name, unit.Requested, vg.Capacity-vg.Requested)
+		if free := VGFree(vg, unit.Thin, unit.ThinClass); unit.Requested > free {
+			return nil, fmt.Errorf("VG %s resource is not enough, requested = %d, actual left = %d", vg.Name, unit.Requested, free)
 		}
 	} else {
 		// vg is not found
@@ -169,16 +189,34 @@ func (c *ClusterNodeCache) assumeLVMAllocatedUnit(unit AllocatedUnit, nodeCache
 	}
 	nodeCache.AllocatedNum += 1
 
-	nodeCache.VGs[ResourceName(vg.Name)] = SharedResource{
-		Name:      vg.Name,
-		Capacity:  vg.Capacity,
-		Requested: vg.Requested + unit.Requested,
-	}
+	AddVGRequested(&vg, unit.Requested, unit.Thin)
+	nodeCache.VGs[ResourceName(vg.Name)] = vg
 	log.Debugf("assume node cache successfully: node = %s, vg = %s", nodeCache.NodeName, vg.Name)
 	c.SetNodeCache(nodeCache)
 	return nodeCache, nil
 }
 
+func (c *ClusterNodeCache) assumeQuotaAllocatedUnit(unit AllocatedUnit, nodeCache *NodeCache) (*NodeCache, error) {
+	quota, ok := nodeCache.Quotas[ResourceName(unit.MountPoint)]
+	if ok {
+		if quota.Requested+unit.Requested > quota.Capacity {
+			return nil, fmt.Errorf("quota mount point %s resource is not enough, requested = %d, actual left = %d", quota.Name, unit.Requested, quota.Capacity-quota.Requested)
+		}
+	} else {
+		return nil, fmt.Errorf("quota mount point %s/%s is not found in cache, please retry later", nodeCache.NodeName, unit.MountPoint)
+	}
+	nodeCache.AllocatedNum += 1
+
+	nodeCache.Quotas[ResourceName(quota.Name)] = SharedResource{
+		Name:      quota.Name,
+		Capacity:  quota.Capacity,
+		Requested: quota.Requested + unit.Requested,
+	}
+	log.Debugf("assume node cache successfully: node = %s, quota mount point = %s", nodeCache.NodeName, quota.Name)
+	c.SetNodeCache(nodeCache)
+	return nodeCache, nil
+}
+
 func (c *ClusterNodeCache) assumeDeviceAllocatedUnit(unit AllocatedUnit, nodeCache *NodeCache) (*NodeCache, error) {
 	nodeCache.AllocatedNum += 1
 
@@ -193,8 +231,133 @@ func (c *ClusterNodeCache) assumeDeviceAllocatedUnit(unit AllocatedUnit, nodeCac
 		Device:      unit.Device,
 		MediaType:   nodeCache.Devices[ResourceName(unit.Device)].MediaType,
 		IsAllocated: true,
+		Condition:   nodeCache.Devices[ResourceName(unit.Device)].Condition,
 	}
 	log.Debugf("assume node cache successfully: node = %s, device = %s", nodeCache.NodeName, unit.Device)
 	c.SetNodeCache(nodeCache)
 	return nodeCache, nil
 }
+
+// Unassume reverts the allocated units written into cache by Assume,
+// it is called when the reserved storage will not be provisioned, e.g. the pod failed to bind
+func (c *ClusterNodeCache) Unassume(units []AllocatedUnit) (err error) {
+	for _, u := range units {
+		c.mu.Lock()
+		delete(c.Assumed, u.PVCName)
+		c.mu.Unlock()
+		nodeCache := c.GetNodeCache(u.NodeName)
+		if nodeCache == nil {
+			err = fmt.Errorf("node %s not found from cache when unassume", u.NodeName)
+			continue
+		}
+		if e := nodeCache.unassume(u); e != nil {
+			err = e
+			continue
+		}
+		log.Debugf("unassume node cache successfully: node = %s, pvc = %s", nodeCache.NodeName, u.PVCName)
+		c.SetNodeCache(nodeCache)
+	}
+	return err
+}
+
+// unassume reverts the unit in the node cache, holding its lock as the maps are read by others
+func (nc *NodeCache) unassume(u AllocatedUnit) error {
+	nc.rwLock.Lock()
+	defer nc.rwLock.Unlock()
+	switch u.VolumeType {
+	case pkg.VolumeTypeLVM:
+		if vg, ok := nc.VGs[ResourceName(u.VgName)]; ok {
+			if u.Thin {
+				vg.ThinRequested = unassumeRequested(vg.ThinRequested, u.Requested)
+			} else {
+				vg.Requested = unassumeRequested(vg.Requested, u.Requested)
+			}
+			nc.VGs[ResourceName(u.VgName)] = vg
+		}
+	case pkg.VolumeTypeQuota:
+		if quota, ok := nc.Quotas[ResourceName(u.MountPoint)]; ok {
+			quota.Requested = unassumeRequested(quota.Requested, u.Requested)
+			nc.Quotas[ResourceName(u.MountPoint)] = quota
+		}
+	case pkg.VolumeTypeDevice:
+		if v, ok := nc.Devices[ResourceName(u.Device)]; ok {
+			v.IsAllocated = false
+			nc.Devices[ResourceName(u.Device)] = v
+		}
+	case pkg.VolumeTypeMountPoint:
+		if v, ok := nc.MountPoints[ResourceName(u.MountPoint)]; ok {
+			v.IsAllocated = false
+			nc.MountPoints[ResourceName(u.MountPoint)] = v
+		}
+	default:
+		return fmt.Errorf("invalid volumeType %s", u.VolumeType)
+	}
+	if nc.AllocatedNum > 0 {
+		nc.AllocatedNum -= 1
+	}
+	return nil
+}
+
+func unassumeRequested(requested, released int64) int64 {
+	if requested < released {
+		return 0
+	}
+	return requested - released
+}
+
+// ReleaseAssumed reverts the unit assumed for pvc, it returns nil if nothing is assumed for it
+func (c *ClusterNodeCache) ReleaseAssumed(pvcName string) (*AssumedUnit, error) {
+	c.mu.RLock()
+	assumed, ok := c.Assumed[pvcName]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return assumed, c.Unassume([]AllocatedUnit{assumed.AllocatedUnit})
+}
+
+// GetAssumed returns the unit assumed for pvc
+func (c *ClusterNodeCache) GetAssumed(pvcName string) (*AssumedUnit, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	assumed, ok := c.Assumed[pvcName]
+	return assumed, ok
+}
+
+// ExpiredAssumed returns the assumed units which expire before now
+func (c *ClusterNodeCache) ExpiredAssumed(now time.Time) []AssumedUnit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var expired []AssumedUnit
+	for _, assumed := range c.Assumed {
+		if now.After(assumed.ExpireAt) {
+			expired = append(expired, *assumed)
+		}
+	}
+	return expired
+}
+
+// RebuildNodeCache replaces the node cache with the one rebuilt from apiserver, the units
+// still assumed on the node are applied to it, drifts between the two are returned
+func (c *ClusterNodeCache) RebuildNodeCache(rebuilt *NodeCache) []Drift {
+	cached := c.GetNodeCache(rebuilt.NodeName)
+
+	c.mu.RLock()
+	var assumed []AllocatedUnit
+	for _, unit := range c.Assumed {
+		if unit.NodeName == rebuilt.NodeName {
+			assumed = append(assumed, unit.AllocatedUnit)
+		}
+	}
+	c.mu.RUnlock()
+	for _, unit := range assumed {
+		if err := c.assumeUnit(unit, rebuilt); err != nil {
+			log.Warningf("failed to apply assumed unit of pvc %s to rebuilt node cache %s: %s", unit.PVCName, rebuilt.NodeName, err.Error())
+		}
+	}
+	c.SetNodeCache(rebuilt)
+	if cached == nil {
+		return nil
+	}
+	return DiffNodeCache(cached, rebuilt)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alibaba/open-local/pkg"
+)
+
+func TestAssumeAndUnassume(t *testing.T) {
+	c := NewClusterNodeCache()
+	nc := NewNodeCache("testnode")
+	nc.VGs["ssd"] = SharedResource{Name: "ssd", Capacity: 100}
+	nc.Devices["/dev/vdb"] = ExclusiveResource{Name: "/dev/vdb", Device: "/dev/vdb", Capacity: 50, MediaType: "ssd"}
+	c.SetNodeCache(nc)
+
+	units := []AllocatedUnit{
+		{NodeName: "testnode", VolumeType: pkg.VolumeTypeLVM, Requested: 30, Allocated: 30, VgName: "ssd", PVCName: "default/pvc-lvm"},
+		{NodeName: "testnode", VolumeType: pkg.VolumeTypeDevice, Requested: 50, Allocated: 50, Device: "/dev/vdb", PVCName: "default/pvc-device"},
+	}
+	if err := c.Assume(units); err != nil {
+		t.Fatalf("failed to assume: %v", err)
+	}
+	nc = c.GetNodeCache("testnode")
+	if nc.VGs["ssd"].Requested != 30 || !nc.Devices["/dev/vdb"].IsAllocated || nc.AllocatedNum != 2 {
+		t.Fatalf("unexpected node cache after assume: %#v", nc.NodeInfo)
+	}
+
+	if err := c.Unassume(units); err != nil {
+		t.Fatalf("failed to unassume: %v", err)
+	}
+	nc = c.GetNodeCache("testnode")
+	if nc.VGs["ssd"].Requested != 0 || nc.Devices["/dev/vdb"].IsAllocated || nc.AllocatedNum != 0 {
+		t.Errorf("unexpected node cache after unassume: %#v", nc.NodeInfo)
+	}
+	if nc.Devices["/dev/vdb"].MediaType != "ssd" {
+		t.Errorf("media type of device should be kept, got %q", nc.Devices["/dev/vdb"].MediaType)
+	}
+	if len(c.Assumed) != 0 {
+		t.Errorf("assumed units should be released, got %+v", c.Assumed)
+	}
+}
+
+func TestAssumedExpireAndRebuild(t *testing.T) {
+	c := NewClusterNodeCache()
+	nc := NewNodeCache("testnode")
+	nc.VGs["ssd"] = SharedResource{Name: "ssd", Capacity: 100}
+	c.SetNodeCache(nc)
+
+	unit := AllocatedUnit{NodeName: "testnode", VolumeType: pkg.VolumeTypeLVM, Requested: 30, Allocated: 30, VgName: "ssd", PVCName: "default/pvc-lvm"}
+	if err := c.Assume([]AllocatedUnit{unit}); err != nil {
+		t.Fatalf("failed to assume: %v", err)
+	}
+	if _, ok := c.GetAssumed(unit.PVCName); !ok {
+		t.Fatalf("expect unit of %s assumed", unit.PVCName)
+	}
+	if expired := c.ExpiredAssumed(time.Now()); len(expired) != 0 {
+		t.Errorf("expect no expired unit, got %+v", expired)
+	}
+	if expired := c.ExpiredAssumed(time.Now().Add(c.AssumeTTL + time.Second)); len(expired) != 1 {
+		t.Errorf("expect unit expired after ttl, got %+v", expired)
+	}
+
+	// 20 is leaked in cache, the assumed unit is kept after rebuilding
+	nc.VGs["ssd"] = SharedResource{Name: "ssd", Capacity: 100, Requested: 50}
+	rebuilt := NewNodeCache("testnode")
+	rebuilt.VGs["ssd"] = SharedResource{Name: "ssd", Capacity: 100}
+	drifts := c.RebuildNodeCache(rebuilt)
+	if len(drifts) != 1 || drifts[0].Cached != 50 || drifts[0].Actual != 30 {
+		t.Errorf("unexpected drifts: %+v", drifts)
+	}
+	if requested := c.GetNodeCache("testnode").VGs["ssd"].Requested; requested != 30 {
+		t.Errorf("expect requested 30 after rebuilding, got %d", requested)
+	}
+
+	if assumed, err := c.ReleaseAssumed(unit.PVCName); assumed == nil || err != nil {
+		t.Fatalf("failed to release assumed unit: %v", err)
+	}
+	if requested := c.GetNodeCache("testnode").VGs["ssd"].Requested; requested != 0 {
+		t.Errorf("expect requested 0 after releasing, got %d", requested)
+	}
+}
+
+func TestAssumeThinAndThick(t *testing.T) {
+	c := NewClusterNodeCache()
+	nc := NewNodeCache("testnode")
+	nc.VGs["ssd"] = SharedResource{Name: "ssd", Capacity: 100, ThinCapacity: 50}
+	c.SetNodeCache(nc)
+
+	thick := AllocatedUnit{NodeName: "testnode", VolumeType: pkg.VolumeTypeLVM, Requested: 80, Allocated: 80, VgName: "ssd", PVCName: "default/pvc-thick"}
+	thin := AllocatedUnit{NodeName: "testnode", VolumeType: pkg.VolumeTypeLVM, Requested: 40, Allocated: 40, VgName: "ssd", PVCName: "default/pvc-thin", Thin: true}
+	if err := c.Assume([]AllocatedUnit{thick, thin}); err != nil {
+		t.Fatalf("failed to assume: %v", err)
+	}
+	vg := c.GetNodeCache("testnode").VGs["ssd"]
+	if vg.Requested != 80 || vg.ThinRequested != 40 {
+		t.Fatalf("thin and thick volumes should be accounted apart, got %+v", vg)
+	}
+	more := AllocatedUnit{NodeName: "testnode", VolumeType: pkg.VolumeTypeLVM, Requested: 20, Allocated: 20, VgName: "ssd", PVCName: "default/pvc-thin-2", Thin: true}
+	if err := c.Assume([]AllocatedUnit{more}); err == nil {
+		t.Errorf("expect thin pool not enough")
+	}
+
+	if err := c.Unassume([]AllocatedUnit{thin}); err != nil {
+		t.Fatalf("failed to unassume: %v", err)
+	}
+	vg = c.GetNodeCache("testnode").VGs["ssd"]
+	if vg.Requested != 80 || vg.ThinRequested != 0 {
+		t.Errorf("unexpected vg after unassume: %+v", vg)
+	}
+}
@@ -35,13 +35,86 @@ func NewNodeCache(nodeName string) *NodeCache {
 			VGs:          make(map[ResourceName]SharedResource),
 			MountPoints:  make(map[ResourceName]ExclusiveResource),
 			Devices:      make(map[ResourceName]ExclusiveResource),
+			Quotas:       make(map[ResourceName]SharedResource),
 			AllocatedNum: 0,
 			// TODO(yuzhi.wx) using pv name may conflict, use pv uid later
 			LocalPVs:            make(map[string]corev1.PersistentVolume),
 			PodInlineVolumeInfo: make(map[string][]InlineVolumeInfo)},
+		deviceNames: make(map[string]string),
 	}
 }
 
+// DeepCopy returns a copy of the node cache, it is used to simulate
+// allocations without touching the cache, e.g. in preemption
+func (nc *NodeCache) DeepCopy() *NodeCache {
+	nc.rwLock.RLock()
+	defer nc.rwLock.RUnlock()
+	copied := NewNodeCache(nc.NodeName)
+	copied.AllocatedNum = nc.AllocatedNum
+	for k, v := range nc.VGs {
+		copied.VGs[k] = v
+	}
+	for k, v := range nc.MountPoints {
+		copied.MountPoints[k] = v
+	}
+	for k, v := range nc.Devices {
+		copied.Devices[k] = v
+	}
+	for k, v := range nc.Quotas {
+		copied.Quotas[k] = v
+	}
+	for k, v := range nc.LocalPVs {
+		copied.LocalPVs[k] = v
+	}
+	for k, v := range nc.PodInlineVolumeInfo {
+		copied.PodInlineVolumeInfo[k] = append([]InlineVolumeInfo{}, v...)
+	}
+	for k, v := range nc.deviceNames {
+		copied.deviceNames[k] = v
+	}
+	return copied
+}
+
+// DiffNodeCache returns the drifts of resources between the cached node cache and
+// the actual one, resources missing from the actual one are not considered as drifts
+func DiffNodeCache(cached, actual *NodeCache) []Drift {
+	cached.rwLock.RLock()
+	defer cached.rwLock.RUnlock()
+	var drifts []Drift
+	diffShared := func(volumeType pkg.VolumeType, cachedResources, actualResources map[ResourceName]SharedResource) {
+		for name, resource := range actualResources {
+			if cachedResources[name].Requested != resource.Requested {
+				drifts = append(drifts, Drift{NodeName: actual.NodeName, VolumeType: volumeType, Name: string(name),
+					Cached: cachedResources[name].Requested, Actual: resource.Requested})
+			}
+			if cachedResources[name].ThinRequested != resource.ThinRequested {
+				// thin pool of vg drifts apart from vg
+				drifts = append(drifts, Drift{NodeName: actual.NodeName, VolumeType: volumeType, Name: fmt.Sprintf("%s/%s", name, localtype.ThinPoolName),
+					Cached: cachedResources[name].ThinRequested, Actual: resource.ThinRequested})
+			}
+		}
+	}
+	diffExclusive := func(volumeType pkg.VolumeType, cachedResources, actualResources map[ResourceName]ExclusiveResource) {
+		allocated := func(r ExclusiveResource) int64 {
+			if r.IsAllocated {
+				return 1
+			}
+			return 0
+		}
+		for name, resource := range actualResources {
+			if cachedResources[name].IsAllocated != resource.IsAllocated {
+				drifts = append(drifts, Drift{NodeName: actual.NodeName, VolumeType: volumeType, Name: string(name),
+					Cached: allocated(cachedResources[name]), Actual: allocated(resource)})
+			}
+		}
+	}
+	diffShared(pkg.VolumeTypeLVM, cached.VGs, actual.VGs)
+	diffShared(pkg.VolumeTypeQuota, cached.Quotas, actual.Quotas)
+	diffExclusive(pkg.VolumeTypeMountPoint, cached.MountPoints, actual.MountPoints)
+	diffExclusive(pkg.VolumeTypeDevice, cached.Devices, actual.Devices)
+	return drifts
+}
+
 func NewNodeCacheFromStorage(nodeLocal *nodelocalstorage.NodeLocalStorage) *NodeCache {
 	newNodeCache := NewNodeCache(nodeLocal.Name) // create a new node cache
 
@@ -56,7 +129,13 @@ func NewNodeCacheFromStorage(nodeLocal *nodelocalstorage.NodeLocalStorage) *Node
 			vgName, vgInfoMap[vgName].Total, vgInfoMap[vgName].Allocatable, vgInfoMap[vgName].Total-vgInfoMap[vgName].Available, newNodeCache.NodeName)
 		log.Debugf("vg raw info:%#v", vgInfoMap[vgName])
 		log.Debugf("cachedNode.VGs: %#v, is nil %t", newNodeCache.VGs, newNodeCache.VGs == nil)
-		vgResource := SharedResource{vgName, int64(vgInfoMap[vgName].Allocatable), 0}
+		vgResource := SharedResource{
+			Name:         vgName,
+			Capacity:     int64(vgInfoMap[vgName].Allocatable),
+			MediaType:    localtype.MediaType(vgInfoMap[vgName].MediaType),
+			Condition:    vgInfoMap[vgName].Condition,
+			ThinCapacity: vgThinCapacity(vgInfoMap[vgName]),
+		}
 		newNodeCache.VGs[ResourceName(vgName)] = vgResource
 		log.Debugf("vgResource: %#v", vgResource)
 	}
@@ -66,6 +145,7 @@ func NewNodeCacheFromStorage(nodeLocal *nodelocalstorage.NodeLocalStorage) *Node
 	for _, d := range nodeLocal.Status.NodeStorageInfo.DeviceInfos {
 		deviceInfoMap[d.Name] = d
 	}
+	newNodeCache.deviceNames = deviceNamesOf(nodeLocal)
 	// add devices
 	for _, deviceName := range nodeLocal.Status.FilteredStorageInfo.Devices {
 		tmpDevice := deviceInfoMap[deviceName]
@@ -80,7 +160,8 @@ func NewNodeCacheFromStorage(nodeLocal *nodelocalstorage.NodeLocalStorage) *Node
 			tmpDevice.Name,
 			int64(tmpDevice.Total),
 			localtype.MediaType(tmpDevice.MediaType),
-			false}
+			false,
+			tmpDevice.Condition}
 		newNodeCache.Devices[ResourceName(deviceName)] = diskResource
 		log.Debugf("diskResource: %#v", diskResource)
 	}
@@ -96,6 +177,15 @@ func NewNodeCacheFromStorage(nodeLocal *nodelocalstorage.NodeLocalStorage) *Node
 		if !utils.CheckMountPointOptions(&tmpMP) {
 			continue
 		}
+		// mount points with project quota enabled are shared by Quota volumes if listed
+		if utils.IsQuotaMountPoint(&tmpMP, nodeLocal.Spec.ListConfig.MountPoints.Quota) {
+			log.Debugf("adding new quota mount point %q(total:%d) on node cache %s",
+				mp, tmpMP.Total, newNodeCache.NodeName)
+			quotaResource := SharedResource{Name: mp, Capacity: int64(tmpMP.Total)}
+			newNodeCache.Quotas[ResourceName(mp)] = quotaResource
+			log.Debugf("quotaResource: %#v", quotaResource)
+			continue
+		}
 		log.Debugf("adding new mount point %q(total:%d) on node cache %s",
 			mp, tmpMP.Total, newNodeCache.NodeName)
 		log.Debugf("disk raw info:%#v", tmpMP)
@@ -104,7 +194,8 @@ func NewNodeCacheFromStorage(nodeLocal *nodelocalstorage.NodeLocalStorage) *Node
 			tmpMP.Device,
 			int64(tmpMP.Total),
 			localtype.MediaType(deviceInfoMap[tmpMP.Device].MediaType),
-			false}
+			false,
+			""}
 		newNodeCache.MountPoints[ResourceName(mp)] = diskResource
 		log.Debugf("diskResource: %#v", diskResource)
 	}
@@ -135,8 +226,15 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 			vg, vgMapInfo[vg].Total, vgMapInfo[vg].Allocatable, vgMapInfo[vg].Total-vgMapInfo[vg].Available, cacheNode.NodeName)
 		log.Debugf("updatedName raw info:%#v", vgMapInfo[vg])
 		log.Debugf("cachedNode.VGs: %#v, is nil %t", cacheNode.VGs, cacheNode.VGs == nil)
-		vgRequested := utils.GetVGRequested(nc.LocalPVs, vg)
-		vgResource := SharedResource{vg, int64(vgMapInfo[vg].Allocatable), vgRequested}
+		vgResource := SharedResource{
+			Name:          vg,
+			Capacity:      int64(vgMapInfo[vg].Allocatable),
+			Requested:     utils.GetVGRequested(nc.LocalPVs, vg, false),
+			MediaType:     localtype.MediaType(vgMapInfo[vg].MediaType),
+			Condition:     vgMapInfo[vg].Condition,
+			ThinCapacity:  vgThinCapacity(vgMapInfo[vg]),
+			ThinRequested: utils.GetVGRequested(nc.LocalPVs, vg, true),
+		}
 		cacheNode.VGs[ResourceName(vg)] = vgResource
 		log.Debugf("vgResource: %#v", vgResource)
 	}
@@ -144,6 +242,9 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 		// update the size if the updatedName got extended
 		v := cacheNode.VGs[ResourceName(vg)]
 		v.Capacity = int64(vgMapInfo[vg].Allocatable)
+		v.MediaType = localtype.MediaType(vgMapInfo[vg].MediaType)
+		v.Condition = vgMapInfo[vg].Condition
+		v.ThinCapacity = vgThinCapacity(vgMapInfo[vg])
 		cacheNode.VGs[ResourceName(vg)] = v
 		log.Debugf("updating existing volume group %q(total:%d,allocatable:%d,used:%d) on node cache %s",
 			vg, vgMapInfo[vg].Total, vgMapInfo[vg].Allocatable, vgMapInfo[vg].Total-vgMapInfo[vg].Available, cacheNode.NodeName)
@@ -171,6 +272,10 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 	for _, d := range devices {
 		deviceMapInfo[d.Name] = d
 	}
+	// resolve the pvs by the by-id links as kernel names may change across reboots
+	heldBefore := cacheNode.devicesOfPVs()
+	cacheNode.deviceNames = deviceNamesOf(nodeLocal)
+	held := cacheNode.devicesOfPVs()
 	// get device from cache
 	deviceCache := make([]string, 0)
 	for _, device := range cacheNode.Devices {
@@ -180,16 +285,14 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 	addedDevices, unchangedDevices, removedDevices := utils.GetAddedAndRemovedItems(nodeLocal.Status.FilteredStorageInfo.Devices, deviceCache)
 	for _, device := range addedDevices {
 		log.Debugf("adding new device %q(total:%d) on node cache %s", device, deviceMapInfo[device].Total, cacheNode.NodeName)
-		allocated := false
-		if nc.IsLocalPVExist(pkg.VolumeTypeDevice, device) {
-			allocated = true
-		}
+		allocated := held[device]
 		diskResource := ExclusiveResource{
 			device,
 			device,
 			int64(deviceMapInfo[device].Total),
 			localtype.MediaType(deviceMapInfo[device].MediaType),
-			allocated}
+			allocated,
+			deviceMapInfo[device].Condition}
 		cacheNode.Devices[ResourceName(device)] = diskResource
 	}
 	for _, device := range unchangedDevices {
@@ -197,10 +300,17 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 		exDevice := cacheNode.Devices[ResourceName(device)]
 		exDevice.Capacity = int64(deviceMapInfo[device].Total)
 		exDevice.MediaType = localtype.MediaType(deviceMapInfo[device].MediaType)
+		exDevice.Condition = deviceMapInfo[device].Condition
+		// the name moves with the disk, keep allocations not made by pvs, e.g. the assumed ones
+		if held[device] {
+			exDevice.IsAllocated = true
+		} else if heldBefore[device] {
+			exDevice.IsAllocated = false
+		}
 		cacheNode.Devices[ResourceName(device)] = exDevice
 	}
 	for _, device := range removedDevices {
-		if cacheNode.Devices[ResourceName(device)].IsAllocated {
+		if cacheNode.Devices[ResourceName(device)].IsAllocated && held[device] {
 			log.Debugf("device %q is used by PV.", device)
 		} else {
 			delete(cacheNode.Devices, ResourceName(device))
@@ -228,6 +338,10 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 			log.Debugf("mount point %s on %s was excluded, readonly: %t, fsType: %s", mp, nodeLocal.Name, tmpMP.ReadOnly, tmpMP.FsType)
 			continue
 		}
+		if utils.IsQuotaMountPoint(&tmpMP, nodeLocal.Spec.ListConfig.MountPoints.Quota) {
+			log.Debugf("mount point %s on %s is project quota enabled, handled as quota", mp, nodeLocal.Name)
+			continue
+		}
 		log.Debugf("adding new mount point %q(total:%d) on node cache %s", mp, mpMapInfo[mp].Total, cacheNode.NodeName)
 		allocated := false
 		if nc.IsLocalPVExist(pkg.VolumeTypeMountPoint, mp) {
@@ -238,12 +352,19 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 			mpMapInfo[mp].Device,
 			int64(mpMapInfo[mp].Total),
 			localtype.MediaType(deviceMapInfo[mpMapInfo[mp].Device].MediaType),
-			allocated}
+			allocated,
+			""}
 		cacheNode.MountPoints[ResourceName(mp)] = diskResource
 		log.Debugf("diskResource: %#v", diskResource)
 	}
 	for _, mp := range unchangedMPs {
 		exMP := cacheNode.MountPoints[ResourceName(mp)]
+		// the mount point may be remounted with project quota enabled
+		if tmpMP := mpMapInfo[mp]; utils.IsQuotaMountPoint(&tmpMP, nodeLocal.Spec.ListConfig.MountPoints.Quota) && !exMP.IsAllocated {
+			delete(cacheNode.MountPoints, ResourceName(mp))
+			log.Debugf("mount point %q is project quota enabled, deleted from cache", mp)
+			continue
+		}
 		// update capacity of existing mount point
 		exMP.Capacity = int64(mpMapInfo[mp].Total)
 		exMP.MediaType = localtype.MediaType(deviceMapInfo[exMP.Device].MediaType)
@@ -260,6 +381,40 @@ func (nc *NodeCache) UpdateNodeInfo(nodeLocal *nodelocalstorage.NodeLocalStorage
 		}
 	}
 
+	// Quota
+	// get quota mount points from CR
+	quotaMPs := make([]string, 0)
+	for _, mp := range nodeLocal.Status.FilteredStorageInfo.MountPoints {
+		tmpMP, ok := mpMapInfo[mp]
+		if ok && utils.CheckMountPointOptions(&tmpMP) && utils.IsQuotaMountPoint(&tmpMP, nodeLocal.Spec.ListConfig.MountPoints.Quota) {
+			quotaMPs = append(quotaMPs, mp)
+		}
+	}
+	// get quota mount points from cache
+	cacheQuota := make([]string, 0)
+	for _, quota := range cacheNode.Quotas {
+		cacheQuota = append(cacheQuota, quota.Name)
+	}
+	// update quota mount points
+	addedQuotas, unchangedQuotas, removedQuotas := utils.GetAddedAndRemovedItems(quotaMPs, cacheQuota)
+	for _, mp := range addedQuotas {
+		log.Debugf("adding new quota mount point %q(total:%d) on node cache %s", mp, mpMapInfo[mp].Total, cacheNode.NodeName)
+		quotaRequested := utils.GetQuotaRequested(nc.LocalPVs, mp)
+		quotaResource := SharedResource{Name: mp, Capacity: int64(mpMapInfo[mp].Total), Requested: quotaRequested}
+		cacheNode.Quotas[ResourceName(mp)] = quotaResource
+		log.Debugf("quotaResource: %#v", quotaResource)
+	}
+	for _, mp := range unchangedQuotas {
+		q := cacheNode.Quotas[ResourceName(mp)]
+		q.Capacity = int64(mpMapInfo[mp].Total)
+		cacheNode.Quotas[ResourceName(mp)] = q
+		log.Debugf("updating existing quota mount point %q(total:%d) on node cache %s", mp, q.Capacity, cacheNode.NodeName)
+	}
+	for _, mp := range removedQuotas {
+		delete(cacheNode.Quotas, ResourceName(mp))
+		log.Debugf("deleted quota mount point %s from node cache %s", mp, nodeLocal.Name)
+	}
+
 	return cacheNode
 }
 
@@ -285,14 +440,15 @@ func (nc *NodeCache) AddLVM(pv *corev1.PersistentVolume) error {
 		if vg, ok := nc.VGs[ResourceName(vgName)]; ok {
 			// TODO(huizhi.szh): when informer resync the cache, this function may be called again, this will be a bug,
 			// because it will do it one more time.
-			oldRequest := vg.Requested
+			thin := utils.IsThinLVMPV(pv)
+			oldRequest := VGRequested(vg, thin)
 			s := pv.Spec.Capacity[corev1.ResourceStorage]
-			vg.Requested = oldRequest + s.Value()
+			AddVGRequested(&vg, s.Value(), thin)
 			// Added to node cache
 			nc.AllocatedNum += 1
 			nc.VGs[ResourceName(vgName)] = vg
-			log.Debugf("[AddLVM]added pv %s: VG info: old size => %d, new size => %d for vg %s ",
-				pv.Name, oldRequest, vg.Requested, vgName)
+			log.Debugf("[AddLVM]added pv %s: VG info: old size => %d, new size => %d for vg %s(thin=%t)",
+				pv.Name, oldRequest, VGRequested(vg, thin), vgName, thin)
 		} else {
 			// ideally, this path should never be reached
 			// log.Errorf("[AddLVM]no vg %s found in for node %s when adding pv %s", vgName, nc.NodeName, pv.Name)
@@ -327,13 +483,14 @@ func (nc *NodeCache) UpdateLVM(old, pv *corev1.PersistentVolume) error {
 		}
 		if vg, ok := nc.VGs[ResourceName(vgName)]; ok {
 			// because it is already in cache, we only recalculate vg requested size and PV object
-			oldRequest := vg.Requested
+			thin := utils.IsThinLVMPV(pv)
+			oldRequest := VGRequested(vg, thin)
 			newPVsize := pv.Spec.Capacity[corev1.ResourceStorage]
 			oldPVsize := old.Spec.Capacity[corev1.ResourceStorage]
-			vg.Requested = oldRequest - oldPVsize.Value() + newPVsize.Value()
+			AddVGRequested(&vg, newPVsize.Value()-oldPVsize.Value(), thin)
 			nc.VGs[ResourceName(vgName)] = vg
-			log.Debugf("[UpdateLVM]updated pv %s: VG info: old size => %d, new size => %d for vg %s ",
-				pv.Name, oldRequest, vg.Requested, vgName)
+			log.Debugf("[UpdateLVM]updated pv %s: VG info: old size => %d, new size => %d for vg %s(thin=%t)",
+				pv.Name, oldRequest, VGRequested(vg, thin), vgName, thin)
 		} else {
 			// ideally, this path should never be reached
 			// log.Errorf("[UpdateLVM]no vg %s found in node cache when updating pv %s", vgName, pv.Name)
@@ -358,12 +515,13 @@ func (nc *NodeCache) RemoveLVM(pv *corev1.PersistentVolume) error {
 		log.Debugf("pv %s is not a valid open-local pv(lvm with name)", pv.Name)
 	}
 	if vg, ok := nc.VGs[ResourceName(vgName)]; ok {
-		oldUsed := vg.Requested
+		thin := utils.IsThinLVMPV(pv)
+		oldUsed := VGRequested(vg, thin)
 		s := pv.Spec.Capacity[corev1.ResourceStorage]
-		vg.Requested = oldUsed - s.Value()
+		AddVGRequested(&vg, -s.Value(), thin)
 		nc.AllocatedNum -= 1
 		nc.VGs[ResourceName(vgName)] = vg
-		log.Debugf("[RemoveLVM]removed pv %s: VG info: old size => %d, new size => %d for vg %s ", pv.Name, oldUsed, vg.Requested, vgName)
+		log.Debugf("[RemoveLVM]removed pv %s: VG info: old size => %d, new size => %d for vg %s(thin=%t)", pv.Name, oldUsed, VGRequested(vg, thin), vgName, thin)
 	} else {
 		nc.AllocatedNum -= 1
 		log.Debugf("[RemoveLVM]pv %s was not in the node cache, skipped updating", pv.Name)
@@ -423,13 +581,124 @@ func (nc *NodeCache) RemoveLocalMountPoint(pv *corev1.PersistentVolume) error {
 	return nil
 }
 
+// AddQuota add quota PV to cache
+// note: this function does not handle pv update event
+func (nc *NodeCache) AddQuota(pv *corev1.PersistentVolume) error {
+	if !nc.isNodeLocal(pv) {
+		return nil
+	}
+	nc.rwLock.Lock()
+	defer nc.rwLock.Unlock()
+	return nc.addQuota(pv)
+}
+
+func (nc *NodeCache) addQuota(pv *corev1.PersistentVolume) error {
+	mpName := utils.GetQuotaMountPointFromCsiPV(pv)
+	if len(mpName) == 0 {
+		log.Debugf("pv %s is not a valid open-local pv(quota with mount point)", pv.Name)
+		return nil
+	}
+	if _, ok := nc.LocalPVs[pv.Name]; ok {
+		log.Debugf("pv %s was already existed", pv.Name)
+		nc.LocalPVs[pv.Name] = *pv
+		return nil
+	}
+	if quota, ok := nc.Quotas[ResourceName(mpName)]; ok {
+		oldRequest := quota.Requested
+		s := pv.Spec.Capacity[corev1.ResourceStorage]
+		quota.Requested = oldRequest + s.Value()
+		nc.Quotas[ResourceName(mpName)] = quota
+		log.Debugf("[AddQuota]added pv %s: quota info: old size => %d, new size => %d for mount point %s ",
+			pv.Name, oldRequest, quota.Requested, mpName)
+	} else {
+		log.Debugf("[AddQuota]quota mount point %s not found in NodeCache", mpName)
+	}
+	nc.AllocatedNum += 1
+	nc.LocalPVs[pv.Name] = *pv
+
+	return nil
+}
+
+// UpdateQuota updates quota PV to cache
+func (nc *NodeCache) UpdateQuota(old, pv *corev1.PersistentVolume) error {
+	if !nc.isNodeLocal(pv) {
+		return nil
+	}
+	nc.rwLock.Lock()
+	defer nc.rwLock.Unlock()
+	mpName := utils.GetQuotaMountPointFromCsiPV(pv)
+	if len(mpName) == 0 {
+		log.Debugf("pv %s is not a valid open-local quota pv", pv.Name)
+		return nil
+	}
+	if _, ok := nc.LocalPVs[pv.Name]; !ok {
+		// pv is not in cache yet, treat it as an add event
+		return nc.addQuota(pv)
+	}
+	if quota, ok := nc.Quotas[ResourceName(mpName)]; ok {
+		oldRequest := quota.Requested
+		newPVsize := pv.Spec.Capacity[corev1.ResourceStorage]
+		oldPVsize := old.Spec.Capacity[corev1.ResourceStorage]
+		quota.Requested = oldRequest - oldPVsize.Value() + newPVsize.Value()
+		nc.Quotas[ResourceName(mpName)] = quota
+		log.Debugf("[UpdateQuota]updated pv %s: quota info: old size => %d, new size => %d for mount point %s ",
+			pv.Name, oldRequest, quota.Requested, mpName)
+	} else {
+		log.Debugf("[UpdateQuota]quota mount point %s not found in NodeCache", mpName)
+	}
+	nc.LocalPVs[pv.Name] = *pv
+
+	return nil
+}
+
+func (nc *NodeCache) RemoveQuota(pv *corev1.PersistentVolume) error {
+	if !nc.isNodeLocal(pv) {
+		return nil
+	}
+	nc.rwLock.Lock()
+	defer nc.rwLock.Unlock()
+	mpName := utils.GetQuotaMountPointFromCsiPV(pv)
+	if len(mpName) == 0 {
+		log.Debugf("pv %s is not a valid open-local pv(quota with mount point)", pv.Name)
+		return nil
+	}
+	if quota, ok := nc.Quotas[ResourceName(mpName)]; ok {
+		oldUsed := quota.Requested
+		s := pv.Spec.Capacity[corev1.ResourceStorage]
+		quota.Requested = oldUsed - s.Value()
+		nc.Quotas[ResourceName(mpName)] = quota
+		log.Debugf("[RemoveQuota]removed pv %s: quota info: old size => %d, new size => %d for mount point %s ", pv.Name, oldUsed, quota.Requested, mpName)
+	} else {
+		log.Debugf("[RemoveQuota]pv %s was not in the node cache, skipped updating", pv.Name)
+	}
+	nc.AllocatedNum -= 1
+	delete(nc.LocalPVs, pv.Name)
+	return nil
+}
+
+// AddLocalPV adds the open-local pv to node cache according to its type
+func (nc *NodeCache) AddLocalPV(pv *corev1.PersistentVolume, pvType pkg.VolumeType) error {
+	switch pvType {
+	case pkg.VolumeTypeLVM:
+		return nc.AddLVM(pv)
+	case pkg.VolumeTypeMountPoint:
+		return nc.AddLocalMountPoint(pv)
+	case pkg.VolumeTypeDevice:
+		return nc.AddLocalDevice(pv)
+	case pkg.VolumeTypeQuota:
+		return nc.AddQuota(pv)
+	default:
+		return fmt.Errorf("not a open-local pv, type %s", pvType)
+	}
+}
+
 func (nc *NodeCache) AddLocalDevice(pv *corev1.PersistentVolume) error {
 	if !nc.isNodeLocal(pv) {
 		return nil
 	}
 	nc.rwLock.Lock()
 	defer nc.rwLock.Unlock()
-	deviceName := utils.GetDeviceNameFromCsiPV(pv)
+	deviceName := nc.deviceOfPV(pv)
 	if len(deviceName) == 0 {
 		err := fmt.Errorf("pv %s is not a valid open-local pv(device with name)", pv.Name)
 		return err
@@ -456,7 +725,7 @@ func (nc *NodeCache) RemoveLocalDevice(pv *corev1.PersistentVolume) error {
 	}
 	nc.rwLock.Lock()
 	defer nc.rwLock.Unlock()
-	deviceName := utils.GetDeviceNameFromCsiPV(pv)
+	deviceName := nc.deviceOfPV(pv)
 	if len(deviceName) == 0 {
 		log.Debugf("pv %s is not a valid open-local pv(device with name)", pv.Name)
 	}
@@ -555,9 +824,12 @@ func (nc *NodeCache) IsLocalPVExist(kind pkg.VolumeType, resourceName string) bo
 				case pkg.VolumeTypeMountPoint:
 					name, exist = attributes[pkg.MPName]
 				case pkg.VolumeTypeDevice:
-					name, exist = attributes[pkg.DeviceName]
+					name = nc.deviceOfPV(&pv)
+					exist = name != ""
 				case pkg.VolumeTypeLVM:
 					name, exist = attributes[pkg.VGName]
+				case pkg.VolumeTypeQuota:
+					name, exist = attributes[pkg.QuotaName]
 				default:
 					exist = false
 				}
@@ -571,6 +843,47 @@ func (nc *NodeCache) IsLocalPVExist(kind pkg.VolumeType, resourceName string) bo
 	return false
 }
 
+// DeviceOfPV returns the current name of the device of the Device PV
+func (nc *NodeCache) DeviceOfPV(pv *corev1.PersistentVolume) string {
+	nc.rwLock.RLock()
+	defer nc.rwLock.RUnlock()
+	return nc.deviceOfPV(pv)
+}
+
+// deviceOfPV resolves the device of the PV by its by-id link, and falls back
+// to the recorded name for PVs created before by-id links were recorded
+func (nc *NodeCache) deviceOfPV(pv *corev1.PersistentVolume) string {
+	if pv.Spec.CSI != nil {
+		if name, ok := nc.deviceNames[pv.Spec.CSI.VolumeAttributes[pkg.DeviceID]]; ok {
+			return name
+		}
+	}
+	return utils.GetDeviceNameFromCsiPV(pv)
+}
+
+// devicesOfPVs returns the devices held by the Device PVs on the node
+func (nc *NodeCache) devicesOfPVs() map[string]bool {
+	devices := make(map[string]bool)
+	for i := range nc.LocalPVs {
+		pv := nc.LocalPVs[i]
+		if pv.Spec.CSI != nil && pv.Spec.CSI.VolumeAttributes[pkg.VolumeTypeKey] == string(pkg.VolumeTypeDevice) {
+			devices[nc.deviceOfPV(&pv)] = true
+		}
+	}
+	return devices
+}
+
+// deviceNamesOf returns the by-id links of the devices reported by the nls
+func deviceNamesOf(nodeLocal *nodelocalstorage.NodeLocalStorage) map[string]string {
+	names := make(map[string]string)
+	for _, d := range nodeLocal.Status.NodeStorageInfo.DeviceInfos {
+		if d.ByID != "" {
+			names[d.ByID] = d.Name
+		}
+	}
+	return names
+}
+
 func (nc *NodeCache) checkInlineVolumes(pod *corev1.Pod) bool {
 	contain, node := utils.ContainInlineVolumes(pod)
 	if contain && node == nc.NodeName && pod.Status.Phase == corev1.PodRunning {
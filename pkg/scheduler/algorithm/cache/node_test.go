@@ -0,0 +1,122 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	nodelocalstorage "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewNodeCacheFromStorageWithQuota(t *testing.T) {
+	nls := &nodelocalstorage.NodeLocalStorage{
+		ObjectMeta: metav1.ObjectMeta{Name: "testnode"},
+	}
+	nls.Spec.ListConfig.MountPoints.Quota = []string{"/mnt/open-local/disk-[12]"}
+	nls.Status.NodeStorageInfo.MountPoints = []nodelocalstorage.MountPoint{
+		{Name: "/mnt/open-local/disk-1", Total: 100, FsType: "xfs", Options: []string{"rw", "prjquota"}},
+		{Name: "/mnt/open-local/disk-2", Total: 200, FsType: "ext4", Options: []string{"rw"}},
+		{Name: "/mnt/open-local/disk-3", Total: 300, FsType: "xfs", Options: []string{"rw", "prjquota"}},
+	}
+	nls.Status.FilteredStorageInfo.MountPoints = []string{"/mnt/open-local/disk-1", "/mnt/open-local/disk-2", "/mnt/open-local/disk-3"}
+
+	nc := NewNodeCacheFromStorage(nls)
+	quota, ok := nc.Quotas["/mnt/open-local/disk-1"]
+	if !ok {
+		t.Fatalf("expect quota mount point /mnt/open-local/disk-1 in Quotas")
+	}
+	if quota.Capacity != 100 || quota.Requested != 0 {
+		t.Errorf("unexpected quota resource: %#v", quota)
+	}
+	if _, ok := nc.MountPoints["/mnt/open-local/disk-1"]; ok {
+		t.Errorf("quota mount point /mnt/open-local/disk-1 should not be in MountPoints")
+	}
+	if _, ok := nc.MountPoints["/mnt/open-local/disk-2"]; !ok {
+		t.Errorf("expect mount point /mnt/open-local/disk-2 in MountPoints")
+	}
+	if _, ok := nc.Quotas["/mnt/open-local/disk-2"]; ok {
+		t.Errorf("mount point /mnt/open-local/disk-2 should not be in Quotas")
+	}
+	// project quota enabled mount points are not shared unless listed
+	if _, ok := nc.Quotas["/mnt/open-local/disk-3"]; ok {
+		t.Errorf("mount point /mnt/open-local/disk-3 should not be in Quotas")
+	}
+	if _, ok := nc.MountPoints["/mnt/open-local/disk-3"]; !ok {
+		t.Errorf("expect mount point /mnt/open-local/disk-3 in MountPoints")
+	}
+}
+
+func TestDeviceResolvedByID(t *testing.T) {
+	byID := "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4"
+	nls := &nodelocalstorage.NodeLocalStorage{
+		ObjectMeta: metav1.ObjectMeta{Name: "testnode"},
+	}
+	// the disk was sdb when the pv was created and is sdc after a reboot
+	nls.Status.NodeStorageInfo.DeviceInfos = []nodelocalstorage.DeviceInfo{
+		{Name: "/dev/sdb", Total: 100},
+		{Name: "/dev/sdc", ByID: byID, Total: 200},
+	}
+	nls.Status.FilteredStorageInfo.Devices = []string{"/dev/sdb", "/dev/sdc"}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-device"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+				VolumeAttributes: map[string]string{
+					localtype.VolumeTypeKey: string(localtype.VolumeTypeDevice),
+					localtype.DeviceName:    "/dev/sdb",
+					localtype.DeviceID:      byID,
+				},
+			}},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key: localtype.KubernetesNodeIdentityKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"testnode"},
+				}},
+			}}}},
+		},
+	}
+
+	nc := NewNodeCacheFromStorage(nls)
+	if err := nc.AddLocalPV(pv, localtype.VolumeTypeDevice); err != nil {
+		t.Fatalf("failed to add pv: %v", err)
+	}
+	if nc.Devices["/dev/sdb"].IsAllocated || !nc.Devices["/dev/sdc"].IsAllocated {
+		t.Errorf("expect only /dev/sdc allocated, got %#v", nc.Devices)
+	}
+	if device := nc.DeepCopy().DeviceOfPV(pv); device != "/dev/sdc" {
+		t.Errorf("expect device /dev/sdc of pv in the copy, got %q", device)
+	}
+
+	// the device is renamed again, the cache follows it by its by-id link
+	nls.Status.NodeStorageInfo.DeviceInfos = []nodelocalstorage.DeviceInfo{
+		{Name: "/dev/sdc", Total: 100},
+		{Name: "/dev/sdd", ByID: byID, Total: 200},
+	}
+	nls.Status.FilteredStorageInfo.Devices = []string{"/dev/sdc", "/dev/sdd"}
+	nc.UpdateNodeInfo(nls)
+	if nc.Devices["/dev/sdc"].IsAllocated || !nc.Devices["/dev/sdd"].IsAllocated {
+		t.Errorf("expect only /dev/sdd allocated, got %#v", nc.Devices)
+	}
+	if err := nc.RemoveLocalDevice(pv); err != nil {
+		t.Fatalf("failed to remove pv: %v", err)
+	}
+	if nc.Devices["/dev/sdd"].IsAllocated || nc.AllocatedNum != 0 {
+		t.Errorf("expect /dev/sdd released, got %#v, allocated %d", nc.Devices, nc.AllocatedNum)
+	}
+}
@@ -18,8 +18,10 @@ package cache
 
 import (
 	"sync"
+	"time"
 
 	localtype "github.com/alibaba/open-local/pkg"
+	nodelocalstorage "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
 	"github.com/alibaba/open-local/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -40,7 +42,10 @@ type NodeInfo struct {
 	VGs         map[ResourceName]SharedResource
 	MountPoints map[ResourceName]ExclusiveResource
 	// Devices only contains the whitelist raw devices
-	Devices             map[ResourceName]ExclusiveResource
+	Devices map[ResourceName]ExclusiveResource
+	// Quotas only contains the mount points with project quota enabled,
+	// they are shared by Quota volumes and excluded from MountPoints
+	Quotas              map[ResourceName]SharedResource
 	AllocatedNum        int64
 	LocalPVs            map[string]corev1.PersistentVolume
 	PodInlineVolumeInfo map[string][]InlineVolumeInfo
@@ -49,6 +54,8 @@ type NodeInfo struct {
 type NodeCache struct {
 	rwLock sync.RWMutex
 	NodeInfo
+	// deviceNames maps the by-id links of the devices to their current kernel names
+	deviceNames map[string]string
 }
 
 type ResourceType string
@@ -66,12 +73,118 @@ type ExclusiveResource struct {
 	MediaType localtype.MediaType `json:"mediaType"`
 	// "IsAllocated = true" means the disk is used by PV
 	IsAllocated bool `json:"isAllocated,string"`
+	// Condition is the condition of the device reported by the agent
+	Condition nodelocalstorage.StorageConditionType `json:"condition,omitempty"`
+}
+
+// Unhealthy returns true if the resource should not be allocated for its condition
+func (r ExclusiveResource) Unhealthy() bool {
+	return unhealthy(r.Condition)
 }
 
 type SharedResource struct {
 	Name      string `json:"name"`
 	Capacity  int64  `json:"capacity,string"`
 	Requested int64  `json:"requested,string"`
+	// MediaType is only known for VGs
+	MediaType localtype.MediaType `json:"mediaType,omitempty"`
+	// Condition is only known for VGs
+	Condition nodelocalstorage.StorageConditionType `json:"condition,omitempty"`
+	// ThinCapacity and ThinRequested are only known for VGs, thin volumes are
+	// provisioned from the thin pool, so they are accounted apart from Capacity and Requested.
+	// ThinCapacity is 0 until the thin pool is created
+	ThinCapacity  int64 `json:"thinCapacity,string,omitempty"`
+	ThinRequested int64 `json:"thinRequested,string,omitempty"`
+}
+
+// Unhealthy returns true if the resource should not be allocated for its condition
+func (r SharedResource) Unhealthy() bool {
+	return unhealthy(r.Condition)
+}
+
+func unhealthy(condition nodelocalstorage.StorageConditionType) bool {
+	switch condition {
+	case nodelocalstorage.StorageDegraded, nodelocalstorage.StorageFailed, nodelocalstorage.StorageFault:
+		return true
+	}
+	return false
+}
+
+// ThinClass is how the thin volumes of a storage class are provisioned
+type ThinClass struct {
+	// PoolPercent is the percentage of the free size of vg the thin pool is created with, if it does not exist
+	PoolPercent uint32
+	// OvercommitRatio is the ratio of the virtual size of thin volumes to the size of the thin pool
+	OvercommitRatio float64
+}
+
+// NewThinClass returns the ThinClass of the parameters of a storage class or the attributes of a pv,
+// with the thin overcommit ratio of the storage class in the scheduling policy
+func NewThinClass(parameters map[string]string, overcommitRatio float64) ThinClass {
+	percent, err := utils.GetThinPoolPercent(parameters)
+	if err != nil {
+		log.Warningf("use the default thin pool percentage %d: %s", localtype.DefaultThinPoolPercent, err.Error())
+		percent = localtype.DefaultThinPoolPercent
+	}
+	return ThinClass{PoolPercent: percent, OvercommitRatio: overcommitRatio}
+}
+
+// VGCapacity returns the capacity of vg for thick or thin volumes, class is only used by thin volumes.
+// Thin volumes are allowed to overcommit the thin pool by the ratio of the class, 1.0 if not set, and the pool
+// not created yet is estimated as what it is created with by the first thin volume of the class
+func VGCapacity(vg SharedResource, thin bool, class ThinClass) int64 {
+	if !thin {
+		return vg.Capacity
+	}
+	pool := vg.ThinCapacity
+	if pool == 0 {
+		pool = vgThinPoolSize(vg, class)
+	}
+	ratio := class.OvercommitRatio
+	if ratio < 1.0 {
+		ratio = localtype.DefaultThinOvercommitRatio
+	}
+	return int64(float64(pool) * ratio)
+}
+
+// vgThinPoolSize returns the data size of the thin pool to be created on vg, which is taken
+// from the free size of vg, so it never takes the size already requested by thick volumes
+func vgThinPoolSize(vg SharedResource, class ThinClass) int64 {
+	free := vg.Capacity - vg.Requested
+	if free <= 0 {
+		return 0
+	}
+	return free * int64(class.PoolPercent) / 100
+}
+
+// VGRequested returns the requested size of vg by thick or thin volumes
+func VGRequested(vg SharedResource, thin bool) int64 {
+	if !thin {
+		return vg.Requested
+	}
+	return vg.ThinRequested
+}
+
+// VGFree returns the free size of vg for thick or thin volumes, class is only used by thin volumes
+func VGFree(vg SharedResource, thin bool, class ThinClass) int64 {
+	return VGCapacity(vg, thin, class) - VGRequested(vg, thin)
+}
+
+// AddVGRequested adds size to the requested size of vg by thick or thin volumes
+func AddVGRequested(vg *SharedResource, size int64, thin bool) {
+	if !thin {
+		vg.Requested += size
+		return
+	}
+	vg.ThinRequested += size
+}
+
+// vgThinCapacity returns the data size of the thin pool of vg, 0 if the pool is not created yet
+func vgThinCapacity(vg nodelocalstorage.VolumeGroup) int64 {
+	if vg.ThinPool != nil {
+		return int64(vg.ThinPool.Total)
+	}
+	return 0
 }
 
 type AllocatedUnit struct {
@@ -83,6 +196,26 @@ type AllocatedUnit struct {
 	Device     string
 	MountPoint string
 	PVCName    string
+	Thin       bool // whether the LVM volume is thin provisioned
+	// ThinClass is how the thin LVM volume is provisioned
+	ThinClass ThinClass
+}
+
+// AssumedUnit is an allocated unit written into cache before its pv is created,
+// it is released if the pv is not created before ExpireAt
+type AssumedUnit struct {
+	AllocatedUnit
+	ExpireAt time.Time
+}
+
+// Drift is the difference of a resource between the cache and the one rebuilt from apiserver,
+// Cached and Actual are the requested size, or 1/0 whether allocated for exclusive resources
+type Drift struct {
+	NodeName   string
+	VolumeType localtype.VolumeType
+	Name       string
+	Cached     int64
+	Actual     int64
 }
 
 // pvc and binding info mapping
@@ -282,3 +282,34 @@ func TestNewPvcStatusInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestVGThinCapacity(t *testing.T) {
+	half := NewThinClass(nil, pkg.DefaultThinOvercommitRatio)
+	quarter := NewThinClass(map[string]string{pkg.ParamThinPoolPercent: "25"}, pkg.DefaultThinOvercommitRatio)
+	overcommitted := NewThinClass(map[string]string{pkg.ParamThinPoolPercent: "25"}, 2.0)
+	tests := []struct {
+		name     string
+		vg       SharedResource
+		class    ThinClass
+		capacity int64
+	}{
+		// the pool to be created takes the percentage of the class from the size left by thick volumes
+		{"no pool", SharedResource{Capacity: 100}, half, 50},
+		{"no pool of class", SharedResource{Capacity: 100}, quarter, 25},
+		{"no pool with thick volumes", SharedResource{Capacity: 100, Requested: 70}, half, 15},
+		{"no pool with full vg", SharedResource{Capacity: 100, Requested: 100}, half, 0},
+		{"pool created", SharedResource{Capacity: 40, Requested: 40, ThinCapacity: 60}, quarter, 60},
+		{"overcommitted pool", SharedResource{Capacity: 40, Requested: 40, ThinCapacity: 60}, overcommitted, 120},
+		{"overcommitted pool not created", SharedResource{Capacity: 100}, overcommitted, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if capacity := VGCapacity(tt.vg, true, tt.class); capacity != tt.capacity {
+				t.Errorf("expect thin capacity %d, got %d", tt.capacity, capacity)
+			}
+			if capacity := VGCapacity(tt.vg, false, tt.class); capacity != tt.vg.Capacity {
+				t.Errorf("expect thick capacity %d, got %d", tt.vg.Capacity, capacity)
+			}
+		})
+	}
+}
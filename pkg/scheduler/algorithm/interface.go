@@ -18,11 +18,12 @@ package algorithm
 
 import (
 	"sync"
+	"time"
 
 	"github.com/alibaba/open-local/pkg"
-
 	nodelocalstorageinformer "github.com/alibaba/open-local/pkg/generated/informers/externalversions/storage/v1alpha1"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
 	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions/volumesnapshot/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
@@ -46,20 +47,23 @@ type NodeBinder interface {
 // 3. nodelocalstorage cache
 
 type SchedulingContext struct {
-	CtxLock                sync.RWMutex
-	ClusterNodeCache       *cache.ClusterNodeCache
-	CoreV1Informers        corev1informers.Interface
-	StorageV1Informers     storagev1informers.Interface
-	SnapshotInformers      volumesnapshotinformers.Interface
-	LocalStorageInformer   nodelocalstorageinformer.Interface
-	NodeAntiAffinityWeight *pkg.NodeAntiAffinityWeight
+	CtxLock              sync.RWMutex
+	ClusterNodeCache     *cache.ClusterNodeCache
+	CoreV1Informers      corev1informers.Interface
+	StorageV1Informers   storagev1informers.Interface
+	SnapshotInformers    volumesnapshotinformers.Interface
+	LocalStorageInformer nodelocalstorageinformer.Interface
+	// Policy holds the scheduling policy, which is replaced when reloaded
+	Policy *policy.Holder
+	// CacheReconcileInterval is the interval to rebuild ClusterNodeCache from listers
+	CacheReconcileInterval time.Duration
 }
 
 func NewSchedulingContext(coreV1Informers corev1informers.Interface,
 	storageV1informers storagev1informers.Interface,
 	localStorageInformer nodelocalstorageinformer.Interface,
 	snapshotInformer volumesnapshotinformers.Interface,
-	weights *pkg.NodeAntiAffinityWeight) *SchedulingContext {
+	schedulingPolicy *policy.Policy) *SchedulingContext {
 
 	return &SchedulingContext{
 		CtxLock:                sync.RWMutex{},
@@ -68,7 +72,8 @@ func NewSchedulingContext(coreV1Informers corev1informers.Interface,
 		StorageV1Informers:     storageV1informers,
 		LocalStorageInformer:   localStorageInformer,
 		SnapshotInformers:      snapshotInformer,
-		NodeAntiAffinityWeight: weights,
+		Policy:                 policy.NewHolder(schedulingPolicy),
+		CacheReconcileInterval: pkg.DefaultCacheReconcileInterval,
 	}
 
 }
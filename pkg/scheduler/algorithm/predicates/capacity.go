@@ -29,7 +29,7 @@ import (
 )
 
 // CapacityPredicate checks if local storage on a node matches the persistent volume claims, follow rules are applied:
-// 1. pvc contains vg or mount point or device claim
+// 1. pvc contains vg or mount point or device or quota claim
 // 2. node free size must larger or equal to pvcs
 // 3. for pvc of type mount point/device:
 //	 a. must contains more mount points than pvc count
@@ -38,7 +38,7 @@ func CapacityPredicate(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *
 	defer trace.LogIfLong(50 * time.Millisecond)
 
 	containReadonlySnapshot := false
-	err, lvmPVCs, mpPVCs, devicePVCs := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
+	err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
 	if err != nil {
 		return false, err
 	}
@@ -54,11 +54,13 @@ func CapacityPredicate(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *
 		}
 	}
 
+	// pvcs of all volume types share the constraints of the pod
+	constraints := algo.NewVolumeConstraints(lvmPVCs, mpPVCs, devicePVCs, node, ctx)
 	var fits bool
 	if len(lvmPVCs) > 0 {
 		trace.Step("Computing AllocateLVMVolume")
 
-		fits, _, err = algo.AllocateLVMVolume(pod, lvmPVCs, node, ctx)
+		fits, _, err = algo.AllocateLVMVolume(pod, lvmPVCs, node, ctx, constraints)
 		if err != nil {
 			log.Error(err)
 			return false, err
@@ -70,7 +72,7 @@ func CapacityPredicate(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *
 	if len(mpPVCs) > 0 {
 		trace.Step("Computing AllocateMountPointVolume")
 
-		fits, _, err = algo.AllocateMountPointVolume(pod, mpPVCs, node, ctx)
+		fits, _, err = algo.AllocateMountPointVolume(pod, mpPVCs, node, ctx, constraints)
 		if err != nil {
 			log.Error(err)
 			return false, err
@@ -82,7 +84,19 @@ func CapacityPredicate(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *
 	if len(devicePVCs) > 0 {
 		trace.Step("Computing AllocateDeviceVolume")
 
-		fits, _, err = algo.AllocateDeviceVolume(pod, devicePVCs, node, ctx)
+		fits, _, err = algo.AllocateDeviceVolume(pod, devicePVCs, node, ctx, constraints)
+		if err != nil {
+			log.Error(err)
+			return false, err
+		} else if !fits {
+			return false, nil
+		}
+	}
+
+	if len(quotaPVCs) > 0 {
+		trace.Step("Computing AllocateQuotaVolume")
+
+		fits, _, err = algo.AllocateQuotaVolume(pod, quotaPVCs, node, ctx)
 		if err != nil {
 			log.Error(err)
 			return false, err
@@ -92,7 +106,7 @@ func CapacityPredicate(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *
 	}
 
 	containReadonlySnapshot = true
-	err, lvmPVCs, _, _ = algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
+	err, lvmPVCs, _, _, _ = algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
 	if err != nil {
 		return false, err
 	}
@@ -109,7 +123,7 @@ func CapacityPredicate(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *
 		}
 	}
 
-	if len(lvmPVCs) <= 0 && len(mpPVCs) <= 0 && len(devicePVCs) <= 0 && !containInlineVolume {
+	if len(lvmPVCs) <= 0 && len(mpPVCs) <= 0 && len(devicePVCs) <= 0 && len(quotaPVCs) <= 0 && !containInlineVolume {
 		log.Infof("no open-local volume request on pod %s, skipped", pod.Name)
 		return true, nil
 	}
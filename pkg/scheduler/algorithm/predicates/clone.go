@@ -0,0 +1,47 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/algo"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	utiltrace "k8s.io/utils/trace"
+)
+
+// ClonePredicate checks if node is where the source volumes of clone pvcs are
+func ClonePredicate(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *corev1.Node) (bool, error) {
+	trace := utiltrace.New(fmt.Sprintf("Scheduling[ClonePredicate] %s/%s", pod.Namespace, pod.Name))
+	defer trace.LogIfLong(50 * time.Millisecond)
+
+	containReadonlySnapshot := false
+	err, lvmPVCs, _, _, _ := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
+	if err != nil {
+		return false, err
+	}
+	if len(lvmPVCs) <= 0 {
+		log.Infof("[ClonePredicate]no open-local lvm volume request on pod %s, skipped", pod.Name)
+		return true, nil
+	}
+
+	trace.Step("Computing ProcessClonePVC")
+	return algo.ProcessClonePVC(lvmPVCs, node, ctx)
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
 	"github.com/alibaba/open-local/pkg/scheduler/errors"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
 	"github.com/alibaba/open-local/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -31,7 +32,7 @@ import (
 type Predicate struct {
 	Name           string
 	Ctx            *algorithm.SchedulingContext
-	PredicateFuncs []PredicateFunc
+	PredicateFuncs map[string]PredicateFunc
 }
 
 // PredicateFunc a single predicate implementation for any algorithm
@@ -42,10 +43,11 @@ type Predicate struct {
 type PredicateFunc func(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *corev1.Node) (bool, error)
 
 var (
-	// Newly added predicates should be placed here
-	DefaultPredicateFuncs = []PredicateFunc{
+	// Newly added predicates should be placed here, keyed by their names in scheduler policy
+	DefaultPredicateFuncs = map[string]PredicateFunc{
 		//LuckyPredicate,
-		CapacityPredicate,
+		policy.PredicateCapacity: CapacityPredicate,
+		policy.PredicateClone:    ClonePredicate,
 	}
 )
 
@@ -84,7 +86,7 @@ func (p Predicate) Handler(args schedulerapi.ExtenderArgs) (*schedulerapi.Extend
 			log.Errorf("unable to fetch node cache %s from informer: %s", nodeName, err.Error())
 			continue
 		}
-		fits, failReasons, err := Predicates(p.Ctx, p.PredicateFuncs, pod, node)
+		fits, failReasons, err := Predicates(p.Ctx, EnabledPredicateFuncs(p.Ctx, p.PredicateFuncs), pod, node)
 		log.Infof("pod=%s/%s, node=%s,fits: %t,failReasons: %s, err: %v",
 			pod.Namespace, pod.Name, node.Name, fits, failReasons, err)
 
@@ -123,6 +125,18 @@ func (p Predicate) Handler(args schedulerapi.ExtenderArgs) (*schedulerapi.Extend
 	return &result, nil
 }
 
+// EnabledPredicateFuncs returns the predicates enabled in the current scheduling policy
+func EnabledPredicateFuncs(ctx *algorithm.SchedulingContext, predicateFuncs map[string]PredicateFunc) []PredicateFunc {
+	schedulingPolicy := ctx.Policy.Get()
+	enabled := make([]PredicateFunc, 0, len(predicateFuncs))
+	for _, name := range policy.ValidPredicates {
+		if pre, ok := predicateFuncs[name]; ok && schedulingPolicy.PredicateEnabled(name) {
+			enabled = append(enabled, pre)
+		}
+	}
+	return enabled
+}
+
 func Predicates(Ctx *algorithm.SchedulingContext, PredicateFuncs []PredicateFunc, pod *corev1.Pod, node *corev1.Node) (fits bool, failedReasons []string, err error) {
 	for _, pre := range PredicateFuncs {
 		fits, err = pre(Ctx, pod, node)
@@ -17,23 +17,159 @@ limitations under the License.
 package preemptions
 
 import (
-	v1 "k8s.io/api/core/v1"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/predicates"
+	"github.com/alibaba/open-local/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	schedulerapi "k8s.io/kube-scheduler/extender/v1"
 )
 
+// Preemption prunes the candidate nodes of preemption whose victims can not release
+// enough local storage for the preemptor
 type Preemption struct {
-	Func func(
-		pod v1.Pod,
-		nodeNameToVictims map[string]*schedulerapi.Victims,
-		nodeNameToMetaVictims map[string]*schedulerapi.MetaVictims,
-	) map[string]*schedulerapi.MetaVictims
+	Name string
+	Ctx  *algorithm.SchedulingContext
 }
 
-func (b Preemption) Handler(
+func NewPreemption(ctx *algorithm.SchedulingContext) *Preemption {
+	if ctx == nil {
+		panic("scheduling context must not be nil")
+	}
+	return &Preemption{"open-local-preemption", ctx}
+}
+
+func (p Preemption) Handler(
 	args schedulerapi.ExtenderPreemptionArgs,
 ) *schedulerapi.ExtenderPreemptionResult {
-	nodeNameToMetaVictims := b.Func(*args.Pod, args.NodeNameToVictims, args.NodeNameToMetaVictims)
+	pod := args.Pod
+	nodeNameToVictims := p.getNodeNameToVictims(args)
+	nodeNameToMetaVictims := make(map[string]*schedulerapi.MetaVictims, len(nodeNameToVictims))
+	for nodeName, victims := range nodeNameToVictims {
+		if !utils.NeedSkip(schedulerapi.ExtenderArgs{Pod: pod}) && !p.victimsReleaseEnoughStorage(pod, nodeName, victims.Pods) {
+			log.Infof("preemption: victims on node %s can not release enough local storage for pod %s/%s, pruned", nodeName, pod.Namespace, pod.Name)
+			continue
+		}
+		nodeNameToMetaVictims[nodeName] = convertToMetaVictims(victims)
+	}
 	return &schedulerapi.ExtenderPreemptionResult{
 		NodeNameToMetaVictims: nodeNameToMetaVictims,
 	}
 }
+
+// victimsReleaseEnoughStorage checks whether the pod fits the local storage of node after the victims are evicted,
+// the storage released by a victim is its inline volumes and the volumes of pvcs owned by it, e.g. generic ephemeral volumes,
+// as other pvcs and their volumes outlive the pod
+func (p Preemption) victimsReleaseEnoughStorage(pod *corev1.Pod, nodeName string, victims []*corev1.Pod) bool {
+	node, err := p.Ctx.CoreV1Informers.Nodes().Lister().Get(nodeName)
+	if err != nil {
+		log.Errorf("preemption: unable to fetch node %s from informer: %s", nodeName, err.Error())
+		return true
+	}
+	fits, _, err := predicates.Predicates(p.Ctx, predicates.EnabledPredicateFuncs(p.Ctx, predicates.DefaultPredicateFuncs), pod, node)
+	if err != nil {
+		log.Errorf("preemption: failed to predicate pod %s/%s on node %s: %s", pod.Namespace, pod.Name, nodeName, err.Error())
+		return true
+	}
+	if fits {
+		// local storage is not the reason to preempt
+		return true
+	}
+	nodeCache := p.Ctx.ClusterNodeCache.GetNodeCache(nodeName)
+	if nodeCache == nil {
+		return false
+	}
+
+	simulated := cache.NewClusterNodeCache()
+	simulatedNode := simulated.SetNodeCache(nodeCache.DeepCopy())
+	for _, victim := range victims {
+		_ = simulatedNode.DeletePodInlineVolumeInfo(victim)
+		for _, volume := range victim.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			pvc, err := p.Ctx.CoreV1Informers.PersistentVolumeClaims().Lister().PersistentVolumeClaims(victim.Namespace).Get(volume.PersistentVolumeClaim.ClaimName)
+			if err != nil || !metav1.IsControlledBy(pvc, victim) {
+				continue
+			}
+			pv, exist := simulatedNode.LocalPVs[pvc.Spec.VolumeName]
+			if !exist {
+				continue
+			}
+			unit, err := algorithm.ConvertAUFromPV(&pv, p.Ctx.StorageV1Informers, p.Ctx.CoreV1Informers)
+			if err != nil {
+				continue
+			}
+			if err := simulated.Unassume([]cache.AllocatedUnit{*unit}); err != nil {
+				log.Warningf("preemption: failed to release pv %s of victim %s/%s: %s", pv.Name, victim.Namespace, victim.Name, err.Error())
+			}
+			delete(simulatedNode.LocalPVs, pv.Name)
+		}
+	}
+
+	simulatedCtx := &algorithm.SchedulingContext{
+		ClusterNodeCache:     simulated,
+		CoreV1Informers:      p.Ctx.CoreV1Informers,
+		StorageV1Informers:   p.Ctx.StorageV1Informers,
+		SnapshotInformers:    p.Ctx.SnapshotInformers,
+		LocalStorageInformer: p.Ctx.LocalStorageInformer,
+		Policy:               p.Ctx.Policy,
+	}
+	fits, failReasons, err := predicates.Predicates(simulatedCtx, predicates.EnabledPredicateFuncs(simulatedCtx, predicates.DefaultPredicateFuncs), pod, node)
+	if err != nil {
+		log.Errorf("preemption: failed to predicate pod %s/%s on node %s without victims: %s", pod.Namespace, pod.Name, nodeName, err.Error())
+		return false
+	}
+	if !fits {
+		log.Infof("preemption: pod %s/%s does not fit node %s without victims, reason: %s", pod.Namespace, pod.Name, nodeName, failReasons)
+	}
+	return fits
+}
+
+// getNodeNameToVictims returns the victims with pods, pods of meta victims are fetched
+// from the informer when the extender is node cache capable
+func (p Preemption) getNodeNameToVictims(args schedulerapi.ExtenderPreemptionArgs) map[string]*schedulerapi.Victims {
+	if args.NodeNameToVictims != nil {
+		return args.NodeNameToVictims
+	}
+	nodeNameToVictims := make(map[string]*schedulerapi.Victims, len(args.NodeNameToMetaVictims))
+	if len(args.NodeNameToMetaVictims) == 0 {
+		return nodeNameToVictims
+	}
+	pods, err := p.Ctx.CoreV1Informers.Pods().Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("preemption: failed to list pods: %s", err.Error())
+	}
+	podsByUID := make(map[types.UID]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podsByUID[pod.UID] = pod
+	}
+	for nodeName, metaVictims := range args.NodeNameToMetaVictims {
+		victims := &schedulerapi.Victims{NumPDBViolations: metaVictims.NumPDBViolations}
+		for _, metaPod := range metaVictims.Pods {
+			pod, ok := podsByUID[types.UID(metaPod.UID)]
+			if !ok {
+				// keep the victim as is
+				pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(metaPod.UID)}}
+			}
+			victims.Pods = append(victims.Pods, pod)
+		}
+		nodeNameToVictims[nodeName] = victims
+	}
+	return nodeNameToVictims
+}
+
+func convertToMetaVictims(victims *schedulerapi.Victims) *schedulerapi.MetaVictims {
+	metaVictims := &schedulerapi.MetaVictims{
+		Pods:             []*schedulerapi.MetaPod{},
+		NumPDBViolations: victims.NumPDBViolations,
+	}
+	for _, pod := range victims.Pods {
+		metaVictims.Pods = append(metaVictims.Pods, &schedulerapi.MetaPod{UID: string(pod.UID)})
+	}
+	return metaVictims
+}
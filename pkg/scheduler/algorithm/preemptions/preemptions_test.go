@@ -0,0 +1,150 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemptions
+
+import (
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	localinformers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	volumesnapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	schedulerapi "k8s.io/kube-scheduler/extender/v1"
+)
+
+const (
+	testNode = "node1"
+	testVG   = "ssd"
+)
+
+func newTestContext(t *testing.T, objects ...interface{}) *algorithm.SchedulingContext {
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0)
+	localInformerFactory := localinformers.NewSharedInformerFactory(localfake.NewSimpleClientset(), 0)
+	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(volumesnapshotfake.NewSimpleClientset(), 0)
+	ctx := algorithm.NewSchedulingContext(kubeInformerFactory.Core().V1(), kubeInformerFactory.Storage().V1(),
+		localInformerFactory.Csi().V1alpha1(), snapshotInformerFactory.Snapshot().V1beta1(), policy.NewDefaultPolicy(localtype.StrategyBinpack))
+	for _, obj := range objects {
+		var err error
+		switch o := obj.(type) {
+		case *corev1.Node:
+			err = ctx.CoreV1Informers.Nodes().Informer().GetIndexer().Add(o)
+		case *corev1.Pod:
+			err = ctx.CoreV1Informers.Pods().Informer().GetIndexer().Add(o)
+		case *corev1.PersistentVolumeClaim:
+			err = ctx.CoreV1Informers.PersistentVolumeClaims().Informer().GetIndexer().Add(o)
+		case *storagev1.StorageClass:
+			err = ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(o)
+		}
+		if err != nil {
+			t.Fatalf("failed to add object to informer: %v", err)
+		}
+	}
+	return ctx
+}
+
+func newInlineVolumePod(name, size string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+		Spec: corev1.PodSpec{
+			NodeName: testNode,
+			Volumes: []corev1.Volume{{
+				Name: "inline",
+				VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{
+					Driver:           localtype.ProvisionerName,
+					VolumeAttributes: map[string]string{localtype.VGName: testVG, localtype.ParamLVSize: size},
+				}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestPreemptionHandler(t *testing.T) {
+	sc := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "open-local-lvm"},
+		Provisioner: localtype.ProvisionerName,
+		Parameters:  map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VGName: testVG},
+	}
+	scName := sc.Name
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &scName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	preemptor := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "preemptor", Namespace: "default"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name}},
+		}}},
+	}
+	storageVictim := newInlineVolumePod("storage-victim", "60Gi")
+	otherVictim := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other-victim", Namespace: "default", UID: "uid-other-victim"}}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNode}}
+
+	ctx := newTestContext(t, sc, pvc, preemptor, storageVictim, otherVictim, node)
+	nc := cache.NewNodeCache(testNode)
+	nc.VGs[testVG] = cache.SharedResource{Name: testVG, Capacity: 100 << 30}
+	if err := nc.AddPodInlineVolumeInfo(storageVictim); err != nil {
+		t.Fatalf("failed to add inline volume: %v", err)
+	}
+	ctx.ClusterNodeCache.SetNodeCache(nc)
+
+	p := NewPreemption(ctx)
+	tests := []struct {
+		name    string
+		victims []*corev1.Pod
+		kept    bool
+	}{
+		{name: "victim releasing enough storage", victims: []*corev1.Pod{storageVictim}, kept: true},
+		{name: "victim releasing no storage", victims: []*corev1.Pod{otherVictim}, kept: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metaVictims := &schedulerapi.MetaVictims{}
+			for _, victim := range tt.victims {
+				metaVictims.Pods = append(metaVictims.Pods, &schedulerapi.MetaPod{UID: string(victim.UID)})
+			}
+			result := p.Handler(schedulerapi.ExtenderPreemptionArgs{
+				Pod:                   preemptor,
+				NodeNameToMetaVictims: map[string]*schedulerapi.MetaVictims{testNode: metaVictims},
+			})
+			if _, kept := result.NodeNameToMetaVictims[testNode]; kept != tt.kept {
+				t.Errorf("expect node kept %t, got %t", tt.kept, kept)
+			}
+		})
+	}
+	if nc.VGs[testVG].Requested != 60<<30 {
+		t.Errorf("node cache should not be changed by preemption, requested %d", nc.VGs[testVG].Requested)
+	}
+}
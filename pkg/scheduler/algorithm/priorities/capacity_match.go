@@ -36,13 +36,13 @@ func CapacityMatch(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *core
 	trace := utiltrace.New(fmt.Sprintf("Scheduling[CapacityMatch] %s/%s", pod.Namespace, pod.Name))
 	defer trace.LogIfLong(50 * time.Millisecond)
 	containReadonlySnapshot := true
-	err, lvmPVCs, mpPVCs, devicePVCs := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
+	err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
 	if err != nil {
 		return MinScore, err
 	}
 	containInlineVolume, _ := utils.ContainInlineVolumes(pod)
 	// if pod has no open-local pvc, it should be scheduled to non Open-Local nodes
-	if len(lvmPVCs) <= 0 && len(mpPVCs) <= 0 && len(devicePVCs) <= 0 && !containInlineVolume {
+	if len(lvmPVCs) <= 0 && len(mpPVCs) <= 0 && len(devicePVCs) <= 0 && len(quotaPVCs) <= 0 && !containInlineVolume {
 		log.Infof("no open-local volume request on pod %s, skipped", pod.Name)
 		if algorithm.IsLocalNode(node.Name, ctx) {
 			log.Infof("node %s is open-local node, so pod %s gets minimal score %d", node.Name, pod.Name, MinScore)
@@ -57,18 +57,25 @@ func CapacityMatch(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *core
 		return MaxScore, nil
 	}
 
+	// pvcs of all volume types share the constraints of the pod
+	constraints := algo.NewVolumeConstraints(lvmPVCs, mpPVCs, devicePVCs, node, ctx)
 	trace.Step("Computing ScoreLVMVolume")
-	lvmScore, _, err := algo.ScoreLVMVolume(pod, lvmPVCs, node, ctx)
+	lvmScore, _, err := algo.ScoreLVMVolume(pod, lvmPVCs, node, ctx, constraints)
 	if err != nil {
 		return MinScore, err
 	}
 	trace.Step("Computing ScoreMountPointVolume")
-	mpScore, _, err := algo.ScoreMountPointVolume(pod, mpPVCs, node, ctx)
+	mpScore, _, err := algo.ScoreMountPointVolume(pod, mpPVCs, node, ctx, constraints)
 	if err != nil {
 		return MinScore, err
 	}
 	trace.Step("Computing ScoreDeviceVolume")
-	deviceScore, _, err := algo.ScoreDeviceVolume(pod, devicePVCs, node, ctx)
+	deviceScore, _, err := algo.ScoreDeviceVolume(pod, devicePVCs, node, ctx, constraints)
+	if err != nil {
+		return MinScore, err
+	}
+	trace.Step("Computing ScoreQuotaVolume")
+	quotaScore, _, err := algo.ScoreQuotaVolume(pod, quotaPVCs, node, ctx)
 	if err != nil {
 		return MinScore, err
 	}
@@ -78,6 +85,6 @@ func CapacityMatch(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *core
 		return MinScore, err
 	}
 
-	score := lvmScore + mpScore + deviceScore + inlineScore
+	score := lvmScore + mpScore + deviceScore + quotaScore + inlineScore
 	return score, nil
 }
@@ -32,7 +32,7 @@ func NodeAntiAffinity(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *c
 	trace := utiltrace.New(fmt.Sprintf("Scheduling[NodeAntiAffinity] %s/%s", pod.Namespace, pod.Name))
 	defer trace.LogIfLong(50 * time.Millisecond)
 	containReadonlySnapshot := false
-	err, _, mpPVCs, devicePVCs := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
+	err, _, mpPVCs, devicePVCs, _ := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
 	if err != nil {
 		return MinScore, err
 	}
@@ -53,7 +53,7 @@ func NodeAntiAffinity(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *c
 		return 0, err
 	}
 	volumeTypeAntiFound := make(map[pkg.VolumeType]bool)
-	for volumeType, weight := range ctx.NodeAntiAffinityWeight.Items(false) {
+	for volumeType, weight := range ctx.Policy.Get().NodeAntiAffinity {
 		if weight <= 0 {
 			continue
 		}
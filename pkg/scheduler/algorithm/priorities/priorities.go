@@ -18,6 +18,7 @@ package priorities
 
 import (
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
 	"github.com/alibaba/open-local/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -25,12 +26,12 @@ import (
 )
 
 var (
-	// Newly added predicates should be placed here
-	DefaultPrioritizeFuncs = []PrioritizeFunc{
+	// Newly added priorities should be placed here, keyed by their names in scheduler policy
+	DefaultPrioritizeFuncs = map[string]PrioritizeFunc{
 		//LuckyPredicate,
-		CapacityMatch,
-		CountMatch,
-		NodeAntiAffinity,
+		policy.PriorityCapacityMatch:    CapacityMatch,
+		policy.PriorityCountMatch:       CountMatch,
+		policy.PriorityNodeAntiAffinity: NodeAntiAffinity,
 	}
 )
 
@@ -39,7 +40,7 @@ type PrioritizeFunc func(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node
 type Prioritize struct {
 	Name            string
 	Ctx             *algorithm.SchedulingContext
-	PrioritizeFuncs []PrioritizeFunc
+	PrioritizeFuncs map[string]PrioritizeFunc
 }
 
 func (p Prioritize) Handler(args schedulerapi.ExtenderArgs) (*schedulerapi.HostPriorityList, error) {
@@ -54,12 +55,18 @@ func (p Prioritize) Handler(args schedulerapi.ExtenderArgs) (*schedulerapi.HostP
 		}
 	}
 	hostPriorityList := InitHostPriorityList(nodeNames)
-	if len(p.Ctx.NodeAntiAffinityWeight.Items(false)) == 0 && utils.NeedSkip(args) {
+	schedulingPolicy := p.Ctx.Policy.Get()
+	if !schedulingPolicy.NodeAntiAffinityEnabled() && utils.NeedSkip(args) {
 		log.Infof("priorities: skip pod %s/%s scheduling", pod.Namespace, pod.Name)
 		return &hostPriorityList, nil
 	}
 
-	for _, pri := range p.PrioritizeFuncs {
+	for _, name := range policy.ValidPriorities {
+		pri, ok := p.PrioritizeFuncs[name]
+		weight := schedulingPolicy.PriorityWeight(name)
+		if !ok || weight == 0 {
+			continue
+		}
 		for i, nodeName := range nodeNames {
 			log.Infof("prioritizing pod %s/%s with node %s", pod.Namespace, pod.Name, nodeName)
 			node, err := p.Ctx.CoreV1Informers.Nodes().Lister().Get(nodeName)
@@ -76,7 +83,7 @@ func (p Prioritize) Handler(args schedulerapi.ExtenderArgs) (*schedulerapi.HostP
 			log.Infof("pod %s/%s on node %q , score=%d", pod.Name, pod.Namespace, node.Name, score)
 			hostPriorityList[i] = schedulerapi.HostPriority{
 				Host:  node.Name,
-				Score: int64(score) + hostPriorityList[i].Score,
+				Score: int64(score*weight) + hostPriorityList[i].Score,
 			}
 		}
 	}
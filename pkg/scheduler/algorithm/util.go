@@ -35,7 +35,8 @@ func GetPodPvcs(pod *corev1.Pod, ctx *SchedulingContext, skipBound bool, contain
 	err error,
 	lvmPVCs []*corev1.PersistentVolumeClaim,
 	mpPVCs []*corev1.PersistentVolumeClaim,
-	devicePVCs []*corev1.PersistentVolumeClaim) {
+	devicePVCs []*corev1.PersistentVolumeClaim,
+	quotaPVCs []*corev1.PersistentVolumeClaim) {
 
 	ns := pod.Namespace
 	for _, v := range pod.Spec.Volumes {
@@ -44,7 +45,7 @@ func GetPodPvcs(pod *corev1.Pod, ctx *SchedulingContext, skipBound bool, contain
 			pvc, err := ctx.CoreV1Informers.PersistentVolumeClaims().Lister().PersistentVolumeClaims(ns).Get(name)
 			if err != nil {
 				log.Errorf("failed to get pvc by name %s/%s: %s", ns, name, err.Error())
-				return err, lvmPVCs, mpPVCs, devicePVCs
+				return err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs
 			}
 			if pvc.Status.Phase == corev1.ClaimBound && skipBound {
 				log.Infof("skip scheduling bound pvc %s/%s", pvc.Namespace, pvc.Name)
@@ -57,11 +58,11 @@ func GetPodPvcs(pod *corev1.Pod, ctx *SchedulingContext, skipBound bool, contain
 			_, err = ctx.StorageV1Informers.StorageClasses().Lister().Get(*scName)
 			if err != nil {
 				log.Errorf("failed to get storage class by name %s: %s", *scName, err.Error())
-				return err, lvmPVCs, mpPVCs, devicePVCs
+				return err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs
 			}
 			var isLocalPV bool
 			var pvType pkg.VolumeType
-			if isLocalPV, pvType = utils.IsLocalPVC(pvc, ctx.StorageV1Informers, containReadonlySnapshot); isLocalPV {
+			if isLocalPV, pvType = utils.IsLocalPVC(pvc, ctx.StorageV1Informers, ctx.SnapshotInformers, containReadonlySnapshot); isLocalPV {
 				switch pvType {
 				case pkg.VolumeTypeLVM:
 					log.Infof("got pvc %s/%s as lvm pvc", pvc.Namespace, pvc.Name)
@@ -72,6 +73,9 @@ func GetPodPvcs(pod *corev1.Pod, ctx *SchedulingContext, skipBound bool, contain
 				case pkg.VolumeTypeDevice:
 					log.Infof("got pvc %s/%s as device pvc", pvc.Namespace, pvc.Name)
 					devicePVCs = append(devicePVCs, pvc)
+				case pkg.VolumeTypeQuota:
+					log.Infof("got pvc %s/%s as quota pvc", pvc.Namespace, pvc.Name)
+					quotaPVCs = append(quotaPVCs, pvc)
 				default:
 					log.Infof("not a open-local pvc %s/%s, should handled by other provisioner", pvc.Namespace, pvc.Name)
 				}
@@ -85,11 +89,12 @@ func GetPodUnboundPvcs(pvc *corev1.PersistentVolumeClaim, ctx *SchedulingContext
 	err error,
 	lvmPVCs []*corev1.PersistentVolumeClaim,
 	mpPVCs []*corev1.PersistentVolumeClaim,
-	devicePVCs []*corev1.PersistentVolumeClaim) {
+	devicePVCs []*corev1.PersistentVolumeClaim,
+	quotaPVCs []*corev1.PersistentVolumeClaim) {
 	pvcName := utils.PVCName(pvc)
 	podName := ctx.ClusterNodeCache.PvcMapping.PvcPod[pvcName]
 	if podName == "" {
-		return fmt.Errorf("pod associated with pvc %s is not yet in PvcPod mapping", pvcName), lvmPVCs, mpPVCs, devicePVCs
+		return fmt.Errorf("pod associated with pvc %s is not yet in PvcPod mapping", pvcName), lvmPVCs, mpPVCs, devicePVCs, quotaPVCs
 	}
 	var pod *corev1.Pod
 	pod, err = ctx.CoreV1Informers.Pods().Lister().Pods(strings.Split(podName, "/")[0]).Get(strings.Split(podName, "/")[1])
@@ -101,7 +106,7 @@ func GetPodUnboundPvcs(pvc *corev1.PersistentVolumeClaim, ctx *SchedulingContext
 }
 
 func GetAllPodPvcs(pod *corev1.Pod, ctx *SchedulingContext, containReadonlySnapshot bool) ([]*corev1.PersistentVolumeClaim, error) {
-	err, pvc1, pvc2, pvc3 := GetPodPvcs(pod, ctx, false, containReadonlySnapshot)
+	err, pvc1, pvc2, pvc3, pvc4 := GetPodPvcs(pod, ctx, false, containReadonlySnapshot)
 	if err != nil {
 		log.Errorf("failed to get pod pvcs: %s", err.Error())
 		return nil, err
@@ -110,6 +115,7 @@ func GetAllPodPvcs(pod *corev1.Pod, ctx *SchedulingContext, containReadonlySnaps
 	pvcs = append(pvcs, pvc1...)
 	pvcs = append(pvcs, pvc2...)
 	pvcs = append(pvcs, pvc3...)
+	pvcs = append(pvcs, pvc4...)
 	return pvcs, err
 }
 
@@ -120,7 +126,7 @@ func IsLocalNode(nodeName string, ctx *SchedulingContext) bool {
 		return false
 	}
 
-	if len(nodeCache.VGs) != 0 || len(nodeCache.MountPoints) != 0 || len(nodeCache.Devices) != 0 {
+	if len(nodeCache.VGs) != 0 || len(nodeCache.MountPoints) != 0 || len(nodeCache.Devices) != 0 || len(nodeCache.Quotas) != 0 {
 		return true
 	}
 
@@ -147,6 +153,9 @@ func ConvertAUFromPV(pv *corev1.PersistentVolume, scInformer storagev1informers.
 	vgName := utils.GetVGNameFromCsiPV(pv)
 	device := utils.GetDeviceNameFromCsiPV(pv)
 	mountPoint := utils.GetMountPointFromCsiPV(pv)
+	if volumeType == pkg.VolumeTypeQuota {
+		mountPoint = utils.GetQuotaMountPointFromCsiPV(pv)
+	}
 	return &cache.AllocatedUnit{
 		NodeName: nodeName,
 		// currently we do not care abort the volume type of au
@@ -157,5 +166,6 @@ func ConvertAUFromPV(pv *corev1.PersistentVolume, scInformer storagev1informers.
 		Device:     device,
 		MountPoint: mountPoint,
 		PVCName:    utils.PVCName(pv),
+		Thin:       utils.IsThinLVMPV(pv),
 	}, nil
 }
@@ -79,6 +79,85 @@ func NewNoAvailableVGError(nodeName string) *NoAvailableVGError {
 	}
 }
 
+// NoVGOfMediaTypeError means the named vg `vgName`, or every vg if it is empty, is not of media type `mediaType`
+type NoVGOfMediaTypeError struct {
+	resource  pkg.VolumeType
+	vgName    string
+	mediaType pkg.MediaType
+	nodeName  string
+}
+
+func (e *NoVGOfMediaTypeError) GetReason() string {
+	if e.vgName == "" {
+		return fmt.Sprintf("no vg(%s) of media type %s in node %s", e.resource, e.mediaType, e.nodeName)
+	}
+	return fmt.Sprintf("vg(%s) %s in node %s is not of media type %s", e.resource, e.vgName, e.nodeName, e.mediaType)
+}
+
+func (e *NoVGOfMediaTypeError) Error() string {
+	return e.GetReason()
+}
+
+func NewNoVGOfMediaTypeError(vgName string, mediaType pkg.MediaType, nodeName string) *NoVGOfMediaTypeError {
+	return &NoVGOfMediaTypeError{
+		resource:  pkg.VolumeTypeLVM,
+		vgName:    vgName,
+		mediaType: mediaType,
+		nodeName:  nodeName,
+	}
+}
+
+// VolumeConstraintError means no storage on `nodeName` satisfies the co-location or separation constraints of pvc `pvcName`
+type VolumeConstraintError struct {
+	resource pkg.VolumeType
+	pvcName  string
+	nodeName string
+}
+
+func (e *VolumeConstraintError) GetReason() string {
+	return fmt.Sprintf("no %s storage on node %s satisfies the co-location or separation constraints of pvc %s", e.resource, e.nodeName, e.pvcName)
+}
+
+func (e *VolumeConstraintError) Error() string {
+	return e.GetReason()
+}
+
+func NewVolumeConstraintError(resource pkg.VolumeType, pvcName string, nodeName string) *VolumeConstraintError {
+	return &VolumeConstraintError{
+		resource: resource,
+		pvcName:  pvcName,
+		nodeName: nodeName,
+	}
+}
+
+// UnhealthyStorageError means the storage `name`, or every storage of the type if it is empty, is unhealthy
+type UnhealthyStorageError struct {
+	resource  pkg.VolumeType
+	name      string
+	condition string
+	nodeName  string
+}
+
+func (e *UnhealthyStorageError) GetReason() string {
+	if e.name == "" {
+		return fmt.Sprintf("all %s storage in node %s is unhealthy", e.resource, e.nodeName)
+	}
+	return fmt.Sprintf("%s storage %s in node %s is unhealthy, condition is %s", e.resource, e.name, e.nodeName, e.condition)
+}
+
+func (e *UnhealthyStorageError) Error() string {
+	return e.GetReason()
+}
+
+func NewUnhealthyStorageError(resource pkg.VolumeType, name, condition, nodeName string) *UnhealthyStorageError {
+	return &UnhealthyStorageError{
+		resource:  resource,
+		name:      name,
+		condition: condition,
+		nodeName:  nodeName,
+	}
+}
+
 type InsufficientLVMError struct {
 	requested int64
 	used      int64
@@ -201,3 +280,90 @@ func NewInsufficientExclusiveResourceError(resource pkg.VolumeType, requested, m
 		maxSize:       max,
 	}
 }
+
+// InsufficientQuotaError means none of the quota mount points on `nodeName` has enough free size
+type InsufficientQuotaError struct {
+	requested int64
+	maxFree   int64
+	nodeName  string
+	resource  pkg.VolumeType
+}
+
+func (e *InsufficientQuotaError) GetReason() string {
+	requested := resource.NewQuantity(e.requested, resource.BinarySI)
+	maxFree := resource.NewQuantity(e.maxFree, resource.BinarySI)
+	return fmt.Sprintf("Insufficient %s storage on node %s, pvc requested %s, max free size of quota mount points %s",
+		e.resource, e.nodeName, requested.String(), maxFree.String())
+}
+
+func (e *InsufficientQuotaError) Error() string {
+	requested := resource.NewQuantity(e.requested, resource.BinarySI)
+	maxFree := resource.NewQuantity(e.maxFree, resource.BinarySI)
+	return fmt.Sprintf("Insufficient %s storage on node %s, pvc requested %s, max free size of quota mount points %s",
+		e.resource, e.nodeName, requested.String(), maxFree.String())
+}
+
+func NewInsufficientQuotaError(requested, maxFree int64, nodeName string) *InsufficientQuotaError {
+	return &InsufficientQuotaError{
+		resource:  pkg.VolumeTypeQuota,
+		requested: requested,
+		maxFree:   maxFree,
+		nodeName:  nodeName,
+	}
+}
+
+// CloneSourceNotOnNodeError means the source volume of a clone pvc is not on `nodeName`
+type CloneSourceNotOnNodeError struct {
+	sourceNode string
+	nodeName   string
+	resource   pkg.VolumeType
+}
+
+func (e *CloneSourceNotOnNodeError) GetReason() string {
+	return fmt.Sprintf("%s clone must be on the node of its source volume %s, not node %s", e.resource, e.sourceNode, e.nodeName)
+}
+
+func (e *CloneSourceNotOnNodeError) Error() string {
+	return fmt.Sprintf("%s clone must be on the node of its source volume %s, not node %s", e.resource, e.sourceNode, e.nodeName)
+}
+
+func NewCloneSourceNotOnNodeError(sourceNode, nodeName string) *CloneSourceNotOnNodeError {
+	return &CloneSourceNotOnNodeError{
+		resource:   pkg.VolumeTypeLVM,
+		sourceNode: sourceNode,
+		nodeName:   nodeName,
+	}
+}
+
+// InsufficientExclusiveExpansionError means the device or mount point exclusively used by a pv has no room for its new size
+type InsufficientExclusiveExpansionError struct {
+	requested int64
+	capacity  int64
+	name      string
+	nodeName  string
+	resource  pkg.VolumeType
+}
+
+func (e *InsufficientExclusiveExpansionError) GetReason() string {
+	requested := resource.NewQuantity(e.requested, resource.BinarySI)
+	capacity := resource.NewQuantity(e.capacity, resource.BinarySI)
+	return fmt.Sprintf("%s volume can not be expanded beyond the capacity of its storage, pvc requested %s, capacity %s",
+		e.resource, requested.String(), capacity.String())
+}
+
+func (e *InsufficientExclusiveExpansionError) Error() string {
+	requested := resource.NewQuantity(e.requested, resource.BinarySI)
+	capacity := resource.NewQuantity(e.capacity, resource.BinarySI)
+	return fmt.Sprintf("%s volume can not be expanded beyond the capacity of %s on node %s, pvc requested %s, capacity %s",
+		e.resource, e.name, e.nodeName, requested.String(), capacity.String())
+}
+
+func NewInsufficientExclusiveExpansionError(resource pkg.VolumeType, requested, capacity int64, name, nodeName string) *InsufficientExclusiveExpansionError {
+	return &InsufficientExclusiveExpansionError{
+		resource:  resource,
+		requested: requested,
+		capacity:  capacity,
+		name:      name,
+		nodeName:  nodeName,
+	}
+}
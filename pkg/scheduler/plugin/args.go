@@ -0,0 +1,75 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/priorities"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+)
+
+// Args holds the arguments of the plugin, they are the same as the flags of the scheduler extender
+type Args struct {
+	// Kubeconfig is the path of kubeconfig used to access open-local and snapshot resources,
+	// in-cluster config is used if it is empty
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// ServeHTTP starts the http server of the apis requested by the provisioner, e.g. /apis/scheduling
+	ServeHTTP bool `json:"serveHTTP,omitempty"`
+	// Port is the port of the http server, it must be set if ServeHTTP is true
+	Port int32 `json:"port,omitempty"`
+	// NodeAntiAffinityWeight is the weight of node anti-affinity for each volume type, e.g. {"MountPoint": 5}
+	NodeAntiAffinityWeight map[string]int `json:"nodeAntiAffinityWeight,omitempty"`
+	// Strategy is binpack, spread or most-free-vg, binpack by default
+	Strategy string `json:"strategy,omitempty"`
+	// ThinOvercommitRatio is the ratio of the virtual size of thin LVM volumes to the size of the thin pool, 1.0 by default
+	ThinOvercommitRatio float64 `json:"thinOvercommitRatio,omitempty"`
+	// PolicyFile is the path of the scheduling policy file, which is reloaded when changed.
+	// NodeAntiAffinityWeight, Strategy and ThinOvercommitRatio are ignored if it is set
+	PolicyFile string `json:"policyFile,omitempty"`
+}
+
+// Complete validates the args and returns the scheduling policy
+func (args *Args) Complete() (*policy.Policy, error) {
+	if args.ServeHTTP && args.Port <= 0 {
+		return nil, fmt.Errorf("port must be set to serve http, current value is %d", args.Port)
+	}
+
+	if args.PolicyFile != "" {
+		return policy.Load(args.PolicyFile)
+	}
+	weights := localtype.NewNodeAntiAffinityWeight()
+	for t, weight := range args.NodeAntiAffinityWeight {
+		vt, err := localtype.VolumeTypeFromString(t)
+		if err != nil {
+			return nil, err
+		}
+		if weight < priorities.MinScore || weight > priorities.MaxScore {
+			return nil, fmt.Errorf("weight is out-of-range [%d, %d], current value is %d", priorities.MinScore, priorities.MaxScore, weight)
+		}
+		weights.Put(vt, weight)
+	}
+
+	p := policy.NewDefaultPolicy(localtype.StrategyType(args.Strategy))
+	p.NodeAntiAffinity = weights.Items(true)
+	p.ThinOvercommitRatio = args.ThinOvercommitRatio
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,257 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	clientset "github.com/alibaba/open-local/pkg/generated/clientset/versioned"
+	informers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/predicates"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/priorities"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	"github.com/alibaba/open-local/pkg/scheduler/server"
+	"github.com/alibaba/open-local/pkg/scheduler/server/apis"
+	"github.com/alibaba/open-local/pkg/utils"
+	volumesnapshot "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	schedulerapi "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations
+	Name = "Open-Local"
+
+	reservedStateKey framework.StateKey = Name + "/reserved"
+)
+
+var (
+	_ framework.FilterPlugin  = &OpenLocal{}
+	_ framework.ScorePlugin   = &OpenLocal{}
+	_ framework.ReservePlugin = &OpenLocal{}
+	_ framework.PreBindPlugin = &OpenLocal{}
+)
+
+// OpenLocal is the scheduling framework plugin of open-local, it runs the same
+// predicates, priorities and cache as the scheduler extender inside kube-scheduler
+type OpenLocal struct {
+	handle   framework.Handle
+	extender *server.ExtenderServer
+	ctx      *algorithm.SchedulingContext
+}
+
+// reservedState records the storage reserved for the pod in the scheduling cycle
+type reservedState struct {
+	units []cache.AllocatedUnit
+}
+
+// Clone copies the units, so that the state of another cycle is not changed
+func (s *reservedState) Clone() framework.StateData {
+	units := make([]cache.AllocatedUnit, len(s.units))
+	copy(units, s.units)
+	return &reservedState{units: units}
+}
+
+// New initializes the plugin, informers of kube resources are shared with kube-scheduler
+func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	args := &Args{}
+	if err := frameworkruntime.DecodeInto(obj, args); err != nil {
+		return nil, err
+	}
+	schedulingPolicy, err := args.Complete()
+	if err != nil {
+		return nil, fmt.Errorf("invalid args of plugin %s: %s", Name, err.Error())
+	}
+
+	cfg, err := clientcmd.BuildConfigFromFlags("", args.Kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubeconfig: %s", err.Error())
+	}
+	localClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building open-local clientset: %s", err.Error())
+	}
+	snapClient, err := volumesnapshot.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building snapshot clientset: %s", err.Error())
+	}
+	localStorageInformerFactory := informers.NewSharedInformerFactory(localClient, 0)
+	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(snapClient, 0)
+	// the http server is only needed if the provisioner fetches the reserved storage from kube-scheduler
+	var port int32
+	if args.ServeHTTP {
+		port = args.Port
+	}
+	extenderServer := server.NewExtenderServer(handle.ClientSet(), localClient, snapClient, handle.SharedInformerFactory(), localStorageInformerFactory, snapshotInformerFactory, port, schedulingPolicy)
+
+	// kube informers are started by kube-scheduler
+	localStorageInformerFactory.Start(wait.NeverStop)
+	snapshotInformerFactory.Start(wait.NeverStop)
+	// cache reconciliation and pending pod rescheduling run in background, along with the http server if enabled
+	go extenderServer.Start(wait.NeverStop)
+	if args.PolicyFile != "" {
+		go extenderServer.WatchPolicyFile(args.PolicyFile, policy.DefaultReloadInterval, wait.NeverStop)
+	}
+
+	log.Infof("plugin %s is initialized with args %+v", Name, *args)
+	return &OpenLocal{
+		handle:   handle,
+		extender: extenderServer,
+		ctx:      extenderServer.Ctx,
+	}, nil
+}
+
+// Name returns name of the plugin
+func (ol *OpenLocal) Name() string {
+	return Name
+}
+
+// Filter runs the predicates of open-local against the node
+func (ol *OpenLocal) Filter(_ context.Context, _ *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if needSkip(pod) {
+		return nil
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	fits, failReasons, err := predicates.Predicates(ol.ctx, predicates.EnabledPredicateFuncs(ol.ctx, predicates.DefaultPredicateFuncs), pod, node)
+	if err != nil {
+		log.Errorf("node %s is not suitable for pod %s/%s, err: %s ", node.Name, pod.Namespace, pod.Name, err.Error())
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	if !fits {
+		log.Infof("node %s is not suitable for pod %s/%s, reason: %s ", node.Name, pod.Namespace, pod.Name, failReasons)
+		return framework.NewStatus(framework.Unschedulable, failReasons...)
+	}
+	return nil
+}
+
+// Score sums up the scores of open-local priorities, and scales it to [0, framework.MaxNodeScore]
+func (ol *OpenLocal) Score(_ context.Context, _ *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	schedulingPolicy := ol.ctx.Policy.Get()
+	if !schedulingPolicy.NodeAntiAffinityEnabled() && needSkip(pod) {
+		return 0, nil
+	}
+	node, err := ol.ctx.CoreV1Informers.Nodes().Lister().Get(nodeName)
+	if err != nil {
+		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("unable to fetch node %s from informer: %s", nodeName, err.Error()))
+	}
+	var score int64
+	for name, pri := range priorities.DefaultPrioritizeFuncs {
+		weight := schedulingPolicy.PriorityWeight(name)
+		if weight == 0 {
+			continue
+		}
+		s, err := pri(ol.ctx, pod, node)
+		if err != nil {
+			log.Errorf("error when prioritize pod %s, for node %s: %s", pod.Name, node.Name, err.Error())
+			continue
+		}
+		score += int64(s * weight)
+	}
+	log.Infof("pod %s/%s on node %q , score=%d", pod.Namespace, pod.Name, node.Name, score)
+	maxScore := schedulingPolicy.MaxPriorityScore(priorities.MaxScore)
+	if maxScore == 0 {
+		return 0, nil
+	}
+	return score * framework.MaxNodeScore / int64(maxScore), nil
+}
+
+// ScoreExtensions returns nil as scores are already normalized
+func (ol *OpenLocal) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// Reserve assumes the storage of the unbound pvcs of pod on the node
+func (ol *OpenLocal) Reserve(_ context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	if needSkip(pod) {
+		return nil
+	}
+	node, err := ol.ctx.CoreV1Informers.Nodes().Lister().Get(nodeName)
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("unable to fetch node %s from informer: %s", nodeName, err.Error()))
+	}
+	units, err := apis.SchedulingPod(ol.ctx, pod, node)
+	if err != nil {
+		log.Errorf("failed to reserve storage for pod %s/%s on node %s: %s", pod.Namespace, pod.Name, nodeName, err.Error())
+		return framework.NewStatus(framework.Unschedulable, err.Error())
+	}
+	state.Write(reservedStateKey, &reservedState{units: units})
+	return nil
+}
+
+// Unreserve reverts the storage assumed in Reserve
+func (ol *OpenLocal) Unreserve(_ context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) {
+	reserved, err := getReservedState(state)
+	if err != nil || len(reserved.units) == 0 {
+		return
+	}
+	if err := apis.UnschedulingUnits(ol.ctx, reserved.units); err != nil {
+		log.Errorf("failed to unreserve storage for pod %s/%s on node %s: %s", pod.Namespace, pod.Name, nodeName, err.Error())
+	}
+	state.Delete(reservedStateKey)
+}
+
+// PreBind checks the reserved storage is still held by the pvcs of pod before binding,
+// the reservation may have been dropped, e.g. the pvc was deleted and recreated
+func (ol *OpenLocal) PreBind(_ context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	reserved, err := getReservedState(state)
+	if err != nil {
+		return nil
+	}
+	ol.ctx.CtxLock.RLock()
+	defer ol.ctx.CtxLock.RUnlock()
+	var lost []string
+	for _, unit := range reserved.units {
+		existing, ok := ol.ctx.ClusterNodeCache.BindingInfo[unit.PVCName]
+		if !ok || existing.NodeName != nodeName {
+			lost = append(lost, unit.PVCName)
+		}
+	}
+	if len(lost) > 0 {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("storage reserved on node %s is lost for pvc %s", nodeName, strings.Join(lost, ",")))
+	}
+	return nil
+}
+
+func getReservedState(state *framework.CycleState) (*reservedState, error) {
+	data, err := state.Read(reservedStateKey)
+	if err != nil {
+		return nil, err
+	}
+	reserved, ok := data.(*reservedState)
+	if !ok {
+		return nil, fmt.Errorf("%+v convert to reservedState error", data)
+	}
+	return reserved, nil
+}
+
+func needSkip(pod *corev1.Pod) bool {
+	return utils.NeedSkip(schedulerapi.ExtenderArgs{Pod: pod})
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	localinformers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	"github.com/alibaba/open-local/pkg/scheduler/server"
+	volumesnapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+)
+
+const (
+	testVG        = "ssd"
+	testNode      = "node-1"
+	testSmallNode = "node-2"
+)
+
+// fakeRequired is the queue sort and bind plugin required by the framework
+type fakeRequired struct{}
+
+func (fakeRequired) Name() string {
+	return "FakeRequired"
+}
+
+func (fakeRequired) Less(_, _ *framework.QueuedPodInfo) bool {
+	return false
+}
+
+func (fakeRequired) Bind(_ context.Context, _ *framework.CycleState, _ *corev1.Pod, _ string) *framework.Status {
+	return nil
+}
+
+// newTestPlugin returns the plugin with a fake framework handle, objects are added to the informers
+func newTestPlugin(t *testing.T, objects ...interface{}) *OpenLocal {
+	kubeClient := k8sfake.NewSimpleClientset()
+	registry := frameworkruntime.Registry{
+		fakeRequired{}.Name(): func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+			return fakeRequired{}, nil
+		},
+	}
+	required := &config.PluginSet{Enabled: []config.Plugin{{Name: fakeRequired{}.Name()}}}
+	plugins := &config.Plugins{QueueSort: required, Bind: required}
+	handle, err := frameworkruntime.NewFramework(registry, plugins, nil,
+		frameworkruntime.WithClientSet(kubeClient),
+		frameworkruntime.WithInformerFactory(kubeinformers.NewSharedInformerFactory(kubeClient, 0)))
+	if err != nil {
+		t.Fatalf("failed to create framework handle: %v", err)
+	}
+	localClient := localfake.NewSimpleClientset()
+	snapClient := volumesnapshotfake.NewSimpleClientset()
+	extenderServer := server.NewExtenderServer(handle.ClientSet(), localClient, snapClient, handle.SharedInformerFactory(),
+		localinformers.NewSharedInformerFactory(localClient, 0), volumesnapshotinformers.NewSharedInformerFactory(snapClient, 0),
+		0, policy.NewDefaultPolicy(localtype.StrategyBinpack))
+	ctx := extenderServer.Ctx
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *corev1.Node:
+			err = ctx.CoreV1Informers.Nodes().Informer().GetIndexer().Add(o)
+		case *corev1.Pod:
+			err = ctx.CoreV1Informers.Pods().Informer().GetIndexer().Add(o)
+		case *corev1.PersistentVolumeClaim:
+			err = ctx.CoreV1Informers.PersistentVolumeClaims().Informer().GetIndexer().Add(o)
+		case *storagev1.StorageClass:
+			err = ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(o)
+		}
+		if err != nil {
+			t.Fatalf("failed to add object to informer: %v", err)
+		}
+	}
+	return &OpenLocal{handle: handle, extender: extenderServer, ctx: ctx}
+}
+
+func TestOpenLocal(t *testing.T) {
+	sc := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "open-local-lvm"},
+		Provisioner: localtype.ProvisionerName,
+		Parameters:  map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VGName: testVG},
+	}
+	scName := sc.Name
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &scName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name}},
+		}}},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNode}}
+	smallNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: testSmallNode}}
+
+	ol := newTestPlugin(t, sc, pvc, pod, node, smallNode)
+	for name, capacity := range map[string]int64{testNode: 100 << 30, testSmallNode: 10 << 30} {
+		nc := cache.NewNodeCache(name)
+		nc.VGs[testVG] = cache.SharedResource{Name: testVG, Capacity: capacity}
+		ol.ctx.ClusterNodeCache.SetNodeCache(nc)
+	}
+	requested := func() int64 {
+		return ol.ctx.ClusterNodeCache.GetNodeCache(testNode).VGs[testVG].Requested
+	}
+	ctx := context.Background()
+
+	// Filter
+	for name, expect := range map[*corev1.Node]framework.Code{node: framework.Success, smallNode: framework.Unschedulable} {
+		nodeInfo := framework.NewNodeInfo()
+		if err := nodeInfo.SetNode(name); err != nil {
+			t.Fatalf("failed to set node: %v", err)
+		}
+		if code := ol.Filter(ctx, framework.NewCycleState(), pod, nodeInfo).Code(); code != expect {
+			t.Errorf("expect %s filtering node %s, got %s", expect, name.Name, code)
+		}
+	}
+
+	// Score
+	score, status := ol.Score(ctx, framework.NewCycleState(), pod, testNode)
+	if !status.IsSuccess() || score <= 0 || score > framework.MaxNodeScore {
+		t.Errorf("expect score in (0, %d] of node %s, got %d, %v", framework.MaxNodeScore, testNode, score, status)
+	}
+
+	// Reserve then Unreserve restores the cache
+	state := framework.NewCycleState()
+	if status := ol.Reserve(ctx, state, pod, testNode); !status.IsSuccess() {
+		t.Fatalf("failed to reserve: %v", status)
+	}
+	if requested() != 50<<30 || ol.ctx.ClusterNodeCache.BindingInfo[pvc.Namespace+"/"+pvc.Name] == nil {
+		t.Fatalf("expect 50Gi reserved on node %s, got %d", testNode, requested())
+	}
+	cloned, err := getReservedState(state.Clone())
+	if err != nil {
+		t.Fatalf("failed to get cloned state: %v", err)
+	}
+	reserved, _ := getReservedState(state)
+	cloned.units[0].Requested = 0
+	if reserved.units[0].Requested != 50<<30 {
+		t.Errorf("cloned state should not share units with the origin")
+	}
+	if status := ol.PreBind(ctx, state, pod, testNode); !status.IsSuccess() {
+		t.Errorf("expect reservation held in PreBind, got %v", status)
+	}
+	ol.Unreserve(ctx, state, pod, testNode)
+	if requested() != 0 || len(ol.ctx.ClusterNodeCache.BindingInfo) != 0 {
+		t.Errorf("expect reservation reverted, requested %d, binding info %+v", requested(), ol.ctx.ClusterNodeCache.BindingInfo)
+	}
+
+	// PreBind fails once the reservation is dropped
+	state = framework.NewCycleState()
+	if status := ol.Reserve(ctx, state, pod, testNode); !status.IsSuccess() {
+		t.Fatalf("failed to reserve: %v", status)
+	}
+	ol.ctx.ClusterNodeCache.BindingInfo = map[string]*cache.AllocatedUnit{}
+	if code := ol.PreBind(ctx, state, pod, testNode).Code(); code != framework.Unschedulable {
+		t.Errorf("expect %s in PreBind with reservation lost, got %s", framework.Unschedulable, code)
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+	"time"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	APIVersion = "scheduler.csi.aliyun.com/v1alpha1"
+	Kind       = "SchedulerPolicy"
+	// ConfigMapKey is the key of the policy in the ConfigMap
+	ConfigMapKey = "policy.yaml"
+	// DefaultReloadInterval is the interval to check the changes of the policy file
+	DefaultReloadInterval = 10 * time.Second
+
+	// names of predicates
+	PredicateCapacity = "CapacityPredicate"
+	PredicateClone    = "ClonePredicate"
+	// names of priorities
+	PriorityCapacityMatch    = "CapacityMatch"
+	PriorityCountMatch       = "CountMatch"
+	PriorityNodeAntiAffinity = "NodeAntiAffinity"
+
+	DefaultPriorityWeight = 1
+	MaxPriorityWeight     = 10
+	// MaxNodeAntiAffinityWeight is the max weight of a volume type in priority NodeAntiAffinity
+	MaxNodeAntiAffinityWeight = 10
+)
+
+var (
+	ValidPredicates = []string{PredicateCapacity, PredicateClone}
+	ValidPriorities = []string{PriorityCapacityMatch, PriorityCountMatch, PriorityNodeAntiAffinity}
+	ValidStrategies = []localtype.StrategyType{localtype.StrategyBinpack, localtype.StrategySpread, localtype.StrategyMostFreeVG}
+)
+
+// Policy is the scheduling policy of open-local, it replaces the strategy and weight flags of scheduler
+type Policy struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Strategy picks the storage on a node for pvcs whose storage class has no strategy set, binpack by default
+	Strategy localtype.StrategyType `json:"strategy,omitempty"`
+	// ThinOvercommitRatio is the ratio of the virtual size of thin LVM volumes to the size of the thin pool
+	// for storage classes that have no ratio set, 1.0 by default
+	ThinOvercommitRatio float64 `json:"thinOvercommitRatio,omitempty"`
+	// StorageClasses sets the policy of pvcs of each storage class
+	StorageClasses map[string]StorageClassPolicy `json:"storageClasses,omitempty"`
+	// Priorities is the weight of each priority in [0, 10], 1 if not set, and 0 disables the priority
+	Priorities map[string]int `json:"priorities,omitempty"`
+	// Predicates enables or disables each predicate, all of them are enabled by default
+	Predicates map[string]bool `json:"predicates,omitempty"`
+	// NodeAntiAffinity is the weight in [0, 10] of each volume type in priority NodeAntiAffinity,
+	// which keeps the pods without such volumes away from nodes having them
+	NodeAntiAffinity map[localtype.VolumeType]int `json:"nodeAntiAffinity,omitempty"`
+}
+
+// StorageClassPolicy is the policy of pvcs of a storage class
+type StorageClassPolicy struct {
+	Strategy            localtype.StrategyType `json:"strategy,omitempty"`
+	ThinOvercommitRatio float64                `json:"thinOvercommitRatio,omitempty"`
+}
+
+// NewDefaultPolicy returns the policy with the strategy
+func NewDefaultPolicy(strategy localtype.StrategyType) *Policy {
+	return &Policy{
+		APIVersion:          APIVersion,
+		Kind:                Kind,
+		Strategy:            strategy,
+		ThinOvercommitRatio: localtype.DefaultThinOvercommitRatio,
+	}
+}
+
+// Parse parses and validates the policy in yaml or json
+func Parse(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %s", err.Error())
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Load reads the policy from file
+func Load(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %s", path, err.Error())
+	}
+	return Parse(data)
+}
+
+// FromConfigMap parses the policy under key ConfigMapKey of the ConfigMap
+func FromConfigMap(cm *corev1.ConfigMap) (*Policy, error) {
+	data, ok := cm.Data[ConfigMapKey]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in configmap %s/%s", ConfigMapKey, cm.Namespace, cm.Name)
+	}
+	return Parse([]byte(data))
+}
+
+// Validate checks the policy and fills the default strategy and thin overcommit ratio
+func (p *Policy) Validate() error {
+	if p.APIVersion != APIVersion || p.Kind != Kind {
+		return fmt.Errorf("policy of %s %s is not supported, expect apiVersion %s and kind %s", p.APIVersion, p.Kind, APIVersion, Kind)
+	}
+	if p.Strategy == "" {
+		p.Strategy = localtype.StrategyBinpack
+	}
+	if err := validateStrategy(p.Strategy); err != nil {
+		return err
+	}
+	if p.ThinOvercommitRatio == 0 {
+		p.ThinOvercommitRatio = localtype.DefaultThinOvercommitRatio
+	}
+	if err := validateThinOvercommitRatio(p.ThinOvercommitRatio); err != nil {
+		return err
+	}
+	for name, sc := range p.StorageClasses {
+		if sc.Strategy != "" {
+			if err := validateStrategy(sc.Strategy); err != nil {
+				return fmt.Errorf("storage class %s: %s", name, err.Error())
+			}
+		}
+		if sc.ThinOvercommitRatio != 0 {
+			if err := validateThinOvercommitRatio(sc.ThinOvercommitRatio); err != nil {
+				return fmt.Errorf("storage class %s: %s", name, err.Error())
+			}
+		}
+	}
+	for name, weight := range p.Priorities {
+		if !contains(ValidPriorities, name) {
+			return fmt.Errorf("unknown priority %s, valid values are %v", name, ValidPriorities)
+		}
+		if weight < 0 || weight > MaxPriorityWeight {
+			return fmt.Errorf("weight of priority %s is out-of-range [0, %d], current value is %d", name, MaxPriorityWeight, weight)
+		}
+	}
+	for name := range p.Predicates {
+		if !contains(ValidPredicates, name) {
+			return fmt.Errorf("unknown predicate %s, valid values are %v", name, ValidPredicates)
+		}
+	}
+	for volumeType, weight := range p.NodeAntiAffinity {
+		if _, err := localtype.VolumeTypeFromString(string(volumeType)); err != nil {
+			return err
+		}
+		if weight < 0 || weight > MaxNodeAntiAffinityWeight {
+			return fmt.Errorf("node anti-affinity weight of %s is out-of-range [0, %d], current value is %d", volumeType, MaxNodeAntiAffinityWeight, weight)
+		}
+	}
+	return nil
+}
+
+// StorageClassStrategy returns the strategy of pvcs of the storage class
+func (p *Policy) StorageClassStrategy(scName string) localtype.StrategyType {
+	if sc, ok := p.StorageClasses[scName]; ok && sc.Strategy != "" {
+		return sc.Strategy
+	}
+	return p.Strategy
+}
+
+// PVCStrategy returns the strategy of the pvc
+func (p *Policy) PVCStrategy(pvc *corev1.PersistentVolumeClaim) localtype.StrategyType {
+	if pvc == nil || pvc.Spec.StorageClassName == nil {
+		return p.Strategy
+	}
+	return p.StorageClassStrategy(*pvc.Spec.StorageClassName)
+}
+
+// StorageClassThinOvercommitRatio returns the thin overcommit ratio of pvcs of the storage class
+func (p *Policy) StorageClassThinOvercommitRatio(scName string) float64 {
+	if sc, ok := p.StorageClasses[scName]; ok && sc.ThinOvercommitRatio != 0 {
+		return sc.ThinOvercommitRatio
+	}
+	return p.ThinOvercommitRatio
+}
+
+// PVCThinOvercommitRatio returns the thin overcommit ratio of the pvc
+func (p *Policy) PVCThinOvercommitRatio(pvc *corev1.PersistentVolumeClaim) float64 {
+	if pvc == nil || pvc.Spec.StorageClassName == nil {
+		return p.ThinOvercommitRatio
+	}
+	return p.StorageClassThinOvercommitRatio(*pvc.Spec.StorageClassName)
+}
+
+// PriorityWeight returns the weight of the priority, 0 if disabled
+func (p *Policy) PriorityWeight(name string) int {
+	if weight, ok := p.Priorities[name]; ok {
+		return weight
+	}
+	return DefaultPriorityWeight
+}
+
+// MaxPriorityScore returns the max sum of the weighted scores of the priorities with maxScore each
+func (p *Policy) MaxPriorityScore(maxScore int) int {
+	total := 0
+	for _, name := range ValidPriorities {
+		total += p.PriorityWeight(name) * maxScore
+	}
+	return total
+}
+
+// PredicateEnabled returns whether the predicate is enabled
+func (p *Policy) PredicateEnabled(name string) bool {
+	if enabled, ok := p.Predicates[name]; ok {
+		return enabled
+	}
+	return true
+}
+
+// NodeAntiAffinityEnabled returns whether priority NodeAntiAffinity takes effect
+func (p *Policy) NodeAntiAffinityEnabled() bool {
+	if p.PriorityWeight(PriorityNodeAntiAffinity) == 0 {
+		return false
+	}
+	for _, weight := range p.NodeAntiAffinity {
+		if weight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Hash returns the digest of the policy, to tell whether it changed
+func (p *Policy) Hash() string {
+	data, _ := yaml.Marshal(p)
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+func validateStrategy(strategy localtype.StrategyType) error {
+	for _, s := range ValidStrategies {
+		if s == strategy {
+			return nil
+		}
+	}
+	return fmt.Errorf("scheduler strategy %q is invalid, valid values are %v", strategy, ValidStrategies)
+}
+
+func validateThinOvercommitRatio(ratio float64) error {
+	if ratio < 1.0 {
+		return fmt.Errorf("thin overcommit ratio must be no less than 1.0, current value is %v", ratio)
+	}
+	return nil
+}
+
+func contains(array []string, val string) bool {
+	for _, v := range array {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Holder holds the current policy, which may be replaced when the policy file changes
+type Holder struct {
+	value atomic.Value
+}
+
+// NewHolder returns a Holder of the policy
+func NewHolder(p *Policy) *Holder {
+	h := &Holder{}
+	h.Set(p)
+	return h
+}
+
+// Get returns the current policy, it must not be modified
+func (h *Holder) Get() *Policy {
+	return h.value.Load().(*Policy)
+}
+
+// Set replaces the current policy
+func (h *Holder) Set(p *Policy) {
+	h.value.Store(p)
+}
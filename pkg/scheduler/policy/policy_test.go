@@ -0,0 +1,133 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "test-full",
+			data: `apiVersion: scheduler.csi.aliyun.com/v1alpha1
+kind: SchedulerPolicy
+strategy: spread
+thinOvercommitRatio: 2.0
+storageClasses:
+  open-local-lvm-fast:
+    strategy: most-free-vg
+    thinOvercommitRatio: 1.5
+priorities:
+  CapacityMatch: 2
+  CountMatch: 0
+predicates:
+  ClonePredicate: false
+nodeAntiAffinity:
+  MountPoint: 5
+`,
+		},
+		{
+			name:    "test-invalid-kind",
+			data:    "apiVersion: scheduler.csi.aliyun.com/v1alpha1\nkind: Policy\n",
+			wantErr: true,
+		},
+		{
+			name:    "test-invalid-strategy",
+			data:    "apiVersion: scheduler.csi.aliyun.com/v1alpha1\nkind: SchedulerPolicy\nstrategy: random\n",
+			wantErr: true,
+		},
+		{
+			name:    "test-invalid-thin-overcommit-ratio",
+			data:    "apiVersion: scheduler.csi.aliyun.com/v1alpha1\nkind: SchedulerPolicy\nstorageClasses:\n  open-local-lvm:\n    thinOvercommitRatio: 0.5\n",
+			wantErr: true,
+		},
+		{
+			name:    "test-unknown-priority",
+			data:    "apiVersion: scheduler.csi.aliyun.com/v1alpha1\nkind: SchedulerPolicy\npriorities:\n  Lucky: 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "test-invalid-weight",
+			data:    "apiVersion: scheduler.csi.aliyun.com/v1alpha1\nkind: SchedulerPolicy\nnodeAntiAffinity:\n  Device: 15\n",
+			wantErr: true,
+		},
+		{
+			name:    "test-unknown-field",
+			data:    "apiVersion: scheduler.csi.aliyun.com/v1alpha1\nkind: SchedulerPolicy\nweights: {}\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	p, err := Parse([]byte(`apiVersion: scheduler.csi.aliyun.com/v1alpha1
+kind: SchedulerPolicy
+storageClasses:
+  open-local-lvm-fast:
+    strategy: most-free-vg
+    thinOvercommitRatio: 2.0
+priorities:
+  CountMatch: 0
+  NodeAntiAffinity: 3
+predicates:
+  ClonePredicate: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, other := "open-local-lvm-fast", "open-local-lvm"
+	if s := p.PVCStrategy(&corev1.PersistentVolumeClaim{Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &fast}}); s != localtype.StrategyMostFreeVG {
+		t.Errorf("strategy of %s = %s, want %s", fast, s, localtype.StrategyMostFreeVG)
+	}
+	if s := p.PVCStrategy(&corev1.PersistentVolumeClaim{Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &other}}); s != localtype.StrategyBinpack {
+		t.Errorf("strategy of %s = %s, want %s", other, s, localtype.StrategyBinpack)
+	}
+	if r := p.StorageClassThinOvercommitRatio(fast); r != 2.0 {
+		t.Errorf("thin overcommit ratio of %s = %v, want 2.0", fast, r)
+	}
+	if r := p.StorageClassThinOvercommitRatio(other); r != localtype.DefaultThinOvercommitRatio {
+		t.Errorf("thin overcommit ratio of %s = %v, want %v", other, r, localtype.DefaultThinOvercommitRatio)
+	}
+	if w := p.PriorityWeight(PriorityCapacityMatch); w != DefaultPriorityWeight {
+		t.Errorf("weight of %s = %d, want %d", PriorityCapacityMatch, w, DefaultPriorityWeight)
+	}
+	if w := p.MaxPriorityScore(10); w != 40 {
+		t.Errorf("MaxPriorityScore() = %d, want 40", w)
+	}
+	if p.PredicateEnabled(PredicateClone) || !p.PredicateEnabled(PredicateCapacity) {
+		t.Errorf("predicates enabled: %s %t, %s %t", PredicateClone, p.PredicateEnabled(PredicateClone), PredicateCapacity, p.PredicateEnabled(PredicateCapacity))
+	}
+	if p.NodeAntiAffinityEnabled() {
+		t.Errorf("NodeAntiAffinity should be disabled without weights of volume types")
+	}
+}
@@ -19,21 +19,30 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/alibaba/open-local/pkg/scheduler/server/apis"
 	"github.com/alibaba/open-local/pkg/utils"
 	"github.com/julienschmidt/httprouter"
 
+	"github.com/alibaba/open-local/pkg/scheduler"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/errors"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	clientgocache "k8s.io/client-go/tools/cache"
 )
 
-const schedulingPVCPrefix = "/apis/scheduling/:namespace/persistentvolumeclaims/:name"
-const schedulingExpandPVCPrefix = "/apis/expand/:namespace/persistentvolumeclaims/:name"
+const schedulingPVCPrefix = apisPrefix + "/scheduling/:namespace/persistentvolumeclaims/:name"
+const schedulingExpandPVCPrefix = apisPrefix + "/expand/:namespace/persistentvolumeclaims/:name"
 
-func AddSchedulingApis(router *httprouter.Router, ctx *algorithm.SchedulingContext) {
-	router.POST(schedulingPVCPrefix, DebugLogging(SchedulingPVCWrap(ctx), schedulingPVCPrefix))
+// AddSchedulingApis adds the apis for the provisioner and controller, recordBinding is called
+// with the pvc once its storage is assumed
+func AddSchedulingApis(router *httprouter.Router, ctx *algorithm.SchedulingContext, recordBinding func(pvc *corev1.PersistentVolumeClaim)) {
+	router.POST(schedulingPVCPrefix, DebugLogging(SchedulingPVCWrap(ctx, recordBinding), schedulingPVCPrefix))
 	router.POST(schedulingExpandPVCPrefix, DebugLogging(SchedulingExpandWrap(ctx), schedulingExpandPVCPrefix))
 }
 
@@ -48,8 +57,13 @@ func SchedulingExpandWrap(ctx *algorithm.SchedulingContext) httprouter.Handle {
 			return
 		}
 		if err = apis.ExpandPVC(ctx, pvc); err != nil {
+			log.Errorf("failed to expand pvc %s/%s: %s", pvc.Namespace, pvc.Name, err.Error())
+			// the expansion is rejected for lack of storage, rather than failed
+			if predicateErr, ok := err.(errors.PredicateError); ok {
+				utils.HttpResponse(w, http.StatusUnprocessableEntity, []byte(predicateErr.GetReason()))
+				return
+			}
 			err = fmt.Errorf("failed to expand pvc %s/%s: %s", pvc.Namespace, pvc.Name, err.Error())
-			log.Errorf(err.Error())
 			utils.HttpResponse(w, http.StatusInternalServerError, []byte(err.Error()))
 			return
 		}
@@ -59,7 +73,7 @@ func SchedulingExpandWrap(ctx *algorithm.SchedulingContext) httprouter.Handle {
 }
 
 // SchedulingPVCWrap handles the request during volume provisioning
-func SchedulingPVCWrap(ctx *algorithm.SchedulingContext) httprouter.Handle {
+func SchedulingPVCWrap(ctx *algorithm.SchedulingContext, recordBinding func(pvc *corev1.PersistentVolumeClaim)) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 		var pvc *corev1.PersistentVolumeClaim
 		var err error
@@ -85,6 +99,7 @@ func SchedulingPVCWrap(ctx *algorithm.SchedulingContext) httprouter.Handle {
 			utils.HttpResponse(w, http.StatusInternalServerError, []byte(err.Error()))
 			return
 		}
+		recordBinding(pvc)
 		utils.HttpJSON(w, http.StatusOK, info)
 	}
 }
@@ -93,6 +108,90 @@ func AddGetNodeCache(router *httprouter.Router, ctx *algorithm.SchedulingContext
 	router.POST(cachePath, DebugLogging(apis.CacheRoute(ctx), cachePath))
 }
 
+// AddExplain serves the explanation of the scheduling of a pod or pvc, e.g.
+// GET /explain?pod=default/nginx-0&nodes=node1,node2, all nodes are explained if nodes is not set
+func AddExplain(router *httprouter.Router, ctx *algorithm.SchedulingContext) {
+	router.GET(explainPath, DebugLogging(ExplainRoute(ctx), explainPath))
+}
+
+func ExplainRoute(ctx *algorithm.SchedulingContext) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if err := r.ParseForm(); err != nil {
+			utils.HttpResponse(w, http.StatusBadRequest, []byte(err.Error()))
+			return
+		}
+		podKey, pvcKey := r.Form.Get("pod"), r.Form.Get("pvc")
+		if (podKey == "") == (pvcKey == "") {
+			utils.HttpResponse(w, http.StatusBadRequest, []byte("exactly one of pod and pvc must be set"))
+			return
+		}
+
+		var nodes []*corev1.Node
+		var err error
+		if nodeNames := r.Form.Get("nodes"); nodeNames != "" {
+			for _, nodeName := range strings.Split(nodeNames, ",") {
+				node, err := ctx.CoreV1Informers.Nodes().Lister().Get(nodeName)
+				if err != nil {
+					utils.HttpResponse(w, httpStatusOf(err), []byte(fmt.Sprintf("failed to get node %s: %s", nodeName, err.Error())))
+					return
+				}
+				nodes = append(nodes, node)
+			}
+		} else if nodes, err = ctx.CoreV1Informers.Nodes().Lister().List(labels.Everything()); err != nil {
+			utils.HttpResponse(w, http.StatusInternalServerError, []byte(err.Error()))
+			return
+		}
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+		explanation, code, err := explain(ctx, podKey, pvcKey, nodes)
+		if err != nil {
+			utils.HttpResponse(w, code, []byte(err.Error()))
+			return
+		}
+		utils.HttpJSON(w, http.StatusOK, explanation)
+	}
+}
+
+func explain(ctx *algorithm.SchedulingContext, podKey, pvcKey string, nodes []*corev1.Node) (*scheduler.Explanation, int, error) {
+	key := podKey
+	if key == "" {
+		key = pvcKey
+	}
+	namespace, name, err := clientgocache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	var explanation *scheduler.Explanation
+	if podKey != "" {
+		pod, err := ctx.CoreV1Informers.Pods().Lister().Pods(namespace).Get(name)
+		if err != nil {
+			return nil, httpStatusOf(err), fmt.Errorf("failed to get pod %s: %s", podKey, err.Error())
+		}
+		explanation, err = apis.ExplainPod(ctx, pod, nodes)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	} else {
+		pvc, err := ctx.CoreV1Informers.PersistentVolumeClaims().Lister().PersistentVolumeClaims(namespace).Get(name)
+		if err != nil {
+			return nil, httpStatusOf(err), fmt.Errorf("failed to get pvc %s: %s", pvcKey, err.Error())
+		}
+		explanation, err = apis.ExplainPVC(ctx, pvc, nodes)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	return explanation, http.StatusOK, nil
+}
+
+func httpStatusOf(err error) int {
+	if apierrors.IsNotFound(err) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
 func validatePVCParams(ctx *algorithm.SchedulingContext, w http.ResponseWriter, r *http.Request, ps httprouter.Params) (pvc *corev1.PersistentVolumeClaim, err error) {
 	if err := r.ParseForm(); err != nil {
 		utils.HttpResponse(w, http.StatusInternalServerError, []byte(err.Error()))
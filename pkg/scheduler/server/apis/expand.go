@@ -22,6 +22,7 @@ import (
 	"github.com/alibaba/open-local/pkg"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/errors"
 	"github.com/alibaba/open-local/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -77,13 +78,16 @@ func ExpandPVC(ctx *algorithm.SchedulingContext, pvc *corev1.PersistentVolumeCla
 			return fmt.Errorf("vgName is empty for pv %s", pv.Name)
 		}
 		if vgCache, ok := nc.VGs[cache.ResourceName(vg)]; ok {
-			newRequested := vgCache.Requested + int64(newSize-oldSize)
-			log.Infof("matching pvc %s/%s on vg %s(left=%d bytes), ", pvc.Namespace, pvc.Name, vg, vgCache.Capacity-vgCache.Requested)
-			if newRequested > vgCache.Capacity {
-				err := fmt.Errorf("failed to extend pvc, vg %s is not enough, requested total %d, capacity %d", vg, newRequested, vgCache.Capacity)
+			thin := utils.IsThinLVMPV(pv)
+			class := cache.NewThinClass(pv.Spec.CSI.VolumeAttributes, ctx.Policy.Get().PVCThinOvercommitRatio(pvc))
+			capacity := cache.VGCapacity(vgCache, thin, class)
+			newRequested := cache.VGRequested(vgCache, thin) + int64(newSize-oldSize)
+			log.Infof("matching pvc %s/%s on vg %s(left=%d bytes), ", pvc.Namespace, pvc.Name, vg, cache.VGFree(vgCache, thin, class))
+			if newRequested > capacity {
+				err := fmt.Errorf("failed to extend pvc, vg %s is not enough, requested total %d, capacity %d", vg, newRequested, capacity)
 				return err
 			}
-			vgCache.Requested += newSize - oldSize
+			cache.AddVGRequested(&vgCache, newSize-oldSize, thin)
 			nc.VGs[cache.ResourceName(vg)] = vgCache
 			return nil
 		} else {
@@ -91,12 +95,55 @@ func ExpandPVC(ctx *algorithm.SchedulingContext, pvc *corev1.PersistentVolumeCla
 			return err
 		}
 	case pkg.VolumeTypeMountPoint:
-		return fmt.Errorf("expansion on mountpoint volume is not supported")
+		mp := utils.GetMountPointFromCsiPV(pv)
+		if mp == "" {
+			return fmt.Errorf("mount point is empty for pv %s", pv.Name)
+		}
+		mpCache, ok := nc.MountPoints[cache.ResourceName(mp)]
+		if !ok {
+			return fmt.Errorf("mount point cache is not found for mount point %s", mp)
+		}
+		return expandExclusiveResource(pvc, pkg.VolumeTypeMountPoint, mpCache, newSize, nodeName)
 	case pkg.VolumeTypeDevice:
-		return fmt.Errorf("expansion on Device volume is not supported")
+		device := nc.DeviceOfPV(pv)
+		if device == "" {
+			return fmt.Errorf("device is empty for pv %s", pv.Name)
+		}
+		deviceCache, ok := nc.Devices[cache.ResourceName(device)]
+		if !ok {
+			return fmt.Errorf("device cache is not found for device %s", device)
+		}
+		return expandExclusiveResource(pvc, pkg.VolumeTypeDevice, deviceCache, newSize, nodeName)
 	case pkg.VolumeTypeQuota:
-		return fmt.Errorf("expansion on Quota volume is not supported")
+		mp := utils.GetQuotaMountPointFromCsiPV(pv)
+		if mp == "" {
+			return fmt.Errorf("quota mount point is empty for pv %s", pv.Name)
+		}
+		if quotaCache, ok := nc.Quotas[cache.ResourceName(mp)]; ok {
+			newRequested := quotaCache.Requested + int64(newSize-oldSize)
+			log.Infof("matching pvc %s/%s on quota mount point %s(left=%d bytes), ", pvc.Namespace, pvc.Name, mp, quotaCache.Capacity-quotaCache.Requested)
+			if newRequested > quotaCache.Capacity {
+				err := fmt.Errorf("failed to extend pvc, quota mount point %s is not enough, requested total %d, capacity %d", mp, newRequested, quotaCache.Capacity)
+				return err
+			}
+			quotaCache.Requested += newSize - oldSize
+			nc.Quotas[cache.ResourceName(mp)] = quotaCache
+			return nil
+		} else {
+			err := fmt.Errorf("quota cache is not found for mount point %s", mp)
+			return err
+		}
 	}
 	return fmt.Errorf("unhandled error during volume expansion")
 
 }
+
+// expandExclusiveResource accepts the new size if the device or mount point exclusively used by the pv already has room for it,
+// nothing needs to be reserved as the whole resource is allocated to the pv
+func expandExclusiveResource(pvc *corev1.PersistentVolumeClaim, volumeType pkg.VolumeType, resource cache.ExclusiveResource, newSize int64, nodeName string) error {
+	log.Infof("matching pvc %s/%s on %s %s(capacity=%d bytes)", pvc.Namespace, pvc.Name, volumeType, resource.Name, resource.Capacity)
+	if newSize > resource.Capacity {
+		return errors.NewInsufficientExclusiveExpansionError(volumeType, newSize, resource.Capacity, resource.Name, nodeName)
+	}
+	return nil
+}
@@ -16,6 +16,27 @@ limitations under the License.
 
 package apis
 
+import (
+	"testing"
+
+	"github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestExpandExclusiveResource(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	device := cache.ExclusiveResource{Name: "/dev/vdb", Device: "/dev/vdb", Capacity: 100 << 30, IsAllocated: true}
+	if err := expandExclusiveResource(pvc, pkg.VolumeTypeDevice, device, 100<<30, "node1"); err != nil {
+		t.Fatalf("expect expansion within capacity accepted, got %v", err)
+	}
+	err := expandExclusiveResource(pvc, pkg.VolumeTypeDevice, device, 101<<30, "node1")
+	if _, ok := err.(errors.PredicateError); !ok {
+		t.Fatalf("expect expansion beyond capacity rejected with PredicateError, got %v", err)
+	}
+}
+
 // import (
 // 	"testing"
 
@@ -0,0 +1,138 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"fmt"
+
+	"github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/predicates"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/priorities"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	"github.com/alibaba/open-local/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulerapi "k8s.io/kube-scheduler/extender/v1"
+)
+
+// ExplainPod evaluates the enabled predicates and priorities of the pod against each node with the
+// current scheduling context, like the extender does, but never assumes storage in the cache
+func ExplainPod(ctx *algorithm.SchedulingContext, pod *corev1.Pod, nodes []*corev1.Node) (*scheduler.Explanation, error) {
+	containReadonlySnapshot := false
+	err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
+	if err != nil {
+		return nil, err
+	}
+	volumes := map[pkg.VolumeType][]*corev1.PersistentVolumeClaim{
+		pkg.VolumeTypeLVM:        lvmPVCs,
+		pkg.VolumeTypeMountPoint: mpPVCs,
+		pkg.VolumeTypeDevice:     devicePVCs,
+		pkg.VolumeTypeQuota:      quotaPVCs,
+	}
+
+	predicateFuncs := predicates.EnabledPredicateFuncs(ctx, predicates.DefaultPredicateFuncs)
+	schedulingPolicy := ctx.Policy.Get()
+	skipPriorities := !schedulingPolicy.NodeAntiAffinityEnabled() && utils.NeedSkip(schedulerapi.ExtenderArgs{Pod: pod})
+
+	explanation := &scheduler.Explanation{
+		Pod:   fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+		Nodes: make([]scheduler.NodeExplanation, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		result := scheduler.NodeExplanation{Node: node.Name}
+		fits, reasons, err := predicates.Predicates(ctx, predicateFuncs, pod, node)
+		result.Fits = fits && err == nil
+		result.Reasons = reasons
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		for _, volumeType := range []pkg.VolumeType{pkg.VolumeTypeLVM, pkg.VolumeTypeMountPoint, pkg.VolumeTypeDevice, pkg.VolumeTypeQuota} {
+			for _, pvc := range volumes[volumeType] {
+				volume := scheduler.VolumeExplanation{
+					PersistentVolumeClaim: utils.PVCName(pvc),
+					VolumeType:            volumeType,
+				}
+				fits, reasons, err := predicates.Predicates(ctx, predicateFuncs, podWithPVC(pod, pvc.Name), node)
+				volume.Fits = fits && err == nil
+				volume.Reasons = reasons
+				if err != nil {
+					volume.Error = err.Error()
+				}
+				result.Volumes = append(result.Volumes, volume)
+			}
+		}
+
+		if result.Fits && !skipPriorities {
+			for _, name := range policy.ValidPriorities {
+				pri, ok := priorities.DefaultPrioritizeFuncs[name]
+				weight := schedulingPolicy.PriorityWeight(name)
+				if !ok || weight == 0 {
+					continue
+				}
+				score := scheduler.PriorityScore{Name: name, Weight: weight}
+				if score.Score, err = pri(ctx, pod, node); err != nil {
+					// a failed priority scores 0, the same as the extender
+					score.Score = 0
+					score.Error = err.Error()
+				}
+				result.Score += int64(score.Score * weight)
+				result.Scores = append(result.Scores, score)
+			}
+		}
+		explanation.Nodes = append(explanation.Nodes, result)
+	}
+	return explanation, nil
+}
+
+// ExplainPVC explains the scheduling of a pod with the pvc only
+func ExplainPVC(ctx *algorithm.SchedulingContext, pvc *corev1.PersistentVolumeClaim, nodes []*corev1.Node) (*scheduler.Explanation, error) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvc.Name,
+			Namespace: pvc.Namespace,
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{pvcVolume(pvc.Name)},
+		},
+	}
+	explanation, err := ExplainPod(ctx, pod, nodes)
+	if err != nil {
+		return nil, err
+	}
+	explanation.Pod = ""
+	explanation.PersistentVolumeClaim = utils.PVCName(pvc)
+	return explanation, nil
+}
+
+// podWithPVC returns a copy of the pod with the volume of claimName only
+func podWithPVC(pod *corev1.Pod, claimName string) *corev1.Pod {
+	podCopy := pod.DeepCopy()
+	podCopy.Spec.Volumes = []corev1.Volume{pvcVolume(claimName)}
+	return podCopy
+}
+
+func pvcVolume(claimName string) corev1.Volume {
+	return corev1.Volume{
+		Name: claimName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+		},
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"strings"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	localinformers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	volumesnapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExplainPod(t *testing.T) {
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0)
+	localInformerFactory := localinformers.NewSharedInformerFactory(localfake.NewSimpleClientset(), 0)
+	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(volumesnapshotfake.NewSimpleClientset(), 0)
+	ctx := algorithm.NewSchedulingContext(kubeInformerFactory.Core().V1(), kubeInformerFactory.Storage().V1(),
+		localInformerFactory.Csi().V1alpha1(), snapshotInformerFactory.Snapshot().V1beta1(), policy.NewDefaultPolicy(localtype.StrategyBinpack))
+
+	sc := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "open-local-lvm"},
+		Provisioner: localtype.ProvisionerName,
+		Parameters:  map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM), localtype.VGName: "share"},
+	}
+	scName := sc.Name
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &scName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       corev1.PodSpec{Volumes: []corev1.Volume{pvcVolume(pvc.Name)}},
+	}
+	_ = ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(sc)
+	_ = ctx.CoreV1Informers.PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)
+
+	for name, capacity := range map[string]int64{"node1": 100 << 30, "node2": 10 << 30} {
+		nc := cache.NewNodeCache(name)
+		nc.VGs["share"] = cache.SharedResource{Name: "share", Capacity: capacity}
+		ctx.ClusterNodeCache.SetNodeCache(nc)
+	}
+	nodes := []*corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}, {ObjectMeta: metav1.ObjectMeta{Name: "node2"}}}
+
+	explanation, err := ExplainPod(ctx, pod, nodes)
+	if err != nil {
+		t.Fatalf("ExplainPod() error = %v", err)
+	}
+	if explanation.Pod != "default/nginx" || len(explanation.Nodes) != 2 {
+		t.Fatalf("unexpected explanation %+v", explanation)
+	}
+	fit, unfit := explanation.Nodes[0], explanation.Nodes[1]
+	if !fit.Fits || len(fit.Scores) != len(policy.ValidPriorities) || fit.Score <= 0 {
+		t.Errorf("expect pod fits node1 with scores, got %+v", fit)
+	}
+	if unfit.Fits || len(unfit.Reasons) != 1 || !strings.Contains(unfit.Reasons[0], "Insufficient LVM storage") || len(unfit.Scores) != 0 {
+		t.Errorf("expect pod does not fit node2 for insufficient storage, got %+v", unfit)
+	}
+	if len(unfit.Volumes) != 1 || unfit.Volumes[0].PersistentVolumeClaim != "default/data" || unfit.Volumes[0].Fits {
+		t.Errorf("expect pvc default/data does not fit node2, got %+v", unfit.Volumes)
+	}
+	if vg := ctx.ClusterNodeCache.GetNodeCache("node1").VGs["share"]; vg.Requested != 0 {
+		t.Errorf("expect cache unchanged, got requested %d", vg.Requested)
+	}
+
+	explanation, err = ExplainPVC(ctx, pvc, nodes)
+	if err != nil {
+		t.Fatalf("ExplainPVC() error = %v", err)
+	}
+	if explanation.PersistentVolumeClaim != "default/data" || !explanation.Nodes[0].Fits || explanation.Nodes[1].Fits {
+		t.Errorf("unexpected explanation %+v", explanation)
+	}
+}
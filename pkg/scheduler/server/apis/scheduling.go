@@ -65,44 +65,25 @@ func SchedulingPVC(ctx *algorithm.SchedulingContext, pvc *corev1.PersistentVolum
 		return nil, fmt.Errorf(msg)
 	}
 	containReadonlySnapshot := false
-	err, lvmPVCs, mpPVCs, devicePVCs := algorithm.GetPodUnboundPvcs(pvc, ctx, containReadonlySnapshot)
+	err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs := algorithm.GetPodUnboundPvcs(pvc, ctx, containReadonlySnapshot)
 	if err != nil {
 		log.Errorf("failed to get pod unbound pvcs: %s", err.Error())
 		return nil, err
 	}
 
-	if len(lvmPVCs)+len(mpPVCs)+len(devicePVCs) == 0 {
+	if len(lvmPVCs)+len(mpPVCs)+len(devicePVCs)+len(quotaPVCs) == 0 {
 		msg := "unexpected schedulering request for all pvcs are bounded"
 		log.Info(msg)
 		return nil, fmt.Errorf(msg)
 	}
-	var allocatedUnits []cache.AllocatedUnit
-	trace.Step("Computing ScoreLVMVolume")
-	if _, lvmUnits, err := algo.ScoreLVMVolume(nil /*do we need pod here*/, lvmPVCs, node, ctx); err != nil {
-		err = fmt.Errorf("failed to allocate local storage for pvc %s/%s: %s", pvc.Namespace, pvc.Name, err.Error())
-		log.Errorf(err.Error())
-		return nil, err
-	} else {
-		allocatedUnits = append(allocatedUnits, lvmUnits...)
-	}
-	trace.Step("Computing ScoreMountPointVolume")
-	if _, mpUnits, err := algo.ScoreMountPointVolume(nil /*do we need pod here*/, mpPVCs, node, ctx); err != nil {
-		err = fmt.Errorf("failed to allocate local storage for pvc %s/%s: %s", pvc.Namespace, pvc.Name, err.Error())
-		log.Errorf(err.Error())
-		return nil, err
-	} else {
-		allocatedUnits = append(allocatedUnits, mpUnits...)
-	}
-	trace.Step("Computing ScoreDeviceVolume")
-	if _, deviceUnits, err := algo.ScoreDeviceVolume(nil /*do we need pod here*/, devicePVCs, node, ctx); err != nil {
+	allocatedUnits, err := allocateUnits(nil /*do we need pod here*/, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs, node, ctx, trace)
+	if err != nil {
 		err = fmt.Errorf("failed to allocate local storage for pvc %s/%s: %s", pvc.Namespace, pvc.Name, err.Error())
 		log.Errorf(err.Error())
 		return nil, err
-	} else {
-		allocatedUnits = append(allocatedUnits, deviceUnits...)
 	}
 
-	if (allocatedUnits == nil || len(allocatedUnits) <= 0) || len(allocatedUnits) != (len(lvmPVCs)+len(mpPVCs)+len(devicePVCs)) {
+	if (allocatedUnits == nil || len(allocatedUnits) <= 0) || len(allocatedUnits) != (len(lvmPVCs)+len(mpPVCs)+len(devicePVCs)+len(quotaPVCs)) {
 		log.Errorf("unexpected allocated unit number: %d", len(allocatedUnits))
 		return nil, err
 	}
@@ -129,6 +110,104 @@ func SchedulingPVC(ctx *algorithm.SchedulingContext, pvc *corev1.PersistentVolum
 	return bindingInfo, nil
 }
 
+// SchedulingPod reserves storage on node for all unbound pvcs of pod in one go,
+// it is used by the scheduling framework plugin in Reserve, so that the provisioner
+// gets the reserved storage when calling SchedulingPVC later
+func SchedulingPod(ctx *algorithm.SchedulingContext, pod *corev1.Pod, node *corev1.Node) ([]cache.AllocatedUnit, error) {
+	trace := utiltrace.New(fmt.Sprintf("Scheduling[SchedulingPod] %s/%s", pod.Namespace, pod.Name))
+	defer trace.LogIfLong(50 * time.Millisecond)
+
+	ctx.CtxLock.Lock()
+	defer ctx.CtxLock.Unlock()
+
+	containReadonlySnapshot := false
+	err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs := algorithm.GetPodPvcs(pod, ctx, true, containReadonlySnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod unbound pvcs: %s", err.Error())
+	}
+	// pvcs reserved before, e.g. by a former scheduling cycle, are kept as is
+	notReserved := func(pvcs []*corev1.PersistentVolumeClaim) []*corev1.PersistentVolumeClaim {
+		var result []*corev1.PersistentVolumeClaim
+		for _, pvc := range pvcs {
+			if !ctx.ClusterNodeCache.BindingInfo.IsPVCExists(utils.PVCName(pvc)) {
+				result = append(result, pvc)
+			}
+		}
+		return result
+	}
+	lvmPVCs, mpPVCs, devicePVCs, quotaPVCs = notReserved(lvmPVCs), notReserved(mpPVCs), notReserved(devicePVCs), notReserved(quotaPVCs)
+	total := len(lvmPVCs) + len(mpPVCs) + len(devicePVCs) + len(quotaPVCs)
+	if total == 0 {
+		return nil, nil
+	}
+
+	allocatedUnits, err := allocateUnits(pod, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs, node, ctx, trace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate local storage for pod %s/%s: %s", pod.Namespace, pod.Name, err.Error())
+	}
+	if len(allocatedUnits) != total {
+		return nil, fmt.Errorf("unexpected allocated unit number of pod %s/%s: %d, expect %d", pod.Namespace, pod.Name, len(allocatedUnits), total)
+	}
+	trace.Step("Computing Assume")
+	if err := ctx.ClusterNodeCache.Assume(allocatedUnits); err != nil {
+		return nil, fmt.Errorf("failed to assume local storage for pod %s/%s: %s", pod.Namespace, pod.Name, err.Error())
+	}
+	for i := range allocatedUnits {
+		ctx.ClusterNodeCache.BindingInfo[allocatedUnits[i].PVCName] = &allocatedUnits[i]
+	}
+	log.Infof("successfully reserve storage for pod %s/%s on node %s: %+v", pod.Namespace, pod.Name, node.Name, allocatedUnits)
+	return allocatedUnits, nil
+}
+
+// UnschedulingUnits reverts the units reserved by SchedulingPod, units of pvcs
+// whose binding info was replaced, e.g. the pv was already provisioned, are skipped
+func UnschedulingUnits(ctx *algorithm.SchedulingContext, units []cache.AllocatedUnit) error {
+	ctx.CtxLock.Lock()
+	defer ctx.CtxLock.Unlock()
+
+	var reverted []cache.AllocatedUnit
+	for _, unit := range units {
+		existing, ok := ctx.ClusterNodeCache.BindingInfo[unit.PVCName]
+		if !ok || *existing != unit {
+			continue
+		}
+		delete(ctx.ClusterNodeCache.BindingInfo, unit.PVCName)
+		reverted = append(reverted, unit)
+	}
+	log.Infof("unreserve storage: %+v", reverted)
+	return ctx.ClusterNodeCache.Unassume(reverted)
+}
+
+func allocateUnits(pod *corev1.Pod, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs []*corev1.PersistentVolumeClaim, node *corev1.Node, ctx *algorithm.SchedulingContext, trace *utiltrace.Trace) ([]cache.AllocatedUnit, error) {
+	var allocatedUnits []cache.AllocatedUnit
+	// pvcs of all volume types share the constraints of the pod
+	constraints := algo.NewVolumeConstraints(lvmPVCs, mpPVCs, devicePVCs, node, ctx)
+	trace.Step("Computing ScoreLVMVolume")
+	_, lvmUnits, err := algo.ScoreLVMVolume(pod, lvmPVCs, node, ctx, constraints)
+	if err != nil {
+		return nil, err
+	}
+	allocatedUnits = append(allocatedUnits, lvmUnits...)
+	trace.Step("Computing ScoreMountPointVolume")
+	_, mpUnits, err := algo.ScoreMountPointVolume(pod, mpPVCs, node, ctx, constraints)
+	if err != nil {
+		return nil, err
+	}
+	allocatedUnits = append(allocatedUnits, mpUnits...)
+	trace.Step("Computing ScoreDeviceVolume")
+	_, deviceUnits, err := algo.ScoreDeviceVolume(pod, devicePVCs, node, ctx, constraints)
+	if err != nil {
+		return nil, err
+	}
+	allocatedUnits = append(allocatedUnits, deviceUnits...)
+	trace.Step("Computing ScoreQuotaVolume")
+	_, quotaUnits, err := algo.ScoreQuotaVolume(pod, quotaPVCs, node, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(allocatedUnits, quotaUnits...), nil
+}
+
 func unitsToBinding(pvcs []*corev1.PersistentVolumeClaim, units []cache.AllocatedUnit) *scheduler.BindingInfo {
 	if len(units) <= 0 || len(units) > 1 {
 		log.Errorf("unexpected allocated unit number: %d", len(units))
@@ -130,34 +130,17 @@ func (e *ExtenderServer) onPVAdd(obj interface{}) {
 		e.Ctx.ClusterNodeCache.SetNodeCache(nc)
 		log.Debugf("created new node cache %q when adding pv %q", node, pv.Name)
 	}
+	pvcKey, err := algorithm.ExtractPVCKey(pv)
+	if err == nil {
+		// the assumed unit is replaced by the pv
+		e.releaseAssumed(pvcKey, releaseReasonProvisioned)
+	}
 	// handle according to types
-	switch pkg.VolumeType(pvType) {
-	case pkg.VolumeTypeLVM:
-		trace.Step("Computing AddLVM")
-		err := nc.AddLVM(pv)
-		if err != nil {
-			log.Errorf("failed to add local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
-			return
-		}
-	case pkg.VolumeTypeMountPoint:
-		trace.Step("Computing AddLocalMountPoint")
-		err := nc.AddLocalMountPoint(pv)
-		if err != nil {
-			log.Errorf("failed to add local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
-			return
-		}
-	case pkg.VolumeTypeDevice:
-		trace.Step("Computing AddLocalDevice")
-		err := nc.AddLocalDevice(pv)
-		if err != nil {
-			log.Errorf("failed to add local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
-			return
-		}
-	default:
-		log.Debugf("not a open-local pv %s, type %s, not add to cache", pv.Name, pvType)
+	trace.Step("Computing AddLocalPV")
+	if err := nc.AddLocalPV(pv, pkg.VolumeType(pvType)); err != nil {
+		log.Errorf("failed to add local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
 		return
 	}
-	pvcKey, err := algorithm.ExtractPVCKey(pv)
 	if err != nil {
 		log.Errorf("failed to extract pvc name from pv %s: %s", pv.Name, err.Error())
 		return
@@ -229,6 +212,12 @@ func (e *ExtenderServer) onPVDelete(obj interface{}) {
 			log.Errorf("failed to remove local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
 			return
 		}
+	case pkg.VolumeTypeQuota:
+		err := nc.RemoveQuota(pv)
+		if err != nil {
+			log.Errorf("failed to remove local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
+			return
+		}
 	default:
 		log.Infof("not a open-local pv %s, volumeType %s, skipped", pv.Name, pvType)
 		return
@@ -294,6 +283,12 @@ func (e *ExtenderServer) onPVUpdate(oldObj, newObj interface{}) {
 			log.Errorf("failed to update local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
 			return
 		}
+	case pkg.VolumeTypeQuota:
+		err := nc.UpdateQuota(old, pv)
+		if err != nil {
+			log.Errorf("failed to update local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nc.NodeName, err.Error())
+			return
+		}
 	default:
 		log.Infof("not a open-local pv %s, volumeType %s, skipped", pv.Name, pvType)
 		return
@@ -426,7 +421,7 @@ func (e *ExtenderServer) onPodUpdate(_, newObj interface{}) {
 		}
 	}
 	log.Infof("handing pod %s whose pvcs are all pending", utils.PodName(pod))
-	if utils.PodPvcAllowReschedule(pvcs, nil) && pod.Spec.NodeName == "" {
+	if utils.PodPvcAllowReschedule(pvcs, nil) && pod.Spec.NodeName == "" && e.IsLeader() {
 		for _, pvc := range pvcs {
 			contains := utils.PvcContainsSelectedNode(pvc)
 			if contains {
@@ -496,6 +491,7 @@ func (e *ExtenderServer) onPvcDelete(obj interface{}) {
 	e.Ctx.CtxLock.Lock()
 	defer e.Ctx.CtxLock.Unlock()
 	e.Ctx.ClusterNodeCache.PvcMapping.DeletePvc(pvc)
+	e.releaseAssumed(utils.PVCName(pvc), releaseReasonPVCDeleted)
 
 }
 
@@ -513,4 +509,10 @@ func (e *ExtenderServer) onPvcUpdate(_, newObj interface{}) {
 	e.Ctx.CtxLock.Lock()
 	defer e.Ctx.CtxLock.Unlock()
 	e.Ctx.ClusterNodeCache.PvcMapping.PutPvc(pvc)
+	// the pvc is rescheduled to another node
+	if assumed, ok := e.Ctx.ClusterNodeCache.GetAssumed(utils.PVCName(pvc)); ok {
+		if selectedNode := pvc.Annotations[pkg.AnnoSelectedNode]; selectedNode != "" && selectedNode != assumed.NodeName {
+			e.releaseAssumed(utils.PVCName(pvc), releaseReasonRebound)
+		}
+	}
 }
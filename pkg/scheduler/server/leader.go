@@ -0,0 +1,233 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/server/apis"
+	"github.com/alibaba/open-local/pkg/utils"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// LeaderElectionConfig is the config of the leader election among scheduler extenders
+type LeaderElectionConfig struct {
+	Namespace     string
+	Name          string
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// IsLeader returns whether the extender serves scheduling requests, it is always true without leader election
+func (e *ExtenderServer) IsLeader() bool {
+	return !e.leaderElection || atomic.LoadInt32(&e.leading) == 1
+}
+
+// RunLeaderElection keeps the extender as a standby until it becomes the leader, and exits once it
+// loses the lease, as its assumed state may be stale. It returns an error if the config is invalid
+func (e *ExtenderServer) RunLeaderElection(config LeaderElectionConfig, stopCh <-chan struct{}) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed to get hostname: %s", err.Error())
+	}
+	identity := hostname + "_" + string(uuid.NewUUID())
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: config.Namespace, Name: config.Name},
+		Client:     e.kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: config.LeaseDuration,
+		RenewDeadline: config.RenewDeadline,
+		RetryPeriod:   config.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Infof("%s became the leader of scheduler extenders", identity)
+				e.WaitForCacheSync(ctx.Done())
+				e.RestoreAssumed()
+				atomic.StoreInt32(&e.leading, 1)
+			},
+			OnStoppedLeading: func() {
+				select {
+				case <-stopCh:
+					log.Infof("%s released the lease of scheduler extenders", identity)
+				default:
+					log.Fatalf("%s lost the lease of scheduler extenders, quitting now", identity)
+				}
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					log.Infof("%s is the leader of scheduler extenders, standing by", leader)
+				}
+			},
+		},
+		ReleaseOnCancel: true,
+		Name:            config.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid leader election config: %s", err.Error())
+	}
+	e.leaderElection = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	go elector.Run(ctx)
+	return nil
+}
+
+// RestoreAssumed assumes the storage of the pending pvcs scheduled to a node by the former leader, from their
+// binding records if any, or by allocating the storage on the selected node again
+func (e *ExtenderServer) RestoreAssumed() {
+	pvcs, err := e.Ctx.CoreV1Informers.PersistentVolumeClaims().Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("[RestoreAssumed]failed to list pvc: %s", err.Error())
+		return
+	}
+	var unrecorded []*corev1.PersistentVolumeClaim
+	restored := 0
+	e.Ctx.CtxLock.Lock()
+	for _, pvc := range pvcs {
+		nodeName := pvc.Annotations[pkg.AnnoSelectedNode]
+		if nodeName == "" || pvc.Status.Phase == corev1.ClaimBound || pvc.Spec.VolumeName != "" {
+			continue
+		}
+		pvcName := utils.PVCName(pvc)
+		if e.Ctx.ClusterNodeCache.BindingInfo.IsPVCExists(pvcName) {
+			continue
+		}
+		containReadonlySnapshot := false
+		if isLocalPVC, _ := utils.IsLocalPVC(pvc, e.Ctx.StorageV1Informers, e.Ctx.SnapshotInformers, containReadonlySnapshot); !isLocalPVC {
+			continue
+		}
+		unit, err := bindingRecord(pvc)
+		if err != nil || unit == nil || unit.NodeName != nodeName || unit.PVCName != pvcName {
+			unrecorded = append(unrecorded, pvc)
+			continue
+		}
+		if err := e.Ctx.ClusterNodeCache.Assume([]cache.AllocatedUnit{*unit}); err != nil {
+			log.Errorf("[RestoreAssumed]failed to assume recorded storage of pvc %s: %s", pvcName, err.Error())
+			continue
+		}
+		e.Ctx.ClusterNodeCache.BindingInfo[pvcName] = unit
+		restored++
+	}
+	e.Ctx.CtxLock.Unlock()
+
+	// SchedulingPVC takes the CtxLock itself
+	for _, pvc := range unrecorded {
+		nodeName := pvc.Annotations[pkg.AnnoSelectedNode]
+		node, err := e.Ctx.CoreV1Informers.Nodes().Lister().Get(nodeName)
+		if err != nil {
+			log.Errorf("[RestoreAssumed]failed to get node %s of pvc %s: %s", nodeName, utils.PVCName(pvc), err.Error())
+			continue
+		}
+		if _, err := apis.SchedulingPVC(e.Ctx, pvc, node); err != nil {
+			log.Errorf("[RestoreAssumed]failed to allocate storage of pvc %s on node %s: %s", utils.PVCName(pvc), nodeName, err.Error())
+			continue
+		}
+		restored++
+	}
+	log.Infof("[RestoreAssumed]restored assumed storage of %d pvcs, %d without binding records", restored, len(unrecorded))
+}
+
+// RecordBinding persists the storage assumed for the pvc in its annotation, it is a no-op without leader election
+func (e *ExtenderServer) RecordBinding(pvc *corev1.PersistentVolumeClaim) {
+	if !e.leaderElection {
+		return
+	}
+	pvcName := utils.PVCName(pvc)
+	e.Ctx.CtxLock.Lock()
+	unit, ok := e.Ctx.ClusterNodeCache.BindingInfo[pvcName]
+	var record []byte
+	var err error
+	if ok {
+		record, err = json.Marshal(unit)
+	}
+	e.Ctx.CtxLock.Unlock()
+	if !ok || err != nil || pvc.Annotations[pkg.AnnotationBindingRecord] == string(record) {
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{pkg.AnnotationBindingRecord: string(record)},
+		},
+	})
+	if err != nil {
+		log.Errorf("[RecordBinding]failed to marshal patch of pvc %s: %s", pvcName, err.Error())
+		return
+	}
+	if _, err := e.kubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(context.Background(), pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		// the storage is allocated again on the selected node if the leader changes before the pv is created
+		log.Warningf("[RecordBinding]failed to record binding of pvc %s: %s", pvcName, err.Error())
+	}
+}
+
+func bindingRecord(pvc *corev1.PersistentVolumeClaim) (*cache.AllocatedUnit, error) {
+	record, ok := pvc.Annotations[pkg.AnnotationBindingRecord]
+	if !ok {
+		return nil, nil
+	}
+	unit := &cache.AllocatedUnit{}
+	if err := json.Unmarshal([]byte(record), unit); err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+// leaderOnly rejects the scheduling requests on standby extenders, whose caches lack the assumed storage,
+// the other requests, e.g. of cache, policy, explain and metrics, are served as usual
+func (e *ExtenderServer) leaderOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !e.IsLeader() && (strings.HasPrefix(r.URL.Path, apiPrefix+"/") || strings.HasPrefix(r.URL.Path, apisPrefix+"/")) {
+			utils.HttpResponse(w, http.StatusServiceUnavailable, []byte("standby scheduler extender, please retry with the leader"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ReadyzRoute reports ready only on the leader, so that the service of extenders routes requests to it
+func (e *ExtenderServer) ReadyzRoute(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !e.IsLeader() {
+		utils.HttpResponse(w, http.StatusServiceUnavailable, []byte("standby"))
+		return
+	}
+	utils.HttpResponse(w, http.StatusOK, []byte("ok"))
+}
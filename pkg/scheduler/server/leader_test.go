@@ -0,0 +1,157 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	localinformers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	volumesnapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestExtenderServer(objects ...*corev1.PersistentVolumeClaim) *ExtenderServer {
+	var kubeObjects []interface{}
+	kubeClient := k8sfake.NewSimpleClientset()
+	for _, obj := range objects {
+		kubeObjects = append(kubeObjects, obj)
+		_, _ = kubeClient.CoreV1().PersistentVolumeClaims(obj.Namespace).Create(context.Background(), obj, metav1.CreateOptions{})
+	}
+	localClient := localfake.NewSimpleClientset()
+	snapClient := volumesnapshotfake.NewSimpleClientset()
+	e := NewExtenderServer(kubeClient, localClient, snapClient,
+		kubeinformers.NewSharedInformerFactory(kubeClient, 0),
+		localinformers.NewSharedInformerFactory(localClient, 0),
+		volumesnapshotinformers.NewSharedInformerFactory(snapClient, 0),
+		0, policy.NewDefaultPolicy(localtype.StrategyBinpack))
+	for _, obj := range kubeObjects {
+		_ = e.Ctx.CoreV1Informers.PersistentVolumeClaims().Informer().GetIndexer().Add(obj)
+	}
+	return e
+}
+
+func newSelectedPVC(name, nodeName string, record *cache.AllocatedUnit) *corev1.PersistentVolumeClaim {
+	scName := "open-local-lvm"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{localtype.AnnoSelectedNode: nodeName},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &scName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	if record != nil {
+		data, _ := json.Marshal(record)
+		pvc.Annotations[localtype.AnnotationBindingRecord] = string(data)
+	}
+	return pvc
+}
+
+func TestRestoreAssumed(t *testing.T) {
+	unit := &cache.AllocatedUnit{NodeName: "node1", VolumeType: localtype.VolumeTypeLVM, Requested: 10 << 30, Allocated: 10 << 30, VgName: "share", PVCName: "default/recorded"}
+	recorded := newSelectedPVC("recorded", "node1", unit)
+	// the record of a pvc rescheduled to another node is outdated
+	outdated := newSelectedPVC("outdated", "node2", &cache.AllocatedUnit{NodeName: "node1", PVCName: "default/outdated"})
+	e := newTestExtenderServer(recorded, outdated)
+	_ = e.Ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(&storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "open-local-lvm"},
+		Provisioner: localtype.ProvisionerName,
+		Parameters:  map[string]string{localtype.VolumeTypeKey: string(localtype.VolumeTypeLVM)},
+	})
+	nc := cache.NewNodeCache("node1")
+	nc.VGs["share"] = cache.SharedResource{Name: "share", Capacity: 100 << 30}
+	e.Ctx.ClusterNodeCache.SetNodeCache(nc)
+
+	e.RestoreAssumed()
+	if vg := e.Ctx.ClusterNodeCache.GetNodeCache("node1").VGs["share"]; vg.Requested != 10<<30 {
+		t.Errorf("expect 10Gi assumed from binding record, got %d", vg.Requested)
+	}
+	if binding, ok := e.Ctx.ClusterNodeCache.BindingInfo["default/recorded"]; !ok || *binding != *unit {
+		t.Errorf("expect binding info of default/recorded restored, got %+v", binding)
+	}
+	if e.Ctx.ClusterNodeCache.BindingInfo.IsPVCExists("default/outdated") {
+		t.Errorf("expect outdated binding record ignored")
+	}
+}
+
+func TestRecordBinding(t *testing.T) {
+	pvc := newSelectedPVC("pvc", "node1", nil)
+	e := newTestExtenderServer(pvc)
+	unit := &cache.AllocatedUnit{NodeName: "node1", VolumeType: localtype.VolumeTypeLVM, Requested: 10 << 30, Allocated: 10 << 30, VgName: "share", PVCName: "default/pvc"}
+	e.Ctx.ClusterNodeCache.BindingInfo["default/pvc"] = unit
+
+	// nothing is recorded without leader election
+	e.RecordBinding(pvc)
+	got, _ := e.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.Background(), "pvc", metav1.GetOptions{})
+	if _, ok := got.Annotations[localtype.AnnotationBindingRecord]; ok {
+		t.Fatalf("expect no binding record without leader election")
+	}
+
+	e.leaderElection = true
+	e.RecordBinding(pvc)
+	got, _ = e.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.Background(), "pvc", metav1.GetOptions{})
+	record, err := bindingRecord(got)
+	if err != nil || record == nil || *record != *unit {
+		t.Errorf("expect binding record %+v, got %+v, err %v", unit, record, err)
+	}
+}
+
+func TestLeaderOnly(t *testing.T) {
+	e := newTestExtenderServer()
+	e.leaderElection = true
+	handler := e.leaderOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	tests := []struct {
+		path    string
+		leading int32
+		code    int
+	}{
+		{predicatesPrefix, 0, http.StatusServiceUnavailable},
+		{"/apis/scheduling/default/persistentvolumeclaims/pvc", 0, http.StatusServiceUnavailable},
+		{cachePath, 0, http.StatusOK},
+		{predicatesPrefix, 1, http.StatusOK},
+	}
+	for _, tt := range tests {
+		e.leading = tt.leading
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s with leading %d: expect code %d, got %d", tt.path, tt.leading, tt.code, w.Code)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/alibaba/open-local/pkg/metrics"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	"github.com/alibaba/open-local/pkg/utils"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	clientgocache "k8s.io/client-go/tools/cache"
+)
+
+const (
+	policyReloadSucceeded = "succeeded"
+	policyReloadFailed    = "failed"
+)
+
+// AddGetPolicy serves the current scheduling policy
+func AddGetPolicy(router *httprouter.Router, ctx *algorithm.SchedulingContext) {
+	router.GET(policyPath, DebugLogging(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		utils.HttpJSON(w, http.StatusOK, ctx.Policy.Get())
+	}, policyPath))
+}
+
+// WatchPolicyFile reloads the scheduling policy from path every interval until stopCh is closed,
+// e.g. the file of a mounted ConfigMap. The current policy is kept if the file is invalid
+func (e *ExtenderServer) WatchPolicyFile(path string, interval time.Duration, stopCh <-chan struct{}) {
+	var lastDigest [sha256.Size]byte
+	wait.Until(func() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Errorf("[WatchPolicyFile]failed to read policy file %s: %s", path, err.Error())
+			return
+		}
+		digest := sha256.Sum256(data)
+		if digest == lastDigest {
+			return
+		}
+		lastDigest = digest
+		p, err := policy.Parse(data)
+		e.updatePolicy(p, err, path)
+	}, interval, stopCh)
+}
+
+// WatchPolicyConfigMap reloads the scheduling policy when the ConfigMap changes until stopCh is closed,
+// the current policy is kept if the ConfigMap is invalid or deleted
+func (e *ExtenderServer) WatchPolicyConfigMap(namespace, name string, stopCh <-chan struct{}) {
+	factory := kubeinformers.NewSharedInformerFactoryWithOptions(e.kubeClient, 0, kubeinformers.WithNamespace(namespace),
+		kubeinformers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	source := namespace + "/" + name
+	onUpdate := func(obj interface{}) {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok || cm.Name != name {
+			return
+		}
+		p, err := policy.FromConfigMap(cm)
+		e.updatePolicy(p, err, "configmap "+source)
+	}
+	factory.Core().V1().ConfigMaps().Informer().AddEventHandler(clientgocache.ResourceEventHandlerFuncs{
+		AddFunc: onUpdate,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			onUpdate(newObj)
+		},
+	})
+	factory.Start(stopCh)
+}
+
+func (e *ExtenderServer) updatePolicy(p *policy.Policy, err error, source string) {
+	if err != nil {
+		log.Errorf("failed to reload scheduling policy from %s, keeping the current one: %s", source, err.Error())
+		metrics.PolicyReloadTotal.WithLabelValues(policyReloadFailed).Inc()
+		return
+	}
+	if p.Hash() == e.Ctx.Policy.Get().Hash() {
+		return
+	}
+	e.Ctx.Policy.Set(p)
+	log.Infof("scheduling policy reloaded from %s: %+v", source, *p)
+	metrics.PolicyReloadTotal.WithLabelValues(policyReloadSucceeded).Inc()
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"time"
+
+	"github.com/alibaba/open-local/pkg/metrics"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	releaseReasonProvisioned = "Provisioned"
+	releaseReasonPVCDeleted  = "PVCDeleted"
+	releaseReasonRebound     = "Rebound"
+	releaseReasonExpired     = "Expired"
+)
+
+// releaseAssumed reverts the unit assumed for pvc, together with its binding info,
+// the caller must hold the CtxLock
+func (e *ExtenderServer) releaseAssumed(pvcName, reason string) {
+	assumed, err := e.Ctx.ClusterNodeCache.ReleaseAssumed(pvcName)
+	if assumed == nil {
+		return
+	}
+	if err != nil {
+		log.Warningf("failed to release assumed unit of pvc %s: %s", pvcName, err.Error())
+	}
+	if binding, ok := e.Ctx.ClusterNodeCache.BindingInfo[pvcName]; ok && *binding == assumed.AllocatedUnit {
+		delete(e.Ctx.ClusterNodeCache.BindingInfo, pvcName)
+	}
+	metrics.AssumedReleasedTotal.WithLabelValues(reason).Inc()
+	log.Infof("assumed unit of pvc %s on node %s is released, reason: %s", pvcName, assumed.NodeName, reason)
+}
+
+// ReconcileCache releases the expired assumed units and rebuilds node caches from listers periodically
+func (e *ExtenderServer) ReconcileCache(stopCh <-chan struct{}) {
+	wait.Until(e.reconcileCache, e.Ctx.CacheReconcileInterval, stopCh)
+}
+
+func (e *ExtenderServer) reconcileCache() {
+	nlsList, err := e.Ctx.LocalStorageInformer.NodeLocalStorages().Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("[reconcileCache]failed to list nls: %s", err.Error())
+		return
+	}
+	pvs, err := e.Ctx.CoreV1Informers.PersistentVolumes().Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("[reconcileCache]failed to list pv: %s", err.Error())
+		return
+	}
+	pods, err := e.Ctx.CoreV1Informers.Pods().Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("[reconcileCache]failed to list pod: %s", err.Error())
+		return
+	}
+
+	e.Ctx.CtxLock.Lock()
+	defer e.Ctx.CtxLock.Unlock()
+
+	for _, unit := range e.Ctx.ClusterNodeCache.ExpiredAssumed(time.Now()) {
+		e.releaseAssumed(unit.PVCName, releaseReasonExpired)
+	}
+
+	pvsOfNode := map[string][]*corev1.PersistentVolume{}
+	for _, pv := range pvs {
+		if pv.Status.Phase == corev1.VolumePending {
+			continue
+		}
+		if node := e.Ctx.ClusterNodeCache.GetNodeNameFromPV(pv); node != "" {
+			pvsOfNode[node] = append(pvsOfNode[node], pv)
+		}
+	}
+	podsOfNode := map[string][]*corev1.Pod{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			podsOfNode[pod.Spec.NodeName] = append(podsOfNode[pod.Spec.NodeName], pod)
+		}
+	}
+
+	var drifts []cache.Drift
+	for _, nls := range nlsList {
+		rebuilt := cache.NewNodeCacheFromStorage(nls)
+		for _, pv := range pvsOfNode[nls.Name] {
+			containReadonlySnapshot := false
+			isOpenLocalPV, pvType := utils.IsOpenLocalPV(pv, e.Ctx.StorageV1Informers, e.Ctx.CoreV1Informers, containReadonlySnapshot)
+			if !isOpenLocalPV {
+				continue
+			}
+			if err := rebuilt.AddLocalPV(pv, pvType); err != nil {
+				log.Errorf("[reconcileCache]failed to add local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nls.Name, err.Error())
+			}
+		}
+		for _, pod := range podsOfNode[nls.Name] {
+			if err := rebuilt.AddPodInlineVolumeInfo(pod); err != nil {
+				log.Errorf("[reconcileCache]failed to add inline volumes of pod %s/%s on node %s: %s", pod.Namespace, pod.Name, nls.Name, err.Error())
+			}
+		}
+		nodeDrifts := e.Ctx.ClusterNodeCache.RebuildNodeCache(rebuilt)
+		for _, drift := range nodeDrifts {
+			log.Warningf("[reconcileCache]%s %s on node %s drifted, cached %d, actual %d", drift.VolumeType, drift.Name, drift.NodeName, drift.Cached, drift.Actual)
+		}
+		drifts = append(drifts, nodeDrifts...)
+	}
+	metrics.UpdateCacheDrift(drifts)
+	log.Debugf("[reconcileCache]%d nodes reconciled, %d drifts found", len(nlsList), len(drifts))
+}
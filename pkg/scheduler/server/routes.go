@@ -42,6 +42,10 @@ const (
 	versionPath      = "/version"
 	metricsPath      = "/metrics"
 	cachePath        = "/cache"
+	policyPath       = "/policy"
+	explainPath      = "/explain"
+	readyzPath       = "/readyz"
+	apisPrefix       = "/apis"
 	apiPrefix        = "/scheduler"
 	bindPath         = apiPrefix + "/bind"
 	preemptionPath   = apiPrefix + "/preemption"
@@ -30,7 +30,9 @@ import (
 	"github.com/alibaba/open-local/pkg/metrics"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm/predicates"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/preemptions"
 	"github.com/alibaba/open-local/pkg/scheduler/algorithm/priorities"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
 	"github.com/julienschmidt/httprouter"
 	volumesnapshot "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
 	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
@@ -51,13 +53,13 @@ func NewExtenderServer(kubeClient kubernetes.Interface,
 	kubeInformerFactory kubeinformers.SharedInformerFactory,
 	localStorageInformerFactory informers.SharedInformerFactory,
 	volumesnapshotInformerFactory volumesnapshotinformers.SharedInformerFactory,
-	port int32, weights *pkg.NodeAntiAffinityWeight) *ExtenderServer {
+	port int32, schedulingPolicy *policy.Policy) *ExtenderServer {
 	corev1Informers := kubeInformerFactory.Core().V1()
 	storagev1Informers := kubeInformerFactory.Storage().V1()
 	localStorageInformers := localStorageInformerFactory.Csi().V1alpha1()
 	snapshotInformers := volumesnapshotInformerFactory.Snapshot().V1beta1()
 
-	Ctx := algorithm.NewSchedulingContext(corev1Informers, storagev1Informers, localStorageInformers, snapshotInformers, weights)
+	Ctx := algorithm.NewSchedulingContext(corev1Informers, storagev1Informers, localStorageInformers, snapshotInformers, schedulingPolicy)
 
 	informersSyncd := make([]clientgocache.InformerSynced, 0)
 
@@ -141,6 +143,10 @@ type ExtenderServer struct {
 	port                   int32
 	informersSynced        []clientgocache.InformerSynced
 	currentWorkingRoutines int32
+	// leaderElection is true if the extender runs as one of the leader-elected replicas,
+	// leading is 1 once it becomes the leader and restores the assumed storage
+	leaderElection bool
+	leading        int32
 }
 
 func (e *ExtenderServer) Start(stopCh <-chan struct{}) {
@@ -150,6 +156,7 @@ func (e *ExtenderServer) Start(stopCh <-chan struct{}) {
 	log.Infof("maxConcurrentWorkingRoutines was set to %d", MaxConcurrentWorkingRoutines)
 	log.Info("started open-local scheduler extender")
 	go e.TriggerPendingPodReschedule(stopCh)
+	go e.ReconcileCache(stopCh)
 	<-stopCh
 	log.Info("Shutting down open-local scheduler extender")
 }
@@ -165,9 +172,16 @@ func (e *ExtenderServer) InitRouter() {
 		metrics.DeviceAvailable,
 		metrics.DeviceBind,
 		metrics.MountPointBind,
+		metrics.QuotaTotal,
+		metrics.QuotaUsedByLocal,
 		metrics.AllocatedNum,
 		metrics.LocalPV,
 		metrics.InlineVolume,
+		metrics.AssumedUnits,
+		metrics.AssumedReleasedTotal,
+		metrics.CacheDrift,
+		metrics.CacheDriftTotal,
+		metrics.PolicyReloadTotal,
 	}...)
 
 	// Setting up the extender http server
@@ -175,14 +189,18 @@ func (e *ExtenderServer) InitRouter() {
 	AddVersion(router)
 	AddMetrics(router, e.Ctx)
 	AddGetNodeCache(router, e.Ctx)
+	AddGetPolicy(router, e.Ctx)
+	AddExplain(router, e.Ctx)
+	router.GET(readyzPath, e.ReadyzRoute)
 	AddPredicate(router, *predicates.NewPredicate(e.Ctx))
 	AddPrioritize(router, *priorities.NewPrioritize(e.Ctx))
-	AddSchedulingApis(router, e.Ctx)
+	AddPreemption(router, *preemptions.NewPreemption(e.Ctx))
+	AddSchedulingApis(router, e.Ctx, e.RecordBinding)
 
 	go func() {
 		if e.port > 0 {
 			log.Infof("starting http server on port %d", e.port)
-			if err := http.ListenAndServe(fmt.Sprintf(":%d", e.port), router); err != nil {
+			if err := http.ListenAndServe(fmt.Sprintf(":%d", e.port), e.leaderOnly(router)); err != nil {
 				log.Fatal(err)
 			}
 			log.Infof("started http server on port %d", e.port)
@@ -206,6 +224,10 @@ func (e *ExtenderServer) TriggerPendingPodReschedule(stopCh <-chan struct{}) {
 	ticker := time.NewTicker(pkg.TriggerPendingPodCycle)
 	opt := metav1.ListOptions{FieldSelector: pkg.PendingWithoutScheduledFieldSelector}
 	for range ticker.C {
+		// standby extenders leave the pods to the leader
+		if !e.IsLeader() {
+			continue
+		}
 		podList, err := e.kubeClient.CoreV1().Pods("").List(context.Background(), opt)
 		if err != nil {
 			log.Errorf("list pod with FieldSelector %+v :%+v", pkg.PendingWithoutScheduledFieldSelector, err)
@@ -0,0 +1,139 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	localscheme "github.com/alibaba/open-local/pkg/generated/clientset/versioned/scheme"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(localscheme.AddToScheme(scheme))
+}
+
+// LoadFiles decodes the objects in the YAML or JSON files, directories are walked for
+// .yaml, .yml and .json files. Lists, e.g. the output of "kubectl get -o yaml", are flattened
+func LoadFiles(paths []string) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// files given explicitly are loaded whatever the extension is
+			if file != path && !isManifest(file) {
+				return nil
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			loaded, err := Load(f)
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %s", file, err.Error())
+			}
+			objects = append(objects, loaded...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// Load decodes the objects of a YAML or JSON stream
+func Load(r io.Reader) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(string(doc)) == "" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(doc, &obj.Object); err != nil {
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				typed, err := toTyped(&list.Items[i])
+				if err != nil {
+					return nil, err
+				}
+				objects = append(objects, typed)
+			}
+			continue
+		}
+		typed, err := toTyped(obj)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, typed)
+	}
+}
+
+func toTyped(obj *unstructured.Unstructured) (runtime.Object, error) {
+	gvk := obj.GroupVersionKind()
+	typed, err := scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported object %s %s: %s", gvk.String(), obj.GetName(), err.Error())
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return nil, fmt.Errorf("failed to decode %s %s: %s", gvk.Kind, obj.GetName(), err.Error())
+	}
+	return typed, nil
+}
+
+func isManifest(file string) bool {
+	switch filepath.Ext(file) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
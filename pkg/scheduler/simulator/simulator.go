@@ -0,0 +1,394 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"sort"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	localv1alpha1 "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
+	localfake "github.com/alibaba/open-local/pkg/generated/clientset/versioned/fake"
+	localinformers "github.com/alibaba/open-local/pkg/generated/informers/externalversions"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm"
+	"github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+	"github.com/alibaba/open-local/pkg/scheduler/server/apis"
+	"github.com/alibaba/open-local/pkg/utils"
+	volumesnapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// Simulator places pods on a snapshot of the cluster with the same predicates, priorities
+// and assume of the scheduler extender, no apiserver is involved
+type Simulator struct {
+	Ctx   *algorithm.SchedulingContext
+	nodes []*corev1.Node
+}
+
+// Result is the outcome of a simulation
+type Result struct {
+	Placements []Placement `json:"placements"`
+	Failures   []Failure   `json:"failures"`
+	// Skipped are the pods without pending open-local volumes
+	Skipped []string `json:"skipped,omitempty"`
+	// Nodes are the remaining storage of nodes after the placements
+	Nodes []NodeCapacity `json:"nodes"`
+}
+
+// Placement is the node selected for a pod and the storage assumed for its pvcs
+type Placement struct {
+	Pod     string   `json:"pod"`
+	Node    string   `json:"node"`
+	Score   int64    `json:"score"`
+	Volumes []Volume `json:"volumes,omitempty"`
+}
+
+// Volume is the storage assumed for a pvc
+type Volume struct {
+	PersistentVolumeClaim string               `json:"persistentVolumeClaim"`
+	VolumeType            localtype.VolumeType `json:"volumeType"`
+	Size                  int64                `json:"size"`
+	VgName                string               `json:"vgName,omitempty"`
+	MountPoint            string               `json:"mountPoint,omitempty"`
+	Device                string               `json:"device,omitempty"`
+}
+
+// Failure is a pod which fits no node, with the failure reasons of each node
+type Failure struct {
+	Pod     string              `json:"pod"`
+	Reasons map[string][]string `json:"reasons,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// NodeCapacity is the storage of a node
+type NodeCapacity struct {
+	Node        string              `json:"node"`
+	VGs         []SharedCapacity    `json:"vgs,omitempty"`
+	Quotas      []SharedCapacity    `json:"quotas,omitempty"`
+	MountPoints []ExclusiveCapacity `json:"mountPoints,omitempty"`
+	Devices     []ExclusiveCapacity `json:"devices,omitempty"`
+}
+
+// SharedCapacity is the storage of a VG or a quota mount point
+type SharedCapacity struct {
+	Name      string              `json:"name"`
+	MediaType localtype.MediaType `json:"mediaType,omitempty"`
+	Capacity  int64               `json:"capacity"`
+	Requested int64               `json:"requested"`
+	Free      int64               `json:"free"`
+}
+
+// ExclusiveCapacity is the storage of a mount point or device
+type ExclusiveCapacity struct {
+	Name      string              `json:"name"`
+	Capacity  int64               `json:"capacity"`
+	MediaType localtype.MediaType `json:"mediaType"`
+	Allocated bool                `json:"allocated"`
+}
+
+// New builds the scheduling context from the cluster snapshot, which consists of NodeLocalStorages,
+// Nodes, StorageClasses, PVs, PVCs and Pods. Nodes are made up for NodeLocalStorages without them
+func New(objects []runtime.Object, schedulingPolicy *policy.Policy) (*Simulator, error) {
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0)
+	localInformerFactory := localinformers.NewSharedInformerFactory(localfake.NewSimpleClientset(), 0)
+	snapshotInformerFactory := volumesnapshotinformers.NewSharedInformerFactory(volumesnapshotfake.NewSimpleClientset(), 0)
+	ctx := algorithm.NewSchedulingContext(kubeInformerFactory.Core().V1(), kubeInformerFactory.Storage().V1(),
+		localInformerFactory.Csi().V1alpha1(), snapshotInformerFactory.Snapshot().V1beta1(), schedulingPolicy)
+	s := &Simulator{Ctx: ctx}
+
+	var nlsList []*localv1alpha1.NodeLocalStorage
+	var pvs []*corev1.PersistentVolume
+	var pods []*corev1.Pod
+	nodes := map[string]*corev1.Node{}
+	for _, obj := range objects {
+		var err error
+		switch o := obj.(type) {
+		case *localv1alpha1.NodeLocalStorage:
+			nlsList = append(nlsList, o)
+			err = ctx.LocalStorageInformer.NodeLocalStorages().Informer().GetIndexer().Add(o)
+		case *corev1.Node:
+			nodes[o.Name] = o
+		case *storagev1.StorageClass:
+			err = ctx.StorageV1Informers.StorageClasses().Informer().GetIndexer().Add(o)
+		case *corev1.PersistentVolume:
+			pvs = append(pvs, o)
+			err = ctx.CoreV1Informers.PersistentVolumes().Informer().GetIndexer().Add(o)
+		case *corev1.PersistentVolumeClaim:
+			err = ctx.CoreV1Informers.PersistentVolumeClaims().Informer().GetIndexer().Add(o)
+		case *corev1.Pod:
+			pods = append(pods, o)
+			err = ctx.CoreV1Informers.Pods().Informer().GetIndexer().Add(o)
+		default:
+			log.Warningf("[simulator]%s is ignored in cluster snapshot", obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, nls := range nlsList {
+		if _, ok := nodes[nls.Name]; !ok {
+			nodes[nls.Name] = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nls.Name}}
+		}
+		nc := cache.NewNodeCacheFromStorage(nls)
+		for _, pv := range pvs {
+			if pv.Status.Phase == corev1.VolumePending {
+				continue
+			}
+			if _, node := utils.IsLocalPV(pv); node != nls.Name {
+				continue
+			}
+			containReadonlySnapshot := false
+			isOpenLocalPV, pvType := utils.IsOpenLocalPV(pv, ctx.StorageV1Informers, ctx.CoreV1Informers, containReadonlySnapshot)
+			if !isOpenLocalPV {
+				continue
+			}
+			if err := nc.AddLocalPV(pv, pvType); err != nil {
+				return nil, fmt.Errorf("failed to add local pv %s (type: %s) on node %s: %s", pv.Name, pvType, nls.Name, err.Error())
+			}
+		}
+		for _, pod := range pods {
+			if err := nc.AddPodInlineVolumeInfo(pod); err != nil {
+				return nil, fmt.Errorf("failed to add inline volumes of pod %s/%s on node %s: %s", pod.Namespace, pod.Name, nls.Name, err.Error())
+			}
+		}
+		ctx.ClusterNodeCache.SetNodeCache(nc)
+	}
+
+	for _, node := range nodes {
+		if err := ctx.CoreV1Informers.Nodes().Informer().GetIndexer().Add(node); err != nil {
+			return nil, err
+		}
+		s.nodes = append(s.nodes, node)
+	}
+	sort.Slice(s.nodes, func(i, j int) bool { return s.nodes[i].Name < s.nodes[j].Name })
+	return s, nil
+}
+
+// Run places the pods of the workloads one by one, in the order of the objects. StatefulSets are
+// expanded to pods and pvcs of their replicas, and pvcs are created before pods
+func (s *Simulator) Run(workloads []runtime.Object) (*Result, error) {
+	pods, pvcs, err := expand(workloads)
+	if err != nil {
+		return nil, err
+	}
+	for _, pvc := range pvcs {
+		if _, err := s.Ctx.CoreV1Informers.PersistentVolumeClaims().Lister().PersistentVolumeClaims(pvc.Namespace).Get(pvc.Name); err == nil {
+			log.Infof("[simulator]pvc %s/%s exists in cluster snapshot, skipped", pvc.Namespace, pvc.Name)
+			continue
+		}
+		if err := s.Ctx.CoreV1Informers.PersistentVolumeClaims().Informer().GetIndexer().Add(pvc); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Result{Placements: []Placement{}, Failures: []Failure{}}
+	for _, pod := range pods {
+		if err := s.schedule(pod, result); err != nil {
+			return nil, err
+		}
+	}
+	result.Nodes = s.NodeCapacities()
+	return result, nil
+}
+
+// schedule places the pod on the node of the highest score, like kube-scheduler does with the extender only,
+// and assumes the storage of its pvcs. Nodes of the same score are picked in the order of names
+func (s *Simulator) schedule(pod *corev1.Pod, result *Result) error {
+	podName := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	containReadonlySnapshot := false
+	err, lvmPVCs, mpPVCs, devicePVCs, quotaPVCs := algorithm.GetPodPvcs(pod, s.Ctx, true, containReadonlySnapshot)
+	if err != nil {
+		result.Failures = append(result.Failures, Failure{Pod: podName, Error: err.Error()})
+		return nil
+	}
+	containInlineVolume, _ := utils.ContainInlineVolumes(pod)
+	if len(lvmPVCs)+len(mpPVCs)+len(devicePVCs)+len(quotaPVCs) == 0 && !containInlineVolume {
+		result.Skipped = append(result.Skipped, podName)
+		return s.Ctx.CoreV1Informers.Pods().Informer().GetIndexer().Add(pod)
+	}
+
+	explanation, err := apis.ExplainPod(s.Ctx, pod, s.nodes)
+	if err != nil {
+		result.Failures = append(result.Failures, Failure{Pod: podName, Error: err.Error()})
+		return nil
+	}
+	best := -1
+	for i, node := range explanation.Nodes {
+		if node.Fits && (best < 0 || node.Score > explanation.Nodes[best].Score) {
+			best = i
+		}
+	}
+	if best < 0 {
+		failure := Failure{Pod: podName, Reasons: map[string][]string{}}
+		for _, node := range explanation.Nodes {
+			reasons := node.Reasons
+			if node.Error != "" {
+				reasons = append(reasons, node.Error)
+			}
+			failure.Reasons[node.Node] = reasons
+		}
+		result.Failures = append(result.Failures, failure)
+		return nil
+	}
+
+	node := s.nodes[best]
+	units, err := apis.SchedulingPod(s.Ctx, pod, node)
+	if err != nil {
+		result.Failures = append(result.Failures, Failure{Pod: podName, Error: err.Error()})
+		return nil
+	}
+	// the pod runs on the node from now on, so that its inline volumes take the storage
+	pod.Spec.NodeName = node.Name
+	pod.Status.Phase = corev1.PodRunning
+	if pod.UID == "" {
+		pod.UID = types.UID(podName)
+	}
+	if err := s.Ctx.CoreV1Informers.Pods().Informer().GetIndexer().Add(pod); err != nil {
+		return err
+	}
+	if nc := s.Ctx.ClusterNodeCache.GetNodeCache(node.Name); nc != nil && containInlineVolume {
+		if err := nc.AddPodInlineVolumeInfo(pod); err != nil {
+			return err
+		}
+		s.Ctx.ClusterNodeCache.SetNodeCache(nc)
+	}
+
+	placement := Placement{Pod: podName, Node: node.Name, Score: explanation.Nodes[best].Score}
+	for _, unit := range units {
+		placement.Volumes = append(placement.Volumes, Volume{
+			PersistentVolumeClaim: unit.PVCName,
+			VolumeType:            unit.VolumeType,
+			Size:                  unit.Allocated,
+			VgName:                unit.VgName,
+			MountPoint:            unit.MountPoint,
+			Device:                unit.Device,
+		})
+	}
+	result.Placements = append(result.Placements, placement)
+	return nil
+}
+
+// NodeCapacities returns the storage of all nodes in the order of names
+func (s *Simulator) NodeCapacities() []NodeCapacity {
+	capacities := make([]NodeCapacity, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		nc := s.Ctx.ClusterNodeCache.GetNodeCache(node.Name)
+		if nc == nil {
+			continue
+		}
+		nc = nc.DeepCopy()
+		capacity := NodeCapacity{Node: node.Name}
+		for _, vg := range nc.VGs {
+			capacity.VGs = append(capacity.VGs, SharedCapacity{Name: vg.Name, MediaType: vg.MediaType, Capacity: vg.Capacity, Requested: vg.Requested, Free: vg.Capacity - vg.Requested})
+		}
+		for _, quota := range nc.Quotas {
+			capacity.Quotas = append(capacity.Quotas, SharedCapacity{Name: quota.Name, Capacity: quota.Capacity, Requested: quota.Requested, Free: quota.Capacity - quota.Requested})
+		}
+		for _, mp := range nc.MountPoints {
+			capacity.MountPoints = append(capacity.MountPoints, ExclusiveCapacity{Name: mp.Name, Capacity: mp.Capacity, MediaType: mp.MediaType, Allocated: mp.IsAllocated})
+		}
+		for _, device := range nc.Devices {
+			capacity.Devices = append(capacity.Devices, ExclusiveCapacity{Name: device.Name, Capacity: device.Capacity, MediaType: device.MediaType, Allocated: device.IsAllocated})
+		}
+		sort.Slice(capacity.VGs, func(i, j int) bool { return capacity.VGs[i].Name < capacity.VGs[j].Name })
+		sort.Slice(capacity.Quotas, func(i, j int) bool { return capacity.Quotas[i].Name < capacity.Quotas[j].Name })
+		sort.Slice(capacity.MountPoints, func(i, j int) bool { return capacity.MountPoints[i].Name < capacity.MountPoints[j].Name })
+		sort.Slice(capacity.Devices, func(i, j int) bool { return capacity.Devices[i].Name < capacity.Devices[j].Name })
+		capacities = append(capacities, capacity)
+	}
+	return capacities
+}
+
+// expand returns the pods and pvcs of the workloads, namespaces default to "default"
+func expand(workloads []runtime.Object) ([]*corev1.Pod, []*corev1.PersistentVolumeClaim, error) {
+	var pods []*corev1.Pod
+	var pvcs []*corev1.PersistentVolumeClaim
+	for _, obj := range workloads {
+		switch o := obj.(type) {
+		case *appsv1.StatefulSet:
+			stsPods, stsPVCs := expandStatefulSet(o)
+			pods = append(pods, stsPods...)
+			pvcs = append(pvcs, stsPVCs...)
+		case *corev1.Pod:
+			pod := o.DeepCopy()
+			if pod.Namespace == "" {
+				pod.Namespace = metav1.NamespaceDefault
+			}
+			pod.Spec.NodeName = ""
+			pods = append(pods, pod)
+		case *corev1.PersistentVolumeClaim:
+			pvc := o.DeepCopy()
+			if pvc.Namespace == "" {
+				pvc.Namespace = metav1.NamespaceDefault
+			}
+			pvc.Status.Phase = corev1.ClaimPending
+			pvcs = append(pvcs, pvc)
+		default:
+			return nil, nil, fmt.Errorf("unsupported workload %s, only StatefulSet, Pod and PersistentVolumeClaim are supported",
+				obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+	}
+	return pods, pvcs, nil
+}
+
+// expandStatefulSet returns the pods and pvcs of the replicas named after the StatefulSet controller
+func expandStatefulSet(sts *appsv1.StatefulSet) ([]*corev1.Pod, []*corev1.PersistentVolumeClaim) {
+	namespace := sts.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	var pods []*corev1.Pod
+	var pvcs []*corev1.PersistentVolumeClaim
+	for i := int32(0); i < replicas; i++ {
+		pod := &corev1.Pod{
+			ObjectMeta: *sts.Spec.Template.ObjectMeta.DeepCopy(),
+			Spec:       *sts.Spec.Template.Spec.DeepCopy(),
+		}
+		pod.Name = fmt.Sprintf("%s-%d", sts.Name, i)
+		pod.Namespace = namespace
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			pvc := template.DeepCopy()
+			pvc.Name = fmt.Sprintf("%s-%s", template.Name, pod.Name)
+			pvc.Namespace = namespace
+			pvc.Status.Phase = corev1.ClaimPending
+			pvcs = append(pvcs, pvc)
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+				Name: template.Name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+				},
+			})
+		}
+		pods = append(pods, pod)
+	}
+	return pods, pvcs
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"strings"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	"github.com/alibaba/open-local/pkg/scheduler/policy"
+)
+
+const testCluster = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: csi.aliyun.com/v1alpha1
+  kind: NodeLocalStorage
+  metadata:
+    name: node1
+  status:
+    nodeStorageInfo:
+      volumeGroups:
+      - name: share
+        total: 107374182400
+        allocatable: 107374182400
+    filteredStorageInfo:
+      volumeGroups: [share]
+- apiVersion: csi.aliyun.com/v1alpha1
+  kind: NodeLocalStorage
+  metadata:
+    name: node2
+  status:
+    nodeStorageInfo:
+      volumeGroups:
+      - name: share
+        total: 53687091200
+        allocatable: 53687091200
+    filteredStorageInfo:
+      volumeGroups: [share]
+---
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: open-local-lvm
+provisioner: local.csi.aliyun.com
+parameters:
+  volumeType: LVM
+  vgName: share
+`
+
+const testWorkload = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: nginx
+spec:
+  replicas: 4
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+  volumeClaimTemplates:
+  - metadata:
+      name: html
+    spec:
+      accessModes: [ReadWriteOnce]
+      storageClassName: open-local-lvm
+      resources:
+        requests:
+          storage: 40Gi
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: no-storage
+spec:
+  containers:
+  - name: busybox
+    image: busybox
+`
+
+func TestSimulator(t *testing.T) {
+	cluster, err := Load(strings.NewReader(testCluster))
+	if err != nil {
+		t.Fatalf("failed to load cluster: %v", err)
+	}
+	workloads, err := Load(strings.NewReader(testWorkload))
+	if err != nil {
+		t.Fatalf("failed to load workloads: %v", err)
+	}
+	if len(cluster) != 3 || len(workloads) != 2 {
+		t.Fatalf("expect 3 cluster objects and 2 workloads, got %d and %d", len(cluster), len(workloads))
+	}
+
+	s, err := New(cluster, policy.NewDefaultPolicy(localtype.StrategyBinpack))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := s.Run(workloads)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// 40Gi pvcs: two fit node1 of 100Gi, one fits node2 of 50Gi
+	if len(result.Placements) != 3 || len(result.Failures) != 1 || len(result.Skipped) != 1 {
+		t.Fatalf("expect 3 placed, 1 failed and 1 skipped, got %+v", result)
+	}
+	placed := map[string]int{}
+	for _, placement := range result.Placements {
+		placed[placement.Node]++
+		if len(placement.Volumes) != 1 || placement.Volumes[0].VgName != "share" || placement.Volumes[0].Size != 40<<30 {
+			t.Errorf("unexpected volumes of %s: %+v", placement.Pod, placement.Volumes)
+		}
+	}
+	if placed["node1"] != 2 || placed["node2"] != 1 {
+		t.Errorf("expect 2 pods on node1 and 1 on node2, got %v", placed)
+	}
+	failure := result.Failures[0]
+	if failure.Pod != "default/nginx-3" || len(failure.Reasons["node1"]) != 1 || !strings.Contains(failure.Reasons["node2"][0], "Insufficient LVM storage") {
+		t.Errorf("unexpected failure %+v", failure)
+	}
+	free := map[string]int64{}
+	for _, node := range result.Nodes {
+		free[node.Node] = node.VGs[0].Free
+	}
+	if free["node1"] != 20<<30 || free["node2"] != 10<<30 {
+		t.Errorf("expect 20Gi free on node1 and 10Gi on node2, got %v", free)
+	}
+}
@@ -36,3 +36,47 @@ type BindingInfo struct {
 	// PersistentVolumeClaim is the metakey for pvc: {namespace}/{name}
 	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
 }
+
+// Explanation tells whether a pod fits each node and how the nodes are scored
+type Explanation struct {
+	// Pod is the metakey of the explained pod: {namespace}/{name}
+	Pod string `json:"pod,omitempty"`
+	// PersistentVolumeClaim is the metakey of the explained pvc if a pvc rather than a pod is explained
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+	// Nodes are the explanations of all nodes, in the order of node names
+	Nodes []NodeExplanation `json:"nodes"`
+}
+
+// NodeExplanation is the result of predicates and priorities of the pod on a node
+type NodeExplanation struct {
+	Node string `json:"node"`
+	Fits bool   `json:"fits"`
+	// Reasons are the failure reasons of the predicates
+	Reasons []string `json:"reasons,omitempty"`
+	// Error is the unexpected error which terminates the scheduling of the pod
+	Error string `json:"error,omitempty"`
+	// Volumes are the results of predicates of each pending open-local pvc on its own
+	Volumes []VolumeExplanation `json:"volumes,omitempty"`
+	// Scores are the scores of the enabled priorities, only for the nodes the pod fits
+	Scores []PriorityScore `json:"scores,omitempty"`
+	// Score is the weighted sum of Scores
+	Score int64 `json:"score"`
+}
+
+// VolumeExplanation is the result of predicates of a pvc on a node
+type VolumeExplanation struct {
+	// PersistentVolumeClaim is the metakey for pvc: {namespace}/{name}
+	PersistentVolumeClaim string         `json:"persistentVolumeClaim"`
+	VolumeType            pkg.VolumeType `json:"volumeType"`
+	Fits                  bool           `json:"fits"`
+	Reasons               []string       `json:"reasons,omitempty"`
+	Error                 string         `json:"error,omitempty"`
+}
+
+// PriorityScore is the score of a priority on a node
+type PriorityScore struct {
+	Name   string `json:"name"`
+	Score  int    `json:"score"`
+	Weight int    `json:"weight"`
+	Error  string `json:"error,omitempty"`
+}
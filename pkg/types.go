@@ -34,6 +34,9 @@ const (
 
 	StrategyBinpack StrategyType = "binpack"
 	StrategySpread  StrategyType = "spread"
+	// StrategyMostFreeVG picks the VG with the most free space, the same as StrategySpread,
+	// but prefers the node left with the most free space rather than the least used one
+	StrategyMostFreeVG StrategyType = "most-free-vg"
 
 	AgentName           string = "open-local-agent"
 	ProvisionerNameYoda string = "yodaplugin.csi.alibabacloud.com"
@@ -69,6 +72,8 @@ const (
 	VGName       = "vgName"
 	MPName       = "MountPoint"
 	DeviceName   = "Device"
+	DeviceID     = "deviceID"
+	QuotaName    = "Quota"
 
 	// VolumeType MUST BE case sensitive
 	VolumeTypeMountPoint VolumeType = "MountPoint"
@@ -88,6 +93,8 @@ const (
 	EnvForceCreateVG                     = "Force_Create_VG"
 	PendingWithoutScheduledFieldSelector = "status.phase=Pending,spec.nodeName="
 	TriggerPendingPodCycle               = time.Second * 300
+	DefaultAssumeTTL                     = time.Minute * 10
+	DefaultCacheReconcileInterval        = time.Minute * 5
 
 	ParamSnapshotName            = "yoda.io/snapshot-name"
 	ParamSnapshotReadonly        = "csi.aliyun.com/readonly"
@@ -96,6 +103,8 @@ const (
 	ParamSnapshotExpansionSize   = "csi.aliyun.com/snapshot-expansion-size"
 	ParamVGName                  = "vgName"
 	ParamLVSize                  = "size"
+	ParamLVMType                 = "lvmType"
+	ParamThinPoolPercent         = "thinPoolPercent"
 	EnvSnapshotPrefix            = "SNAPSHOT_PREFIX"
 	DefaultSnapshotPrefix        = "snap"
 	DefaultSnapshotInitialSize   = 4 * 1024 * 1024 * 1024
@@ -104,6 +113,101 @@ const (
 
 	Separator = "<:SEP:>"
 
+	// thin provisioning
+	LVMTypeThin                = "thin"
+	ThinPoolName               = "open-local-thinpool"
+	DefaultThinPoolPercent     = 50
+	DefaultThinOvercommitRatio = 1.0
+
+	// project quota
+	MountOptionPrjQuota = "prjquota"
+	MountOptionPQuota   = "pquota"
+
+	// volume ownership
+	VolumeOwnerTagPrefix   = "open-local.pv="
+	EnvStorageClaimDir     = "LVMD_CLAIM_DIR"
+	DefaultStorageClaimDir = "/var/lib/open-local/claims"
+
+	// volume clone
+	ParamSourceVolumeID = "csi.aliyun.com/source-volume-id"
+	ParamSourceVGName   = "csi.aliyun.com/source-vg-name"
+	// snapshot restored into a writable volume
+	ParamSourceSnapshotID = "csi.aliyun.com/source-snapshot-id"
+	// ClonedLVTag marks the lv whose data has been copied from the source volume
+	ClonedLVTag = "open-local.cloned"
+	// CopyingLVTag marks the lv whose data is being copied, and is removed once the copy ends
+	CopyingLVTag = "open-local.copying"
+	// events of copying data to a clone
+	EventCloneStarted   = "CloneStarted"
+	EventCloneProgress  = "CloneProgress"
+	EventCloneSucceeded = "CloneSucceeded"
+	EventCloneFailed    = "CloneFailed"
+
+	// snapshot backup, parameters of the VolumeSnapshotClass to back up snapshots and of the StorageClass to restore from backups
+	ParamBackupTarget          = "csi.aliyun.com/backup-target"
+	ParamBackupSecretName      = "csi.aliyun.com/backup-secret-name"
+	ParamBackupSecretNamespace = "csi.aliyun.com/backup-secret-namespace"
+	// ParamRestoreBackupName is set in the volume context of a volume restored from a backup
+	ParamRestoreBackupName = "csi.aliyun.com/restore-backup-name"
+	// AnnotationRestoreFromBackup of a pvc is the name of the backup to restore from
+	AnnotationRestoreFromBackup = "csi.aliyun.com/restore-from-backup"
+	// status of the backup, set in annotations of the VolumeSnapshotContent
+	AnnotationBackupStatus         = "csi.aliyun.com/backup-status"
+	AnnotationBackupMessage        = "csi.aliyun.com/backup-message"
+	AnnotationBackupName           = "csi.aliyun.com/backup-name"
+	AnnotationBackupSize           = "csi.aliyun.com/backup-size"
+	AnnotationBackupUploadedBytes  = "csi.aliyun.com/backup-uploaded-bytes"
+	AnnotationBackupCompletionTime = "csi.aliyun.com/backup-completion-time"
+	BackupStatusInProgress         = "InProgress"
+	BackupStatusCompleted          = "Completed"
+	BackupStatusFailed             = "Failed"
+	EnvBackupSnapshotInterval      = "Backup_Snapshot_Interval"
+	EventBackupStarted             = "BackupStarted"
+	EventBackupSucceeded           = "BackupSucceeded"
+	EventBackupFailed              = "BackupFailed"
+
+	// failures of expanding a volume are reported in this condition of its pvc, which is removed once expanded
+	PVCConditionVolumeResizeFailed = "VolumeResizeFailed"
+	ReasonControllerExpandFailed   = "ControllerExpandFailed"
+	ReasonNodeExpandFailed         = "NodeExpandFailed"
+	// EventExpansionRejected is recorded on the pvc whose storage has no room for the new size
+	EventExpansionRejected = "ExpansionRejected"
+
+	// lvmd transport
+	LvmdTransportGRPC = "grpc"
+	LvmdTransportCRD  = "crd"
+	// LvmdRequest objects are labeled with the node to serve them
+	LabelLvmdRequestNode = "csi.aliyun.com/lvmd-node"
+	// LvmdTokenAudience is the audience of the service account tokens csi controller authenticates LvmdRequests with,
+	// each token is bound to the node and the nonce of its request by LvmdRequestAudience
+	LvmdTokenAudience = "open-local-lvmd"
+	// LvmdReplyAudience is the audience of the service account token the node authenticates the results of LvmdRequests with
+	LvmdReplyAudience = "open-local-lvmd-reply"
+	// DefaultLvmdReplyTokenFile is the projected service account token of the csi-plugin of the node
+	DefaultLvmdReplyTokenFile = "/var/run/secrets/open-local/lvmd-reply/token"
+	// DefaultLvmdRequestTokenExpiration is the lifetime in seconds of the token of a LvmdRequest, the minimum allowed
+	DefaultLvmdRequestTokenExpiration = 600
+
+	// CSIStorageCapacity objects published by controller for every node and storage class
+	LabelCapacityDriverName = "csi.storage.k8s.io/drivername"
+	LabelCapacityManagedBy  = "csi.storage.k8s.io/managed-by"
+	CapacityManagedBy       = "open-local-controller"
+	CapacityNamePrefix      = "open-local-"
+	DefaultCapacityVersion  = "v1"
+
+	// co-location and separation of the volumes of a pod, the values are group names shared by the pvcs of the pod:
+	// pvcs of a co-location group get the same VG, pvcs of a separation group get different VGs,
+	// and pvcs of a device separation group get VGs, mount points or devices on different disks
+	AnnotationVolumeColocation = "csi.aliyun.com/volume-colocation"
+	AnnotationVolumeSeparation = "csi.aliyun.com/volume-separation"
+	AnnotationDeviceSeparation = "csi.aliyun.com/device-separation"
+
+	// leader election of scheduler extenders
+	DefaultLeaderElectionName = "open-local-scheduler-extender"
+	// AnnotationBindingRecord of a pending pvc is the storage assumed for it by the leader extender,
+	// from which a new leader rebuilds the assumed state
+	AnnotationBindingRecord = "csi.aliyun.com/binding-record"
+
 	// lv tags
 	Lvm2LVNameTag        = "LVM2_LV_NAME"
 	Lvm2LVSizeTag        = "LVM2_LV_SIZE"
@@ -130,7 +234,12 @@ const (
 	Lvm2PVTagsTag = "LVM2_PV_TAGS"
 
 	// EVENT
-	EventCreateVGFailed = "CreateVGFailed"
+	EventCreateVGFailed   = "CreateVGFailed"
+	EventUpdateVGFailed   = "UpdateVGFailed"
+	EventPartitionFailed  = "PartitionFailed"
+	EventStorageDegraded  = "StorageDegraded"
+	EventStorageFailed    = "StorageFailed"
+	EventStorageRecovered = "StorageRecovered"
 
 	NsenterCmd = "/bin/nsenter --mount=/proc/1/ns/mnt --ipc=/proc/1/ns/ipc --net=/proc/1/ns/net --uts=/proc/1/ns/uts "
 )
@@ -146,8 +255,7 @@ var (
 		VolumeTypeDevice,
 		VolumeTypeQuota,
 	}
-	SupportedFS                    = []string{VolumeFSTypeExt3, VolumeFSTypeExt4, VolumeFSTypeXFS}
-	SchedulerStrategy StrategyType = StrategyBinpack
+	SupportedFS = []string{VolumeFSTypeExt3, VolumeFSTypeExt4, VolumeFSTypeXFS}
 )
 
 type UpdateStatus string
@@ -165,6 +273,12 @@ type StorageSpecUpdateStatus struct {
 	NewSpec  nodelocalstorage.NodeLocalStorageSpec `json:"newSpec"`
 }
 
+// LvmdRequestAudience returns the audience of the token of the LvmdRequest to nodeName with nonce,
+// so that the token can not be replayed to other nodes or requests
+func LvmdRequestAudience(nodeName, nonce string) string {
+	return fmt.Sprintf("%s/%s/%s", LvmdTokenAudience, nodeName, nonce)
+}
+
 func VolumeTypeFromString(s string) (VolumeType, error) {
 	for _, v := range ValidVolumeType {
 		if string(v) == s {
@@ -17,6 +17,7 @@ limitations under the License.
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,22 +25,27 @@ import (
 	"net/http"
 	"os/exec"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	localtype "github.com/alibaba/open-local/pkg"
 	nodelocalstorage "github.com/alibaba/open-local/pkg/apis/storage/v1alpha1"
 	csilib "github.com/container-storage-interface/spec/lib/go/csi"
+	volumesnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions/volumesnapshot/v1beta1"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	storagev1informers "k8s.io/client-go/informers/storage/v1"
+	"k8s.io/client-go/kubernetes"
 	schedulerapi "k8s.io/kube-scheduler/extender/v1"
 	hashutil "k8s.io/kubernetes/pkg/util/hash"
 	k8svol "k8s.io/kubernetes/pkg/volume"
@@ -168,11 +174,26 @@ func GetMountPointFromCsiPV(pv *corev1.PersistentVolume) string {
 	return ""
 }
 
-func GetVGRequested(localPVs map[string]corev1.PersistentVolume, vgName string) (requested int64) {
+// GetQuotaMountPointFromCsiPV extracts the mount point of a Quota volume
+// from open-local csi PV via VolumeAttributes
+func GetQuotaMountPointFromCsiPV(pv *corev1.PersistentVolume) string {
+	csi := pv.Spec.CSI
+	if csi == nil {
+		return ""
+	}
+	if v, ok := csi.VolumeAttributes[string(localtype.VolumeTypeQuota)]; ok {
+		return v
+	}
+	log.Debugf("PV %s has no csi volumeAttributes %q", pv.Name, "quota")
+
+	return ""
+}
+
+func GetVGRequested(localPVs map[string]corev1.PersistentVolume, vgName string, thin bool) (requested int64) {
 	requested = 0
 	for _, pv := range localPVs {
 		vgNameFromPV := GetVGNameFromCsiPV(&pv)
-		if vgNameFromPV == vgName {
+		if vgNameFromPV == vgName && IsThinLVMPV(&pv) == thin {
 			v := pv.Spec.Capacity[corev1.ResourceStorage]
 			requested += v.Value()
 			log.Debugf("size of pv(%s) from VG(%s) is %d", pv.Name, vgNameFromPV, requested)
@@ -184,6 +205,48 @@ func GetVGRequested(localPVs map[string]corev1.PersistentVolume, vgName string)
 	return requested
 }
 
+// GetQuotaRequested returns the total size of Quota PVs carved from mount point mpName
+func GetQuotaRequested(localPVs map[string]corev1.PersistentVolume, mpName string) (requested int64) {
+	requested = 0
+	for _, pv := range localPVs {
+		if GetQuotaMountPointFromCsiPV(&pv) == mpName {
+			v := pv.Spec.Capacity[corev1.ResourceStorage]
+			requested += v.Value()
+		}
+	}
+	log.Debugf("requested size of quota mount point %s is %d", mpName, requested)
+	return requested
+}
+
+// IsQuotaMountPoint tells whether the mount point is shared by Quota volumes, which requires it to
+// match one of the quota patterns of ListConfig and to be mounted with project quota enabled
+func IsQuotaMountPoint(mp *nodelocalstorage.MountPoint, quota []string) bool {
+	if mp == nil {
+		return false
+	}
+	matched := false
+	for _, pattern := range quota {
+		reg, err := regexp.Compile(pattern)
+		if err != nil {
+			log.Warningf("invalid quota mount point pattern %q: %s", pattern, err.Error())
+			continue
+		}
+		if reg.FindString(mp.Name) == mp.Name {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	for _, opt := range mp.Options {
+		if opt == localtype.MountOptionPrjQuota || opt == localtype.MountOptionPQuota {
+			return true
+		}
+	}
+	return false
+}
+
 //CheckDiskOptions excludes mp which is readyonly or with unsupported fs type
 func CheckMountPointOptions(mp *nodelocalstorage.MountPoint) bool {
 	if mp == nil {
@@ -372,7 +435,38 @@ func GetMediaTypeFromPVC(pvc *corev1.PersistentVolumeClaim, p storagev1informers
 	return localtype.MediaType(mediaType)
 }
 
-func IsLocalPVC(claim *corev1.PersistentVolumeClaim, p storagev1informers.Interface, containReadonlySnapshot bool) (bool, localtype.VolumeType) {
+// IsThinLVMPVC returns true if the pvc requests a thin provisioned LVM volume
+func IsThinLVMPVC(pvc *corev1.PersistentVolumeClaim, p storagev1informers.Interface) bool {
+	sc := GetStorageClassFromPVC(pvc, p)
+	if sc == nil {
+		return false
+	}
+	return sc.Parameters[localtype.ParamLVMType] == localtype.LVMTypeThin
+}
+
+// IsThinLVMPV returns true if the pv is a thin provisioned LVM volume
+func IsThinLVMPV(pv *corev1.PersistentVolume) bool {
+	if pv.Spec.CSI == nil {
+		return false
+	}
+	return pv.Spec.CSI.VolumeAttributes[localtype.ParamLVMType] == localtype.LVMTypeThin
+}
+
+// GetThinPoolPercent returns the percentage of the free size of VG that the thin pool is created with,
+// which is taken from the parameters of storage class
+func GetThinPoolPercent(parameters map[string]string) (uint32, error) {
+	value, ok := parameters[localtype.ParamThinPoolPercent]
+	if !ok {
+		return localtype.DefaultThinPoolPercent, nil
+	}
+	percent, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || percent == 0 || percent > 100 {
+		return 0, fmt.Errorf("%s must be an integer in (0, 100], current value is %q", localtype.ParamThinPoolPercent, value)
+	}
+	return uint32(percent), nil
+}
+
+func IsLocalPVC(claim *corev1.PersistentVolumeClaim, p storagev1informers.Interface, s volumesnapshotinformers.Interface, containReadonlySnapshot bool) (bool, localtype.VolumeType) {
 	sc := GetStorageClassFromPVC(claim, p)
 	if sc == nil {
 		return false, ""
@@ -380,7 +474,7 @@ func IsLocalPVC(claim *corev1.PersistentVolumeClaim, p storagev1informers.Interf
 	if !ContainsProvisioner(sc.Provisioner) {
 		return false, ""
 	}
-	if IsLocalSnapshotPVC(claim) && !containReadonlySnapshot {
+	if IsLocalSnapshotPVC(claim, s) && !containReadonlySnapshot {
 		return false, ""
 	}
 	return true, LocalPVType(sc)
@@ -415,8 +509,29 @@ func IsOpenLocalPV(pv *corev1.PersistentVolume, p storagev1informers.Interface,
 	return false, localtype.VolumeTypeUnknown
 }
 
-func IsLocalSnapshotPVC(claim *corev1.PersistentVolumeClaim) bool {
-	return IsSnapshotPVC(claim)
+// IsLocalSnapshotPVC returns true if the pvc mounts a readonly snapshot, which needs no storage allocated.
+// Pvc restored from a snapshot of a class without readonly parameter is a writable lvm volume
+func IsLocalSnapshotPVC(claim *corev1.PersistentVolumeClaim, s volumesnapshotinformers.Interface) bool {
+	if !IsSnapshotPVC(claim) {
+		return false
+	}
+	if s == nil {
+		return true
+	}
+	snapshot, err := s.VolumeSnapshots().Lister().VolumeSnapshots(claim.Namespace).Get(claim.Spec.DataSource.Name)
+	if err != nil {
+		log.Warningf("[IsLocalSnapshotPVC]get snapshot %s/%s failed: %s", claim.Namespace, claim.Spec.DataSource.Name, err.Error())
+		return true
+	}
+	if snapshot.Spec.VolumeSnapshotClassName == nil {
+		return true
+	}
+	class, err := s.VolumeSnapshotClasses().Lister().Get(*snapshot.Spec.VolumeSnapshotClassName)
+	if err != nil {
+		log.Warningf("[IsLocalSnapshotPVC]get snapshot class %s failed: %s", *snapshot.Spec.VolumeSnapshotClassName, err.Error())
+		return true
+	}
+	return class.Parameters[localtype.ParamSnapshotReadonly] == "true"
 }
 
 func IsSnapshotPVC(claim *corev1.PersistentVolumeClaim) bool {
@@ -427,6 +542,11 @@ func IsSnapshotPVC(claim *corev1.PersistentVolumeClaim) bool {
 	return false
 }
 
+// IsClonePVC returns true if the data source of the pvc is another pvc
+func IsClonePVC(claim *corev1.PersistentVolumeClaim) bool {
+	return claim.Spec.DataSource != nil && claim.Spec.DataSource.Kind == "PersistentVolumeClaim"
+}
+
 func ContainsSnapshotPVC(claims []*corev1.PersistentVolumeClaim) (contain bool) {
 	contain = false
 	for _, claim := range claims {
@@ -695,3 +815,48 @@ func NeedSkip(args schedulerapi.ExtenderArgs) bool {
 
 	return true
 }
+
+// UpdatePVCCondition sets the condition of conditionType in the status of the pvc, or removes it if condition is nil.
+// The transition time is kept if the status of the condition is unchanged.
+func UpdatePVCCondition(client kubernetes.Interface, namespace, name string, conditionType corev1.PersistentVolumeClaimConditionType, condition *corev1.PersistentVolumeClaimCondition) error {
+	var err error
+	pvcClient := client.CoreV1().PersistentVolumeClaims(namespace)
+	for i := 0; i < 5; i++ {
+		pvc, getErr := pvcClient.Get(context.Background(), name, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		conditions := []corev1.PersistentVolumeClaimCondition{}
+		var existing *corev1.PersistentVolumeClaimCondition
+		for i := range pvc.Status.Conditions {
+			if pvc.Status.Conditions[i].Type == conditionType {
+				existing = &pvc.Status.Conditions[i]
+				continue
+			}
+			conditions = append(conditions, pvc.Status.Conditions[i])
+		}
+		if condition == nil && existing == nil {
+			return nil
+		}
+		if condition != nil {
+			if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+				return nil
+			}
+			newCondition := *condition
+			newCondition.Type = conditionType
+			newCondition.LastProbeTime = metav1.Now()
+			if existing != nil && existing.Status == condition.Status {
+				newCondition.LastTransitionTime = existing.LastTransitionTime
+			} else {
+				newCondition.LastTransitionTime = metav1.Now()
+			}
+			conditions = append(conditions, newCondition)
+		}
+		pvcCopy := pvc.DeepCopy()
+		pvcCopy.Status.Conditions = conditions
+		if _, err = pvcClient.UpdateStatus(context.Background(), pvcCopy, metav1.UpdateOptions{}); err == nil || !apierrors.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
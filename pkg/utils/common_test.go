@@ -0,0 +1,109 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	snapshotapi "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	snapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	snapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIsLocalSnapshotPVC(t *testing.T) {
+	informers := snapshotinformers.NewSharedInformerFactory(snapshotfake.NewSimpleClientset(), 0).Snapshot().V1beta1()
+	roClass, rwClass := "ro-class", "rw-class"
+	objects := []interface{}{
+		&snapshotapi.VolumeSnapshotClass{ObjectMeta: metav1.ObjectMeta{Name: roClass}, Parameters: map[string]string{localtype.ParamSnapshotReadonly: "true"}},
+		&snapshotapi.VolumeSnapshotClass{ObjectMeta: metav1.ObjectMeta{Name: rwClass}},
+	}
+	for _, obj := range objects {
+		if err := informers.VolumeSnapshotClasses().Informer().GetIndexer().Add(obj); err != nil {
+			t.Fatalf("fail to add snapshot class: %s", err.Error())
+		}
+	}
+	for name, class := range map[string]string{"ro-snap": roClass, "rw-snap": rwClass} {
+		className := class
+		snapshot := &snapshotapi.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       snapshotapi.VolumeSnapshotSpec{VolumeSnapshotClassName: &className},
+		}
+		if err := informers.VolumeSnapshots().Informer().GetIndexer().Add(snapshot); err != nil {
+			t.Fatalf("fail to add snapshot: %s", err.Error())
+		}
+	}
+	pvcFrom := func(kind, name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{DataSource: &corev1.TypedLocalObjectReference{Kind: kind, Name: name}},
+		}
+	}
+
+	cases := []struct {
+		pvc    *corev1.PersistentVolumeClaim
+		expect bool
+	}{
+		{pvcFrom("VolumeSnapshot", "ro-snap"), true},
+		{pvcFrom("VolumeSnapshot", "rw-snap"), false},
+		// unknown snapshot is treated as readonly
+		{pvcFrom("VolumeSnapshot", "no-snap"), true},
+		{pvcFrom("PersistentVolumeClaim", "src"), false},
+	}
+	for _, c := range cases {
+		if got := IsLocalSnapshotPVC(c.pvc, informers); got != c.expect {
+			t.Errorf("IsLocalSnapshotPVC of pvc from %s %s: expect %t, got %t", c.pvc.Spec.DataSource.Kind, c.pvc.Spec.DataSource.Name, c.expect, got)
+		}
+	}
+}
+
+func TestUpdatePVCCondition(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Conditions: []corev1.PersistentVolumeClaimCondition{{Type: corev1.PersistentVolumeClaimResizing, Status: corev1.ConditionTrue}},
+		},
+	}
+	client := fake.NewSimpleClientset(pvc)
+	getConditions := func() []corev1.PersistentVolumeClaimCondition {
+		pvc, err := client.CoreV1().PersistentVolumeClaims("default").Get(context.Background(), "pvc", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("fail to get pvc: %s", err.Error())
+		}
+		return pvc.Status.Conditions
+	}
+
+	failed := &corev1.PersistentVolumeClaimCondition{Status: corev1.ConditionTrue, Reason: localtype.ReasonNodeExpandFailed, Message: "resize2fs failed"}
+	if err := UpdatePVCCondition(client, "default", "pvc", localtype.PVCConditionVolumeResizeFailed, failed); err != nil {
+		t.Fatalf("fail to set condition: %s", err.Error())
+	}
+	conditions := getConditions()
+	if len(conditions) != 2 || conditions[1].Type != localtype.PVCConditionVolumeResizeFailed || conditions[1].Reason != localtype.ReasonNodeExpandFailed {
+		t.Fatalf("unexpected conditions %v", conditions)
+	}
+
+	if err := UpdatePVCCondition(client, "default", "pvc", localtype.PVCConditionVolumeResizeFailed, nil); err != nil {
+		t.Fatalf("fail to remove condition: %s", err.Error())
+	}
+	if conditions := getConditions(); len(conditions) != 1 || conditions[0].Type != corev1.PersistentVolumeClaimResizing {
+		t.Fatalf("unexpected conditions %v", conditions)
+	}
+}
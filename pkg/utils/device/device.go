@@ -19,6 +19,7 @@ package device
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -75,6 +76,10 @@ func GetBlockInfo(sysPath, blockName string) (Device, error) {
 	device.MediaType = media
 	device.Total = total
 	device.ReadOnly = ro
+	// Identity
+	device.Serial = getOptionalFileContext(filepath.Join(blockPath, "serial"), filepath.Join(blockPath, "device/serial"))
+	device.WWN = getOptionalFileContext(filepath.Join(blockPath, "wwid"), filepath.Join(blockPath, "device/wwid"))
+	device.Model = getOptionalFileContext(filepath.Join(blockPath, "device/model"))
 
 	return device, nil
 }
@@ -129,8 +134,12 @@ func GetPartitionsInfo(sysPath, blockName string) ([]Device, error) {
 			}
 			total = uint64(datatmp) * 512
 
+			// PartLabel
+			device.PartLabel = getUeventValue(filepath.Join(blockPath, partName, "uevent"), "PARTNAME")
+
 			device.Name = fmt.Sprintf("/dev/%s", partName)
 			device.IsPartition = true
+			device.ParentName = fmt.Sprintf("/dev/%s", blockName)
 			device.MediaType = media
 			device.Total = total
 			device.ReadOnly = ro
@@ -141,6 +150,84 @@ func GetPartitionsInfo(sysPath, blockName string) ([]Device, error) {
 	return devices, nil
 }
 
+// GetMediaType returns the media type of the block device, e.g. /dev/sdb1 or /dev/mapper/vg-lv,
+// which is read from the rotational attribute of its disk in sysfs
+func GetMediaType(sysPath, devicePath string) (string, error) {
+	if realPath, err := filepath.EvalSymlinks(devicePath); err == nil {
+		devicePath = realPath
+	}
+	blockPath, err := filepath.EvalSymlinks(filepath.Join(sysPath, "class/block", filepath.Base(devicePath)))
+	if err != nil {
+		return "", fmt.Errorf("block device %s not found in sysfs: %s", devicePath, err.Error())
+	}
+	// partitions are sub directories of their disks
+	if _, err := os.Stat(filepath.Join(blockPath, "partition")); err == nil {
+		blockPath = filepath.Dir(blockPath)
+	}
+	data, err := getFileContext(filepath.Join(blockPath, "queue/rotational"))
+	if err != nil {
+		return "", err
+	}
+	if data == "1" {
+		return string(localtype.MediaTypeHDD), nil
+	}
+	return string(localtype.MediaTypeSSD), nil
+}
+
+// GetByIDLinks returns the by-id links of the block devices in byIDPath, e.g. /dev/disk/by-id,
+// the wwn link is preferred if a device has several links
+func GetByIDLinks(byIDPath string) (map[string]string, error) {
+	files, err := ioutil.ReadDir(byIDPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	links := make(map[string]string)
+	for _, file := range files {
+		target, err := os.Readlink(filepath.Join(byIDPath, file.Name()))
+		if err != nil {
+			continue
+		}
+		name := fmt.Sprintf("/dev/%s", filepath.Base(target))
+		link := filepath.Join(byIDPath, file.Name())
+		if old, ok := links[name]; ok && !preferByIDLink(link, old) {
+			continue
+		}
+		links[name] = link
+	}
+	return links, nil
+}
+
+func preferByIDLink(link, old string) bool {
+	isWWN, oldIsWWN := strings.HasPrefix(filepath.Base(link), "wwn-"), strings.HasPrefix(filepath.Base(old), "wwn-")
+	if isWWN != oldIsWWN {
+		return isWWN
+	}
+	return link < old
+}
+
+// getUeventValue returns the value of the key in the uevent file in sysfs, whose lines are KEY=value
+func getUeventValue(filePath, key string) string {
+	for _, line := range strings.Split(getOptionalFileContext(filePath), "\n") {
+		if strings.HasPrefix(line, key+"=") {
+			return strings.TrimPrefix(line, key+"=")
+		}
+	}
+	return ""
+}
+
+// getOptionalFileContext returns the trimmed content of the first existing file, empty if none exists
+func getOptionalFileContext(filePaths ...string) string {
+	for _, filePath := range filePaths {
+		if b, err := ioutil.ReadFile(filePath); err == nil {
+			return strings.TrimSpace(string(b))
+		}
+	}
+	return ""
+}
+
 func getFileContext(filePath string) (string, error) {
 	b, err := ioutil.ReadFile(filePath)
 	if err != nil {
@@ -16,11 +16,25 @@ limitations under the License.
 
 package device
 
+// ByIDPath is where udev creates the stable links of block devices
+const ByIDPath = "/dev/disk/by-id"
+
+// ByPartLabelPath is where udev creates the links of GPT partitions by their names
+const ByPartLabelPath = "/dev/disk/by-partlabel"
+
 // Device contains the necessary information of the block device
 type Device struct {
 	Name        string
 	IsPartition bool
-	ReadOnly    bool
-	MediaType   string
-	Total       uint64
+	// ParentName is the disk of the partition
+	ParentName string
+	ReadOnly   bool
+	MediaType  string
+	Total      uint64
+	// Serial, WWN and Model are read from sysfs for disks
+	Serial string
+	WWN    string
+	Model  string
+	// PartLabel is the name of the GPT partition
+	PartLabel string
 }
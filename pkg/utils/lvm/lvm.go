@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 
 	localtype "github.com/alibaba/open-local/pkg"
@@ -75,6 +76,19 @@ func (pv *PhysicalVolume) Remove() error {
 	return nil
 }
 
+// SetAllocatable allows or disallows the allocation of extents on the physical volume.
+func (pv *PhysicalVolume) SetAllocatable(allocatable bool) error {
+	value := "n"
+	if allocatable {
+		value = "y"
+	}
+	if err := run("pvchange", nil, "--allocatable", value, pv.dev); err != nil {
+		log.Errorf("pv change error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
 // Check runs the pvck command on the physical volume.
 func (pv *PhysicalVolume) Check() error {
 	if err := run("pvck", nil, pv.dev); err != nil {
@@ -315,6 +329,62 @@ func (vg *VolumeGroup) ListLogicalVolumeNames() ([]string, error) {
 	return names, nil
 }
 
+type thinPoolOutput struct {
+	Report []struct {
+		Lv []struct {
+			Name            string `json:"lv_name"`
+			VgName          string `json:"vg_name"`
+			LvSize          uint64 `json:"lv_size,string"`
+			Segtype         string `json:"segtype"`
+			PoolLv          string `json:"pool_lv"`
+			DataPercent     string `json:"data_percent"`
+			MetadataSize    string `json:"lv_metadata_size"`
+			MetadataPercent string `json:"metadata_percent"`
+		} `json:"lv"`
+	} `json:"report"`
+}
+
+// LookupThinPool looks up the thin pool in the volume group
+// with the given name.
+func (vg *VolumeGroup) LookupThinPool(name string) (*ThinPool, error) {
+	result := new(thinPoolOutput)
+	if err := run("lvs", result, "--options=lv_name,vg_name,lv_size,segtype,pool_lv,data_percent,lv_metadata_size,metadata_percent", vg.name); err != nil {
+		log.Errorf("LookupThinPool error: %s", err.Error())
+		return nil, err
+	}
+	var pool *ThinPool
+	var virtualSize uint64
+	for _, report := range result.Report {
+		for _, lv := range report.Lv {
+			if lv.VgName != vg.name {
+				continue
+			}
+			if lv.PoolLv == name {
+				virtualSize += lv.LvSize
+				continue
+			}
+			if lv.Name != name || lv.Segtype != "thin-pool" {
+				continue
+			}
+			dataPercent, _ := strconv.ParseFloat(lv.DataPercent, 64)
+			metadataPercent, _ := strconv.ParseFloat(lv.MetadataPercent, 64)
+			metadataSize, _ := strconv.ParseUint(lv.MetadataSize, 10, 64)
+			pool = &ThinPool{
+				name:                lv.Name,
+				sizeInBytes:         lv.LvSize,
+				metadataSizeInBytes: metadataSize,
+				dataUsage:           dataPercent / 100,
+				metadataUsage:       metadataPercent / 100,
+			}
+		}
+	}
+	if pool == nil {
+		return nil, ErrLogicalVolumeNotFound
+	}
+	pool.virtualSizeInBytes = virtualSize
+	return pool, nil
+}
+
 func IsPhysicalVolumeNotFound(err error) bool {
 	return isPhysicalVolumeNotFound(err) ||
 		isNoPhysicalVolumeLabel(err)
@@ -390,6 +460,111 @@ func (vg *VolumeGroup) Remove() error {
 	return nil
 }
 
+// Extend adds the physical volumes to the volume group.
+func (vg *VolumeGroup) Extend(pvs []*PhysicalVolume) error {
+	args := []string{vg.name}
+	for _, pv := range pvs {
+		args = append(args, pv.dev)
+	}
+	if err := run("vgextend", nil, args...); err != nil {
+		log.Errorf("volume group Extend error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Reduce removes the physical volume, which has no extents allocated, from the volume group.
+func (vg *VolumeGroup) Reduce(dev string) error {
+	if err := run("vgreduce", nil, vg.name, dev); err != nil {
+		log.Errorf("volume group Reduce error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// MovePhysicalVolume starts to move the allocated extents of the physical volume
+// to the destination physical volumes in the background.
+func (vg *VolumeGroup) MovePhysicalVolume(dev string, dests []string) error {
+	args := append([]string{"--background", dev}, dests...)
+	if err := run("pvmove", nil, args...); err != nil {
+		log.Errorf("MovePhysicalVolume error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+type pvmoveOutput struct {
+	Report []struct {
+		Lv []struct {
+			Name        string `json:"lv_name"`
+			VgName      string `json:"vg_name"`
+			LvAttr      string `json:"lv_attr"`
+			CopyPercent string `json:"copy_percent"`
+		} `json:"lv"`
+	} `json:"report"`
+}
+
+// MoveProgress returns whether a pvmove is running in the volume group, and the percentage it has copied.
+func (vg *VolumeGroup) MoveProgress() (bool, float64, error) {
+	result := new(pvmoveOutput)
+	if err := run("lvs", result, "--all", "--options=lv_name,vg_name,lv_attr,copy_percent", vg.name); err != nil {
+		log.Errorf("MoveProgress error: %s", err.Error())
+		return false, 0, err
+	}
+	for _, report := range result.Report {
+		for _, lv := range report.Lv {
+			// the temporary logical volume of pvmove has the attribute 'p'
+			if lv.VgName == vg.name && strings.HasPrefix(lv.LvAttr, "p") {
+				percent, _ := strconv.ParseFloat(lv.CopyPercent, 64)
+				return true, percent, nil
+			}
+		}
+	}
+	return false, 0, nil
+}
+
+// PhysicalVolumeUsage is the size and the free space in bytes of a physical volume.
+type PhysicalVolumeUsage struct {
+	Size uint64
+	Free uint64
+	// Allocatable is false if new extents can not be allocated on the physical volume
+	Allocatable bool
+}
+
+type pvsUsageOutput struct {
+	Report []struct {
+		Pv []struct {
+			Name   string `json:"pv_name"`
+			VgName string `json:"vg_name"`
+			PvAttr string `json:"pv_attr"`
+			PvSize uint64 `json:"pv_size,string"`
+			PvFree uint64 `json:"pv_free,string"`
+		} `json:"pv"`
+	} `json:"report"`
+}
+
+// PhysicalVolumeUsages returns the usages of the physical volumes in this volume group by their names.
+func (vg *VolumeGroup) PhysicalVolumeUsages() (map[string]PhysicalVolumeUsage, error) {
+	result := new(pvsUsageOutput)
+	if err := run("pvs", result, "--options=pv_name,vg_name,pv_attr,pv_size,pv_free"); err != nil {
+		log.Errorf("PhysicalVolumeUsages error: %s", err.Error())
+		return nil, err
+	}
+	usages := make(map[string]PhysicalVolumeUsage)
+	for _, report := range result.Report {
+		for _, pv := range report.Pv {
+			if pv.VgName == vg.name {
+				usages[pv.Name] = PhysicalVolumeUsage{
+					Size:        pv.PvSize,
+					Free:        pv.PvFree,
+					Allocatable: strings.HasPrefix(pv.PvAttr, "a"),
+				}
+			}
+		}
+	}
+	return usages, nil
+}
+
 type LogicalVolume struct {
 	name           string
 	sizeInBytes    uint64
@@ -457,6 +632,42 @@ func (lv *LogicalVolume) Expand(size uint64) error {
 	return nil
 }
 
+type ThinPool struct {
+	name                string
+	sizeInBytes         uint64
+	metadataSizeInBytes uint64
+	dataUsage           float64
+	metadataUsage       float64
+	virtualSizeInBytes  uint64
+}
+
+func (tp *ThinPool) Name() string {
+	return tp.name
+}
+
+func (tp *ThinPool) SizeInBytes() uint64 {
+	return tp.sizeInBytes
+}
+
+func (tp *ThinPool) MetadataSizeInBytes() uint64 {
+	return tp.metadataSizeInBytes
+}
+
+// DataUsage returns the used ratio of the data LV
+func (tp *ThinPool) DataUsage() float64 {
+	return tp.dataUsage
+}
+
+// MetadataUsage returns the used ratio of the metadata LV
+func (tp *ThinPool) MetadataUsage() float64 {
+	return tp.metadataUsage
+}
+
+// VirtualSizeInBytes returns the sum of virtual size of thin LVs in the pool
+func (tp *ThinPool) VirtualSizeInBytes() uint64 {
+	return tp.virtualSizeInBytes
+}
+
 // PVScan runs the `pvscan --cache <dev>` command. It scans for the
 // device at `dev` and adds it to the LVM metadata cache if `lvmetad`
 // is running. If `dev` is an empty string, it scans all devices.
@@ -0,0 +1,155 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partition
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	localtype "github.com/alibaba/open-local/pkg"
+	log "github.com/sirupsen/logrus"
+)
+
+// MiB is the unit of the partition sizes, which keeps partitions aligned
+const MiB uint64 = 1 << 20
+
+// Table is the partition table of a disk
+type Table struct {
+	// Label is the type of the partition table, e.g. gpt or dos
+	Label      string      `json:"label"`
+	Partitions []Partition `json:"partitions"`
+}
+
+// Partition is a partition in the partition table, whose start and size are in sectors
+type Partition struct {
+	Node  string `json:"node"`
+	Start uint64 `json:"start"`
+	Size  uint64 `json:"size"`
+	Name  string `json:"name"`
+}
+
+// Spec is a GPT partition to be created, the partition takes the rest of the disk if Size is zero
+type Spec struct {
+	Name string
+	// Size is the size in bytes, which is rounded down to MiB
+	Size uint64
+}
+
+type sfdiskOutput struct {
+	PartitionTable *Table `json:"partitiontable"`
+}
+
+// ReadTable returns the partition table of the disk, nil is returned if the disk has no partition table
+func ReadTable(disk string) (*Table, error) {
+	out, err := run("sfdisk", nil, "--json", disk)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not contain a recognized partition table") {
+			return nil, nil
+		}
+		log.Errorf("ReadTable error: %s", err.Error())
+		return nil, err
+	}
+	return parseTable(out)
+}
+
+func parseTable(out []byte) (*Table, error) {
+	result := new(sfdiskOutput)
+	if err := json.Unmarshal(out, result); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %s", err.Error())
+	}
+	return result.PartitionTable, nil
+}
+
+// Signatures returns the types of the signatures on the disk, e.g. ext4, LVM2_member or gpt
+func Signatures(disk string) ([]string, error) {
+	out, err := run("wipefs", nil, "--parsable", disk)
+	if err != nil {
+		log.Errorf("Signatures error: %s", err.Error())
+		return nil, err
+	}
+	return parseSignatures(string(out)), nil
+}
+
+// parseSignatures parses the output of wipefs --parsable, whose lines are offset,uuid,label,type
+func parseSignatures(out string) []string {
+	var types []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		types = append(types, fields[len(fields)-1])
+	}
+	return types
+}
+
+// Create creates the partitions on the disk, they are appended to the existing GPT partition table
+// if appendOnly is set, otherwise a new GPT partition table is created. Signatures on the disk are
+// wiped if wipe is set, and signatures on the new partitions are always wiped.
+func Create(disk string, specs []Spec, appendOnly, wipe bool) error {
+	args := []string{"--wipe-partitions", "always"}
+	if appendOnly {
+		args = append(args, "--append")
+	}
+	if wipe {
+		args = append(args, "--wipe", "always")
+	}
+	args = append(args, disk)
+	if _, err := run("sfdisk", strings.NewReader(Script(specs, appendOnly)), args...); err != nil {
+		log.Errorf("Create partitions error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Script returns the sfdisk script creating the partitions
+func Script(specs []Spec, appendOnly bool) string {
+	var lines []string
+	if !appendOnly {
+		lines = append(lines, "label: gpt")
+	}
+	for _, spec := range specs {
+		line := fmt.Sprintf("name=%q", spec.Name)
+		if spec.Size > 0 {
+			line = fmt.Sprintf("%s, size=%dMiB", line, spec.Size/MiB)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func run(cmd string, stdin *strings.Reader, extraArgs ...string) ([]byte, error) {
+	args := append([]string{fmt.Sprintf("%s %s", localtype.NsenterCmd, cmd)}, extraArgs...)
+	c := exec.Command("sh", "-c", strings.Join(args, " "))
+	if stdin != nil {
+		c.Stdin = stdin
+	}
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		log.Debugf("[debug run]: command %s", c.String())
+		log.Debugf("[debug run]: error %s", err.Error())
+		return nil, errors.New(strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
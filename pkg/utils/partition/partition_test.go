@@ -0,0 +1,73 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partition
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTable(t *testing.T) {
+	out := []byte(`{
+   "partitiontable": {
+      "label": "gpt",
+      "id": "9F0A1C2E-6D0B-4B5E-9C4A-2F7E3C1D5A6B",
+      "device": "/dev/nvme0n1",
+      "unit": "sectors",
+      "firstlba": 2048,
+      "lastlba": 3907029134,
+      "partitions": [
+         {"node": "/dev/nvme0n1p1", "start": 2048, "size": 209715200, "type": "0FC63DAF-8483-4772-8E79-3D69D8477DE4", "uuid": "5C1E2A3B-1111-4C2D-8E3F-000000000001", "name": "open-local-pool-0"},
+         {"node": "/dev/nvme0n1p2", "start": 209717248, "size": 104857600, "type": "0FC63DAF-8483-4772-8E79-3D69D8477DE4", "uuid": "5C1E2A3B-1111-4C2D-8E3F-000000000002", "name": "mnt-0"}
+      ]
+   }
+}`)
+	table, err := parseTable(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Table{
+		Label: "gpt",
+		Partitions: []Partition{
+			{Node: "/dev/nvme0n1p1", Start: 2048, Size: 209715200, Name: "open-local-pool-0"},
+			{Node: "/dev/nvme0n1p2", Start: 209717248, Size: 104857600, Name: "mnt-0"},
+		},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("parseTable() = %+v, want %+v", table, want)
+	}
+}
+
+func TestParseSignatures(t *testing.T) {
+	out := "# offset,uuid,label,type\n0x218,Y2fn3c-0Hlr-uGsM-OEGl-tqxI-tEqp-2kE3bC,,LVM2_member\n0x438,1b1c5a4e-8f0c-4c1e-9d0a-4a3b2c1d0e9f,data,ext4\n"
+	if got, want := parseSignatures(out), []string{"LVM2_member", "ext4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSignatures() = %v, want %v", got, want)
+	}
+	if got := parseSignatures(""); len(got) != 0 {
+		t.Errorf("expect no signatures, got %v", got)
+	}
+}
+
+func TestScript(t *testing.T) {
+	specs := []Spec{{Name: "open-local-pool-0", Size: 100 << 30}, {Name: "raw-0"}}
+	if got, want := Script(specs, false), "label: gpt\nname=\"open-local-pool-0\", size=102400MiB\nname=\"raw-0\"\n"; got != want {
+		t.Errorf("Script() = %q, want %q", got, want)
+	}
+	if got, want := Script(specs[1:], true), "name=\"raw-0\"\n"; got != want {
+		t.Errorf("Script() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2021 Alibaba Group Holding Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TLSOptions is the key pair and the ca used to set up mutual tls
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Enabled returns true if all the files are set
+func (opt *TLSOptions) Enabled() bool {
+	return opt != nil && opt.CertFile != "" && opt.KeyFile != "" && opt.CAFile != ""
+}
+
+// TLSReloader loads the key pair and the ca from files and reloads them once the files change,
+// so that the certificates can be rotated without restart
+type TLSReloader struct {
+	opt      TLSOptions
+	lock     sync.Mutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewTLSReloader returns a TLSReloader of opt
+func NewTLSReloader(opt TLSOptions) *TLSReloader {
+	return &TLSReloader{
+		opt:      opt,
+		modTimes: map[string]time.Time{},
+	}
+}
+
+// Load returns the current key pair and ca pool, the files are reloaded if any of them changes.
+// The certificates loaded before are kept if the reloading fails, e.g. a secret being updated
+func (r *TLSReloader) Load() (*tls.Certificate, *x509.CertPool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	changed := r.cert == nil
+	modTimes := map[string]time.Time{}
+	for _, file := range []string{r.opt.CertFile, r.opt.KeyFile, r.opt.CAFile} {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return r.keepOrFail(fmt.Errorf("failed to stat %s: %v", file, err))
+		}
+		modTimes[file] = fi.ModTime()
+		if !fi.ModTime().Equal(r.modTimes[file]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return r.cert, r.pool, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.opt.CertFile, r.opt.KeyFile)
+	if err != nil {
+		return r.keepOrFail(fmt.Errorf("failed to load key pair %s/%s: %v", r.opt.CertFile, r.opt.KeyFile, err))
+	}
+	ca, err := ioutil.ReadFile(r.opt.CAFile)
+	if err != nil {
+		return r.keepOrFail(fmt.Errorf("failed to read ca %s: %v", r.opt.CAFile, err))
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return r.keepOrFail(fmt.Errorf("no certificate found in ca %s", r.opt.CAFile))
+	}
+	if r.cert != nil {
+		log.Infof("reloaded tls certificates from %s", r.opt.CertFile)
+	}
+	r.cert, r.pool, r.modTimes = &cert, pool, modTimes
+	return r.cert, r.pool, nil
+}
+
+func (r *TLSReloader) keepOrFail(err error) (*tls.Certificate, *x509.CertPool, error) {
+	if r.cert == nil {
+		return nil, nil, err
+	}
+	log.Warningf("keep using the loaded tls certificates: %s", err.Error())
+	return r.cert, r.pool, nil
+}
+
+// ServerConfig returns the tls config which requires and verifies client certificates
+func (r *TLSReloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool, err := r.Load()
+			if err != nil {
+				return nil, err
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}
+}
+
+// ClientConfig returns the tls config which presents the client certificate and
+// verifies the server certificate against serverName
+func (r *TLSReloader) ClientConfig(serverName string) (*tls.Config, error) {
+	cert, pool, err := r.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		RootCAs:      pool,
+		ServerName:   serverName,
+	}, nil
+}